@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+// Command routegen generates the route table of the API server from the OpenAPI spec. It is run by 'go generate'
+// in pkg/server:
+//
+//	go run ../../cmd/routegen -spec ../openapi/spec-v1.json -out routes_gen.go
+package main
+
+import (
+	"apiserver/pkg/openapi"
+	"flag"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+)
+
+func main() {
+	specPath := flag.String("spec", "", "path of the OpenAPI spec")
+	out := flag.String("out", "routes_gen.go", "path of the generated file")
+	pkg := flag.String("package", openapi.DefaultRouteConfig.Package, "package of the generated file")
+	handlers := flag.String("handlers", openapi.DefaultRouteConfig.Handlers, "import path of the handlers package")
+	flag.Parse()
+
+	data, err := ioutil.ReadFile(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	spec, err := openapi.Parse(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := openapi.GenerateRoutes(spec, openapi.RouteConfig{Package: *pkg, Source: filepath.Base(*specPath), Handlers: *handlers})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+// Command sourcegen generates the table of the URLs the ingestion pipeline downloads each dataset from, so that
+// responses cite the same source as the pipeline configuration. It is run by 'go generate' in pkg/database/models:
+//
+//	go run ../../../cmd/sourcegen -sources ../../../../../pipeline/intake/sources -out sources_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// header is the license header of the generated file
+const header = `/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/`
+
+func main() {
+	dir := flag.String("sources", "", "path of the source directory of the ingestion pipeline")
+	out := flag.String("out", "sources_gen.go", "path of the generated file")
+	flag.Parse()
+
+	src, err := generate(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate returns the source of a Go file mapping the Id of each dataset configured in the source directory of the
+// ingestion pipeline to its 'source' URL. Each dataset is configured in '<id>/<id>_config.yml'.
+func generate(dir string) ([]byte, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "*_config.yml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no dataset is configured in '%v'", dir)
+	}
+	sort.Strings(paths)
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "%v\n\n// Code generated by sourcegen from pipeline/intake/sources. DO NOT EDIT.\n\npackage models\n\n", header)
+	src.WriteString("// sources maps the Id of each dataset to the URL the ingestion pipeline downloads it from.\n")
+	src.WriteString("var sources = map[string]string{\n")
+	for _, path := range paths {
+		id := filepath.Base(filepath.Dir(path))
+		if filepath.Base(path) != id+"_config.yml" {
+			return nil, fmt.Errorf("'%v' does not configure the dataset '%v' of its directory", path, id)
+		}
+
+		config := viper.New()
+		config.SetConfigFile(path)
+		config.SetConfigType("yaml")
+		if err := config.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("cannot read '%v': %v", path, err)
+		}
+		source := strings.TrimSpace(config.GetString("source"))
+		if source == "" {
+			return nil, fmt.Errorf("'%v' has no source", path)
+		}
+		fmt.Fprintf(&src, "\t%q: %q,\n", id, source)
+	}
+	src.WriteString("}\n")
+
+	return format.Source(src.Bytes())
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+// TestGenerated checks that the sources of the datasets were generated from the current pipeline configuration.
+func TestGenerated(t *testing.T) {
+	want, err := generate("../../../../pipeline/intake/sources")
+	if err != nil {
+		t.Fatalf("cannot generate the sources: %v", err)
+	}
+	got, err := ioutil.ReadFile("../../pkg/database/models/sources_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("sources_gen.go is out of date with the pipeline configuration, run 'go generate ./pkg/database/models'")
+	}
+}
+
+func TestGenerateMissingSource(t *testing.T) {
+	if _, err := generate(t.TempDir()); err == nil {
+		t.Error("Expected an error generating the sources of an empty directory, got nil.")
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package chart
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	// badgeCharWidth approximates the width of a character of 11px Verdana, the font badges are drawn in
+	badgeCharWidth = 7
+
+	// badgePadding is the horizontal space around the text of each half of a badge
+	badgePadding = 10
+
+	badgeHeight = 20
+)
+
+// Badge is a shields-style badge, made of a label on the left and a colored message on the right.
+type Badge struct {
+	Label   string
+	Message string
+
+	// Color is the background color of the message
+	Color string
+}
+
+// RenderBadge writes a badge as SVG. The width of each half is estimated from the number of characters it holds.
+func RenderBadge(w io.Writer, badge Badge) error {
+	if badge.Label == "" || badge.Message == "" {
+		return fmt.Errorf("badge needs both a label and a message")
+	}
+
+	labelWidth := badgeCharWidth*utf8.RuneCountInString(badge.Label) + badgePadding
+	messageWidth := badgeCharWidth*utf8.RuneCountInString(badge.Message) + badgePadding
+	width := labelWidth + messageWidth
+	title := escape(badge.Label + ": " + badge.Message)
+
+	buf := bufio.NewWriter(w)
+	p := func(format string, args ...interface{}) {
+		fmt.Fprintf(buf, format, args...)
+		buf.WriteByte('\n')
+	}
+
+	p(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" role="img" aria-label="%s">`, width, badgeHeight, title)
+	p(`<title>%s</title>`, title)
+	p(`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	p(`<clipPath id="r"><rect width="%d" height="%d" rx="3" fill="#fff"/></clipPath>`, width, badgeHeight)
+	p(`<g clip-path="url(#r)">`)
+	p(`<rect width="%d" height="%d" fill="#555"/>`, labelWidth, badgeHeight)
+	p(`<rect x="%d" width="%d" height="%d" fill="%s"/>`, labelWidth, messageWidth, badgeHeight, badge.Color)
+	p(`<rect width="%d" height="%d" fill="url(#s)"/>`, width, badgeHeight)
+	p(`</g>`)
+	p(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	for _, half := range []struct {
+		x    float64
+		text string
+	}{
+		{float64(labelWidth) / 2, escape(badge.Label)},
+		{float64(labelWidth) + float64(messageWidth)/2, escape(badge.Message)},
+	} {
+		// The text is drawn twice, the first time as a shadow
+		p(`<text x="%s" y="15" fill="#010101" fill-opacity=".3">%s</text>`, f(half.x), half.text)
+		p(`<text x="%s" y="14">%s</text>`, f(half.x), half.text)
+	}
+	p(`</g>`)
+	p(`</svg>`)
+	return buf.Flush()
+}
@@ -0,0 +1,357 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package chart
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	marginLeft   = 64
+	marginRight  = 24
+	marginTop    = 40
+	marginBottom = 36
+
+	// yTicks is the number of ticks the value axis aims for
+	yTicks = 6
+
+	// xTicks is the maximum number of ticks on the time axis
+	xTicks = 10
+)
+
+// Point is a single observation of a series. A NaN value marks a missing observation, which leaves a gap in the line.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series is a named line drawn on a chart.
+type Series struct {
+	Name   string
+	Points []Point
+
+	// Dashed draws the series with a dashed line. This is used for overlays such as trends.
+	Dashed bool
+}
+
+// Theme holds the colors used to draw a chart.
+type Theme struct {
+	Background string
+	Foreground string
+	Grid       string
+
+	// Colors are assigned to series in order
+	Colors []string
+}
+
+// Themes lists the themes a chart may be drawn with by name.
+var Themes = map[string]Theme{
+	"light": {Background: "#ffffff", Foreground: "#333333", Grid: "#e5e5e5", Colors: []string{"#1f77b4", "#d62728", "#2ca02c"}},
+	"dark":  {Background: "#1e1e1e", Foreground: "#dddddd", Grid: "#3a3a3a", Colors: []string{"#4fa3e0", "#ff7f50", "#7fd17f"}},
+}
+
+// Options configures the layout of a chart.
+type Options struct {
+	Title string
+
+	// Units labels the value axis, eg. 'ppm'
+	Units string
+
+	Width  int
+	Height int
+	Theme  Theme
+}
+
+// axis maps a range of data values onto a range of pixels.
+type axis struct {
+	min, max float64
+	from, to float64
+}
+
+func (a axis) scale(v float64) float64 {
+	return a.from + (v-a.min)/(a.max-a.min)*(a.to-a.from)
+}
+
+// tick is a labelled position on an axis.
+type tick struct {
+	value float64
+	label string
+}
+
+// Render writes an SVG line chart of the given series.
+func Render(w io.Writer, opts Options, series ...Series) error {
+	if opts.Width <= marginLeft+marginRight || opts.Height <= marginTop+marginBottom {
+		return fmt.Errorf("chart of size %vx%v is too small", opts.Width, opts.Height)
+	}
+	if len(opts.Theme.Colors) == 0 {
+		return fmt.Errorf("chart theme has no series colors")
+	}
+
+	// Points are drawn in chronological order regardless of how the series were queried
+	series = append([]Series(nil), series...)
+	for i := range series {
+		sorted := make([]Point, len(series[i].Points))
+		copy(sorted, series[i].Points)
+		sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Time.Before(sorted[b].Time) })
+		series[i].Points = sorted
+	}
+
+	tMin, tMax, vMin, vMax, ok := bounds(series)
+	if !ok {
+		// Draw empty axes over a fixed year, 1999-01-01 to 2000-01-01, when there is nothing to plot, so that empty
+		// charts render identically whenever they are drawn
+		tMax = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		tMin = tMax.AddDate(-1, 0, 0)
+		vMin, vMax = 0, 1
+	}
+	if !tMax.After(tMin) {
+		tMin, tMax = tMin.AddDate(0, 0, -15), tMax.AddDate(0, 0, 15)
+	}
+	yTickValues, vMin, vMax := valueTicks(vMin, vMax)
+
+	x := axis{min: float64(tMin.Unix()), max: float64(tMax.Unix()), from: marginLeft, to: float64(opts.Width - marginRight)}
+	y := axis{min: vMin, max: vMax, from: float64(opts.Height - marginBottom), to: marginTop}
+	theme := opts.Theme
+
+	buf := bufio.NewWriter(w)
+	p := func(format string, args ...interface{}) {
+		fmt.Fprintf(buf, format, args...)
+		buf.WriteByte('\n')
+	}
+
+	p(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`, opts.Width, opts.Height, opts.Width, opts.Height)
+	p(`<rect width="%d" height="%d" fill="%s"/>`, opts.Width, opts.Height, theme.Background)
+	p(`<text x="%d" y="%d" fill="%s" font-size="14" font-weight="bold">%s</text>`, marginLeft, marginTop/2+4, theme.Foreground, escape(opts.Title))
+
+	// Grid lines and value axis labels
+	p(`<g stroke="%s" stroke-width="1">`, theme.Grid)
+	for _, t := range yTickValues {
+		p(`<line x1="%s" y1="%s" x2="%s" y2="%s"/>`, f(x.from), f(y.scale(t.value)), f(x.to), f(y.scale(t.value)))
+	}
+	timeTickValues := timeTicks(tMin, tMax)
+	for _, t := range timeTickValues {
+		p(`<line x1="%s" y1="%s" x2="%s" y2="%s"/>`, f(x.scale(t.value)), f(y.from), f(x.scale(t.value)), f(y.to))
+	}
+	p(`</g>`)
+
+	p(`<g fill="%s">`, theme.Foreground)
+	for _, t := range yTickValues {
+		p(`<text x="%s" y="%s" text-anchor="end">%s</text>`, f(x.from-6), f(y.scale(t.value)+4), t.label)
+	}
+	for _, t := range timeTickValues {
+		p(`<text x="%s" y="%s" text-anchor="middle">%s</text>`, f(x.scale(t.value)), f(y.from+16), t.label)
+	}
+	p(`<text transform="translate(14 %s) rotate(-90)" text-anchor="middle">%s</text>`, f((y.from+y.to)/2), escape(opts.Units))
+	if !ok {
+		p(`<text x="%s" y="%s" text-anchor="middle">No data</text>`, f((x.from+x.to)/2), f((y.from+y.to)/2))
+	}
+	p(`</g>`)
+
+	// Axes
+	p(`<path d="M%s %sV%sH%s" fill="none" stroke="%s" stroke-width="1"/>`, f(x.from), f(y.to), f(y.from), f(x.to), theme.Foreground)
+
+	// Series, split into separate lines wherever an observation is missing
+	for i, s := range series {
+		color := theme.Colors[i%len(theme.Colors)]
+		dash := ""
+		if s.Dashed {
+			dash = ` stroke-dasharray="6 4"`
+		}
+		p(`<g fill="none" stroke="%s" stroke-width="1.5" stroke-linejoin="round"%s>`, color, dash)
+		var points []string
+		flush := func() {
+			if len(points) > 0 {
+				p(`<polyline points="%s"/>`, strings.Join(points, " "))
+			}
+			points = points[:0]
+		}
+		for _, pt := range s.Points {
+			if math.IsNaN(pt.Value) {
+				flush()
+				continue
+			}
+			points = append(points, f(x.scale(float64(pt.Time.Unix())))+","+f(y.scale(pt.Value)))
+		}
+		flush()
+		p(`</g>`)
+	}
+
+	// Legend
+	if len(series) > 1 {
+		legendX := x.to - 8
+		for i := len(series) - 1; i >= 0; i-- {
+			name := escape(series[i].Name)
+			p(`<text x="%s" y="%d" text-anchor="end" fill="%s">%s</text>`, f(legendX), marginTop/2+4, theme.Colors[i%len(theme.Colors)], name)
+			legendX -= float64(7*len(series[i].Name) + 16)
+		}
+	}
+
+	p(`</svg>`)
+	return buf.Flush()
+}
+
+// bounds finds the range of times and values covered by the observations in a list of series.
+func bounds(series []Series) (tMin, tMax time.Time, vMin, vMax float64, ok bool) {
+	for _, s := range series {
+		for _, pt := range s.Points {
+			if math.IsNaN(pt.Value) {
+				continue
+			}
+			if !ok {
+				tMin, tMax, vMin, vMax, ok = pt.Time, pt.Time, pt.Value, pt.Value, true
+				continue
+			}
+			if pt.Time.Before(tMin) {
+				tMin = pt.Time
+			}
+			if pt.Time.After(tMax) {
+				tMax = pt.Time
+			}
+			vMin = math.Min(vMin, pt.Value)
+			vMax = math.Max(vMax, pt.Value)
+		}
+	}
+	return
+}
+
+// valueTicks picks evenly spaced round values covering [min, max]. The range of the axis is widened to the outer ticks.
+func valueTicks(min, max float64) ([]tick, float64, float64) {
+	if max <= min {
+		min, max = min-1, max+1
+	}
+	step := niceNum(niceNum(max-min, false)/(yTicks-1), true)
+	lo := math.Floor(min/step) * step
+	hi := math.Ceil(max/step) * step
+
+	decimals := 0
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step)))
+	}
+
+	var ticks []tick
+	for i := 0; lo+float64(i)*step <= hi+step/2; i++ {
+		v := lo + float64(i)*step
+		ticks = append(ticks, tick{v, strconv.FormatFloat(v, 'f', decimals, 64)})
+	}
+	return ticks, lo, hi
+}
+
+// niceNum rounds a range to 1, 2, 5 or 10 times a power of ten.
+func niceNum(v float64, round bool) float64 {
+	exp := math.Floor(math.Log10(v))
+	frac := v / math.Pow(10, exp)
+	var nice float64
+	switch {
+	case round && frac < 1.5, !round && frac <= 1:
+		nice = 1
+	case round && frac < 3, !round && frac <= 2:
+		nice = 2
+	case round && frac < 7, !round && frac <= 5:
+		nice = 5
+	default:
+		nice = 10
+	}
+	return nice * math.Pow(10, exp)
+}
+
+// timeTicks picks the first days of years, or of months for short ranges, between min and max.
+func timeTicks(min, max time.Time) []tick {
+	for _, step := range []int{1, 2, 5, 10, 20, 50, 100} {
+		first := min.Year()
+		if !time.Date(first, 1, 1, 0, 0, 0, 0, time.UTC).Equal(min) {
+			first++
+		}
+		first = (first + step - 1) / step * step
+
+		var ticks []tick
+		for year := first; year <= max.Year(); year += step {
+			t := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+			ticks = append(ticks, tick{float64(t.Unix()), strconv.Itoa(year)})
+		}
+		if len(ticks) >= 2 && len(ticks) <= xTicks {
+			return ticks
+		}
+		if len(ticks) < 2 {
+			break
+		}
+	}
+
+	// Ranges spanning less than two year boundaries are labelled by month
+	for _, step := range []int{1, 2, 3, 6} {
+		t := time.Date(min.Year(), min.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if t.Before(min) {
+			t = t.AddDate(0, 1, 0)
+		}
+		var ticks []tick
+		for ; !t.After(max); t = t.AddDate(0, step, 0) {
+			ticks = append(ticks, tick{float64(t.Unix()), t.Format("Jan 2006")})
+		}
+		if len(ticks) <= xTicks {
+			return ticks
+		}
+	}
+	return nil
+}
+
+// MovingAverage smooths a series with a centered moving average over window observations.
+// Points without a full window of observations around them are left out.
+func MovingAverage(points []Point, window int) []Point {
+	var result []Point
+	half := window / 2
+	for i := half; i+window-half <= len(points); i++ {
+		sum, n := 0.0, 0
+		for _, pt := range points[i-half : i-half+window] {
+			if !math.IsNaN(pt.Value) {
+				sum += pt.Value
+				n++
+			}
+		}
+		// Require most of the window to be observed so that gaps are not bridged by a few values
+		if n*2 < window {
+			result = append(result, Point{points[i].Time, math.NaN()})
+			continue
+		}
+		result = append(result, Point{points[i].Time, sum / float64(n)})
+	}
+	return result
+}
+
+// f formats a coordinate with a fixed precision.
+func f(v float64) string {
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
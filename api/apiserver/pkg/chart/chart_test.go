@@ -0,0 +1,227 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package chart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrites the golden files in testdata with the current output")
+
+// weekly returns n weekly observations starting on start, rising by step each week with a yearly cycle.
+func weekly(start time.Time, n int, base float64, step float64) []Point {
+	points := make([]Point, n)
+	for i := range points {
+		t := start.AddDate(0, 0, 7*i)
+		season := 3 * math.Sin(2*math.Pi*float64(t.YearDay())/365)
+		points[i] = Point{t, math.Round((base+step*float64(i)+season)*100) / 100}
+	}
+	return points
+}
+
+func TestRenderGolden(t *testing.T) {
+	start := time.Date(2015, 1, 4, 0, 0, 0, 0, time.UTC)
+	co2 := weekly(start, 52*6, 400, 0.045)
+	co2[100].Value = math.NaN() // A missing week leaves a gap
+
+	monthly := make([]Point, 18)
+	for i := range monthly {
+		monthly[i] = Point{time.Date(2020, time.Month(1+i), 1, 0, 0, 0, 0, time.UTC), 1870 + float64(i)}
+	}
+
+	testVals := []struct {
+		name   string
+		opts   Options
+		series []Series
+	}{
+		{
+			"co2_light",
+			Options{Title: "Weekly CO2 average", Units: "ppm", Width: 800, Height: 400, Theme: Themes["light"]},
+			[]Series{{Name: "Average", Points: co2}},
+		},
+		{
+			"co2_trend_dark",
+			Options{Title: "Weekly CO2 average", Units: "ppm", Width: 640, Height: 320, Theme: Themes["dark"]},
+			[]Series{{Name: "Average", Points: co2}, {Name: "Trend", Points: MovingAverage(co2, 52), Dashed: true}},
+		},
+		{
+			"ch4_months",
+			Options{Title: "Monthly CH4 <global> & trend", Units: "ppb", Width: 500, Height: 300, Theme: Themes["light"]},
+			[]Series{{Name: "Average", Points: monthly}},
+		},
+		{
+			"empty",
+			Options{Title: "Weekly CO2 average", Units: "ppm", Width: 400, Height: 200, Theme: Themes["light"]},
+			[]Series{{Name: "Average"}},
+		},
+	}
+
+	for _, testVal := range testVals {
+		var buf bytes.Buffer
+		if err := Render(&buf, testVal.opts, testVal.series...); err != nil {
+			t.Fatalf("Unexpected error rendering '%v': %v", testVal.name, err)
+		}
+
+		// Charts must be well-formed XML
+		dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+		for {
+			if _, err := dec.Token(); err != nil {
+				if err.Error() != "EOF" {
+					t.Errorf("Chart '%v' is not well-formed: %v", testVal.name, err)
+				}
+				break
+			}
+		}
+
+		// Rendering twice must produce identical output
+		var again bytes.Buffer
+		Render(&again, testVal.opts, testVal.series...)
+		if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+			t.Errorf("Chart '%v' is not deterministic.", testVal.name)
+		}
+
+		golden := filepath.Join("testdata", testVal.name+".svg")
+		if *update {
+			if err := ioutil.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("Cannot read golden file, run 'go test -update' to create it: %v", err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("Chart '%v' does not match '%v'. Run 'go test -update' if the change is intended.", testVal.name, golden)
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	testVals := []Options{
+		{Width: 50, Height: 400, Theme: Themes["light"]},
+		{Width: 800, Height: 60, Theme: Themes["light"]},
+		{Width: 800, Height: 400},
+	}
+	for _, opts := range testVals {
+		if err := Render(ioutil.Discard, opts); err == nil {
+			t.Errorf("Expected an error for options '%+v', got nil.", opts)
+		}
+	}
+}
+
+func TestValueTicks(t *testing.T) {
+	testVals := []struct {
+		min, max float64
+		labels   []string
+	}{
+		{330.1, 419.8, []string{"320", "340", "360", "380", "400", "420"}},
+		{1625.4, 1712.1, []string{"1620", "1640", "1660", "1680", "1700", "1720"}},
+		{0.42, 0.47, []string{"0.42", "0.43", "0.44", "0.45", "0.46", "0.47"}},
+		{5, 5, []string{"4.0", "4.5", "5.0", "5.5", "6.0"}},
+	}
+	for _, testVal := range testVals {
+		ticks, _, _ := valueTicks(testVal.min, testVal.max)
+		var labels []string
+		for _, tick := range ticks {
+			labels = append(labels, tick.label)
+		}
+		if len(labels) != len(testVal.labels) {
+			t.Errorf("Wanted ticks %v for [%v, %v], Got: %v.", testVal.labels, testVal.min, testVal.max, labels)
+			continue
+		}
+		for i := range labels {
+			if labels[i] != testVal.labels[i] {
+				t.Errorf("Wanted ticks %v for [%v, %v], Got: %v.", testVal.labels, testVal.min, testVal.max, labels)
+				break
+			}
+		}
+	}
+}
+
+func TestTimeTicks(t *testing.T) {
+	testVals := []struct {
+		min, max time.Time
+		first    string
+		count    int
+	}{
+		{time.Date(1974, 5, 19, 0, 0, 0, 0, time.UTC), time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC), "1975", 10},
+		{time.Date(2015, 1, 4, 0, 0, 0, 0, time.UTC), time.Date(2020, 12, 20, 0, 0, 0, 0, time.UTC), "2016", 5},
+		{time.Date(2020, 3, 15, 0, 0, 0, 0, time.UTC), time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC), "Apr 2020", 6},
+	}
+	for _, testVal := range testVals {
+		ticks := timeTicks(testVal.min, testVal.max)
+		if len(ticks) != testVal.count || ticks[0].label != testVal.first {
+			t.Errorf("Wanted %v ticks starting at '%v' for [%v, %v], Got: %v.", testVal.count, testVal.first, testVal.min, testVal.max, ticks)
+		}
+	}
+}
+
+func TestMovingAverage(t *testing.T) {
+	var points []Point
+	for i := 0; i < 6; i++ {
+		points = append(points, Point{time.Date(2020, time.Month(i+1), 1, 0, 0, 0, 0, time.UTC), float64(i)})
+	}
+
+	avg := MovingAverage(points, 3)
+	if len(avg) != 4 {
+		t.Fatalf("Wanted 4 points, Got: %v.", avg)
+	}
+	for i, pt := range avg {
+		if pt.Value != float64(i+1) || !pt.Time.Equal(points[i+1].Time) {
+			t.Errorf("Wanted %v at %v, Got: %v.", float64(i+1), points[i+1].Time, pt)
+		}
+	}
+}
+
+func TestRenderBadge(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderBadge(&buf, Badge{Label: "CO₂ Mauna Loa", Message: "421.08 ppm (+2.41 y/y)", Color: "#007ec6"}); err != nil {
+		t.Fatalf("Unexpected error rendering badge: %v", err)
+	}
+
+	golden := filepath.Join("testdata", "badge.svg")
+	if *update {
+		if err := ioutil.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Cannot read golden file, run 'go test -update' to create it: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("Badge does not match '%v'. Run 'go test -update' if the change is intended.", golden)
+	}
+
+	if err := RenderBadge(ioutil.Discard, Badge{Label: "CO₂ Mauna Loa"}); err == nil {
+		t.Error("Expected an error for a badge without a message, got nil.")
+	}
+}
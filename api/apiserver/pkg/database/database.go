@@ -25,13 +25,14 @@ package database
 
 import (
 	"apiserver/pkg/database/models"
+	"apiserver/pkg/utils"
 	"database/sql"
 	"fmt"
 	"net/url"
 	"strconv"
+	"time"
 
-	// The blank import here is used to import the pq PostgreSQL drivers
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -68,9 +69,16 @@ type DBQuery struct {
 	// The columns to select from
 	Cols []string
 
+	// Fields lists the dataset columns selected when only a subset of the columns has been requested.
+	// It is empty when every column is selected.
+	Fields []models.Column
+
 	// A list of Boolean SQL expressions to be used as 'WHERE' clauses
 	Where []string
 
+	// Args holds the values referenced by positional parameters ($1, $2, ...) in the Where expressions
+	Args []interface{}
+
 	// An expression passed directly to the ORDER BY keyword. Usually should just be one or more cols.
 	OrderBy string
 
@@ -97,9 +105,9 @@ func (database *Database) Query(query DBQuery, dataObject models.DataObject) err
 		return err
 	}
 
-	rows, err := database.DB.Query(query.ToString())
+	rows, err := database.DB.Query(query.ToString(), query.Args...)
 	if err != nil {
-		return err
+		return Classify(err)
 	}
 
 	defer rows.Close()
@@ -111,16 +119,92 @@ func (database *Database) Query(query DBQuery, dataObject models.DataObject) err
 	}
 	err = rows.Err()
 	if err != nil {
-		return err
+		return Classify(err)
 	}
 	return nil
 }
 
+// QueryPeriod queries the database for the observation in the period of a dataset containing date.
+// The Where, Limit, Offset, and Page values of the supplied DBQuery are replaced to select a single row.
+func (database *Database) QueryPeriod(dataset models.Dataset, query DBQuery, date time.Time, dataObject models.DataObject) error {
+	query.Where = dataset.Cadence.PeriodWhere(date)
+	query.Limit = 1
+	query.Offset = 0
+	query.Page = 0
+	return database.Query(query, dataObject)
+}
+
+// LatestObservation returns the date of the most recent observation in a dataset.
+// sql.ErrNoRows is returned if the dataset is empty.
+func (database *Database) LatestObservation(dataset models.Dataset) (time.Time, error) {
+	if err := database.ProbeConnection(); err != nil {
+		return time.Time{}, err
+	}
+
+	var latest sql.NullTime
+	if err := database.DB.QueryRow("SELECT max(yyyymmdd) FROM " + dataset.Table).Scan(&latest); err != nil {
+		return time.Time{}, Classify(err)
+	}
+	if !latest.Valid {
+		return time.Time{}, sql.ErrNoRows
+	}
+	return latest.Time, nil
+}
+
+// LastIngested returns the time a dataset was last loaded into the database by the ingestion pipeline.
+// A nil time is returned if the pipeline has not logged an ingestion for the dataset.
+func (database *Database) LastIngested(dataset models.Dataset) (*time.Time, error) {
+	if err := database.ProbeConnection(); err != nil {
+		return nil, err
+	}
+
+	var ingested sql.NullTime
+	err := database.DB.QueryRow("SELECT max(ingested_at) FROM public.ingest_log WHERE dataset = $1", dataset.Id).Scan(&ingested)
+	if err != nil {
+		// Databases created before the ingest log was introduced will not have the table
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "42P01" {
+			return nil, nil
+		}
+		return nil, Classify(err)
+	}
+	if !ingested.Valid {
+		return nil, nil
+	}
+	return &ingested.Time, nil
+}
+
+// Coverage computes the Coverage of a dataset: the dates of its oldest and most recent observations, its number of
+// observations and the time it was last ingested.
+func (database *Database) Coverage(dataset models.Dataset) (models.Coverage, error) {
+	if err := database.ProbeConnection(); err != nil {
+		return models.Coverage{}, err
+	}
+
+	var first, last sql.NullTime
+	var coverage models.Coverage
+	if err := database.DB.QueryRow("SELECT min(yyyymmdd), max(yyyymmdd), count(*) FROM "+dataset.Table).Scan(&first, &last, &coverage.Rows); err != nil {
+		return models.Coverage{}, Classify(err)
+	}
+	if first.Valid && last.Valid {
+		coverage.First, coverage.Last = &first.Time, &last.Time
+	}
+
+	ingested, err := database.LastIngested(dataset)
+	if err != nil {
+		return models.Coverage{}, err
+	}
+	coverage.LastIngested = ingested
+	return coverage, nil
+}
+
+// ConnInfo returns the connection string of the database, built from the DBConfig values.
+func (database *Database) ConnInfo() string {
+	return fmt.Sprintf("postgres://%s:%s@%s/postgres?connect_timeout=%d", url.PathEscape(database.Config.DBUser), url.PathEscape(database.Config.DBPass), database.Config.DBHost, database.Config.DBConnTimeout)
+}
+
 // Connect establishes a database connection based on the DBConfig values.
 func (database *Database) Connect() error {
-	conninfo := fmt.Sprintf("postgres://%s:%s@%s/postgres?connect_timeout=%d", url.PathEscape(database.Config.DBUser), url.PathEscape(database.Config.DBPass), database.Config.DBHost, database.Config.DBConnTimeout)
-
-	db, err := sql.Open("postgres", conninfo)
+	db, err := sql.Open("postgres", database.ConnInfo())
 	if err != nil {
 		return err
 	}
@@ -138,7 +222,7 @@ func (database *Database) Connect() error {
 // ProbeConnection provides a safe mechanism for checking the database connection.
 // This function should be called before a query is made. It first detects if a database connection
 // has not been initialized. If this is the case a new connection attempt will be made.
-// An error is returned when a connection cannot be established.
+// An Error reported as DB_UNAVAILABLE is returned when a connection cannot be established.
 func (database *Database) ProbeConnection() error {
 	// If database failed to initialize, apiserver.Db will be nil
 	if database.DB == nil {
@@ -147,13 +231,13 @@ func (database *Database) ProbeConnection() error {
 		log.Info("Retrying database connection....")
 		status := database.Connect()
 		if status != nil {
-			return status
+			return &Error{Code: utils.CodeDbUnavailable, Err: status}
 		}
 		log.Info("Database connection successfully established.")
 	}
 
 	if err := database.DB.Ping(); err != nil {
-		return err
+		return &Error{Code: utils.CodeDbUnavailable, Err: err}
 	}
 	return nil
 }
@@ -173,6 +257,15 @@ func NewQuery(table string, cols []string, orderBy string) DBQuery {
 	}
 }
 
+// Select restricts a DBQuery to a subset of the columns of a dataset.
+func (query *DBQuery) Select(fields []models.Column) {
+	query.Fields = fields
+	query.Cols = make([]string, len(fields))
+	for i, col := range fields {
+		query.Cols[i] = col.Name
+	}
+}
+
 // ToString marshalls a DBQuery object into a string query that can be sent to an SQL database.
 func (query DBQuery) ToString() string {
 	sqlString := "SELECT "
@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package database
+
+import (
+	"apiserver/pkg/utils"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// queryCanceled is the Postgres error code of a statement canceled by statement_timeout or by the client.
+const queryCanceled = "57014"
+
+// Error is a database error known to be caused by the database being unreachable or slow, rather than by the
+// query. It carries the code of the error catalog it is reported with, eg. DB_UNAVAILABLE.
+type Error struct {
+	Code string
+	Err  error
+}
+
+func (err *Error) Error() string {
+	return err.Err.Error()
+}
+
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+// ErrorCode returns the code of the error catalog the error is reported with.
+func (err *Error) ErrorCode() string {
+	return err.Code
+}
+
+// Classify wraps an error returned by the database driver in an Error when it is caused by the database being
+// unreachable (DB_UNAVAILABLE) or a query taking too long (QUERY_TIMEOUT). Other errors are returned unchanged,
+// so that eg. sql.ErrNoRows can still be compared against.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Code: utils.CodeQueryTimeout, Err: err}
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == queryCanceled:
+			return &Error{Code: utils.CodeQueryTimeout, Err: err}
+		case strings.HasPrefix(string(pqErr.Code), "08"), // Connection exceptions
+			strings.HasPrefix(string(pqErr.Code), "57P"): // Server shutting down or unable to accept connections
+			return &Error{Code: utils.CodeDbUnavailable, Err: err}
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.As(err, &netErr) {
+		if netErr != nil && netErr.Timeout() {
+			return &Error{Code: utils.CodeQueryTimeout, Err: err}
+		}
+		return &Error{Code: utils.CodeDbUnavailable, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package filter
+
+import (
+	"apiserver/pkg/database/models"
+	"strconv"
+	"strings"
+)
+
+// Node represents a node in the syntax tree of a filter expression.
+type Node interface {
+	// compile writes the SQL representation of the node to buf and appends its values to args.
+	compile(buf *strings.Builder, args *[]interface{})
+}
+
+// Logical represents two expressions joined by 'and' or 'or'.
+type Logical struct {
+	Op    string
+	Left  Node
+	Right Node
+}
+
+// Not represents a negated expression.
+type Not struct {
+	X Node
+}
+
+// Comparison represents a comparison between a column and a single value.
+type Comparison struct {
+	Column models.Column
+	Op     string
+	Value  interface{}
+}
+
+// In represents a test for membership of a column in a list of values.
+type In struct {
+	Column models.Column
+	Not    bool
+	Values []interface{}
+}
+
+// Compile translates a filter expression into a boolean SQL expression. Values are never embedded in
+// the SQL, they are appended to args and referenced by placeholders numbered after any existing args.
+func Compile(node Node, args []interface{}) (string, []interface{}) {
+	var buf strings.Builder
+	node.compile(&buf, &args)
+	return buf.String(), args
+}
+
+// Convert replaces each number compared against a column for which match returns true with the result of
+// calling convert on it. This lets a filter given in one unit be compared against columns stored in another.
+func Convert(node Node, match func(models.Column) bool, convert func(float64) float64) {
+	switch n := node.(type) {
+	case *Logical:
+		Convert(n.Left, match, convert)
+		Convert(n.Right, match, convert)
+	case *Not:
+		Convert(n.X, match, convert)
+	case *Comparison:
+		if val, ok := n.Value.(float64); ok && match(n.Column) {
+			n.Value = convert(val)
+		}
+	case *In:
+		for i, v := range n.Values {
+			if val, ok := v.(float64); ok && match(n.Column) {
+				n.Values[i] = convert(val)
+			}
+		}
+	}
+}
+
+func (node *Logical) compile(buf *strings.Builder, args *[]interface{}) {
+	buf.WriteString("(")
+	node.Left.compile(buf, args)
+	buf.WriteString(" " + strings.ToUpper(node.Op) + " ")
+	node.Right.compile(buf, args)
+	buf.WriteString(")")
+}
+
+func (node *Not) compile(buf *strings.Builder, args *[]interface{}) {
+	buf.WriteString("NOT ")
+	node.X.compile(buf, args)
+}
+
+func (node *Comparison) compile(buf *strings.Builder, args *[]interface{}) {
+	op := node.Op
+	if op == "!=" {
+		op = "<>"
+	}
+	buf.WriteString(node.Column.Name + " " + op + " " + placeholder(args, node.Value))
+}
+
+func (node *In) compile(buf *strings.Builder, args *[]interface{}) {
+	buf.WriteString(node.Column.Name)
+	if node.Not {
+		buf.WriteString(" NOT")
+	}
+	buf.WriteString(" IN (")
+	for i, val := range node.Values {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(placeholder(args, val))
+	}
+	buf.WriteString(")")
+}
+
+// placeholder appends a value to args and returns the positional parameter referencing it.
+func placeholder(args *[]interface{}, val interface{}) string {
+	*args = append(*args, val)
+	return "$" + strconv.Itoa(len(*args))
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+// Package filter parses filter expressions supplied by clients, checks them against the columns of a dataset,
+// and compiles them into parameterized SQL.
+//
+// The grammar of a filter expression is:
+//
+//	expr       = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" expr ")" | comparison
+//	comparison = field ( op literal | [ "not" ] "in" "(" literal { "," literal } ")" )
+//	op         = "=" | "!=" | "<>" | "<" | "<=" | ">" | ">="
+//	literal    = [ "-" ] number | "'" date "'"
+//
+// For example: average >= 410 and (month in (5,6) or ndays < 5)
+package filter
@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package filter
+
+import (
+	"apiserver/pkg/database/models"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCompile(t *testing.T) {
+	testVals := []struct {
+		filter string
+		sql    string
+		args   []interface{}
+	}{
+		{
+			"average >= 410",
+			"average >= $1",
+			[]interface{}{410.0},
+		},
+		{
+			"average >= 410 and (month in (5,6) or ndays < 5)",
+			"(average >= $1 AND (month IN ($2, $3) OR ndays < $4))",
+			[]interface{}{410.0, int64(5), int64(6), int64(5)},
+		},
+		{
+			"a or b and c",
+			"",
+			nil,
+		},
+		{
+			"YEAR = 2020 OR year=2021 AND NOT month != 1",
+			"(year = $1 OR (year = $2 AND NOT month <> $3))",
+			[]interface{}{int64(2020), int64(2021), int64(1)},
+		},
+		{
+			"month not in (1, 2) and one_year_ago > -999.99",
+			"(month NOT IN ($1, $2) AND one_year_ago > $3)",
+			[]interface{}{int64(1), int64(2), -999.99},
+		},
+		{
+			"yyyymmdd >= '2020-01-05' and not (average < 300)",
+			"(yyyymmdd >= $1 AND NOT average < $2)",
+			[]interface{}{time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC), 300.0},
+		},
+	}
+
+	for _, testVal := range testVals {
+		if testVal.sql == "" {
+			continue
+		}
+		node, err := Parse(testVal.filter, models.Co2WeeklyMlo)
+		if err != nil {
+			t.Errorf("Unexpected error parsing '%v': %v", testVal.filter, err)
+			continue
+		}
+
+		sql, args := Compile(node, nil)
+		if sql != testVal.sql {
+			t.Errorf("Wanted SQL '%v', Got: '%v'.", testVal.sql, sql)
+		}
+		if fmt.Sprint(args) != fmt.Sprint(testVal.args) {
+			t.Errorf("Wanted args '%v', Got: '%v'.", testVal.args, args)
+		}
+	}
+}
+
+func TestCompileArgsOffset(t *testing.T) {
+	node, err := Parse("trend > 1800 or average_unc < 1.5", models.Ch4MmGl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sql, args := Compile(node, []interface{}{"existing"})
+	if sql != "(trend > $2 OR average_unc < $3)" {
+		t.Errorf("Placeholders should be numbered after existing arguments, Got: '%v'.", sql)
+	}
+	if len(args) != 3 {
+		t.Errorf("Wanted 3 arguments, Got: '%v'.", args)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	node, err := Parse("average > 1.9 and (trend in (1.8, 2) or not average_unc < 0.002) and year > 2000", models.Ch4MmGl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convert(node, func(col models.Column) bool { return col.Unit == "ppb" }, func(val float64) float64 { return val * 1000 })
+	sql, args := Compile(node, nil)
+	if sql != "((average > $1 AND (trend IN ($2, $3) OR NOT average_unc < $4)) AND year > $5)" {
+		t.Errorf("Converting values should not change the expression, Got: '%v'.", sql)
+	}
+	if fmt.Sprint(args) != fmt.Sprint([]interface{}{1900.0, 1800.0, 2000.0, 2.0, int64(2000)}) {
+		t.Errorf("Wanted the values compared against ppb columns to be converted, Got: '%v'.", args)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testVals := []struct {
+		filter string
+		pos    int
+	}{
+		{"", 1},
+		{"avg > 400", 1},
+		{"average >= 410 and (month in (5,6) or ndays < 5", 48},
+		{"average >= 410 and (month in (5,6) or ndays < 5))", 49},
+		{"year = 2020.5", 8},
+		{"average ~ 400", 9},
+		{"average > 'high'", 11},
+		{"yyyymmdd > 2020", 12},
+		{"yyyymmdd > '2020-13-01'", 12},
+		{"month in (1 2)", 13},
+		{"month in 1", 10},
+		{"average > 400 average < 500", 15},
+		{"average > 400 and", 18},
+		{"year = 'unterminated", 8},
+		{"trend > 1800", 1},
+		{"; DROP TABLE co2_weekly_mlo", 1},
+	}
+
+	for _, testVal := range testVals {
+		_, err := Parse(testVal.filter, models.Co2WeeklyMlo)
+		if err == nil {
+			t.Errorf("Expected an error parsing '%v', got nil.", testVal.filter)
+			continue
+		}
+
+		filterErr, ok := err.(*Error)
+		if !ok {
+			t.Errorf("Expected a filter.Error parsing '%v', Got: '%T'.", testVal.filter, err)
+			continue
+		}
+		if filterErr.Pos != testVal.pos {
+			t.Errorf("Wanted error at position %v for '%v', Got: '%v'.", testVal.pos, testVal.filter, err)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	deep := ""
+	for i := 0; i < MaxDepth+1; i++ {
+		deep += "("
+	}
+	if _, err := Parse(deep+"year = 2020", models.Co2WeeklyMlo); err == nil {
+		t.Error("Expected an error for a deeply nested filter, got nil.")
+	}
+
+	long := "year = 2020"
+	for len(long) <= MaxLength {
+		long += " or year = 2020"
+	}
+	if _, err := Parse(long, models.Co2WeeklyMlo); err == nil {
+		t.Error("Expected an error for a long filter, got nil.")
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenType int
+
+const (
+	tokEOF tokenType = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+	tokMinus
+)
+
+// token represents a single lexical element of a filter expression. Pos is the 1-based position of the
+// token's first character in the expression.
+type token struct {
+	typ tokenType
+	val string
+	pos int
+}
+
+// String describes a token for use in error messages.
+func (tok token) String() string {
+	if tok.typ == tokEOF {
+		return "end of filter"
+	}
+	return "'" + tok.val + "'"
+}
+
+// keyword reports whether the token is the given keyword. Keywords are case insensitive.
+func (tok token) keyword(word string) bool {
+	return tok.typ == tokIdent && strings.EqualFold(tok.val, word)
+}
+
+// Error represents a problem with a filter expression, located at the position of the offending token.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", err.Msg, err.Pos)
+}
+
+// lex splits a filter expression into tokens.
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, token{tokLParen, "(", start + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokRParen, ")", start + 1})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokComma, ",", start + 1})
+			i++
+		case r == '-':
+			tokens = append(tokens, token{tokMinus, "-", start + 1})
+			i++
+		case r == '=':
+			tokens = append(tokens, token{tokOp, "=", start + 1})
+			i++
+		case r == '<' || r == '>' || r == '!':
+			i++
+			if i < len(runes) && (runes[i] == '=' || (r == '<' && runes[i] == '>')) {
+				i++
+			}
+			op := string(runes[start:i])
+			if op == "!" {
+				return nil, &Error{start + 1, "unexpected character '!'"}
+			}
+			tokens = append(tokens, token{tokOp, op, start + 1})
+		case r == '\'':
+			i++
+			for i < len(runes) && runes[i] != '\'' {
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &Error{start + 1, "unterminated string"}
+			}
+			tokens = append(tokens, token{tokString, string(runes[start+1 : i]), start + 1})
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, string(runes[start:i]), start + 1})
+		case unicode.IsLetter(r) || r == '_':
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, string(runes[start:i]), start + 1})
+		default:
+			return nil, &Error{start + 1, fmt.Sprintf("unexpected character '%c'", r)}
+		}
+	}
+	return append(tokens, token{tokEOF, "", len(runes) + 1}), nil
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package filter
+
+import (
+	"apiserver/pkg/database/models"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxLength is the maximum number of characters allowed in a filter expression
+	MaxLength = 1024
+
+	// MaxDepth is the maximum nesting depth of parentheses and negations in a filter expression
+	MaxDepth = 32
+)
+
+// parser holds the state of a filter expression being parsed.
+type parser struct {
+	tokens  []token
+	pos     int
+	depth   int
+	dataset models.Dataset
+}
+
+// Parse parses a filter expression into a syntax tree. Every field referenced by the expression must be a
+// column of the dataset, and every value must match the type of the field it is compared against.
+func Parse(input string, dataset models.Dataset) (Node, error) {
+	if len([]rune(input)) > MaxLength {
+		return nil, &Error{MaxLength + 1, fmt.Sprintf("filter is longer than %d characters", MaxLength)}
+	}
+
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, dataset: dataset}
+	if p.peek().typ == tokEOF {
+		return nil, &Error{1, "filter is empty"}
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.typ != tokEOF {
+		return nil, &Error{tok.pos, fmt.Sprintf("unexpected %v, expected 'and', 'or' or end of filter", tok)}
+	}
+	return node, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.typ != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(typ tokenType, want string) (token, error) {
+	tok := p.next()
+	if tok.typ != typ {
+		return tok, &Error{tok.pos, fmt.Sprintf("unexpected %v, expected %v", tok, want)}
+	}
+	return tok, nil
+}
+
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().keyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Op: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().keyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Op: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	tok := p.peek()
+	if tok.keyword("not") || tok.typ == tokLParen {
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > MaxDepth {
+			return nil, &Error{tok.pos, fmt.Sprintf("filter is nested more than %d levels deep", MaxDepth)}
+		}
+	}
+
+	switch {
+	case tok.keyword("not"):
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	case tok.typ == tokLParen:
+		p.next()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return x, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Node, error) {
+	tok, err := p.expect(tokIdent, "a field name")
+	if err != nil {
+		return nil, err
+	}
+	col, ok := p.dataset.Column(strings.ToLower(tok.val))
+	if !ok {
+		return nil, &Error{tok.pos, fmt.Sprintf("unknown field '%v'. Allowed fields are: %v", tok.val, strings.Join(p.dataset.ColumnNames(), ", "))}
+	}
+
+	op := p.next()
+	switch {
+	case op.typ == tokOp:
+		val, err := p.parseLiteral(col)
+		if err != nil {
+			return nil, err
+		}
+		return &Comparison{Column: col, Op: op.val, Value: val}, nil
+	case op.keyword("in"):
+		return p.parseIn(col, false)
+	case op.keyword("not") && p.peek().keyword("in"):
+		p.next()
+		return p.parseIn(col, true)
+	}
+	return nil, &Error{op.pos, fmt.Sprintf("unexpected %v, expected a comparison operator or 'in' after '%v'", op, tok.val)}
+}
+
+func (p *parser) parseIn(col models.Column, not bool) (Node, error) {
+	if _, err := p.expect(tokLParen, "'(' to start a list of values"); err != nil {
+		return nil, err
+	}
+
+	node := &In{Column: col, Not: not}
+	for {
+		val, err := p.parseLiteral(col)
+		if err != nil {
+			return nil, err
+		}
+		node.Values = append(node.Values, val)
+
+		tok := p.next()
+		if tok.typ == tokRParen {
+			return node, nil
+		}
+		if tok.typ != tokComma {
+			return nil, &Error{tok.pos, fmt.Sprintf("unexpected %v, expected ',' or ')'", tok)}
+		}
+	}
+}
+
+// parseLiteral parses a value to be compared against col and converts it to the column's type.
+func (p *parser) parseLiteral(col models.Column) (interface{}, error) {
+	tok := p.next()
+	sign := ""
+	if tok.typ == tokMinus {
+		sign = "-"
+		tok = p.next()
+	}
+
+	switch col.Type {
+	case models.Integer:
+		if tok.typ == tokNumber {
+			if val, err := strconv.ParseInt(sign+tok.val, 10, 32); err == nil {
+				return val, nil
+			}
+		}
+		return nil, &Error{tok.pos, fmt.Sprintf("field '%v' expects an integer, got %v", col.Name, tok)}
+	case models.Float:
+		if tok.typ == tokNumber {
+			if val, err := strconv.ParseFloat(sign+tok.val, 64); err == nil {
+				return val, nil
+			}
+		}
+		return nil, &Error{tok.pos, fmt.Sprintf("field '%v' expects a number, got %v", col.Name, tok)}
+	case models.Date:
+		if tok.typ == tokString && sign == "" {
+			if val, err := time.Parse("2006-01-02", tok.val); err == nil {
+				return val, nil
+			}
+		}
+		return nil, &Error{tok.pos, fmt.Sprintf("field '%v' expects a quoted date formatted as 'yyyy-mm-dd', got %v", col.Name, tok)}
+	}
+	return nil, &Error{tok.pos, fmt.Sprintf("field '%v' cannot be filtered", col.Name)}
+}
@@ -36,6 +36,43 @@ const (
 	Ch4PpbMin = 0
 )
 
+// Ch4MmGl describes the monthly global average CH4 measurements taken over marine surface sites.
+// NOAA publishes these several months after the fact as the global average requires data from every site.
+var Ch4MmGl = Dataset{
+	Id:          "ch4_mm_gl",
+	Description: "Monthly global average CH4 mole fraction in dry air, measured over marine surface sites.",
+	Table:       "public.ch4_mm_gl",
+	Cadence:     Monthly,
+	Lag:         120 * 24 * time.Hour,
+	Site:        "Global marine surface sites",
+	Source:      sources["ch4_mm_gl"],
+	Citation:    "Ed Dlugokencky and Xin Lan, NOAA/GML (gml.noaa.gov/ccgg/trends_ch4/).",
+	License:     noaaLicense,
+	Columns: []Column{
+		{Name: "year", Field: "Year", Type: Integer},
+		{Name: "month", Field: "Month", Type: Integer},
+		{Name: "day", Field: "Day", Type: Integer},
+		{Name: "date_decimal", Field: "DateDecimal", Type: Float, Unit: "year"},
+		{Name: "average", Field: "Average", Type: Float, Unit: "ppb"},
+		{Name: "average_unc", Field: "AverageUncertainty", Type: Float, Unit: "ppb"},
+		{Name: "trend", Field: "Trend", Type: Float, Unit: "ppb"},
+		{Name: "trend_unc", Field: "TrendUncertainty", Type: Float, Unit: "ppb"},
+		{Name: "yyyymmdd", Field: "Timestamp", Type: Date},
+	},
+	Order:   []string{"year", "month"},
+	Missing: -999.99,
+	Entry:   Ch4Entry{},
+	Presets: map[string][]string{
+		"simple": {"year", "month", "average", "trend"},
+	},
+	Filterable: []string{"year", "month", "average", "trend"},
+	Min:        Ch4PpbMin,
+	Max:        Ch4PpbMax,
+	Unit:       Ppb,
+	Units:      []string{Ppb, Ppm, UgPerM3},
+	MolarMass:  16.04,
+}
+
 // Ch4Table represents a list of Ch4Entry objects
 type Ch4Table []interface{}
 
@@ -43,6 +80,7 @@ type Ch4Table []interface{}
 type Ch4Entry struct {
 	Year               int
 	Month              int
+	Day                int
 	DateDecimal        float32
 	Average            float32
 	AverageUncertainty float32
@@ -63,7 +101,7 @@ type Ch4EntrySimple struct {
 func (ch4Table *Ch4Table) Load(rows *sql.Rows, simple bool) error {
 	if !simple {
 		var ch4entry Ch4Entry
-		if err := rows.Scan(&ch4entry.Year, &ch4entry.Month, &ch4entry.DateDecimal, &ch4entry.Average, &ch4entry.AverageUncertainty, &ch4entry.Trend, &ch4entry.TrendUncertainty, &ch4entry.Timestamp); err != nil {
+		if err := rows.Scan(&ch4entry.Year, &ch4entry.Month, &ch4entry.Day, &ch4entry.DateDecimal, &ch4entry.Average, &ch4entry.AverageUncertainty, &ch4entry.Trend, &ch4entry.TrendUncertainty, &ch4entry.Timestamp); err != nil {
 			return err
 		}
 		*ch4Table = append(*ch4Table, ch4entry)
@@ -36,6 +36,44 @@ const (
 	Co2PpmMin = 0
 )
 
+// Co2WeeklyMlo describes the weekly average CO2 measurements taken at Mauna Loa Observatory.
+var Co2WeeklyMlo = Dataset{
+	Id:          "co2_weekly_mlo",
+	Description: "Weekly average CO2 mole fraction in dry air, measured at Mauna Loa Observatory.",
+	Table:       "public.co2_weekly_mlo",
+	Cadence:     Weekly,
+	Lag:         3 * 24 * time.Hour,
+	Site:        "Mauna Loa Observatory, Hawaii",
+	Source:      sources["co2_weekly_mlo"],
+	Citation: "Dr. Xin Lan, NOAA/GML (gml.noaa.gov/ccgg/trends/) and Dr. Ralph Keeling, Scripps Institution of " +
+		"Oceanography (scrippsco2.ucsd.edu/).",
+	License: noaaLicense,
+	Columns: []Column{
+		{Name: "year", Field: "Year", Type: Integer},
+		{Name: "month", Field: "Month", Type: Integer},
+		{Name: "day", Field: "Day", Type: Integer},
+		{Name: "date_decimal", Field: "DateDecimal", Type: Float, Unit: "year"},
+		{Name: "average", Field: "Average", Type: Float, Unit: "ppm"},
+		{Name: "ndays", Field: "NumDays", Type: Integer, Unit: "days"},
+		{Name: "one_year_ago", Field: "OneYearAgo", Type: Float, Unit: "ppm"},
+		{Name: "ten_years_ago", Field: "TenYearsAgo", Type: Float, Unit: "ppm"},
+		{Name: "increase_since_1800", Field: "IncSincePreIndustrial", Type: Float, Unit: "ppm"},
+		{Name: "yyyymmdd", Field: "Timestamp", Type: Date},
+	},
+	Order:   []string{"year", "month", "day"},
+	Missing: -999.99,
+	Entry:   Co2Entry{},
+	Presets: map[string][]string{
+		"simple": {"year", "month", "day", "average", "increase_since_1800"},
+	},
+	Filterable: []string{"year", "month", "average", "increase_since_1800"},
+	Min:        Co2PpmMin,
+	Max:        Co2PpmMax,
+	Unit:       Ppm,
+	Units:      []string{Ppm, Ppb, UgPerM3, GtC},
+	MolarMass:  44.01,
+}
+
 // Co2Table represents a list of Co2Entry objects
 type Co2Table []interface{}
 
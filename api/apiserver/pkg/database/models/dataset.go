@@ -0,0 +1,428 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Cadence represents the expected interval between two consecutive observations in a dataset.
+type Cadence string
+
+const (
+	// Weekly datasets publish one observation per week. Each observation is dated on the first day of its week.
+	Weekly Cadence = "weekly"
+
+	// Monthly datasets publish one observation per month. Each observation is dated on the first day of its month.
+	Monthly Cadence = "monthly"
+)
+
+// ColumnType represents the type of data held by a dataset column.
+type ColumnType string
+
+const (
+	// Integer columns hold whole numbers
+	Integer ColumnType = "integer"
+
+	// Float columns hold decimal numbers
+	Float ColumnType = "float"
+
+	// Date columns hold calendar dates
+	Date ColumnType = "date"
+)
+
+// Column maps a column of a dataset table to the field it is served as.
+type Column struct {
+	// Name is the name of the column in the database. Clients use this name to refer to the column in query parameters.
+	Name string
+
+	// Field is the name of the field holding the column in a response
+	Field string
+
+	// Type is the type of data held by the column
+	Type ColumnType
+
+	// Unit is the unit of the measurements held by the column, eg. 'ppm'. It is empty for columns without a unit.
+	Unit string
+}
+
+// Dataset describes a table of observations served by the API.
+type Dataset struct {
+	// Id uniquely identifies the dataset. This matches the source name used by the ingestion pipeline.
+	Id string
+
+	// Description summarizes the measurements held by the dataset
+	Description string
+
+	// Table is the fully qualified name of the database table holding the dataset
+	Table string
+
+	// Cadence is the expected interval between observations
+	Cadence Cadence
+
+	// Lag is the typical delay between the end of an observation period and NOAA publishing it
+	Lag time.Duration
+
+	// Site is the station or region the observations describe
+	Site string
+
+	// Source is the URL the ingestion pipeline downloads the dataset from (see sources_gen.go)
+	Source string
+
+	// Citation is the text NOAA asks users of the dataset to cite it with
+	Citation string
+
+	// License describes the terms the dataset may be used under
+	License string
+
+	// Columns lists the columns of the table in the order they are selected by 'SELECT *'
+	Columns []Column
+
+	// Order lists the columns that uniquely order observations from oldest to newest
+	Order []string
+
+	// Presets maps the name of a commonly requested set of fields to the columns in that set
+	Presets map[string][]string
+
+	// Filterable lists the columns filtered by the dedicated query parameters of the dataset's endpoints, eg. 'year'
+	// or 'gt'. Any column may be filtered with the 'filter' parameter.
+	Filterable []string
+
+	// Min and Max bound the measurements that may be used in a query, eg. Co2PpmMin and Co2PpmMax
+	Min, Max float64
+
+	// Unit is the unit the concentrations of the dataset are stored in. Columns in this unit are converted when
+	// clients request another unit (see Conversion).
+	Unit string
+
+	// Units lists the units the concentrations of the dataset may be converted to, including Unit
+	Units []string
+
+	// MolarMass is the molar mass in g/mol of the measured gas, used to convert its concentrations to mass concentrations
+	MolarMass float64
+
+	// Missing is the value NOAA uses in place of a measurement that could not be made
+	Missing float32
+
+	// Entry is the struct a complete row of the table is loaded into. The types of its fields define
+	// the types of the columns in columnar exports.
+	Entry interface{}
+}
+
+// noaaLicense is the License of the datasets published by NOAA GML, which are works of the U.S. Government.
+const noaaLicense = "Public domain. NOAA GML data are freely available, see gml.noaa.gov/about/disclaimer.html."
+
+// Datasets lists every dataset served by the API.
+var Datasets = []Dataset{Co2WeeklyMlo, Ch4MmGl}
+
+// DatasetById looks up a dataset served by the API by its Id.
+func DatasetById(id string) (Dataset, bool) {
+	for _, dataset := range Datasets {
+		if dataset.Id == id {
+			return dataset, true
+		}
+	}
+	return Dataset{}, false
+}
+
+// Column looks up a column of the dataset by name.
+func (dataset Dataset) Column(name string) (Column, bool) {
+	for _, col := range dataset.Columns {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return Column{}, false
+}
+
+// IsMissing reports whether a value of a float column marks a missing measurement.
+func (dataset Dataset) IsMissing(val float32) bool {
+	return val == dataset.Missing
+}
+
+// ColumnNames returns the names of all columns in the dataset.
+func (dataset Dataset) ColumnNames() []string {
+	names := make([]string, len(dataset.Columns))
+	for i, col := range dataset.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// Fields looks up a list of columns by name. An error listing the names of all columns in the dataset
+// is returned if a name is unknown.
+func (dataset Dataset) Fields(names []string) ([]Column, error) {
+	var cols []Column
+	selected := make(map[string]bool)
+
+	for _, name := range names {
+		col, ok := dataset.Column(name)
+		if !ok {
+			return nil, fmt.Errorf("malformed query parameters, unknown field '%v'. Allowed fields are: %v", name, strings.Join(dataset.ColumnNames(), ", "))
+		}
+		if selected[name] {
+			return nil, fmt.Errorf("malformed query parameters, field '%v' was requested more than once", name)
+		}
+		selected[name] = true
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+// OrderBy translates a list of sort keys into an expression for the ORDER BY keyword. Each key is the name of a
+// column, optionally prefixed with '-' to sort in descending order. The dataset's default order is appended to the
+// expression for any column not already sorted on, so that rows are always returned in a stable order across pages.
+func (dataset Dataset) OrderBy(keys []string) (string, error) {
+	var exprs []string
+	sorted := make(map[string]bool)
+
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, "-")
+		if _, ok := dataset.Column(name); !ok {
+			return "", fmt.Errorf("malformed query parameters, cannot sort by '%v'. Sortable fields are: %v", name, strings.Join(dataset.ColumnNames(), ", "))
+		}
+		if sorted[name] {
+			return "", fmt.Errorf("malformed query parameters, cannot sort by '%v' more than once", name)
+		}
+		sorted[name] = true
+
+		if strings.HasPrefix(key, "-") {
+			exprs = append(exprs, name+" DESC")
+		} else {
+			exprs = append(exprs, name)
+		}
+	}
+
+	for _, name := range dataset.Order {
+		if !sorted[name] {
+			exprs = append(exprs, name)
+		}
+	}
+	return strings.Join(exprs, ","), nil
+}
+
+// Next returns the date of the observation period following the one starting at date.
+func (cadence Cadence) Next(date time.Time) time.Time {
+	switch cadence {
+	case Weekly:
+		return date.AddDate(0, 0, 7)
+	default:
+		return date.AddDate(0, 1, 0)
+	}
+}
+
+// PeriodWhere returns a list of SQL WHERE expressions selecting the observation period containing date.
+// Weekly observations do not always fall on the same weekday from one year to the next, so the
+// period is matched as the week centered on date.
+func (cadence Cadence) PeriodWhere(date time.Time) []string {
+	switch cadence {
+	case Weekly:
+		return []string{fmt.Sprintf("yyyymmdd BETWEEN '%s' AND '%s'", date.AddDate(0, 0, -3).Format("2006-01-02"), date.AddDate(0, 0, 3).Format("2006-01-02"))}
+	default:
+		return []string{fmt.Sprintf("year = %d", date.Year()), fmt.Sprintf("month = %d", int(date.Month()))}
+	}
+}
+
+// Freshness reports how up to date a dataset is relative to its expected cadence.
+type Freshness struct {
+	// Cadence is the expected interval between observations
+	Cadence Cadence `v2:"cadence"`
+
+	// Observed is the date of the most recent observation in the dataset
+	Observed time.Time `v2:"observed,date"`
+
+	// Ingested is the time the dataset was last loaded into the database. It is omitted when unknown.
+	Ingested *time.Time `json:",omitempty" v2:"ingested"`
+
+	// Expected is the time by which the next observation should have been published
+	Expected time.Time `v2:"expected"`
+
+	// AgeDays is the number of days elapsed since the most recent observation
+	AgeDays int `v2:"age_days"`
+
+	// PeriodsBehind is the number of published observations the dataset is missing
+	PeriodsBehind int `v2:"periods_behind"`
+
+	// Stale is true when the dataset has missed at least one expected observation
+	Stale bool `v2:"stale"`
+}
+
+// Freshness computes the Freshness of a dataset whose most recent observation is dated observed.
+func (dataset Dataset) Freshness(observed time.Time, ingested *time.Time, now time.Time) Freshness {
+	// An observation is expected once its period has ended and the publishing lag has passed
+	next := dataset.Cadence.Next(observed)
+	behind := 0
+	for !dataset.Cadence.Next(next).Add(dataset.Lag).After(now) {
+		behind++
+		next = dataset.Cadence.Next(next)
+	}
+
+	return Freshness{
+		Cadence:       dataset.Cadence,
+		Observed:      observed,
+		Ingested:      ingested,
+		Expected:      dataset.Cadence.Next(next).Add(dataset.Lag),
+		AgeDays:       int(now.Sub(observed).Hours() / 24),
+		PeriodsBehind: behind,
+		Stale:         behind > 0,
+	}
+}
+
+// Metadata describes the dataset the results of a response are read from.
+type Metadata struct {
+	Dataset string `v2:"id"`
+
+	Site string `v2:"site"`
+
+	Source string `v2:"source"`
+
+	Citation string `v2:"citation"`
+
+	License string `v2:"license"`
+
+	// LastUpdated is the time the dataset was last loaded into the database. It is null when unknown.
+	LastUpdated *time.Time `v2:"last_updated"`
+
+	// Units holds the unit of each field of the results that has one
+	Units Fields `v2:"units"`
+}
+
+// Metadata returns the Metadata of the dataset for results holding the given columns. Units are read from the
+// dataset's own columns, so that the Dataset of a Conversion reports the units results were converted to.
+func (dataset Dataset) Metadata(columns []Column, lastUpdated *time.Time) Metadata {
+	units := Fields{Columns: []Column{}, Values: []interface{}{}}
+	for _, col := range columns {
+		if own, ok := dataset.Column(col.Name); ok {
+			col.Unit = own.Unit
+		}
+		if col.Unit != "" {
+			units.Columns = append(units.Columns, col)
+			units.Values = append(units.Values, col.Unit)
+		}
+	}
+
+	return Metadata{
+		Dataset:     dataset.Id,
+		Site:        dataset.Site,
+		Source:      dataset.Source,
+		Citation:    dataset.Citation,
+		License:     dataset.License,
+		LastUpdated: lastUpdated,
+		Units:       units,
+	}
+}
+
+// Coverage summarizes the observations held by a dataset in the database.
+type Coverage struct {
+	// First and Last are the dates of the oldest and most recent observations. They are nil when the dataset is empty.
+	First *time.Time `v2:"first,date"`
+
+	Last *time.Time `v2:"last,date"`
+
+	// Rows is the number of observations
+	Rows int `v2:"rows"`
+
+	// LastIngested is the time the dataset was last loaded into the database. It is nil when unknown.
+	LastIngested *time.Time `v2:"last_ingested"`
+}
+
+// DatasetInfo describes a dataset in the catalog of the API.
+type DatasetInfo struct {
+	Id string `v2:"id"`
+
+	Description string `v2:"description"`
+
+	Cadence Cadence `v2:"cadence"`
+
+	Site string `v2:"site"`
+
+	Source string `v2:"source"`
+
+	Citation string `v2:"citation"`
+
+	License string `v2:"license"`
+
+	// Fields lists the fields of the measurements, named as in the 'fields' query parameter
+	Fields []FieldInfo `v2:"fields"`
+
+	// Filterable lists the fields filtered by dedicated query parameters
+	Filterable []string `v2:"filterable"`
+
+	Bounds Bounds `v2:"bounds"`
+
+	Coverage Coverage `v2:"coverage"`
+}
+
+// FieldInfo describes a field of the measurements of a dataset.
+type FieldInfo struct {
+	Name string `v2:"name"`
+
+	Type ColumnType `v2:"type"`
+
+	// Unit is the unit of the field. It is empty for fields without a unit.
+	Unit string `v2:"unit"`
+}
+
+// Bounds are the bounds of the measurements that may be used in a query.
+type Bounds struct {
+	Min float64 `v2:"min"`
+
+	Max float64 `v2:"max"`
+}
+
+// Info returns the description of the dataset in the catalog of the API.
+func (dataset Dataset) Info(coverage Coverage) DatasetInfo {
+	fields := make([]FieldInfo, len(dataset.Columns))
+	for i, col := range dataset.Columns {
+		fields[i] = FieldInfo{Name: col.Name, Type: col.Type, Unit: col.Unit}
+	}
+
+	return DatasetInfo{
+		Id:          dataset.Id,
+		Description: dataset.Description,
+		Cadence:     dataset.Cadence,
+		Site:        dataset.Site,
+		Source:      dataset.Source,
+		Citation:    dataset.Citation,
+		License:     dataset.License,
+		Fields:      fields,
+		Filterable:  dataset.Filterable,
+		Bounds:      Bounds{Min: dataset.Min, Max: dataset.Max},
+		Coverage:    coverage,
+	}
+}
+
+// Latest represents the most recent observation in a dataset along with the observations
+// for the same period one and ten years earlier. Past observations are nil when missing.
+type Latest struct {
+	Latest interface{} `v2:"latest"`
+
+	OneYearAgo interface{} `v2:"one_year_ago"`
+
+	TenYearsAgo interface{} `v2:"ten_years_ago"`
+
+	Freshness Freshness `v2:"freshness"`
+}
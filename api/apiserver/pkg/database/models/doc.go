@@ -21,5 +21,7 @@ https://www.gnu.org/licenses/
 Contact: planetpulse.api@gmail.com
 */
 
+//go:generate go run ../../../cmd/sourcegen -sources ../../../../../pipeline/intake/sources -out sources_gen.go
+
 // Package models provides datastructures and methods for modeling and parsing data returned from a database query.
 package models
@@ -23,7 +23,14 @@ Contact: planetpulse.api@gmail.com
 
 package models
 
-import "database/sql"
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
 
 // DataObject represents any struct/type which will hold data returned from a
 // database query.
@@ -37,6 +44,105 @@ type DataObject interface {
 	Load(rows *sql.Rows, simple bool) error
 }
 
+// Fields represents a single measurement restricted to a subset of the columns of a dataset.
+// It is encoded as a JSON object holding the requested fields in the order they were requested.
+type Fields struct {
+	Columns []Column
+
+	Values []interface{}
+}
+
+// MarshalJSON encodes Fields as a JSON object keyed by the response field name of each column.
+func (fields Fields) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, col := range fields.Columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(col.Field)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(fields.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Values returns the values of the requested columns of an entry loaded from a dataset table.
+// The entry may either be a Fields object holding the same columns or a struct with a field for each column.
+func Values(entry interface{}, columns []Column) ([]interface{}, error) {
+	if fields, ok := entry.(Fields); ok {
+		return fields.Values, nil
+	}
+
+	val := reflect.ValueOf(entry)
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot read the fields of an entry of type '%T'", entry)
+	}
+
+	values := make([]interface{}, len(columns))
+	for i, col := range columns {
+		field := val.FieldByName(col.Field)
+		if !field.IsValid() {
+			return nil, fmt.Errorf("entry of type '%T' has no field '%v'", entry, col.Field)
+		}
+		values[i] = field.Interface()
+	}
+	return values, nil
+}
+
+// FieldsTable loads the selected columns of each row returned from a query as Fields objects.
+// Entries usually points at a dataset's own table (eg. a Co2Table) so that handlers can treat
+// projected and complete measurements alike.
+type FieldsTable struct {
+	Columns []Column
+
+	Entries *[]interface{}
+}
+
+// Load imports the results of a database query into the FieldsTable entries
+func (fieldsTable *FieldsTable) Load(rows *sql.Rows, simple bool) error {
+	values := make([]interface{}, len(fieldsTable.Columns))
+	dest := make([]interface{}, len(fieldsTable.Columns))
+	for i, col := range fieldsTable.Columns {
+		switch col.Type {
+		case Integer:
+			dest[i] = new(int)
+		case Float:
+			dest[i] = new(float32)
+		case Date:
+			dest[i] = new(time.Time)
+		default:
+			return fmt.Errorf("cannot load column '%v' of unknown type '%v'", col.Name, col.Type)
+		}
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	for i, d := range dest {
+		switch v := d.(type) {
+		case *int:
+			values[i] = *v
+		case *float32:
+			values[i] = *v
+		case *time.Time:
+			values[i] = *v
+		}
+	}
+	*fieldsTable.Entries = append(*fieldsTable.Entries, Fields{Columns: fieldsTable.Columns, Values: values})
+	return nil
+}
+
 type ServerResp struct {
 	Results []interface{} `json:",omitempty"`
 
@@ -44,6 +150,9 @@ type ServerResp struct {
 
 	RequestId string
 
+	// Metadata describes the dataset the results are read from. It is omitted from responses without a dataset.
+	Metadata *Metadata `json:",omitempty"`
+
 	// Error holds an ErrorResp object. Should this be nil,
 	// it will not be included in the response.
 	Error *ErrorResp `json:",omitempty"`
@@ -58,3 +167,40 @@ type ErrorResp struct {
 	// call chain to the response handler.
 	Message string
 }
+
+// Problem represents error context as RFC 7807 problem details, returned instead of an ErrorResp to clients
+// accepting 'application/problem+json'.
+type Problem struct {
+	// Type is a URI identifying the kind of problem, which documents its code
+	Type string `json:"type"`
+
+	// Title is a short summary of the kind of problem, eg. 'Parameter out of range'
+	Title string `json:"title"`
+
+	// Status is the HTTP status of the response
+	Status int `json:"status"`
+
+	// Detail explains this occurrence of the problem. It is the Message of the legacy ErrorResp.
+	Detail string `json:"detail,omitempty"`
+
+	// Instance is the path of the request
+	Instance string `json:"instance,omitempty"`
+
+	// Code is the stable code of the problem in the error catalog, eg. 'OUT_OF_RANGE'
+	Code string `json:"code"`
+
+	// Param is the name of the parameter at fault, if any
+	Param string `json:"param,omitempty"`
+
+	// Errors lists the problems with the parameters of the request one by one, when they were checked together
+	Errors []ProblemError `json:"errors,omitempty"`
+
+	RequestId string `json:"requestId"`
+}
+
+// ProblemError is a single problem with the parameters of a request, listed in Problem.Errors.
+type ProblemError struct {
+	Code   string `json:"code"`
+	Param  string `json:"param,omitempty"`
+	Detail string `json:"detail"`
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Units concentrations may be converted to. Each dataset lists the units it supports in Dataset.Units.
+const (
+	// Ppm is parts per million, a mole fraction in µmol/mol
+	Ppm = "ppm"
+
+	// Ppb is parts per billion, a mole fraction in nmol/mol
+	Ppb = "ppb"
+
+	// UgPerM3 is micrograms of the gas per cubic meter of air, at the Conditions of the Conversion
+	UgPerM3 = "ug/m3"
+
+	// GtC is gigatonnes of carbon held in the atmosphere. Only CO2 may be converted to GtC.
+	GtC = "GtC"
+)
+
+const (
+	// StandardTemperature is the temperature in kelvin mass concentrations are computed at by default
+	StandardTemperature = 273.15
+
+	// StandardPressure is the pressure in pascals mass concentrations are computed at by default
+	StandardPressure = 101325
+
+	// gasConstant is the molar gas constant in J/(mol·K)
+	gasConstant = 8.314462618
+
+	// gtcPerPpm is the mass of carbon in gigatonnes held in the atmosphere by a CO2 mole fraction of 1 ppm
+	gtcPerPpm = 2.124
+)
+
+// Conditions are the temperature and pressure of the air mass concentrations are computed at.
+type Conditions struct {
+	// Temperature is the temperature in kelvin
+	Temperature float64
+
+	// Pressure is the pressure in pascals
+	Pressure float64
+}
+
+// StandardConditions are the Conditions used when none are configured.
+var StandardConditions = Conditions{Temperature: StandardTemperature, Pressure: StandardPressure}
+
+// Conversion converts the concentrations of a dataset from the unit they are stored in to another unit.
+// Every conversion is linear, so differences and uncertainties are converted like the concentrations themselves.
+type Conversion struct {
+	// Unit is the unit concentrations are converted to
+	Unit string
+
+	// Factor is the value in Unit of a concentration of one unit of the dataset
+	Factor float64
+
+	// Dataset is the converted dataset, whose concentration columns are in Unit
+	Dataset Dataset
+
+	// columns holds the names of the columns holding concentrations
+	columns map[string]bool
+}
+
+// Conversion returns the Conversion of the dataset's concentrations to unit. Mass concentrations are computed at
+// conditions, or at StandardConditions when conditions are zero.
+func (dataset Dataset) Conversion(unit string, conditions Conditions) (Conversion, error) {
+	supported := false
+	for _, u := range dataset.Units {
+		if u == unit {
+			supported = true
+		}
+	}
+	if !supported {
+		return Conversion{}, fmt.Errorf("malformed query parameters, unknown unit '%v'. Allowed units are: %v", unit, strings.Join(dataset.Units, ", "))
+	}
+	if conditions == (Conditions{}) {
+		conditions = StandardConditions
+	}
+
+	conversion := Conversion{
+		Unit:    unit,
+		Factor:  dataset.perPpm(unit, conditions) / dataset.perPpm(dataset.Unit, conditions),
+		Dataset: dataset,
+		columns: make(map[string]bool),
+	}
+
+	// The columns of the converted dataset are copied so that the dataset's own columns are left untouched
+	conversion.Dataset.Columns = make([]Column, len(dataset.Columns))
+	for i, col := range dataset.Columns {
+		if col.Unit == dataset.Unit {
+			conversion.columns[col.Name] = true
+			col.Unit = unit
+		}
+		conversion.Dataset.Columns[i] = col
+	}
+	conversion.Dataset.Unit = unit
+	conversion.Dataset.Min, conversion.Dataset.Max = conversion.Value(dataset.Min), conversion.Value(dataset.Max)
+	return conversion, nil
+}
+
+// perPpm returns the value in unit of a concentration of 1 ppm of the dataset's gas.
+func (dataset Dataset) perPpm(unit string, conditions Conditions) float64 {
+	switch unit {
+	case Ppb:
+		return 1000
+	case UgPerM3:
+		// 1 µmol of gas per mol of air, times the moles of air per cubic meter, times the molar mass of the gas
+		return dataset.MolarMass * conditions.Pressure / (gasConstant * conditions.Temperature)
+	case GtC:
+		return gtcPerPpm
+	}
+	return 1
+}
+
+// Converts reports whether the values of col are converted.
+func (conversion Conversion) Converts(col Column) bool {
+	return conversion.columns[col.Name]
+}
+
+// Value converts a concentration from the unit of the dataset to Unit. The value marking missing measurements is
+// the same in every unit.
+func (conversion Conversion) Value(val float64) float64 {
+	if conversion.Dataset.IsMissing(float32(val)) {
+		return val
+	}
+	return val * conversion.Factor
+}
+
+// Canonical converts a concentration from Unit back to the unit of the dataset.
+func (conversion Conversion) Canonical(val float64) float64 {
+	if conversion.Dataset.IsMissing(float32(val)) {
+		return val
+	}
+	return val / conversion.Factor
+}
+
+// Entry returns a copy of an entry loaded from the dataset table with its concentrations converted. The entry may
+// either be a Fields object or a struct with a field for each column, as read by Values.
+func (conversion Conversion) Entry(entry interface{}) interface{} {
+	if fields, ok := entry.(Fields); ok {
+		values := make([]interface{}, len(fields.Values))
+		for i, val := range fields.Values {
+			values[i] = val
+			if v, ok := val.(float32); ok && conversion.Converts(fields.Columns[i]) {
+				values[i] = float32(conversion.Value(float64(v)))
+			}
+		}
+		return Fields{Columns: fields.Columns, Values: values}
+	}
+
+	val := reflect.ValueOf(entry)
+	if val.Kind() != reflect.Struct {
+		return entry
+	}
+	converted := reflect.New(val.Type()).Elem()
+	converted.Set(val)
+	for _, col := range conversion.Dataset.Columns {
+		field := converted.FieldByName(col.Field)
+		if conversion.Converts(col) && field.IsValid() && field.Kind() == reflect.Float32 {
+			field.SetFloat(conversion.Value(field.Float()))
+		}
+	}
+	return converted.Interface()
+}
+
+// ConvertedTable wraps a DataObject, converting the concentrations of each entry it loads into Entries.
+// Entries usually points at the table the DataObject loads into.
+type ConvertedTable struct {
+	DataObject
+
+	Entries *[]interface{}
+
+	Conversion Conversion
+}
+
+// Load imports the results of a database query with the wrapped DataObject and converts the new entries
+func (convertedTable *ConvertedTable) Load(rows *sql.Rows, simple bool) error {
+	loaded := len(*convertedTable.Entries)
+	if err := convertedTable.DataObject.Load(rows, simple); err != nil {
+		return err
+	}
+	for i := loaded; i < len(*convertedTable.Entries); i++ {
+		(*convertedTable.Entries)[i] = convertedTable.Conversion.Entry((*convertedTable.Entries)[i])
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ServerRespV2 is the envelope of the responses of the v2 API. Unlike ServerResp, its members are named in
+// lower_snake case and describe the response in a meta object.
+type ServerRespV2 struct {
+	// Data holds the results of a successful response. It is omitted from errors.
+	Data interface{} `json:"data,omitempty"`
+
+	Meta MetaV2 `json:"meta"`
+
+	// Error holds the error of a failed response. It is omitted from successful responses.
+	Error *ErrorRespV2 `json:"error,omitempty"`
+}
+
+// MetaV2 describes a response of the v2 API.
+type MetaV2 struct {
+	// ApiVersion is the version of the API that served the response, eg. 'v2'
+	ApiVersion string `json:"api_version"`
+
+	// Status is either 'ok' or 'error'
+	Status string `json:"status"`
+
+	RequestId string `json:"request_id"`
+
+	// Dataset describes the dataset the results are read from, if any. It holds the v2 representation of a Metadata.
+	Dataset interface{} `json:"dataset,omitempty"`
+
+	// Count is the number of results in the data of a successful response
+	Count *int `json:"count,omitempty"`
+}
+
+// ErrorRespV2 represents error context returned from the v2 API. It holds the same members as a Problem.
+type ErrorRespV2 struct {
+	// Status is the HTTP status of the response
+	Status int `json:"status"`
+
+	// Code is the stable code of the error in the error catalog, eg. 'OUT_OF_RANGE'
+	Code string `json:"code"`
+
+	// Title is a short summary of the kind of error, eg. 'Parameter out of range'
+	Title string `json:"title"`
+
+	// Message explains this occurrence of the error
+	Message string `json:"message"`
+
+	// Param is the name of the parameter at fault, if any
+	Param string `json:"param,omitempty"`
+
+	// Errors lists the problems with the parameters of the request one by one, when they were checked together
+	Errors []ProblemError `json:"errors,omitempty"`
+}
+
+// Object is a JSON object encoding its members in order.
+type Object struct {
+	Keys []string
+
+	Values []interface{}
+}
+
+// MarshalJSON encodes an Object as a JSON object holding its members in order.
+func (obj Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range obj.Keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(obj.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// V2 returns the representation of a value in the responses of the v2 API. Struct fields are named with their v2
+// tag, or after the column of the dataset they hold, so that measurements are named as in the 'fields', 'sort' and
+// 'filter' parameters. Dates are written as 'yyyy-mm-dd', and missing measurements of the dataset as null. A tag of
+// the form 'name,date' marks a field holding a date, and '-' a field left out.
+func V2(value interface{}, dataset *Dataset) (interface{}, error) {
+	return v2Value(reflect.ValueOf(value), dataset, false)
+}
+
+func v2Value(val reflect.Value, dataset *Dataset, date bool) (interface{}, error) {
+	if !val.IsValid() {
+		return nil, nil
+	}
+
+	switch v := val.Interface().(type) {
+	case Fields:
+		return v2Fields(v, dataset)
+	case time.Time:
+		if date {
+			return v.Format("2006-01-02"), nil
+		}
+		return v, nil
+	case float32:
+		if dataset != nil && dataset.IsMissing(v) {
+			return nil, nil
+		}
+		return v, nil
+	}
+
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if val.IsNil() {
+			return nil, nil
+		}
+		return v2Value(val.Elem(), dataset, date)
+	case reflect.Slice:
+		if val.IsNil() {
+			return nil, nil
+		}
+		items := make([]interface{}, val.Len())
+		for i := range items {
+			item, err := v2Value(val.Index(i), dataset, date)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case reflect.Struct:
+		return v2Struct(val, dataset)
+	}
+	return val.Interface(), nil
+}
+
+// v2Struct returns the v2 representation of a struct. Every exported field must be named.
+func v2Struct(val reflect.Value, dataset *Dataset) (interface{}, error) {
+	typ := val.Type()
+	obj := Object{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, date := "", false
+		if tag, ok := field.Tag.Lookup("v2"); ok {
+			parts := strings.Split(tag, ",")
+			name, date = parts[0], len(parts) > 1 && parts[1] == "date"
+		} else if col, ok := dataset.columnOfField(field.Name); ok {
+			name, date = col.Name, col.Type == Date
+		}
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			return nil, fmt.Errorf("field '%v' of '%v' has no name in the v2 API", field.Name, typ)
+		}
+
+		value, err := v2Value(val.Field(i), dataset, date)
+		if err != nil {
+			return nil, err
+		}
+		obj.Keys = append(obj.Keys, name)
+		obj.Values = append(obj.Values, value)
+	}
+	return obj, nil
+}
+
+// v2Fields returns the v2 representation of a measurement restricted to a subset of the columns of a dataset.
+func v2Fields(fields Fields, dataset *Dataset) (interface{}, error) {
+	obj := Object{Keys: make([]string, len(fields.Columns)), Values: make([]interface{}, len(fields.Columns))}
+	for i, col := range fields.Columns {
+		value, err := v2Value(reflect.ValueOf(fields.Values[i]), dataset, col.Type == Date)
+		if err != nil {
+			return nil, err
+		}
+		obj.Keys[i], obj.Values[i] = col.Name, value
+	}
+	return obj, nil
+}
+
+// columnOfField looks up the column of a dataset held by a field of its entries. A nil dataset has no columns.
+func (dataset *Dataset) columnOfField(field string) (Column, bool) {
+	if dataset == nil {
+		return Column{}, false
+	}
+	for _, col := range dataset.Columns {
+		if col.Field == field {
+			return col, true
+		}
+	}
+	return Column{}, false
+}
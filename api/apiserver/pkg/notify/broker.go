@@ -0,0 +1,212 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is the number of events buffered for each subscriber. Subscribers that fall further
+// behind are dropped, and resume from the history of the broker when they reconnect.
+const subscriberBuffer = 64
+
+// Change describes an observation that was added to or revised in a dataset.
+type Change struct {
+	// Dataset is the Id of the dataset that changed
+	Dataset string
+
+	// Date is the date of the observation
+	Date time.Time
+
+	// Revised is true when an observation that was already published changed
+	Revised bool
+
+	// Entry holds the observation as it now reads in the database
+	Entry interface{}
+}
+
+// Notifier detects changes to the datasets.
+type Notifier interface {
+	// Watch sends every change it detects on changes until ctx is done or it fails.
+	Watch(ctx context.Context, changes chan<- Change) error
+}
+
+// NotifierFunc adapts a function to the Notifier interface.
+type NotifierFunc func(ctx context.Context, changes chan<- Change) error
+
+// Watch calls f(ctx, changes).
+func (f NotifierFunc) Watch(ctx context.Context, changes chan<- Change) error {
+	return f(ctx, changes)
+}
+
+// Event is a change published by a Broker. Event Ids are increasing, and are derived from the time the
+// change was published so that they keep increasing across restarts of the server.
+type Event struct {
+	Id uint64
+
+	Change
+}
+
+// Subscription receives the events of the datasets a subscriber is interested in.
+type Subscription struct {
+	// Replay holds the events published after the last event the subscriber received, oldest first
+	Replay []Event
+
+	// Missed is true when some of the events published after the last event the subscriber received
+	// are no longer held by the broker, eg. because the server restarted.
+	Missed bool
+
+	// Events receives events as they are published. It is closed when the subscriber falls too far behind.
+	Events <-chan Event
+
+	events   chan Event
+	datasets map[string]bool
+}
+
+// wants reports whether the subscription is interested in an event.
+func (sub *Subscription) wants(event Event) bool {
+	return len(sub.datasets) == 0 || sub.datasets[event.Dataset]
+}
+
+// Broker publishes changes to subscribers. It keeps a bounded history of recent events so that subscribers
+// can resume where they left off after reconnecting.
+type Broker struct {
+	mu          sync.Mutex
+	history     []Event
+	size        int
+	lastId      uint64
+	evicted     uint64
+	subscribers map[*Subscription]bool
+
+	// now returns the current time. It is replaced in tests.
+	now func() time.Time
+}
+
+// NewBroker returns a Broker holding at most size events in its history.
+func NewBroker(size int) *Broker {
+	broker := &Broker{size: size, subscribers: map[*Subscription]bool{}, now: time.Now}
+
+	// Events published before the broker was created, eg. by a previous server process, are unknown
+	broker.evicted = broker.nextId()
+	broker.lastId = broker.evicted
+	return broker
+}
+
+// nextId returns an Id greater than any published so far.
+func (broker *Broker) nextId() uint64 {
+	id := uint64(broker.now().UnixNano() / int64(time.Millisecond))
+	if id <= broker.lastId {
+		id = broker.lastId + 1
+	}
+	return id
+}
+
+// Run publishes the changes detected by notifier until ctx is done or the notifier fails.
+func (broker *Broker) Run(ctx context.Context, notifier Notifier) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changes := make(chan Change)
+	done := make(chan error, 1)
+	go func() {
+		done <- notifier.Watch(ctx, changes)
+	}()
+
+	for {
+		select {
+		case change := <-changes:
+			broker.Publish(change)
+		case err := <-done:
+			return err
+		}
+	}
+}
+
+// Publish assigns the next Id to a change and sends it to every interested subscriber.
+func (broker *Broker) Publish(change Change) Event {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	event := Event{Id: broker.nextId(), Change: change}
+	broker.lastId = event.Id
+
+	broker.history = append(broker.history, event)
+	if len(broker.history) > broker.size {
+		broker.evicted = broker.history[0].Id
+		broker.history = append(broker.history[:0], broker.history[1:]...)
+	}
+
+	for sub := range broker.subscribers {
+		if !sub.wants(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			// The subscriber is not keeping up. It will resume from the history when it reconnects.
+			delete(broker.subscribers, sub)
+			close(sub.events)
+		}
+	}
+	return event
+}
+
+// Subscribe subscribes to the events of the given datasets, or of every dataset when none are given.
+// When resume is true, the events published after lastId are replayed. The subscription must be closed
+// with Unsubscribe.
+func (broker *Broker) Subscribe(datasets []string, lastId uint64, resume bool) *Subscription {
+	sub := &Subscription{events: make(chan Event, subscriberBuffer), datasets: map[string]bool{}}
+	sub.Events = sub.events
+	for _, dataset := range datasets {
+		sub.datasets[dataset] = true
+	}
+
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	if resume {
+		sub.Missed = lastId < broker.evicted
+		for _, event := range broker.history {
+			if event.Id > lastId && sub.wants(event) {
+				sub.Replay = append(sub.Replay, event)
+			}
+		}
+	}
+
+	broker.subscribers[sub] = true
+	return sub
+}
+
+// Unsubscribe stops sending events to a subscription.
+func (broker *Broker) Unsubscribe(sub *Subscription) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	if broker.subscribers[sub] {
+		delete(broker.subscribers, sub)
+		close(sub.events)
+	}
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+// Package notify detects new and revised observations in the datasets and fans them out to subscribers,
+// such as the clients of the /v1/stream endpoint.
+//
+// Changes are detected by a Notifier. PollNotifier polls the database for the date of the latest observation
+// and the last ingestion of each dataset, while ListenNotifier is woken up by Postgres NOTIFY messages sent when
+// the ingestion pipeline logs an ingestion (see pipeline/sql/create_ingest_log_notify.sql). Any other Notifier,
+// eg. a NotifierFunc feeding changes by hand in tests, can be plugged into a Broker.
+package notify
@@ -0,0 +1,264 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package notify
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// newTestBroker returns a broker whose clock only moves when the returned function is called.
+func newTestBroker(size int) (*Broker, func(time.Duration)) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	broker := &Broker{size: size, subscribers: map[*Subscription]bool{}, now: func() time.Time { return now }}
+	broker.evicted = broker.nextId()
+	broker.lastId = broker.evicted
+	return broker, func(d time.Duration) { now = now.Add(d) }
+}
+
+func change(dataset string, day int) Change {
+	return Change{Dataset: dataset, Date: time.Date(2021, 5, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestBrokerIds(t *testing.T) {
+	broker, tick := newTestBroker(10)
+	first := broker.Publish(change("co2_weekly_mlo", 1))
+	second := broker.Publish(change("co2_weekly_mlo", 2))
+	if second.Id <= first.Id {
+		t.Errorf("Wanted increasing event Ids when published in the same millisecond, Got: %v then %v", first.Id, second.Id)
+	}
+
+	tick(time.Hour)
+	if third := broker.Publish(change("co2_weekly_mlo", 3)); third.Id != uint64(time.Date(2021, 6, 1, 1, 0, 0, 0, time.UTC).UnixNano()/int64(time.Millisecond)) {
+		t.Errorf("Wanted event Ids derived from the publication time, Got: %v after %v", third.Id, second.Id)
+	}
+}
+
+func TestBrokerSubscribe(t *testing.T) {
+	broker, tick := newTestBroker(3)
+	start := broker.lastId
+
+	var published []Event
+	for day := 1; day <= 4; day++ {
+		tick(time.Second)
+		dataset := "co2_weekly_mlo"
+		if day%2 == 0 {
+			dataset = "ch4_mm_gl"
+		}
+		published = append(published, broker.Publish(change(dataset, day)))
+	}
+
+	testVals := []struct {
+		name     string
+		datasets []string
+		lastId   uint64
+		resume   bool
+		replay   []Event
+		missed   bool
+	}{
+		{"new subscriber", nil, 0, false, nil, false},
+		{"resumed subscriber", nil, published[1].Id, true, published[2:], false},
+		{"filtered subscriber", []string{"ch4_mm_gl"}, published[1].Id, true, published[3:], false},
+		{"up to date subscriber", nil, published[3].Id, true, nil, false},
+		{"subscriber missing evicted events", nil, published[0].Id - 1, true, published[1:], true},
+		{"subscriber from a previous server", nil, start - 1, true, published[1:], true},
+	}
+
+	for _, testVal := range testVals {
+		sub := broker.Subscribe(testVal.datasets, testVal.lastId, testVal.resume)
+		if len(sub.Replay) != len(testVal.replay) {
+			t.Errorf("Wanted %v events replayed for a %v, Got: %v", len(testVal.replay), testVal.name, sub.Replay)
+		} else {
+			for i := range sub.Replay {
+				if sub.Replay[i].Id != testVal.replay[i].Id {
+					t.Errorf("Wanted event %v replayed for a %v, Got: %v", testVal.replay[i].Id, testVal.name, sub.Replay[i].Id)
+				}
+			}
+		}
+		if sub.Missed != testVal.missed {
+			t.Errorf("Wanted missed=%v for a %v, Got: %v", testVal.missed, testVal.name, sub.Missed)
+		}
+		broker.Unsubscribe(sub)
+	}
+}
+
+func TestBrokerPublish(t *testing.T) {
+	broker, _ := newTestBroker(10)
+	all := broker.Subscribe(nil, 0, false)
+	ch4 := broker.Subscribe([]string{"ch4_mm_gl"}, 0, false)
+	slow := broker.Subscribe(nil, 0, false)
+	defer broker.Unsubscribe(all)
+	defer broker.Unsubscribe(ch4)
+
+	// Drain the fast subscribers while the slow one falls behind
+	for i := 0; i <= subscriberBuffer; i++ {
+		broker.Publish(change("co2_weekly_mlo", 1))
+		<-all.Events
+	}
+	broker.Publish(change("ch4_mm_gl", 1))
+
+	if event := <-all.Events; event.Dataset != "ch4_mm_gl" {
+		t.Errorf("Wanted the ch4 event, Got: %+v", event)
+	}
+	if event := <-ch4.Events; event.Dataset != "ch4_mm_gl" {
+		t.Errorf("Wanted only ch4 events for a filtered subscriber, Got: %+v", event)
+	}
+
+	count := 0
+	for range slow.Events {
+		count++
+	}
+	if count != subscriberBuffer {
+		t.Errorf("Wanted a slow subscriber to be dropped after %v events, Got: %v", subscriberBuffer, count)
+	}
+	broker.Unsubscribe(slow)
+}
+
+func TestBrokerRun(t *testing.T) {
+	broker, _ := newTestBroker(10)
+	sub := broker.Subscribe(nil, 0, false)
+	defer broker.Unsubscribe(sub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- broker.Run(ctx, NotifierFunc(func(ctx context.Context, changes chan<- Change) error {
+			changes <- change("co2_weekly_mlo", 30)
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+	}()
+
+	if event := <-sub.Events; !event.Date.Equal(change("", 30).Date) {
+		t.Errorf("Wanted the change sent by the notifier, Got: %+v", event)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Wanted Run to stop with the notifier, Got: %v", err)
+	}
+}
+
+var ch4Columns = []string{"year", "month", "day", "date_decimal", "average", "average_unc", "trend", "trend_unc", "yyyymmdd"}
+
+func ch4Rows(averages map[int]float32) *sqlmock.Rows {
+	rows := sqlmock.NewRows(ch4Columns)
+	for month := 1; month <= 12; month++ {
+		if avg, ok := averages[month]; ok {
+			rows.AddRow(2021, month, 1, float32(2021), avg, float32(0.5), avg, float32(0.5), time.Date(2021, time.Month(month), 1, 0, 0, 0, 0, time.UTC))
+		}
+	}
+	return rows
+}
+
+func TestPollNotifier(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error generating mock database: %s", err.Error())
+	}
+	defer db.Close()
+
+	dataset := models.Ch4MmGl
+	poll := func(latest time.Time, ingested interface{}) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(yyyymmdd) FROM public.ch4_mm_gl`)).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(latest))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(ingested_at) FROM public.ingest_log WHERE dataset = $1`)).
+			WithArgs("ch4_mm_gl").
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(ingested))
+	}
+	selectSince := func(since string) *sqlmock.ExpectedQuery {
+		return mock.ExpectQuery(regexp.QuoteMeta(`SELECT year, month, day, date_decimal, average, average_unc, trend, trend_unc, yyyymmdd FROM public.ch4_mm_gl WHERE yyyymmdd >= '` + since + `' ORDER BY year,month`))
+	}
+	ingested := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// The first poll only records the dataset
+	poll(time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), nil)
+	selectSince("2020-02-01").WillReturnRows(ch4Rows(map[int]float32{1: 1890.5, 2: 1891.5}))
+
+	// Nothing moved, so the observations are not read
+	poll(time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), nil)
+
+	// A new month was ingested and January was revised
+	poll(time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), ingested)
+	selectSince("2020-02-29").WillReturnRows(ch4Rows(map[int]float32{1: 1890.25, 2: 1891.5, 3: 1892.5}))
+
+	// A revision alone is detected from the ingestion
+	poll(time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), ingested.Add(time.Hour))
+	selectSince("2020-02-29").WillReturnRows(ch4Rows(map[int]float32{1: 1890.25, 2: 1891.5, 3: 1892.75}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notifier := &PollNotifier{Database: &database.Database{DB: db}, Datasets: []models.Dataset{dataset}, Interval: time.Millisecond}
+	changes := make(chan Change)
+	go notifier.Watch(ctx, changes)
+
+	want := []struct {
+		month   int
+		revised bool
+	}{{1, true}, {3, false}, {3, true}}
+	for _, w := range want {
+		var change Change
+		select {
+		case change = <-changes:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for a change to month %v: %v", w.month, mock.ExpectationsWereMet())
+		}
+		if change.Dataset != dataset.Id || change.Date.Month() != time.Month(w.month) || change.Revised != w.revised {
+			t.Errorf("Wanted a change to month %v with revised=%v, Got: %+v", w.month, w.revised, change)
+		}
+	}
+	cancel()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDetectorGap(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error generating mock database: %s", err.Error())
+	}
+	defer db.Close()
+
+	d := newDetectors(&database.Database{DB: db}, []models.Dataset{models.Ch4MmGl}, 24*time.Hour)[0]
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE yyyymmdd >= '2021-01-31'`)).WillReturnRows(ch4Rows(map[int]float32{2: 1891.5}))
+
+	// Observations published since the last check are all new, even when they are older than the window
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE yyyymmdd >= '2021-02-02'`)).WillReturnRows(ch4Rows(map[int]float32{3: 1892.5, 4: 1893.5}))
+
+	if changes, err := d.check(time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)); err != nil || len(changes) != 0 {
+		t.Fatalf("Wanted no changes from the first check, Got: %v, %v", changes, err)
+	}
+	changes, err := d.check(time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || len(changes) != 2 || changes[0].Revised || changes[1].Revised {
+		t.Errorf("Wanted two new observations, Got: %+v, %v", changes, err)
+	}
+}
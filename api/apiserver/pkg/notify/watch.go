@@ -0,0 +1,311 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package notify
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultWindow is how far back from the latest observation revisions are detected by default.
+	// NOAA revises recent observations as late data is quality controlled.
+	DefaultWindow = 366 * 24 * time.Hour
+
+	// DefaultChannel is the Postgres channel notified when the ingestion pipeline logs an ingestion.
+	DefaultChannel = "planetpulse_ingest"
+)
+
+// dateColumn is the column holding the date of an observation in every dataset
+const dateColumn = "yyyymmdd"
+
+// detector finds the observations of a dataset that were added or revised since it last checked.
+// The first check only records the state of the dataset.
+type detector struct {
+	db      *database.Database
+	dataset models.Dataset
+	window  time.Duration
+
+	// rows maps the date of each observation in the window to the values it was last read with
+	rows map[time.Time]string
+
+	// latest and ingested are the date of the latest observation and the time of the last ingestion
+	// the last time the dataset was checked
+	latest   time.Time
+	ingested time.Time
+}
+
+func newDetectors(db *database.Database, datasets []models.Dataset, window time.Duration) []*detector {
+	if len(datasets) == 0 {
+		datasets = models.Datasets
+	}
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	detectors := make([]*detector, len(datasets))
+	for i, dataset := range datasets {
+		detectors[i] = &detector{db: db, dataset: dataset, window: window}
+	}
+	return detectors
+}
+
+// poll checks the dataset for changes if the date of its latest observation or the time of its last
+// ingestion moved since it was last checked.
+func (d *detector) poll() ([]Change, error) {
+	latest, err := d.db.LatestObservation(d.dataset)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	var ingested time.Time
+	if last, err := d.db.LastIngested(d.dataset); err != nil {
+		return nil, err
+	} else if last != nil {
+		ingested = *last
+	}
+
+	if d.rows != nil && latest.Equal(d.latest) && ingested.Equal(d.ingested) {
+		return nil, nil
+	}
+	d.ingested = ingested
+	return d.check(latest)
+}
+
+// check reads the observations of the window ending at the latest observation and compares them with
+// those read by the previous check.
+func (d *detector) check(latest time.Time) ([]Change, error) {
+	since := latest.Add(-d.window)
+	if d.rows != nil && !d.latest.IsZero() && d.latest.Before(since) {
+		// Every observation published since the last check is new, even those older than the window
+		since = d.latest.AddDate(0, 0, 1)
+	}
+
+	query := database.NewQuery(d.dataset.Table, nil, strings.Join(d.dataset.Order, ","))
+	query.Select(d.dataset.Columns)
+	query.Where = []string{fmt.Sprintf("%v >= '%v'", dateColumn, since.Format("2006-01-02"))}
+	query.Limit = -1
+
+	table := []interface{}{}
+	if err := d.db.Query(query, &models.FieldsTable{Columns: d.dataset.Columns, Entries: &table}); err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	rows := make(map[time.Time]string, len(table))
+	for _, entry := range table {
+		fields := entry.(models.Fields)
+		date, err := d.date(fields)
+		if err != nil {
+			return nil, err
+		}
+		values := fmt.Sprint(fields.Values...)
+		rows[date] = values
+
+		if d.rows == nil {
+			continue
+		}
+		if previous, ok := d.rows[date]; ok && previous != values {
+			changes = append(changes, Change{Dataset: d.dataset.Id, Date: date, Revised: true, Entry: fields})
+		} else if !ok && date.After(d.latest) {
+			changes = append(changes, Change{Dataset: d.dataset.Id, Date: date, Entry: fields})
+		}
+	}
+
+	d.rows = rows
+	d.latest = latest
+	return changes, nil
+}
+
+// date returns the date of an observation.
+func (d *detector) date(fields models.Fields) (time.Time, error) {
+	for i, col := range fields.Columns {
+		if col.Name == dateColumn {
+			if date, ok := fields.Values[i].(time.Time); ok {
+				return date.UTC(), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("observation of dataset '%v' has no date", d.dataset.Id)
+}
+
+// send sends changes until ctx is done.
+func send(ctx context.Context, changes chan<- Change, detected []Change) error {
+	for _, change := range detected {
+		select {
+		case changes <- change:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// PollNotifier detects changes by polling each dataset for the date of its latest observation and the
+// time of its last ingestion. The observations of a dataset are only compared when either moved.
+type PollNotifier struct {
+	Database *database.Database
+
+	// Datasets lists the datasets to watch. Every dataset is watched when it is empty.
+	Datasets []models.Dataset
+
+	// Interval is the time between two polls
+	Interval time.Duration
+
+	// Window is how far back from the latest observation revisions are detected, DefaultWindow when zero
+	Window time.Duration
+}
+
+// Watch polls the datasets until ctx is done. Failed polls are logged and retried at the next interval.
+func (pn *PollNotifier) Watch(ctx context.Context, changes chan<- Change) error {
+	detectors := newDetectors(pn.Database, pn.Datasets, pn.Window)
+	ticker := time.NewTicker(pn.Interval)
+	defer ticker.Stop()
+
+	for {
+		for _, d := range detectors {
+			detected, err := d.poll()
+			if err != nil {
+				log.Errorf("error polling dataset '%v' for changes: %v", d.dataset.Id, err)
+				continue
+			}
+			if err := send(ctx, changes, detected); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// ListenNotifier detects changes when the ingestion pipeline logs an ingestion. It listens on a Postgres
+// channel notified with the Id of the ingested dataset by a trigger on the ingest log. As notifications
+// are lost while the listener is disconnected, every dataset is checked when it reconnects and at each
+// Interval.
+type ListenNotifier struct {
+	Database *database.Database
+
+	// Datasets lists the datasets to watch. Every dataset is watched when it is empty.
+	Datasets []models.Dataset
+
+	// Channel is the channel to listen on, DefaultChannel when empty
+	Channel string
+
+	// Interval is the time between two checks of every dataset
+	Interval time.Duration
+
+	// Window is how far back from the latest observation revisions are detected, DefaultWindow when zero
+	Window time.Duration
+}
+
+// Watch listens for notifications until ctx is done.
+func (ln *ListenNotifier) Watch(ctx context.Context, changes chan<- Change) error {
+	channel := ln.Channel
+	if channel == "" {
+		channel = DefaultChannel
+	}
+
+	listener := pq.NewListener(ln.Database.ConnInfo(), 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Errorf("error listening for dataset changes: %v", err)
+		}
+	})
+	defer listener.Close()
+	if err := listener.Listen(channel); err != nil {
+		return err
+	}
+
+	detectors := newDetectors(ln.Database, ln.Datasets, ln.Window)
+	checkAll := func() error {
+		for _, d := range detectors {
+			if err := ln.check(ctx, d, changes); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ticker := time.NewTicker(ln.Interval)
+	defer ticker.Stop()
+
+	if err := checkAll(); err != nil {
+		return err
+	}
+	for {
+		select {
+		case n := <-listener.Notify:
+			// A nil notification is sent when the connection was re-established
+			if n == nil {
+				if err := checkAll(); err != nil {
+					return err
+				}
+				continue
+			}
+			for _, d := range detectors {
+				if d.dataset.Id == n.Extra {
+					if err := ln.check(ctx, d, changes); err != nil {
+						return err
+					}
+				}
+			}
+		case <-ticker.C:
+			if err := listener.Ping(); err != nil {
+				log.Errorf("error pinging dataset change listener: %v", err)
+			}
+			if err := checkAll(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// check checks a dataset for changes and sends them. Failed checks are logged and retried at the next
+// notification or interval.
+func (ln *ListenNotifier) check(ctx context.Context, d *detector, changes chan<- Change) error {
+	latest, err := d.db.LatestObservation(d.dataset)
+	if err != nil && err != sql.ErrNoRows {
+		log.Errorf("error checking dataset '%v' for changes: %v", d.dataset.Id, err)
+		return nil
+	}
+
+	detected, err := d.check(latest)
+	if err != nil {
+		log.Errorf("error checking dataset '%v' for changes: %v", d.dataset.Id, err)
+		return nil
+	}
+	return send(ctx, changes, detected)
+}
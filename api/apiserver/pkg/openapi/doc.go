@@ -0,0 +1,34 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+// Package openapi embeds the OpenAPI spec of the API (spec-v1.json) and enforces it at runtime.
+//
+// The spec is the source of truth for the routes of the API: it is served at /v1/openapi.json, and a Validator
+// middleware checks the query and path parameters of incoming requests, and the status, content type and JSON
+// body of outgoing responses, against the operation matching each route. Validation can be switched on and off
+// per environment in config.yaml.
+//
+// The validator understands the subset of OpenAPI 3.0 used by the spec: local $refs, the types, formats,
+// enums, bounds, patterns, lengths, properties and items of schemas, nullable values and oneOf alternatives.
+// Alternatives are checked as anyOf, as the response envelopes they describe overlap.
+package openapi
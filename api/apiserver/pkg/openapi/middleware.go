@@ -0,0 +1,265 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package openapi
+
+import (
+	utils "apiserver/pkg/utils"
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// ResponseMode sets what a Validator does with responses.
+type ResponseMode string
+
+const (
+	// ResponsesOff leaves responses unchecked
+	ResponsesOff ResponseMode = "off"
+
+	// ResponsesLog logs responses that do not match the spec, but still sends them
+	ResponsesLog ResponseMode = "log"
+
+	// ResponsesEnforce replaces responses that do not match the spec with a 500 error. The body of JSON responses
+	// is buffered until it has been validated.
+	ResponsesEnforce ResponseMode = "enforce"
+)
+
+// Validator is a gorilla mux middleware validating requests and responses against the operation of a spec
+// matching the path template of each route. Routes outside the base path of the spec and of its versions, or
+// without a matching operation, are passed through unchecked.
+type Validator struct {
+	Spec *Spec
+
+	// Requests turns on the validation of query and path parameters. Invalid requests are answered with a 400 error.
+	Requests bool
+
+	// Strict turns on strict mode for the requests to operations taking the strict parameter which do not set it.
+	// Requests in strict mode are validated even if Requests is off.
+	Strict bool
+
+	// Params holds the validators of the parameters of routes by route name, eg. as generated by routegen.
+	// The parameters of other routes are validated against the spec.
+	Params map[string]Params
+
+	// Responses sets what is done with responses that do not match the spec
+	Responses ResponseMode
+
+	// Versions lists the other versions of the API serving operations of the spec (see Operation.Versions).
+	// Their parameters are validated as those of the spec, but not their responses, which are serialized
+	// differently.
+	Versions []Version
+}
+
+// Version is another version of the API serving operations of a spec under its own base path.
+type Version struct {
+	// Name is the name of the version, eg. 'v2' for the '/v2' base path
+	Name string
+
+	// Strict overrides the Strict setting of the Validator for the requests to the version
+	Strict bool
+}
+
+// Middleware returns a handler validating the requests and responses of next.
+func (validator *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op, version := validator.operation(r)
+		if op == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// The routes of other versions share the validators of the routes of the spec
+		name, strictDefault := mux.CurrentRoute(r).GetName(), validator.Strict
+		if version != nil {
+			name, strictDefault = strings.TrimPrefix(name, VersionedName(version.Name, "")), version.Strict
+		}
+
+		query := r.URL.Query()
+		params, generated := validator.Params[name]
+		var strict bool
+		if generated {
+			strict = params.Strict(query, strictDefault)
+		} else {
+			strict = op.Strict(query, strictDefault)
+		}
+
+		if validator.Requests || strict {
+			var err error
+			if generated {
+				err = params.Validate(query, mux.Vars(r), strict)
+			} else {
+				err = validator.Spec.ValidateParams(op, query, mux.Vars(r), strict)
+			}
+			if err != nil {
+				serverError := utils.NewError(err, "malformed request, "+err.Error(), 400, false)
+				if validationError, ok := err.(*ValidationError); ok {
+					serverError.ForParam(validationError.Param, err)
+					serverError.Problems = validationError.Details
+				}
+				utils.HttpJsonError(w, r, serverError)
+				return
+			}
+		}
+
+		if version != nil || (validator.Responses != ResponsesLog && validator.Responses != ResponsesEnforce) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK, buffer: validator.Responses == ResponsesEnforce}
+		next.ServeHTTP(rw, r)
+		if !rw.wroteHeader {
+			rw.WriteHeader(http.StatusOK)
+		}
+
+		var body []byte
+		if rw.capture {
+			body = rw.body.Bytes()
+		}
+		err := validator.Spec.ValidateResponse(op, rw.status, rw.Header().Get("Content-Type"), body)
+		if err == nil {
+			rw.flushBuffered()
+			return
+		}
+
+		utils.ErrorLog(utils.NewError(err, fmt.Sprintf("response of %v %v does not match the OpenAPI spec", r.Method, r.URL.Path), 500, false))
+		if rw.buffering {
+			utils.HttpJsonError(w, r, utils.NewError(err, "internal server error, the response did not match the API spec", 500, false))
+		}
+	})
+}
+
+// operation returns the operation of the spec matching the route of a request, along with the version of the API
+// serving the route if it is not the version of the spec.
+func (validator *Validator) operation(r *http.Request) (*Operation, *Version) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return nil, nil
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return nil, nil
+	}
+
+	if path, ok := trimBase(template, validator.Spec.BasePath()); ok {
+		return validator.Spec.Operation(r.Method, path), nil
+	}
+	for i, version := range validator.Versions {
+		path, ok := trimBase(template, "/"+version.Name)
+		if !ok {
+			continue
+		}
+		op := validator.Spec.Operation(r.Method, path)
+		if op == nil || !contains(op.Versions, version.Name) {
+			return nil, nil
+		}
+		return op, &validator.Versions[i]
+	}
+	return nil, nil
+}
+
+// trimBase returns the path of the spec a path template under a base path stands for.
+func trimBase(template string, base string) (string, bool) {
+	if template != base && !strings.HasPrefix(template, base+"/") {
+		return "", false
+	}
+	path := strings.TrimPrefix(template, base)
+	if path == "" {
+		path = "/"
+	}
+	return path, true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// responseWriter records the status and captures the JSON body of a response. Other bodies, eg. streams,
+// are passed through as they are written.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+
+	// buffer holds JSON bodies back until they are validated, rather than teeing them to the client
+	buffer    bool
+	buffering bool
+	capture   bool
+	body      bytes.Buffer
+}
+
+func (rw *responseWriter) WriteHeader(status int) {
+	if rw.wroteHeader {
+		return
+	}
+	rw.status, rw.wroteHeader = status, true
+
+	mediaType, _, _ := mime.ParseMediaType(rw.Header().Get("Content-Type"))
+	rw.capture = IsJson(mediaType)
+	rw.buffering = rw.capture && rw.buffer
+	if !rw.buffering {
+		rw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if rw.capture {
+		rw.body.Write(b)
+	}
+	if rw.buffering {
+		return len(b), nil
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+// Flush sends the response written so far to the client. Buffered responses are only sent once validated.
+func (rw *responseWriter) Flush() {
+	if rw.buffering {
+		return
+	}
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// flushBuffered sends a validated buffered response to the client.
+func (rw *responseWriter) flushBuffered() {
+	if !rw.buffering {
+		return
+	}
+	rw.ResponseWriter.WriteHeader(rw.status)
+	rw.ResponseWriter.Write(rw.body.Bytes())
+}
@@ -0,0 +1,415 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package openapi
+
+import (
+	"apiserver/pkg/database/models"
+	utils "apiserver/pkg/utils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func mustV1(t *testing.T) *Spec {
+	spec, err := V1()
+	if err != nil {
+		t.Fatalf("cannot parse the embedded spec: %v", err)
+	}
+	return spec
+}
+
+func TestSpecParse(t *testing.T) {
+	spec := mustV1(t)
+	if base := spec.BasePath(); base != "/v1" {
+		t.Errorf("Wanted base path '/v1', Got: '%v'", base)
+	}
+	if spec.Operation("GET", "/co2/weekly") == nil {
+		t.Error("Wanted an operation for GET /co2/weekly.")
+	}
+	if spec.Operation("GET", "/") != spec.Operation("GET", "/co2") {
+		t.Error("Wanted the '/' path to resolve to the '/co2' path.")
+	}
+	for _, param := range spec.Operation("GET", "/co2/weekly").Parameters {
+		if param.Ref != "" || param.Name == "" {
+			t.Errorf("Wanted every parameter to be resolved, Got: %+v", param)
+		}
+	}
+
+	if _, err := Parse([]byte(`{"paths": {"/a": {"get": {"parameters": [{"$ref": "#/components/parameters/Missing"}]}}}}`)); err == nil {
+		t.Error("Wanted an error for a reference to a missing parameter.")
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	spec := mustV1(t)
+	testVals := []struct {
+		path     string
+		query    string
+		vars     map[string]string
+		problems []string
+	}{
+		{"/co2/weekly", "limit=5&offset=10&sort=-year,month&simple=true", nil, nil},
+		{"/co2/weekly", "limit=all&filter=average > 400&filter=month in (5,6)", nil, nil},
+		{"/co2/weekly", "unknown=1", nil, nil},
+		{"/co2/weekly", "limit=10001", nil, []string{"parameter 'limit' must be at most 10000, got 10001"}},
+		{"/co2/weekly", "limit=ten&simple=maybe", nil, []string{"parameter 'simple' must be of type boolean, got 'maybe'", "parameter 'limit' must be of type integer, got 'ten'"}},
+		{"/co2/weekly", "sort=year,height", nil, []string{"parameter 'sort' must be one of"}},
+		{"/co2/weekly", "format=xml", nil, []string{"parameter 'format' must be one of json, csv"}},
+		{"/co2/weekly", "year=twenty", nil, []string{"parameter 'year' must match the pattern"}},
+		{"/co2/weekly/{ppm}", "", map[string]string{"ppm": "1200"}, []string{"parameter 'ppm' must be at most 1000, got 1200"}},
+		{"/co2/weekly/{ppm}", "", nil, []string{"parameter 'ppm' is required"}},
+	}
+
+	for _, testVal := range testVals {
+		query, _ := url.ParseQuery(testVal.query)
+		err := spec.ValidateParams(spec.Operation("GET", testVal.path), query, testVal.vars, false)
+		if len(testVal.problems) == 0 {
+			if err != nil {
+				t.Errorf("Wanted no problems for %v?%v, Got: %v", testVal.path, testVal.query, err)
+			}
+			continue
+		}
+
+		verr, ok := err.(*ValidationError)
+		if !ok || len(verr.Problems) != len(testVal.problems) {
+			t.Errorf("Wanted %v problems for %v?%v, Got: %v", len(testVal.problems), testVal.path, testVal.query, err)
+			continue
+		}
+		for i, problem := range testVal.problems {
+			if !strings.HasPrefix(verr.Problems[i], problem) {
+				t.Errorf("Wanted a problem starting with '%v', Got: '%v'", problem, verr.Problems[i])
+			}
+		}
+	}
+}
+
+// TestValidateParamsCode checks the parameter and code of the error catalog that invalid requests are reported with.
+func TestValidateParamsCode(t *testing.T) {
+	spec := mustV1(t)
+	testVals := []struct {
+		path  string
+		query string
+		vars  map[string]string
+		param string
+		code  string
+	}{
+		{"/co2/weekly", "limit=10001", nil, "limit", utils.CodeOutOfRange},
+		{"/co2/weekly", "limit=ten", nil, "limit", utils.CodeInvalidParam},
+		{"/co2/weekly", "simple=maybe&limit=10001", nil, "simple", utils.CodeInvalidParam},
+		{"/co2/weekly", "format=xml", nil, "format", utils.CodeInvalidParam},
+		{"/co2/weekly/{ppm}", "", map[string]string{"ppm": "1200"}, "ppm", utils.CodeOutOfRange},
+		{"/co2/weekly/{ppm}", "", nil, "ppm", utils.CodeInvalidParam},
+	}
+
+	for _, testVal := range testVals {
+		query, _ := url.ParseQuery(testVal.query)
+		err := spec.ValidateParams(spec.Operation("GET", testVal.path), query, testVal.vars, false)
+		verr, ok := err.(*ValidationError)
+		if !ok {
+			t.Errorf("Wanted a ValidationError for %v?%v, Got: %v", testVal.path, testVal.query, err)
+			continue
+		}
+		if verr.Param != testVal.param || verr.ErrorCode() != testVal.code {
+			t.Errorf("Wanted '%v' reported as %v for %v?%v, Got: '%v' reported as %v", testVal.param, testVal.code, testVal.path, testVal.query, verr.Param, verr.ErrorCode())
+		}
+	}
+}
+
+// TestValidateParamsStrict checks the problems only reported in strict mode, all at once.
+func TestValidateParamsStrict(t *testing.T) {
+	spec := mustV1(t)
+	testVals := []struct {
+		path     string
+		query    string
+		problems []utils.ParamProblem
+	}{
+		{"/co2/weekly", "year=1990..2020&year=2021&month=!6-8&gt=300&lte=500&sort=year&sort=-month&filter=average > 400&filter=month in (5,6)&strict=true", nil},
+		{"/co2/weekly", "gte=300&lte=300", nil},
+		{"/co2/weekly", "yaer=2020", []utils.ParamProblem{
+			{Code: utils.CodeUnknownParam, Param: "yaer", Detail: "parameter 'yaer' is not taken by this endpoint, did you mean 'year'?"},
+		}},
+		{"/co2/weekly", "callback=f", []utils.ParamProblem{
+			{Code: utils.CodeUnknownParam, Param: "callback", Detail: "parameter 'callback' is not taken by this endpoint"},
+		}},
+		{"/co2/weekly", "gt=420&lt=300", []utils.ParamProblem{
+			{Code: utils.CodeConflictingParams, Param: "gt", Detail: "parameters 'gt=420' and 'lt=300' contradict each other, no value can match both"},
+		}},
+		{"/co2/weekly", "gte=400&gt=410&lte=500&lt=410", []utils.ParamProblem{
+			{Code: utils.CodeConflictingParams, Param: "gt", Detail: "parameters 'gt=410' and 'lt=410' contradict each other, no value can match both"},
+		}},
+		{"/co2/weekly", "limit=5&limit=6", []utils.ParamProblem{
+			{Code: utils.CodeConflictingParams, Param: "limit", Detail: "parameter 'limit' takes a single value, got 2: '5', '6'"},
+		}},
+		{"/co2/weekly", "year=2020..1990&month=8-6,1", []utils.ParamProblem{
+			{Code: utils.CodeConflictingParams, Param: "month", Detail: "parameter 'month' holds the range '8-6', which starts after its end"},
+			{Code: utils.CodeConflictingParams, Param: "year", Detail: "parameter 'year' holds the range '2020..1990', which starts after its end"},
+		}},
+		{"/co2/weekly", "limit=10001&limt=5&simple=true&simple=false&gt=420&lt=300", []utils.ParamProblem{
+			{Code: utils.CodeOutOfRange, Param: "limit", Detail: "parameter 'limit' must be at most 10000, got 10001"},
+			{Code: utils.CodeUnknownParam, Param: "limt", Detail: "parameter 'limt' is not taken by this endpoint, did you mean 'limit'?"},
+			{Code: utils.CodeConflictingParams, Param: "simple", Detail: "parameter 'simple' takes a single value, got 2: 'true', 'false'"},
+			{Code: utils.CodeConflictingParams, Param: "gt", Detail: "parameters 'gt=420' and 'lt=300' contradict each other, no value can match both"},
+		}},
+	}
+
+	for _, testVal := range testVals {
+		query, _ := url.ParseQuery(testVal.query)
+		op := spec.Operation("GET", testVal.path)
+		if err := spec.ValidateParams(op, query, nil, false); err != nil && testVal.problems != nil && testVal.problems[0].Code != utils.CodeOutOfRange {
+			t.Errorf("Wanted no problems for %v?%v outside of strict mode, Got: %v", testVal.path, testVal.query, err)
+		}
+
+		// Generated parameters are checked like the parameters of the spec
+		for _, err := range []error{spec.ValidateParams(op, query, nil, true), Params(op.Parameters).Validate(query, nil, true)} {
+			if testVal.problems == nil {
+				if err != nil {
+					t.Errorf("Wanted no problems for %v?%v in strict mode, Got: %v", testVal.path, testVal.query, err)
+				}
+				continue
+			}
+			verr, ok := err.(*ValidationError)
+			if !ok || !reflect.DeepEqual(verr.Details, testVal.problems) {
+				t.Errorf("Wanted the problems %+v for %v?%v in strict mode, Got: %+v", testVal.problems, testVal.path, testVal.query, err)
+				continue
+			}
+			if verr.Param != testVal.problems[0].Param || verr.Code != testVal.problems[0].Code || len(verr.Problems) != len(testVal.problems) {
+				t.Errorf("Wanted the first problem to be reported, Got: '%v' reported as %v", verr.Param, verr.Code)
+			}
+		}
+	}
+}
+
+// TestStrict checks which requests ask for strict mode.
+func TestStrict(t *testing.T) {
+	spec := mustV1(t)
+	testVals := []struct {
+		path          string
+		query         string
+		strictDefault bool
+		want          bool
+	}{
+		{"/co2/weekly", "", false, false},
+		{"/co2/weekly", "strict=true", false, true},
+		{"/co2/weekly", "strict=1", false, true},
+		{"/co2/weekly", "strict=maybe", false, false},
+		{"/co2/weekly", "", true, true},
+		{"/co2/weekly", "strict=false", true, false},
+		{"/graphql", "strict=true", true, false},
+	}
+	for _, testVal := range testVals {
+		query, _ := url.ParseQuery(testVal.query)
+		op := spec.Operation("GET", testVal.path)
+		if got := op.Strict(query, testVal.strictDefault); got != testVal.want {
+			t.Errorf("Wanted strict mode %v for %v?%v by default %v, Got: %v", testVal.want, testVal.path, testVal.query, testVal.strictDefault, got)
+		}
+		if got := Params(op.Parameters).Strict(query, testVal.strictDefault); got != testVal.want {
+			t.Errorf("Wanted the generated parameters in strict mode %v for %v?%v, Got: %v", testVal.want, testVal.path, testVal.query, got)
+		}
+	}
+}
+
+// TestModelsMatchSpec checks that the JSON encoding of the models served by the API matches the schemas of the spec.
+func TestModelsMatchSpec(t *testing.T) {
+	spec := mustV1(t)
+	date := time.Date(2021, 11, 7, 0, 0, 0, 0, time.UTC)
+	fields, _ := models.Co2WeeklyMlo.Fields([]string{"yyyymmdd", "average"})
+
+	testVals := []struct {
+		path   string
+		status int
+		resp   interface{}
+	}{
+		{"/co2/weekly", 200, models.ServerResp{Status: "OK", RequestId: "id", Results: []interface{}{
+			models.Co2Entry{Year: 2021, Month: 11, Day: 7, DateDecimal: 2021.85, Average: 414.99, NumDays: 7, OneYearAgo: 412.53, TenYearsAgo: -999.99, IncSincePreIndustrial: 134.67, Timestamp: date},
+		}}},
+		{"/co2/weekly", 200, models.ServerResp{Status: "OK", RequestId: "id", Results: []interface{}{
+			models.Co2EntrySimple{Year: 2021, Month: 11, Day: 7, Average: 414.99, IncSincePreIndustrial: 134.67},
+		}}},
+		{"/co2/weekly", 200, models.ServerResp{Status: "OK", RequestId: "id", Results: []interface{}{
+			models.Fields{Columns: fields, Values: []interface{}{date, float32(414.99)}},
+		}}},
+		{"/ch4/monthly", 200, models.ServerResp{Status: "OK", RequestId: "id", Results: []interface{}{
+			models.Ch4Entry{Year: 2021, Month: 8, DateDecimal: 2021.625, Average: 1900.61, AverageUncertainty: 2.2, Trend: 1897.62, TrendUncertainty: 1.97, Timestamp: date},
+		}}},
+		{"/ch4/monthly", 200, models.ServerResp{Status: "OK", RequestId: "id", Results: []interface{}{
+			models.Ch4EntrySimple{Year: 2021, Month: 8, Average: 1900.61, Trend: 1897.62},
+		}}},
+		{"/co2/latest", 200, models.ServerResp{Status: "OK", RequestId: "id", Results: []interface{}{
+			models.Latest{
+				Latest:     models.Co2Entry{Year: 2021, Month: 11, Day: 7, Timestamp: date},
+				OneYearAgo: nil,
+				Freshness:  models.Co2WeeklyMlo.Freshness(date, &date, date),
+			},
+		}}},
+		{"/co2/weekly", 400, models.ServerResp{Status: "ERROR", RequestId: "id", Error: &models.ErrorResp{Description: "400 - Bad Request", Message: "malformed query parameters"}}},
+	}
+
+	for _, testVal := range testVals {
+		body, err := json.Marshal(testVal.resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := spec.ValidateResponse(spec.Operation("GET", testVal.path), testVal.status, "application/json; charset=UTF-8", body); err != nil {
+			t.Errorf("Wanted the %v response of %v to match the spec, Got: %v\n%s", testVal.status, testVal.path, err, body)
+		}
+	}
+
+	// Errors are also served as problem details
+	problem := models.Problem{Type: "/docs#OUT_OF_RANGE", Title: "Parameter out of range", Status: 400, Detail: "malformed query parameters", Instance: "/v1/co2/weekly", Code: utils.CodeOutOfRange, Param: "gte", RequestId: "id"}
+	body, _ := json.Marshal(problem)
+	for _, status := range []int{400, 404, 503} {
+		if err := spec.ValidateResponse(spec.Operation("GET", "/co2/weekly"), status, "application/problem+json; charset=utf-8", body); err != nil {
+			t.Errorf("Wanted problem details with status %v to match the spec, Got: %v\n%s", status, err, body)
+		}
+	}
+	for _, entry := range utils.ErrorCatalog {
+		problem.Code = entry.Code
+		body, _ := json.Marshal(problem)
+		if err := spec.ValidateResponse(spec.Operation("GET", "/co2/weekly"), 400, "application/problem+json", body); err != nil {
+			t.Errorf("Wanted the code '%v' to be documented, Got: %v", entry.Code, err)
+		}
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	spec := mustV1(t)
+	op := spec.Operation("GET", "/co2/weekly")
+	testVals := []struct {
+		status      int
+		contentType string
+		body        string
+		problem     string
+	}{
+		{200, "text/csv", "year,month\n2021,11\n", ""},
+		{200, "text/html", "<html>", "content type 'text/html' is not documented for status 200"},
+		{200, "application/json", `{"Results": [{"Year": "2021"}]}`, "body.Results[0].Year must be of type integer, got string"},
+		{200, "application/json", `{"Results": [{"Year": 2021.5}]}`, "body.Results[0].Year must be of type integer, got number"},
+		{200, "application/json", `{"Results": `, "invalid JSON body"},
+		{503, "application/json", `{"Status": "ERROR"}`, ""},
+	}
+
+	for _, testVal := range testVals {
+		err := spec.ValidateResponse(op, testVal.status, testVal.contentType, []byte(testVal.body))
+		if testVal.problem == "" {
+			if err != nil {
+				t.Errorf("Wanted the %v %v response to match the spec, Got: %v", testVal.status, testVal.contentType, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), testVal.problem) {
+			t.Errorf("Wanted the problem '%v', Got: %v", testVal.problem, err)
+		}
+	}
+
+	if err := spec.ValidateResponse(spec.Operation("DELETE", "/subscriptions/{id}"), 204, "", nil); err != nil {
+		t.Errorf("Wanted an empty 204 response to match the spec, Got: %v", err)
+	}
+}
+
+func TestValidatorMiddleware(t *testing.T) {
+	spec := mustV1(t)
+	var body string
+
+	newRouter := func(responses ResponseMode) *mux.Router {
+		router := mux.NewRouter()
+		router.Use(utils.SetReqId)
+		router.Use((&Validator{Spec: spec, Requests: true, Responses: responses}).Middleware)
+		router.HandleFunc("/v1/co2/weekly", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+			w.Write([]byte(body))
+		})
+		router.HandleFunc("/undocumented", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		})
+		return router
+	}
+
+	testVals := []struct {
+		mode   ResponseMode
+		target string
+		body   string
+		status int
+	}{
+		{ResponsesEnforce, "/v1/co2/weekly?limit=5", `{"Status": "OK", "Results": [{"Year": 2021}]}`, 200},
+		{ResponsesEnforce, "/v1/co2/weekly?limit=-1", `{"Status": "OK"}`, 400},
+		{ResponsesEnforce, "/v1/co2/weekly", `{"Status": "OK", "Results": [{"Year": "2021"}]}`, 500},
+		{ResponsesLog, "/v1/co2/weekly", `{"Status": "OK", "Results": [{"Year": "2021"}]}`, 200},
+		{ResponsesEnforce, "/undocumented?limit=-1", "", 200},
+	}
+
+	for _, testVal := range testVals {
+		body = testVal.body
+		w := httptest.NewRecorder()
+		newRouter(testVal.mode).ServeHTTP(w, httptest.NewRequest("GET", testVal.target, nil))
+		if w.Code != testVal.status {
+			t.Errorf("Wanted status %v for %v in %v mode, Got: %v: %v", testVal.status, testVal.target, testVal.mode, w.Code, w.Body.String())
+		}
+		if w.Code == 200 && testVal.body != "" && w.Body.String() != testVal.body {
+			t.Errorf("Wanted the body to be sent unchanged, Got: %v", w.Body.String())
+		}
+	}
+
+	// Invalid requests are reported with the parameter at fault
+	req := httptest.NewRequest("GET", "/v1/co2/weekly?limit=-1", nil)
+	req.Header.Set("Accept", utils.ProblemMediaType)
+	w := httptest.NewRecorder()
+	newRouter(ResponsesEnforce).ServeHTTP(w, req)
+	var problem models.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || problem.Code != utils.CodeOutOfRange || problem.Param != "limit" {
+		t.Errorf("Wanted 'limit' reported as OUT_OF_RANGE, Got: %v: %v", err, w.Body.String())
+	}
+	// Requests in strict mode are validated even if requests are not, and report every problem at once
+	router := mux.NewRouter()
+	router.Use(utils.SetReqId)
+	router.Use((&Validator{Spec: spec}).Middleware)
+	router.HandleFunc("/v1/co2/weekly", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	serve := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set("Accept", utils.ProblemMediaType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	if w = serve("/v1/co2/weekly?yaer=2020&gt=420&lt=300"); w.Code != 200 {
+		t.Errorf("Wanted requests outside of strict mode to be passed through, Got: %v: %v", w.Code, w.Body.String())
+	}
+	w = serve("/v1/co2/weekly?yaer=2020&gt=420&lt=300&strict=true")
+	problem = models.Problem{}
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || w.Code != 400 {
+		t.Errorf("Wanted a 400 error for a request in strict mode, Got: %v: %v", w.Code, w.Body.String())
+	}
+	if problem.Code != utils.CodeUnknownParam || problem.Param != "yaer" || len(problem.Errors) != 2 || problem.Errors[1].Code != utils.CodeConflictingParams {
+		t.Errorf("Wanted 'yaer' reported as UNKNOWN_PARAM along with the conflicting bounds, Got: %v", w.Body.String())
+	}
+	if err := spec.ValidateResponse(spec.Operation("GET", "/co2/weekly"), 400, utils.ProblemMediaType, w.Body.Bytes()); err != nil {
+		t.Errorf("Wanted the problems of a strict request to match the spec, Got: %v", err)
+	}
+}
@@ -0,0 +1,325 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// routeHeader is the license header of the generated routes
+const routeHeader = `/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/`
+
+// RouteConfig configures the code generated by GenerateRoutes.
+type RouteConfig struct {
+	// Package is the package of the generated file. It must define the ApiServer, Route and Routes types.
+	Package string
+
+	// Source is the name of the spec file, as mentioned in the header of the generated file
+	Source string
+
+	// Handlers is the import path of the handlers package. The package of each x-handler is either 'handlers'
+	// or one of its subpackages.
+	Handlers string
+}
+
+// DefaultRouteConfig generates the routes of the server package, as run by 'go generate'.
+var DefaultRouteConfig = RouteConfig{Package: "server", Source: "spec-v1.json", Handlers: "apiserver/pkg/server/handlers"}
+
+// handlerPattern matches the value of an x-handler extension, eg. 'co2.Get'
+var handlerPattern = regexp.MustCompile(`^([a-z][a-z0-9]*)\.([A-Z][A-Za-z0-9]*)$`)
+
+// versionPattern matches the name of a version of the API listed in an x-versions extension, eg. 'v2'
+var versionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// GenerateRoutes generates the Go source of a route for each operation of a spec, and of the validators of
+// their parameters. The source defines:
+//
+//	func (apiserver *ApiServer) specRoutes() Routes
+//	var specParams map[string]openapi.Params
+//
+// Routes are named after the operationId of their operation, and are listed in the order they must be matched in.
+// Each operation must name its handler with the x-handler extension. The x-sort-by and x-path-param extensions set
+// the SortBy and PathParam values of the handler's configuration. Operations listing other versions of the API in
+// the x-versions extension get a route under the base path of each version, named as by VersionedName, after the
+// routes of the spec.
+func GenerateRoutes(spec *Spec, config RouteConfig) ([]byte, error) {
+	base := spec.BasePath()
+	imports := map[string]bool{"apiserver/pkg/openapi": true, config.Handlers: true}
+
+	var routes, versioned, params bytes.Buffer
+	for _, key := range spec.Operations() {
+		method := strings.SplitN(key, " ", 2)[0]
+		path := strings.SplitN(key, " ", 2)[1]
+		op := spec.Operation(method, path)
+
+		m := handlerPattern.FindStringSubmatch(op.Handler)
+		if m == nil {
+			return nil, fmt.Errorf("%v: x-handler must name an exported function of a handlers package, got '%v'", key, op.Handler)
+		}
+		if m[1] != "handlers" {
+			imports[config.Handlers+"/"+m[1]] = true
+		}
+		if op.OperationId == "" {
+			return nil, fmt.Errorf("%v: operationId is required to name the route", key)
+		}
+
+		// Aliased paths share their operation with the path they reference, so the route is named after both
+		name := op.OperationId
+		if _, ok := spec.Aliases[path]; ok {
+			name += "Via" + pathName(path)
+		}
+
+		writeRoute(&routes, op, name, method, routePattern(base, path))
+		for _, version := range op.Versions {
+			if !versionPattern.MatchString(version) {
+				return nil, fmt.Errorf("%v: x-versions must list versions named like 'v2', got '%v'", key, version)
+			}
+			if "/"+version == base {
+				continue
+			}
+			writeRoute(&versioned, op, VersionedName(version, name), method, routePattern("/"+version, path))
+		}
+
+		fmt.Fprintf(&params, "%q: openapi.NewParams(\n", name)
+		for _, param := range op.Parameters {
+			if param.In != "query" && param.In != "path" {
+				continue
+			}
+			lit, err := spec.parameterLiteral(param)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v", key, err)
+			}
+			fmt.Fprintf(&params, "%v,\n", lit)
+		}
+		fmt.Fprintf(&params, "),\n")
+	}
+
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "%v\n\n// Code generated by routegen from %v. DO NOT EDIT.\n\npackage %v\n\nimport (\n", routeHeader, config.Source, config.Package)
+	for _, path := range paths {
+		fmt.Fprintf(&src, "%q\n", path)
+	}
+	fmt.Fprintf(&src, ")\n\n// specRoutes returns a route for each operation of the OpenAPI spec, in the order they must be matched in.\n")
+	fmt.Fprintf(&src, "func (apiserver *ApiServer) specRoutes() Routes {\nreturn Routes{\n%v%v}\n}\n\n", routes.String(), versioned.String())
+	fmt.Fprintf(&src, "// specParams holds the validators of the query and path parameters of the routes returned by specRoutes, by route name.\n")
+	fmt.Fprintf(&src, "var specParams = map[string]openapi.Params{\n%v}\n", params.String())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("cannot format the generated routes: %v", err)
+	}
+	return formatted, nil
+}
+
+// pathName converts a path to an identifier, eg. 'Co2WeeklyPpm' for '/co2/weekly/{ppm}', or 'Root' for '/'.
+func pathName(path string) string {
+	var name strings.Builder
+	for _, word := range regexp.MustCompile(`[A-Za-z0-9]+`).FindAllString(path, -1) {
+		name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	if name.Len() == 0 {
+		return "Root"
+	}
+	return name.String()
+}
+
+// writeRoute writes the Go expression of the route serving an operation.
+func writeRoute(buf *bytes.Buffer, op *Operation, name string, method string, pattern string) {
+	fmt.Fprintf(buf, "Route{\n%q,\n%q,\n%q,\nhandlers.ApiHandler{\nHandler: %v,\nConfig: &handlers.ApiHandlerConfig{\n", name, method, pattern, op.Handler)
+	fmt.Fprintf(buf, "Database: apiserver.Database,\nEvents: apiserver.Events,\nCatalog: apiserver.Catalog,\nConditions: apiserver.Conditions,\n")
+	if op.PathParam {
+		fmt.Fprintf(buf, "PathParam: true,\n")
+	}
+	if op.SortBy != "" {
+		fmt.Fprintf(buf, "SortBy: %q,\n", op.SortBy)
+	}
+	fmt.Fprintf(buf, "},\n},\n},\n")
+}
+
+// routePattern returns the pattern of the route serving a path of the spec under a base path.
+func routePattern(base string, path string) string {
+	if path == "/" && base != "" {
+		return base
+	}
+	return base + path
+}
+
+// VersionedName returns the name of the route serving an operation under another version of the API, eg.
+// 'v2.getCo2Weekly' for the 'getCo2Weekly' route.
+func VersionedName(version string, name string) string {
+	return version + "." + name
+}
+
+// parameterLiteral returns a Go expression building a parameter, with its schema references inlined.
+func (spec *Spec) parameterLiteral(param *Parameter) (string, error) {
+	schema, err := spec.schemaLiteral(param.Schema, 0)
+	if err != nil {
+		return "", fmt.Errorf("parameter '%v': %v", param.Name, err)
+	}
+
+	fields := []string{"Name: " + strconv.Quote(param.Name), "In: " + strconv.Quote(param.In)}
+	if param.Required {
+		fields = append(fields, "Required: true")
+	}
+	if param.Explode != nil {
+		fields = append(fields, fmt.Sprintf("Explode: openapi.Bool(%v)", *param.Explode))
+	}
+	if schema != "nil" {
+		fields = append(fields, "Schema: "+schema)
+	}
+	if param.Bound != "" {
+		fields = append(fields, "Bound: "+strconv.Quote(param.Bound))
+	}
+	if param.Range {
+		fields = append(fields, "Range: true")
+	}
+	return "&openapi.Parameter{" + strings.Join(fields, ", ") + "}", nil
+}
+
+// schemaLiteral returns a Go expression building a schema, with its references inlined.
+func (spec *Spec) schemaLiteral(schema *Schema, depth int) (string, error) {
+	schema, err := spec.deref(schema, depth)
+	if err != nil {
+		return "", err
+	}
+	if schema == nil {
+		return "nil", nil
+	}
+	if depth > maxRefDepth {
+		return "", fmt.Errorf("schema nested too deeply")
+	}
+
+	var fields []string
+	if schema.Type != "" {
+		fields = append(fields, "Type: "+strconv.Quote(schema.Type))
+	}
+	if schema.Format != "" {
+		fields = append(fields, "Format: "+strconv.Quote(schema.Format))
+	}
+	if len(schema.Enum) != 0 {
+		values := make([]string, len(schema.Enum))
+		for i, e := range schema.Enum {
+			switch v := e.(type) {
+			case string:
+				values[i] = strconv.Quote(v)
+			case float64:
+				values[i] = "float64(" + strconv.FormatFloat(v, 'g', -1, 64) + ")"
+			case bool:
+				values[i] = strconv.FormatBool(v)
+			default:
+				return "", fmt.Errorf("unsupported enum value '%v'", e)
+			}
+		}
+		fields = append(fields, "Enum: []interface{}{"+strings.Join(values, ", ")+"}")
+	}
+	if schema.Minimum != nil {
+		fields = append(fields, "Minimum: openapi.Float("+strconv.FormatFloat(*schema.Minimum, 'g', -1, 64)+")")
+	}
+	if schema.Maximum != nil {
+		fields = append(fields, "Maximum: openapi.Float("+strconv.FormatFloat(*schema.Maximum, 'g', -1, 64)+")")
+	}
+	if schema.MaxLength != nil {
+		fields = append(fields, "MaxLength: openapi.Int("+strconv.Itoa(*schema.MaxLength)+")")
+	}
+	if schema.Pattern != "" {
+		fields = append(fields, "Pattern: "+strconv.Quote(schema.Pattern))
+	}
+	if schema.Nullable {
+		fields = append(fields, "Nullable: true")
+	}
+	if schema.Items != nil {
+		items, err := spec.schemaLiteral(schema.Items, depth+1)
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, "Items: "+items)
+	}
+	if len(schema.Properties) != 0 {
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		props := make([]string, len(names))
+		for i, name := range names {
+			prop, err := spec.schemaLiteral(schema.Properties[name], depth+1)
+			if err != nil {
+				return "", err
+			}
+			props[i] = strconv.Quote(name) + ": " + prop
+		}
+		fields = append(fields, "Properties: map[string]*openapi.Schema{"+strings.Join(props, ", ")+"}")
+	}
+	if len(schema.Required) != 0 {
+		required := make([]string, len(schema.Required))
+		for i, name := range schema.Required {
+			required[i] = strconv.Quote(name)
+		}
+		fields = append(fields, "Required: []string{"+strings.Join(required, ", ")+"}")
+	}
+	if len(schema.OneOf) != 0 {
+		alts := make([]string, len(schema.OneOf))
+		for i, alt := range schema.OneOf {
+			if alts[i], err = spec.schemaLiteral(alt, depth+1); err != nil {
+				return "", err
+			}
+		}
+		fields = append(fields, "OneOf: []*openapi.Schema{"+strings.Join(alts, ", ")+"}")
+	}
+	return "&openapi.Schema{" + strings.Join(fields, ", ") + "}", nil
+}
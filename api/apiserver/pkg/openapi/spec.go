@@ -0,0 +1,456 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package openapi
+
+import (
+	_ "embed" // The spec is embedded so that the served and enforced spec always match the binary
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SpecV1 is the OpenAPI spec of version 1 of the API, as served at /v1/openapi.json.
+//
+//go:embed spec-v1.json
+var SpecV1 []byte
+
+var (
+	v1     *Spec
+	v1Err  error
+	v1Once sync.Once
+)
+
+// Methods lists the HTTP methods an operation can be defined for, in the order they appear in path items.
+var Methods = []string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE"}
+
+// Spec is a parsed OpenAPI document. All references to components and paths are resolved when it is parsed.
+type Spec struct {
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	// Aliases maps each path whose item is a reference to another path to that path
+	Aliases map[string]string `json:"-"`
+}
+
+// Info holds the metadata of the API.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+
+	// Datasets lists the datasets served by the API (x-datasets)
+	Datasets []Dataset `json:"x-datasets"`
+}
+
+// Dataset describes a dataset served by the API and where it comes from.
+type Dataset struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Units       string `json:"units"`
+	Source      string `json:"source"`
+	Path        string `json:"path"`
+}
+
+// Server is a server hosting the API.
+type Server struct {
+	Url string `json:"url"`
+}
+
+// Components holds the reusable objects of a spec.
+type Components struct {
+	Schemas    map[string]*Schema    `json:"schemas"`
+	Parameters map[string]*Parameter `json:"parameters"`
+	Responses  map[string]*Response  `json:"responses"`
+}
+
+// PathItem describes the operations available on a path.
+type PathItem struct {
+	Ref        string       `json:"$ref"`
+	Parameters []*Parameter `json:"parameters"`
+	Get        *Operation   `json:"get"`
+	Put        *Operation   `json:"put"`
+	Post       *Operation   `json:"post"`
+	Delete     *Operation   `json:"delete"`
+	Options    *Operation   `json:"options"`
+	Head       *Operation   `json:"head"`
+	Patch      *Operation   `json:"patch"`
+	Trace      *Operation   `json:"trace"`
+}
+
+// Operation describes a single API operation on a path.
+type Operation struct {
+	OperationId string               `json:"operationId"`
+	Tags        []string             `json:"tags"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description"`
+	Parameters  []*Parameter         `json:"parameters"`
+	Responses   map[string]*Response `json:"responses"`
+
+	// Handler is the ApiHandlerFunc serving the operation, eg. 'co2.Get' (x-handler). The package is one of
+	// the handlers packages.
+	Handler string `json:"x-handler"`
+
+	// SortBy is the SortBy value of the handler's configuration (x-sort-by)
+	SortBy string `json:"x-sort-by"`
+
+	// PathParam is the PathParam value of the handler's configuration (x-path-param)
+	PathParam bool `json:"x-path-param"`
+
+	// Versions lists the other versions of the API serving the operation under their own base path, eg. 'v2'
+	// for '/v2' (x-versions)
+	Versions []string `json:"x-versions"`
+}
+
+// Parameter describes a single operation parameter.
+type Parameter struct {
+	Ref         string  `json:"$ref"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required"`
+	Explode     *bool   `json:"explode"`
+	Schema      *Schema `json:"schema"`
+
+	// Bound marks a parameter bounding the values a request matches, as one of gt, gte, lt or lte. Bounds that
+	// contradict each other are rejected in strict mode.
+	Bound string `json:"x-bound"`
+
+	// Range marks a parameter taking a list of values or ranges of values, written as 'start..end' or
+	// 'start-end'. Ranges starting after their end are rejected in strict mode.
+	Range bool `json:"x-range"`
+}
+
+// Response describes a single response of an operation.
+type Response struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// MediaType describes the body of a response in a given content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema describes a value. Only the keywords used by the spec are supported.
+type Schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format"`
+	Enum       []interface{}      `json:"enum"`
+	Minimum    *float64           `json:"minimum"`
+	Maximum    *float64           `json:"maximum"`
+	MaxLength  *int               `json:"maxLength"`
+	Pattern    string             `json:"pattern"`
+	Nullable   bool               `json:"nullable"`
+	Items      *Schema            `json:"items"`
+	Properties map[string]*Schema `json:"properties"`
+	Required   []string           `json:"required"`
+	OneOf      []*Schema          `json:"oneOf"`
+	Default    interface{}        `json:"default"`
+
+	pattern *regexp.Regexp
+}
+
+// V1 returns the parsed SpecV1. The spec is only parsed once.
+func V1() (*Spec, error) {
+	v1Once.Do(func() {
+		v1, v1Err = Parse(SpecV1)
+	})
+	return v1, v1Err
+}
+
+// Parse parses an OpenAPI document and resolves its references.
+func Parse(data []byte) (*Spec, error) {
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("cannot parse the OpenAPI spec: %v", err)
+	}
+	if err := spec.resolve(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// BasePath returns the path of the first server of the spec, which prefixes every path of the spec.
+func (spec *Spec) BasePath() string {
+	if len(spec.Servers) == 0 {
+		return ""
+	}
+	u, err := url.Parse(spec.Servers[0].Url)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// Operation looks up the operation for a method on a path of the spec, eg. 'GET' on '/co2/weekly/{ppm}'.
+func (spec *Spec) Operation(method string, path string) *Operation {
+	item, ok := spec.Paths[path]
+	if !ok {
+		return nil
+	}
+	return item.Operation(method)
+}
+
+// Operations returns every method and path of the spec with an operation, as 'METHOD /path'. Paths are sorted
+// in the order routes must be matched in: segment by segment, with literal segments before templated ones, so
+// that eg. '/co2/weekly/increase' is matched before '/co2/weekly/{ppm}'.
+func (spec *Spec) Operations() []string {
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool { return pathLess(paths[i], paths[j]) })
+
+	var ops []string
+	for _, path := range paths {
+		for _, method := range Methods {
+			if spec.Paths[path].Operation(method) != nil {
+				ops = append(ops, method+" "+path)
+			}
+		}
+	}
+	return ops
+}
+
+// pathLess orders two paths segment by segment, sorting literal segments before templated ones.
+func pathLess(a string, b string) bool {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+		aTemplated, bTemplated := strings.HasPrefix(as[i], "{"), strings.HasPrefix(bs[i], "{")
+		if aTemplated != bTemplated {
+			return bTemplated
+		}
+		return as[i] < bs[i]
+	}
+	return len(as) < len(bs)
+}
+
+// Operation returns the operation of a path item for a method, or nil if there is none.
+func (item *PathItem) Operation(method string) *Operation {
+	switch strings.ToUpper(method) {
+	case "GET":
+		return item.Get
+	case "PUT":
+		return item.Put
+	case "POST":
+		return item.Post
+	case "DELETE":
+		return item.Delete
+	case "OPTIONS":
+		return item.Options
+	case "HEAD":
+		return item.Head
+	case "PATCH":
+		return item.Patch
+	case "TRACE":
+		return item.Trace
+	}
+	return nil
+}
+
+// resolve replaces every reference of the spec with the object it points to, and compiles schema patterns.
+func (spec *Spec) resolve() error {
+	spec.Aliases = make(map[string]string)
+	for name, item := range spec.Paths {
+		if item.Ref != "" {
+			target, path, err := spec.pathRef(item.Ref)
+			if err != nil {
+				return fmt.Errorf("path '%v': %v", name, err)
+			}
+			spec.Paths[name] = target
+			spec.Aliases[name] = path
+		}
+	}
+
+	for name, param := range spec.Components.Parameters {
+		if err := spec.resolveSchema(param.Schema); err != nil {
+			return fmt.Errorf("parameter '%v': %v", name, err)
+		}
+	}
+	for name, schema := range spec.Components.Schemas {
+		if err := spec.resolveSchema(schema); err != nil {
+			return fmt.Errorf("schema '%v': %v", name, err)
+		}
+	}
+
+	for path, item := range spec.Paths {
+		for _, method := range Methods {
+			op := item.Operation(method)
+			if op == nil {
+				continue
+			}
+			// Parameters of the path apply to each of its operations, unless the operation overrides them
+			params := append(append([]*Parameter{}, item.Parameters...), op.Parameters...)
+			op.Parameters = nil
+			for _, param := range params {
+				resolved, err := spec.resolveParameter(param)
+				if err != nil {
+					return fmt.Errorf("%v %v: %v", method, path, err)
+				}
+				op.Parameters = overrideParameter(op.Parameters, resolved)
+			}
+
+			for code, resp := range op.Responses {
+				resolved, err := spec.resolveResponse(resp)
+				if err != nil {
+					return fmt.Errorf("%v %v, response '%v': %v", method, path, code, err)
+				}
+				op.Responses[code] = resolved
+			}
+		}
+	}
+	return nil
+}
+
+func (spec *Spec) resolveParameter(param *Parameter) (*Parameter, error) {
+	if param.Ref == "" {
+		return param, spec.resolveSchema(param.Schema)
+	}
+	name := strings.TrimPrefix(param.Ref, "#/components/parameters/")
+	target, ok := spec.Components.Parameters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown parameter '%v'", param.Ref)
+	}
+	return target, nil
+}
+
+func (spec *Spec) resolveResponse(resp *Response) (*Response, error) {
+	if resp.Ref != "" {
+		name := strings.TrimPrefix(resp.Ref, "#/components/responses/")
+		target, ok := spec.Components.Responses[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown response '%v'", resp.Ref)
+		}
+		resp = target
+	}
+	for _, media := range resp.Content {
+		if err := spec.resolveSchema(media.Schema); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// resolveSchema checks that the references held by a schema exist, and compiles its patterns. References are
+// followed lazily during validation, as schemas may be recursive.
+func (spec *Spec) resolveSchema(schema *Schema) error {
+	if schema == nil {
+		return nil
+	}
+	if schema.Ref != "" {
+		if _, err := spec.schemaRef(schema.Ref); err != nil {
+			return err
+		}
+		return nil
+	}
+	if schema.Pattern != "" && schema.pattern == nil {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern '%v': %v", schema.Pattern, err)
+		}
+		schema.pattern = pattern
+	}
+	for _, sub := range append(append([]*Schema{schema.Items}, schema.OneOf...), propertySchemas(schema)...) {
+		if err := spec.resolveSchema(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (spec *Spec) schemaRef(ref string) (*Schema, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("unresolved reference '%v'", ref)
+	}
+	target, ok := spec.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+	if !ok || !strings.HasPrefix(ref, "#/components/schemas/") {
+		return nil, fmt.Errorf("unknown schema '%v'", ref)
+	}
+	return target, nil
+}
+
+// pathRef looks up a path item referenced by a JSON pointer, eg. '#/paths/~1co2' for '/co2'.
+func (spec *Spec) pathRef(ref string) (*PathItem, string, error) {
+	if !strings.HasPrefix(ref, "#/paths/") {
+		return nil, "", fmt.Errorf("unsupported path reference '%v'", ref)
+	}
+	path := strings.NewReplacer("~1", "/", "~0", "~").Replace(strings.TrimPrefix(ref, "#/paths/"))
+	target, ok := spec.Paths[path]
+	if !ok || target.Ref != "" {
+		return nil, "", fmt.Errorf("unknown path '%v'", ref)
+	}
+	return target, path, nil
+}
+
+// overrideParameter adds a parameter to a list, replacing any parameter with the same name and location.
+func overrideParameter(params []*Parameter, param *Parameter) []*Parameter {
+	for i, p := range params {
+		if p.Name == param.Name && p.In == param.In {
+			params[i] = param
+			return params
+		}
+	}
+	return append(params, param)
+}
+
+func propertySchemas(schema *Schema) []*Schema {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	schemas := make([]*Schema, len(names))
+	for i, name := range names {
+		schemas[i] = schema.Properties[name]
+	}
+	return schemas
+}
+
+// Float returns a pointer to v, for the optional number fields of literal schemas.
+func Float(v float64) *float64 {
+	return &v
+}
+
+// Int returns a pointer to v, for the optional integer fields of literal schemas.
+func Int(v int) *int {
+	return &v
+}
+
+// Bool returns a pointer to v, for the optional boolean fields of literal parameters.
+func Bool(v bool) *bool {
+	return &v
+}
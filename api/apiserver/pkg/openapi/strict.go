@@ -0,0 +1,214 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package openapi
+
+import (
+	utils "apiserver/pkg/utils"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StrictParam is the name of the query parameter turning on strict mode for a single request.
+const StrictParam = "strict"
+
+// Strict reports whether a request asks for strict mode, that is if the parameters take the strict parameter
+// and it is set to true. Without it, strictDefault is returned.
+func (params Params) Strict(query url.Values, strictDefault bool) bool {
+	return isStrict(params, query, strictDefault)
+}
+
+// Strict reports whether a request to an operation asks for strict mode, as Params.Strict does.
+func (op *Operation) Strict(query url.Values, strictDefault bool) bool {
+	return isStrict(op.Parameters, query, strictDefault)
+}
+
+func isStrict(params []*Parameter, query url.Values, strictDefault bool) bool {
+	if findParam(params, StrictParam) == nil {
+		return false
+	}
+	values, ok := query[StrictParam]
+	if !ok || len(values) == 0 {
+		return strictDefault
+	}
+	strict, err := strconv.ParseBool(values[0])
+	return err == nil && strict
+}
+
+// strict returns the problems with the query parameters of a request that are only checked in strict mode:
+// parameters that are not taken, parameters taking a single value that are given more than once, ranges
+// starting after their end, and bounds that contradict each other so that no value can match them all.
+func (spec *Spec) strict(params []*Parameter, query url.Values) []utils.ParamProblem {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []utils.ParamProblem
+	for _, name := range names {
+		param := findParam(params, name)
+		if param == nil {
+			detail := fmt.Sprintf("parameter '%v' is not taken by this endpoint", name)
+			if suggestion := suggestParam(params, name); suggestion != "" {
+				detail += fmt.Sprintf(", did you mean '%v'?", suggestion)
+			}
+			problems = append(problems, utils.ParamProblem{Code: utils.CodeUnknownParam, Param: name, Detail: detail})
+			continue
+		}
+
+		values := query[name]
+		if len(values) > 1 && !spec.repeatable(param) {
+			problems = append(problems, utils.ParamProblem{
+				Code:   utils.CodeConflictingParams,
+				Param:  name,
+				Detail: fmt.Sprintf("parameter '%v' takes a single value, got %v: '%v'", name, len(values), strings.Join(values, "', '")),
+			})
+		}
+		if param.Range {
+			for _, value := range values {
+				for _, item := range strings.Split(strings.TrimPrefix(value, "!"), ",") {
+					if start, end, ok := parseRange(item); ok && start > end {
+						problems = append(problems, utils.ParamProblem{
+							Code:   utils.CodeConflictingParams,
+							Param:  name,
+							Detail: fmt.Sprintf("parameter '%v' holds the range '%v', which starts after its end", name, item),
+						})
+					}
+				}
+			}
+		}
+	}
+	return append(problems, boundProblems(params, query)...)
+}
+
+// findParam returns the query parameter of a name, or nil if there is none.
+func findParam(params []*Parameter, name string) *Parameter {
+	for _, param := range params {
+		if param.In == "query" && param.Name == name {
+			return param
+		}
+	}
+	return nil
+}
+
+// suggestParam returns the name of the query parameter closest to name by edit distance, or "" if none is within
+// a third of the length of name.
+func suggestParam(params []*Parameter, name string) string {
+	limit := len(name) / 3
+	if limit < 2 {
+		limit = 2
+	}
+
+	var suggestion string
+	for _, param := range params {
+		if param.In != "query" {
+			continue
+		}
+		if d := utils.EditDistance(name, param.Name); d <= limit {
+			suggestion, limit = param.Name, d-1
+		}
+	}
+	return suggestion
+}
+
+// repeatable reports whether a parameter may be given more than once: arrays and ranges take several values,
+// other parameters a single one.
+func (spec *Spec) repeatable(param *Parameter) bool {
+	if param.Range {
+		return true
+	}
+	schema, err := spec.deref(param.Schema, 0)
+	return err == nil && schema != nil && schema.Type == "array"
+}
+
+// parseRange parses an item of a range parameter written as 'start..end' or 'start-end'. Items that are not
+// ranges, or ranges left open ended, are not parsed.
+func parseRange(item string) (float64, float64, bool) {
+	sep := "-"
+	if strings.Contains(item, "..") {
+		sep = ".."
+	}
+	bounds := strings.SplitN(item, sep, 2)
+	if len(bounds) != 2 {
+		return 0, 0, false
+	}
+	start, startErr := strconv.ParseFloat(bounds[0], 64)
+	end, endErr := strconv.ParseFloat(bounds[1], 64)
+	if startErr != nil || endErr != nil {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// bound is the value of a parameter bounding the values a request matches.
+type bound struct {
+	param     string
+	raw       string
+	value     float64
+	exclusive bool
+}
+
+// boundProblems returns a problem if the lower bounds of a request (gt, gte) and its upper bounds (lt, lte)
+// contradict each other, eg. gt=420&lt=300, so that no value can match them all. Only the tightest bound of
+// each side is compared, and values that are not numbers are left to the validation of their schema.
+func boundProblems(params []*Parameter, query url.Values) []utils.ParamProblem {
+	var lower, upper *bound
+	for _, param := range params {
+		values := query[param.Name]
+		if param.In != "query" || param.Bound == "" || len(values) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			continue
+		}
+
+		b := &bound{param: param.Name, raw: values[0], value: value, exclusive: param.Bound == "gt" || param.Bound == "lt"}
+		switch param.Bound {
+		case "gt", "gte":
+			if lower == nil || b.value > lower.value || (b.value == lower.value && b.exclusive) {
+				lower = b
+			}
+		case "lt", "lte":
+			if upper == nil || b.value < upper.value || (b.value == upper.value && b.exclusive) {
+				upper = b
+			}
+		}
+	}
+
+	if lower == nil || upper == nil {
+		return nil
+	}
+	if lower.value < upper.value || (lower.value == upper.value && !lower.exclusive && !upper.exclusive) {
+		return nil
+	}
+	return []utils.ParamProblem{{
+		Code:   utils.CodeConflictingParams,
+		Param:  lower.param,
+		Detail: fmt.Sprintf("parameters '%v=%v' and '%v=%v' contradict each other, no value can match both", lower.param, lower.raw, upper.param, upper.raw),
+	}}
+}
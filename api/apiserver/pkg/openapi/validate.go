@@ -0,0 +1,413 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package openapi
+
+import (
+	"apiserver/pkg/utils"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxRefDepth bounds the number of references followed while validating a single value, so that a
+// recursive schema cannot loop forever.
+const maxRefDepth = 32
+
+// ValidationError lists every problem found while validating a request or response against the spec.
+type ValidationError struct {
+	Problems []string
+
+	// Param is the name of the first parameter of a request with a problem, and Code the code of the error
+	// catalog its problem is reported with
+	Param string
+	Code  string
+
+	// Details holds the problems of a request one by one, with the parameter and code of each
+	Details []utils.ParamProblem
+}
+
+func (err *ValidationError) Error() string {
+	return strings.Join(err.Problems, "; ")
+}
+
+// ErrorCode returns the code of the error catalog the problems of a request are reported with.
+func (err *ValidationError) ErrorCode() string {
+	return err.Code
+}
+
+// errorOrNil returns a ValidationError holding problems, or nil if there are none.
+func errorOrNil(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// Params validates the query and path parameters of an operation without a spec, eg. in generated code.
+// The schemas of the parameters must not hold references.
+type Params []*Parameter
+
+// NewParams returns Params validating params, and compiles the patterns of their schemas. It panics if a pattern
+// is invalid, as it is meant to be called from code generated from a valid spec.
+func NewParams(params ...*Parameter) Params {
+	var spec *Spec
+	for _, param := range params {
+		if err := spec.resolveSchema(param.Schema); err != nil {
+			panic(fmt.Sprintf("openapi: parameter '%v': %v", param.Name, err))
+		}
+	}
+	return params
+}
+
+// Validate checks the query and path parameters of a request. In strict mode, the query parameters are also
+// checked as described by Strict.
+func (params Params) Validate(query url.Values, path map[string]string, strict bool) error {
+	var spec *Spec
+	return spec.validateParams(params, query, path, strict)
+}
+
+// ValidateParams checks the query and path parameters of a request against the parameters of an operation.
+// Query parameters that the operation does not declare are left for the handler to reject, unless in strict mode.
+func (spec *Spec) ValidateParams(op *Operation, query url.Values, path map[string]string, strict bool) error {
+	return spec.validateParams(op.Parameters, query, path, strict)
+}
+
+func (spec *Spec) validateParams(params []*Parameter, query url.Values, path map[string]string, strict bool) error {
+	var details []utils.ParamProblem
+	for _, param := range params {
+		var values []string
+		switch param.In {
+		case "query":
+			values = query[param.Name]
+		case "path":
+			if v, ok := path[param.Name]; ok {
+				values = []string{v}
+			}
+		default:
+			continue
+		}
+
+		if len(values) == 0 {
+			if param.Required {
+				details = append(details, utils.ParamProblem{Code: utils.CodeInvalidParam, Param: param.Name, Detail: fmt.Sprintf("parameter '%v' is required", param.Name)})
+			}
+			continue
+		}
+		for _, value := range values {
+			valueProblems, valueCode := spec.validateParam(param, param.Schema, value)
+			for _, problem := range valueProblems {
+				details = append(details, utils.ParamProblem{Code: valueCode, Param: param.Name, Detail: problem})
+			}
+		}
+	}
+	if strict {
+		details = append(details, spec.strict(params, query)...)
+	}
+	if len(details) == 0 {
+		return nil
+	}
+
+	problems := make([]string, len(details))
+	for i, detail := range details {
+		problems[i] = detail.Detail
+	}
+	return &ValidationError{Problems: problems, Param: details[0].Param, Code: details[0].Code, Details: details}
+}
+
+// validateParam checks the raw value of a parameter. Arrays are written as a comma-separated list unless
+// the parameter is exploded, in which case each value is an item. The code of the error catalog the problems
+// are reported with is returned with them: OUT_OF_RANGE for well formed values outside of the bounds of the
+// schema, INVALID_PARAM otherwise.
+func (spec *Spec) validateParam(param *Parameter, schema *Schema, value string) ([]string, string) {
+	schema, err := spec.deref(schema, 0)
+	if err != nil || schema == nil {
+		return nil, ""
+	}
+
+	if len(schema.OneOf) != 0 {
+		var first []string
+		var firstCode string
+		for i, alt := range schema.OneOf {
+			problems, code := spec.validateParam(param, alt, value)
+			if len(problems) == 0 {
+				return nil, ""
+			}
+			if i == 0 {
+				first, firstCode = problems, code
+			}
+		}
+		return first, firstCode
+	}
+
+	if schema.Type == "array" {
+		items := []string{value}
+		if param.Explode == nil || !*param.Explode {
+			items = strings.Split(value, ",")
+		}
+		var problems []string
+		var code string
+		for _, item := range items {
+			itemProblems, itemCode := spec.validateParam(param, schema.Items, item)
+			if len(itemProblems) != 0 && code == "" {
+				code = itemCode
+			}
+			problems = append(problems, itemProblems...)
+		}
+		return problems, code
+	}
+
+	typed, ok := parseParam(schema.Type, value)
+	if !ok {
+		return []string{fmt.Sprintf("parameter '%v' must be of type %v, got '%v'", param.Name, schema.Type, value)}, utils.CodeInvalidParam
+	}
+	problems := spec.validate(schema, typed, "parameter '"+param.Name+"'", 0)
+	if n, isNumber := typed.(float64); isNumber && len(problems) != 0 &&
+		((schema.Minimum != nil && n < *schema.Minimum) || (schema.Maximum != nil && n > *schema.Maximum)) {
+		return problems, utils.CodeOutOfRange
+	}
+	return problems, utils.CodeInvalidParam
+}
+
+// parseParam converts the raw value of a parameter to the JSON representation of a type.
+func parseParam(typ string, value string) (interface{}, bool) {
+	switch typ {
+	case "integer", "number":
+		n, err := strconv.ParseFloat(value, 64)
+		return n, err == nil && !math.IsInf(n, 0) && !math.IsNaN(n)
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		return b, err == nil
+	default:
+		return value, true
+	}
+}
+
+// ValidateResponse checks that a response is documented by an operation: its status must be listed (or a default
+// response given), its content type must be listed for that status, and a JSON body must match the schema of that
+// content type. A nil body skips the check of the body, eg. for responses that are streamed.
+func (spec *Spec) ValidateResponse(op *Operation, status int, contentType string, body []byte) error {
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		return errorOrNil([]string{fmt.Sprintf("status %v is not documented", status)})
+	}
+
+	if len(resp.Content) == 0 {
+		if len(body) != 0 {
+			return errorOrNil([]string{fmt.Sprintf("status %v is documented without a body", status)})
+		}
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return errorOrNil([]string{fmt.Sprintf("invalid content type '%v'", contentType)})
+	}
+	media, ok := resp.Content[mediaType]
+	if !ok {
+		return errorOrNil([]string{fmt.Sprintf("content type '%v' is not documented for status %v", mediaType, status)})
+	}
+	if body == nil || media.Schema == nil || !IsJson(mediaType) {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return errorOrNil([]string{fmt.Sprintf("invalid JSON body: %v", err)})
+	}
+	return errorOrNil(spec.validate(media.Schema, value, "body", 0))
+}
+
+// IsJson reports whether a media type holds a single JSON document.
+func IsJson(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// deref follows the references of a schema. References cannot be followed without a spec.
+func (spec *Spec) deref(schema *Schema, depth int) (*Schema, error) {
+	for schema != nil && schema.Ref != "" {
+		if depth++; depth > maxRefDepth {
+			return nil, fmt.Errorf("too many nested references")
+		}
+		target, err := spec.schemaRef(schema.Ref)
+		if err != nil {
+			return nil, err
+		}
+		schema = target
+	}
+	return schema, nil
+}
+
+// validate checks a decoded JSON value against a schema, returning a problem for each mismatch.
+// Each problem is prefixed with the location of the mismatched value.
+func (spec *Spec) validate(schema *Schema, value interface{}, at string, depth int) []string {
+	schema, err := spec.deref(schema, depth)
+	if err != nil {
+		return []string{fmt.Sprintf("%v: %v", at, err)}
+	}
+	if schema == nil {
+		return nil
+	}
+
+	if value == nil {
+		if schema.Nullable || (schema.Type == "" && len(schema.OneOf) == 0) {
+			return nil
+		}
+		return []string{fmt.Sprintf("%v must not be null", at)}
+	}
+
+	if len(schema.OneOf) != 0 {
+		var first []string
+		for i, alt := range schema.OneOf {
+			problems := spec.validate(alt, value, at, depth+1)
+			if len(problems) == 0 {
+				return nil
+			}
+			if i == 0 {
+				first = problems
+			}
+		}
+		return first
+	}
+
+	if !hasType(schema.Type, value) {
+		return []string{fmt.Sprintf("%v must be of type %v, got %v", at, schema.Type, jsonType(value))}
+	}
+
+	var problems []string
+	if len(schema.Enum) != 0 && !inEnum(schema.Enum, value) {
+		problems = append(problems, fmt.Sprintf("%v must be one of %v, got '%v'", at, enumString(schema.Enum), value))
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if schema.Minimum != nil && v < *schema.Minimum {
+			problems = append(problems, fmt.Sprintf("%v must be at least %v, got %v", at, *schema.Minimum, v))
+		}
+		if schema.Maximum != nil && v > *schema.Maximum {
+			problems = append(problems, fmt.Sprintf("%v must be at most %v, got %v", at, *schema.Maximum, v))
+		}
+		if schema.Format == "int32" && (v < math.MinInt32 || v > math.MaxInt32) {
+			problems = append(problems, fmt.Sprintf("%v must be a 32-bit integer, got %v", at, v))
+		}
+	case string:
+		if schema.MaxLength != nil && utf8.RuneCountInString(v) > *schema.MaxLength {
+			problems = append(problems, fmt.Sprintf("%v must be at most %v characters long", at, *schema.MaxLength))
+		}
+		if schema.pattern != nil && !schema.pattern.MatchString(v) {
+			problems = append(problems, fmt.Sprintf("%v must match the pattern '%v', got '%v'", at, schema.Pattern, v))
+		}
+		if !hasFormat(schema.Format, v) {
+			problems = append(problems, fmt.Sprintf("%v must be a %v, got '%v'", at, schema.Format, v))
+		}
+	case []interface{}:
+		for i, item := range v {
+			problems = append(problems, spec.validate(schema.Items, item, fmt.Sprintf("%v[%v]", at, i), depth+1)...)
+		}
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%v.%v is required", at, name))
+			}
+		}
+		for name, prop := range schema.Properties {
+			if item, ok := v[name]; ok {
+				problems = append(problems, spec.validate(prop, item, at+"."+name, depth+1)...)
+			}
+		}
+	}
+	return problems
+}
+
+// hasType reports whether a decoded JSON value is of a schema type. An empty type matches any value.
+func hasType(typ string, value interface{}) bool {
+	switch typ {
+	case "":
+		return true
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	default:
+		return jsonType(value) == typ
+	}
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// hasFormat reports whether a string matches a format. Formats that are not checked always match.
+func hasFormat(format string, value string) bool {
+	switch format {
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.IsAbs()
+	}
+	return true
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if e == value {
+			return true
+		}
+	}
+	return false
+}
+
+func enumString(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, e := range enum {
+		values[i] = fmt.Sprint(e)
+	}
+	return strings.Join(values, ", ")
+}
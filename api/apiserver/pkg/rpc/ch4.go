@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package rpc
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/rpc/pb"
+	"apiserver/pkg/server/handlers/ch4"
+	utils "apiserver/pkg/utils"
+	"context"
+	"strings"
+)
+
+// ListCh4 returns a page of monthly CH4 measurements.
+func (service *Service) ListCh4(ctx context.Context, req *pb.Ch4Request) (*pb.Ch4Response, error) {
+	dataset := models.Ch4MmGl
+	query, err := ch4Query(req, false)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	ch4Table := models.Ch4Table{}
+	var dataObject models.DataObject = &ch4Table
+	if len(query.Fields) != 0 {
+		dataObject = &models.FieldsTable{Columns: query.Fields, Entries: (*[]interface{})(&ch4Table)}
+	}
+
+	dberr := service.Database.Query(query, dataObject)
+	if dberr != nil {
+		return nil, statusError(utils.NewError(dberr, "internal database error", 500, false))
+	}
+
+	id, idError := utils.GetCtxReqId(ctx)
+	if idError != nil {
+		return nil, statusError(utils.NewError(idError, "cannot extract request ID", 500, false))
+	}
+
+	resp := &pb.Ch4Response{RequestId: id, NextPage: nextPage(query, len(ch4Table))}
+	for _, entry := range ch4Table {
+		measurement, err := ch4Measurement(dataset, columns(dataset, query), entry)
+		if err != nil {
+			return nil, statusError(utils.NewError(err, "error encoding data as protobuf", 500, false))
+		}
+		resp.Results = append(resp.Results, measurement)
+	}
+	return resp, nil
+}
+
+// ExportCh4 streams monthly CH4 measurements as they are read from the database.
+func (service *Service) ExportCh4(req *pb.Ch4Request, stream pb.PlanetPulse_ExportCh4Server) error {
+	dataset := models.Ch4MmGl
+	query, err := ch4Query(req, true)
+	if err != nil {
+		return statusError(err)
+	}
+
+	ch4Table := models.Ch4Table{}
+	var dataObject models.DataObject = &ch4Table
+	if len(query.Fields) != 0 {
+		dataObject = &models.FieldsTable{Columns: query.Fields, Entries: (*[]interface{})(&ch4Table)}
+	}
+
+	sender := &rowSender{
+		dataObject: dataObject,
+		table:      (*[]interface{})(&ch4Table),
+		send: func(entry interface{}) error {
+			measurement, err := ch4Measurement(dataset, columns(dataset, query), entry)
+			if err != nil {
+				return err
+			}
+			return stream.Send(measurement)
+		},
+	}
+
+	dberr := service.Database.Query(query, sender)
+	if dberr != nil {
+		return statusError(utils.NewError(dberr, "error exporting data", 500, false))
+	}
+	return nil
+}
+
+// ch4Query builds the database query for a request. Exports are unlimited unless a limit is requested.
+func ch4Query(req *pb.Ch4Request, export bool) (database.DBQuery, *utils.ServerError) {
+	dataset := models.Ch4MmGl
+	query := database.NewQuery(dataset.Table, []string{"*"}, strings.Join(dataset.Order, ","))
+	if export {
+		query.Limit = -1
+	}
+
+	sortBy := "average"
+	if req.Value == pb.Ch4Value_CH4_VALUE_TREND {
+		sortBy = "trend"
+	}
+
+	filters, internalArgs, err := ch4.ParseValues(requestValues(req.Filters, req.Page, req.Simple), sortBy)
+	if err != nil {
+		return query, err
+	}
+
+	query.Where = filters
+	ch4.ParseInternalArgs(internalArgs, &query)
+	return query, nil
+}
+
+// ch4Measurement converts a row of a ch4 query into a protobuf message. Columns that were not selected are left unset.
+func ch4Measurement(dataset models.Dataset, columns []models.Column, entry interface{}) (*pb.Ch4Measurement, error) {
+	values, err := models.Values(entry, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	measurement := &pb.Ch4Measurement{}
+	for i, col := range columns {
+		switch col.Name {
+		case "year":
+			measurement.Year = intValue(values[i])
+		case "month":
+			measurement.Month = intValue(values[i])
+		case "date_decimal":
+			measurement.DateDecimal = floatValue(values[i])
+		case "average":
+			measurement.Average = measurementValue(dataset, values[i])
+		case "average_unc":
+			measurement.AverageUnc = measurementValue(dataset, values[i])
+		case "trend":
+			measurement.Trend = measurementValue(dataset, values[i])
+		case "trend_unc":
+			measurement.TrendUnc = measurementValue(dataset, values[i])
+		case "yyyymmdd":
+			measurement.Date = dateValue(values[i])
+		}
+	}
+	return measurement, nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package rpc
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/rpc/pb"
+	"apiserver/pkg/server/handlers/co2"
+	utils "apiserver/pkg/utils"
+	"context"
+	"strings"
+)
+
+// ListCo2 returns a page of weekly CO2 measurements.
+func (service *Service) ListCo2(ctx context.Context, req *pb.Co2Request) (*pb.Co2Response, error) {
+	dataset := models.Co2WeeklyMlo
+	query, err := co2Query(req, false)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	co2Table := models.Co2Table{}
+	var dataObject models.DataObject = &co2Table
+	if len(query.Fields) != 0 {
+		dataObject = &models.FieldsTable{Columns: query.Fields, Entries: (*[]interface{})(&co2Table)}
+	}
+
+	dberr := service.Database.Query(query, dataObject)
+	if dberr != nil {
+		return nil, statusError(utils.NewError(dberr, "internal database error", 500, false))
+	}
+
+	id, idError := utils.GetCtxReqId(ctx)
+	if idError != nil {
+		return nil, statusError(utils.NewError(idError, "cannot extract request ID", 500, false))
+	}
+
+	resp := &pb.Co2Response{RequestId: id, NextPage: nextPage(query, len(co2Table))}
+	for _, entry := range co2Table {
+		measurement, err := co2Measurement(dataset, columns(dataset, query), entry)
+		if err != nil {
+			return nil, statusError(utils.NewError(err, "error encoding data as protobuf", 500, false))
+		}
+		resp.Results = append(resp.Results, measurement)
+	}
+	return resp, nil
+}
+
+// ExportCo2 streams weekly CO2 measurements as they are read from the database.
+func (service *Service) ExportCo2(req *pb.Co2Request, stream pb.PlanetPulse_ExportCo2Server) error {
+	dataset := models.Co2WeeklyMlo
+	query, err := co2Query(req, true)
+	if err != nil {
+		return statusError(err)
+	}
+
+	co2Table := models.Co2Table{}
+	var dataObject models.DataObject = &co2Table
+	if len(query.Fields) != 0 {
+		dataObject = &models.FieldsTable{Columns: query.Fields, Entries: (*[]interface{})(&co2Table)}
+	}
+
+	sender := &rowSender{
+		dataObject: dataObject,
+		table:      (*[]interface{})(&co2Table),
+		send: func(entry interface{}) error {
+			measurement, err := co2Measurement(dataset, columns(dataset, query), entry)
+			if err != nil {
+				return err
+			}
+			return stream.Send(measurement)
+		},
+	}
+
+	dberr := service.Database.Query(query, sender)
+	if dberr != nil {
+		return statusError(utils.NewError(dberr, "error exporting data", 500, false))
+	}
+	return nil
+}
+
+// co2Query builds the database query for a request. Exports are unlimited unless a limit is requested.
+func co2Query(req *pb.Co2Request, export bool) (database.DBQuery, *utils.ServerError) {
+	dataset := models.Co2WeeklyMlo
+	query := database.NewQuery(dataset.Table, []string{"*"}, strings.Join(dataset.Order, ","))
+	if export {
+		query.Limit = -1
+	}
+
+	sortBy := "average"
+	if req.Value == pb.Co2Value_CO2_VALUE_INCREASE_SINCE_1800 {
+		sortBy = "increase"
+	}
+
+	filters, internalArgs, err := co2.ParseValues(requestValues(req.Filters, req.Page, req.Simple), sortBy)
+	if err != nil {
+		return query, err
+	}
+
+	query.Where = filters
+	co2.ParseInternalArgs(internalArgs, &query)
+	return query, nil
+}
+
+// co2Measurement converts a row of a co2 query into a protobuf message. Columns that were not selected are left unset.
+func co2Measurement(dataset models.Dataset, columns []models.Column, entry interface{}) (*pb.Co2Measurement, error) {
+	values, err := models.Values(entry, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	measurement := &pb.Co2Measurement{}
+	for i, col := range columns {
+		switch col.Name {
+		case "year":
+			measurement.Year = intValue(values[i])
+		case "month":
+			measurement.Month = intValue(values[i])
+		case "day":
+			measurement.Day = intValue(values[i])
+		case "date_decimal":
+			measurement.DateDecimal = floatValue(values[i])
+		case "average":
+			measurement.Average = measurementValue(dataset, values[i])
+		case "ndays":
+			measurement.Ndays = intValue(values[i])
+		case "one_year_ago":
+			measurement.OneYearAgo = measurementValue(dataset, values[i])
+		case "ten_years_ago":
+			measurement.TenYearsAgo = measurementValue(dataset, values[i])
+		case "increase_since_1800":
+			measurement.IncreaseSince_1800 = measurementValue(dataset, values[i])
+		case "yyyymmdd":
+			measurement.Date = dateValue(values[i])
+		}
+	}
+	return measurement, nil
+}
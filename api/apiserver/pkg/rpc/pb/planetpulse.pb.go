@@ -0,0 +1,1125 @@
+//
+// Copyright 2021 The PlanetPulse Authors.
+//
+// Planet Pulse is an API designed to serve climate data pulled from NOAA's
+// Global Monitoring Laboratory FTP server. This API is based on the
+// OpenAPI v3 specification.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// A copy of the GNU General Public License can be found here:
+// https://www.gnu.org/licenses/
+//
+// Contact: planetpulse.api@gmail.com
+//
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.1
+// source: planetpulse/v1/planetpulse.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Co2Value selects the CO2 column compared by the bound filters.
+type Co2Value int32
+
+const (
+	Co2Value_CO2_VALUE_AVERAGE             Co2Value = 0
+	Co2Value_CO2_VALUE_INCREASE_SINCE_1800 Co2Value = 1
+)
+
+// Enum value maps for Co2Value.
+var (
+	Co2Value_name = map[int32]string{
+		0: "CO2_VALUE_AVERAGE",
+		1: "CO2_VALUE_INCREASE_SINCE_1800",
+	}
+	Co2Value_value = map[string]int32{
+		"CO2_VALUE_AVERAGE":             0,
+		"CO2_VALUE_INCREASE_SINCE_1800": 1,
+	}
+)
+
+func (x Co2Value) Enum() *Co2Value {
+	p := new(Co2Value)
+	*p = x
+	return p
+}
+
+func (x Co2Value) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Co2Value) Descriptor() protoreflect.EnumDescriptor {
+	return file_planetpulse_v1_planetpulse_proto_enumTypes[0].Descriptor()
+}
+
+func (Co2Value) Type() protoreflect.EnumType {
+	return &file_planetpulse_v1_planetpulse_proto_enumTypes[0]
+}
+
+func (x Co2Value) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Co2Value.Descriptor instead.
+func (Co2Value) EnumDescriptor() ([]byte, []int) {
+	return file_planetpulse_v1_planetpulse_proto_rawDescGZIP(), []int{0}
+}
+
+// Ch4Value selects the CH4 column compared by the bound filters.
+type Ch4Value int32
+
+const (
+	Ch4Value_CH4_VALUE_AVERAGE Ch4Value = 0
+	Ch4Value_CH4_VALUE_TREND   Ch4Value = 1
+)
+
+// Enum value maps for Ch4Value.
+var (
+	Ch4Value_name = map[int32]string{
+		0: "CH4_VALUE_AVERAGE",
+		1: "CH4_VALUE_TREND",
+	}
+	Ch4Value_value = map[string]int32{
+		"CH4_VALUE_AVERAGE": 0,
+		"CH4_VALUE_TREND":   1,
+	}
+)
+
+func (x Ch4Value) Enum() *Ch4Value {
+	p := new(Ch4Value)
+	*p = x
+	return p
+}
+
+func (x Ch4Value) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Ch4Value) Descriptor() protoreflect.EnumDescriptor {
+	return file_planetpulse_v1_planetpulse_proto_enumTypes[1].Descriptor()
+}
+
+func (Ch4Value) Type() protoreflect.EnumType {
+	return &file_planetpulse_v1_planetpulse_proto_enumTypes[1]
+}
+
+func (x Ch4Value) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Ch4Value.Descriptor instead.
+func (Ch4Value) EnumDescriptor() ([]byte, []int) {
+	return file_planetpulse_v1_planetpulse_proto_rawDescGZIP(), []int{1}
+}
+
+// Filters restrict the measurements returned by a request. Every filter must match.
+type Filters struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Years to match, eg. '2020', '1990..2000' or '!2020', as in the REST 'year' parameter
+	Year []string `protobuf:"bytes,1,rep,name=year,proto3" json:"year,omitempty"`
+	// Months to match, eg. '6', '6-8' or '!1', as in the REST 'month' parameter
+	Month []string `protobuf:"bytes,2,rep,name=month,proto3" json:"month,omitempty"`
+	// Bounds on the value selected by the request
+	Gt  *float64 `protobuf:"fixed64,3,opt,name=gt,proto3,oneof" json:"gt,omitempty"`
+	Lt  *float64 `protobuf:"fixed64,4,opt,name=lt,proto3,oneof" json:"lt,omitempty"`
+	Gte *float64 `protobuf:"fixed64,5,opt,name=gte,proto3,oneof" json:"gte,omitempty"`
+	Lte *float64 `protobuf:"fixed64,6,opt,name=lte,proto3,oneof" json:"lte,omitempty"`
+	// A filter expression, as in the REST 'filter' parameter
+	Expression string `protobuf:"bytes,7,opt,name=expression,proto3" json:"expression,omitempty"`
+}
+
+func (x *Filters) Reset() {
+	*x = Filters{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Filters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Filters) ProtoMessage() {}
+
+func (x *Filters) ProtoReflect() protoreflect.Message {
+	mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Filters.ProtoReflect.Descriptor instead.
+func (*Filters) Descriptor() ([]byte, []int) {
+	return file_planetpulse_v1_planetpulse_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Filters) GetYear() []string {
+	if x != nil {
+		return x.Year
+	}
+	return nil
+}
+
+func (x *Filters) GetMonth() []string {
+	if x != nil {
+		return x.Month
+	}
+	return nil
+}
+
+func (x *Filters) GetGt() float64 {
+	if x != nil && x.Gt != nil {
+		return *x.Gt
+	}
+	return 0
+}
+
+func (x *Filters) GetLt() float64 {
+	if x != nil && x.Lt != nil {
+		return *x.Lt
+	}
+	return 0
+}
+
+func (x *Filters) GetGte() float64 {
+	if x != nil && x.Gte != nil {
+		return *x.Gte
+	}
+	return 0
+}
+
+func (x *Filters) GetLte() float64 {
+	if x != nil && x.Lte != nil {
+		return *x.Lte
+	}
+	return 0
+}
+
+func (x *Filters) GetExpression() string {
+	if x != nil {
+		return x.Expression
+	}
+	return ""
+}
+
+// Page selects a page of results. Unset fields take the defaults of the REST API.
+type Page struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The number of measurements per page, 10 when unset for List methods. Export methods are unlimited when unset.
+	Limit int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	// The number of measurements to skip before the first page
+	Offset int32 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// The page to return, numbered from 1
+	Page int32 `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+}
+
+func (x *Page) Reset() {
+	*x = Page{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Page) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Page) ProtoMessage() {}
+
+func (x *Page) ProtoReflect() protoreflect.Message {
+	mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Page.ProtoReflect.Descriptor instead.
+func (*Page) Descriptor() ([]byte, []int) {
+	return file_planetpulse_v1_planetpulse_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Page) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *Page) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *Page) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+type Co2Request struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value   Co2Value `protobuf:"varint,1,opt,name=value,proto3,enum=planetpulse.v1.Co2Value" json:"value,omitempty"`
+	Filters *Filters `protobuf:"bytes,2,opt,name=filters,proto3" json:"filters,omitempty"`
+	Page    *Page    `protobuf:"bytes,3,opt,name=page,proto3" json:"page,omitempty"`
+	// If true, only the fields of the REST 'simple' preset are set in each measurement
+	Simple bool `protobuf:"varint,4,opt,name=simple,proto3" json:"simple,omitempty"`
+}
+
+func (x *Co2Request) Reset() {
+	*x = Co2Request{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Co2Request) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Co2Request) ProtoMessage() {}
+
+func (x *Co2Request) ProtoReflect() protoreflect.Message {
+	mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Co2Request.ProtoReflect.Descriptor instead.
+func (*Co2Request) Descriptor() ([]byte, []int) {
+	return file_planetpulse_v1_planetpulse_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Co2Request) GetValue() Co2Value {
+	if x != nil {
+		return x.Value
+	}
+	return Co2Value_CO2_VALUE_AVERAGE
+}
+
+func (x *Co2Request) GetFilters() *Filters {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *Co2Request) GetPage() *Page {
+	if x != nil {
+		return x.Page
+	}
+	return nil
+}
+
+func (x *Co2Request) GetSimple() bool {
+	if x != nil {
+		return x.Simple
+	}
+	return false
+}
+
+type Co2Response struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results   []*Co2Measurement `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	RequestId string            `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// The next page of results, or 0 when this is the last page
+	NextPage int32 `protobuf:"varint,3,opt,name=next_page,json=nextPage,proto3" json:"next_page,omitempty"`
+}
+
+func (x *Co2Response) Reset() {
+	*x = Co2Response{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Co2Response) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Co2Response) ProtoMessage() {}
+
+func (x *Co2Response) ProtoReflect() protoreflect.Message {
+	mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Co2Response.ProtoReflect.Descriptor instead.
+func (*Co2Response) Descriptor() ([]byte, []int) {
+	return file_planetpulse_v1_planetpulse_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Co2Response) GetResults() []*Co2Measurement {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *Co2Response) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Co2Response) GetNextPage() int32 {
+	if x != nil {
+		return x.NextPage
+	}
+	return 0
+}
+
+// Co2Measurement is a weekly average CO2 measurement in ppm. Measurements NOAA could not make are left unset.
+type Co2Measurement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Year               int32                  `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+	Month              int32                  `protobuf:"varint,2,opt,name=month,proto3" json:"month,omitempty"`
+	Day                int32                  `protobuf:"varint,3,opt,name=day,proto3" json:"day,omitempty"`
+	DateDecimal        float32                `protobuf:"fixed32,4,opt,name=date_decimal,json=dateDecimal,proto3" json:"date_decimal,omitempty"`
+	Average            *float32               `protobuf:"fixed32,5,opt,name=average,proto3,oneof" json:"average,omitempty"`
+	Ndays              int32                  `protobuf:"varint,6,opt,name=ndays,proto3" json:"ndays,omitempty"`
+	OneYearAgo         *float32               `protobuf:"fixed32,7,opt,name=one_year_ago,json=oneYearAgo,proto3,oneof" json:"one_year_ago,omitempty"`
+	TenYearsAgo        *float32               `protobuf:"fixed32,8,opt,name=ten_years_ago,json=tenYearsAgo,proto3,oneof" json:"ten_years_ago,omitempty"`
+	IncreaseSince_1800 *float32               `protobuf:"fixed32,9,opt,name=increase_since_1800,json=increaseSince1800,proto3,oneof" json:"increase_since_1800,omitempty"`
+	Date               *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=date,proto3" json:"date,omitempty"`
+}
+
+func (x *Co2Measurement) Reset() {
+	*x = Co2Measurement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Co2Measurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Co2Measurement) ProtoMessage() {}
+
+func (x *Co2Measurement) ProtoReflect() protoreflect.Message {
+	mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Co2Measurement.ProtoReflect.Descriptor instead.
+func (*Co2Measurement) Descriptor() ([]byte, []int) {
+	return file_planetpulse_v1_planetpulse_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Co2Measurement) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *Co2Measurement) GetMonth() int32 {
+	if x != nil {
+		return x.Month
+	}
+	return 0
+}
+
+func (x *Co2Measurement) GetDay() int32 {
+	if x != nil {
+		return x.Day
+	}
+	return 0
+}
+
+func (x *Co2Measurement) GetDateDecimal() float32 {
+	if x != nil {
+		return x.DateDecimal
+	}
+	return 0
+}
+
+func (x *Co2Measurement) GetAverage() float32 {
+	if x != nil && x.Average != nil {
+		return *x.Average
+	}
+	return 0
+}
+
+func (x *Co2Measurement) GetNdays() int32 {
+	if x != nil {
+		return x.Ndays
+	}
+	return 0
+}
+
+func (x *Co2Measurement) GetOneYearAgo() float32 {
+	if x != nil && x.OneYearAgo != nil {
+		return *x.OneYearAgo
+	}
+	return 0
+}
+
+func (x *Co2Measurement) GetTenYearsAgo() float32 {
+	if x != nil && x.TenYearsAgo != nil {
+		return *x.TenYearsAgo
+	}
+	return 0
+}
+
+func (x *Co2Measurement) GetIncreaseSince_1800() float32 {
+	if x != nil && x.IncreaseSince_1800 != nil {
+		return *x.IncreaseSince_1800
+	}
+	return 0
+}
+
+func (x *Co2Measurement) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+type Ch4Request struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value   Ch4Value `protobuf:"varint,1,opt,name=value,proto3,enum=planetpulse.v1.Ch4Value" json:"value,omitempty"`
+	Filters *Filters `protobuf:"bytes,2,opt,name=filters,proto3" json:"filters,omitempty"`
+	Page    *Page    `protobuf:"bytes,3,opt,name=page,proto3" json:"page,omitempty"`
+	// If true, only the fields of the REST 'simple' preset are set in each measurement
+	Simple bool `protobuf:"varint,4,opt,name=simple,proto3" json:"simple,omitempty"`
+}
+
+func (x *Ch4Request) Reset() {
+	*x = Ch4Request{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Ch4Request) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ch4Request) ProtoMessage() {}
+
+func (x *Ch4Request) ProtoReflect() protoreflect.Message {
+	mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ch4Request.ProtoReflect.Descriptor instead.
+func (*Ch4Request) Descriptor() ([]byte, []int) {
+	return file_planetpulse_v1_planetpulse_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Ch4Request) GetValue() Ch4Value {
+	if x != nil {
+		return x.Value
+	}
+	return Ch4Value_CH4_VALUE_AVERAGE
+}
+
+func (x *Ch4Request) GetFilters() *Filters {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *Ch4Request) GetPage() *Page {
+	if x != nil {
+		return x.Page
+	}
+	return nil
+}
+
+func (x *Ch4Request) GetSimple() bool {
+	if x != nil {
+		return x.Simple
+	}
+	return false
+}
+
+type Ch4Response struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results   []*Ch4Measurement `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	RequestId string            `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// The next page of results, or 0 when this is the last page
+	NextPage int32 `protobuf:"varint,3,opt,name=next_page,json=nextPage,proto3" json:"next_page,omitempty"`
+}
+
+func (x *Ch4Response) Reset() {
+	*x = Ch4Response{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Ch4Response) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ch4Response) ProtoMessage() {}
+
+func (x *Ch4Response) ProtoReflect() protoreflect.Message {
+	mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ch4Response.ProtoReflect.Descriptor instead.
+func (*Ch4Response) Descriptor() ([]byte, []int) {
+	return file_planetpulse_v1_planetpulse_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Ch4Response) GetResults() []*Ch4Measurement {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *Ch4Response) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Ch4Response) GetNextPage() int32 {
+	if x != nil {
+		return x.NextPage
+	}
+	return 0
+}
+
+// Ch4Measurement is a monthly global average CH4 measurement in ppb. Measurements NOAA could not make are left unset.
+type Ch4Measurement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Year        int32                  `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+	Month       int32                  `protobuf:"varint,2,opt,name=month,proto3" json:"month,omitempty"`
+	DateDecimal float32                `protobuf:"fixed32,3,opt,name=date_decimal,json=dateDecimal,proto3" json:"date_decimal,omitempty"`
+	Average     *float32               `protobuf:"fixed32,4,opt,name=average,proto3,oneof" json:"average,omitempty"`
+	AverageUnc  *float32               `protobuf:"fixed32,5,opt,name=average_unc,json=averageUnc,proto3,oneof" json:"average_unc,omitempty"`
+	Trend       *float32               `protobuf:"fixed32,6,opt,name=trend,proto3,oneof" json:"trend,omitempty"`
+	TrendUnc    *float32               `protobuf:"fixed32,7,opt,name=trend_unc,json=trendUnc,proto3,oneof" json:"trend_unc,omitempty"`
+	Date        *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=date,proto3" json:"date,omitempty"`
+}
+
+func (x *Ch4Measurement) Reset() {
+	*x = Ch4Measurement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Ch4Measurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ch4Measurement) ProtoMessage() {}
+
+func (x *Ch4Measurement) ProtoReflect() protoreflect.Message {
+	mi := &file_planetpulse_v1_planetpulse_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ch4Measurement.ProtoReflect.Descriptor instead.
+func (*Ch4Measurement) Descriptor() ([]byte, []int) {
+	return file_planetpulse_v1_planetpulse_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Ch4Measurement) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *Ch4Measurement) GetMonth() int32 {
+	if x != nil {
+		return x.Month
+	}
+	return 0
+}
+
+func (x *Ch4Measurement) GetDateDecimal() float32 {
+	if x != nil {
+		return x.DateDecimal
+	}
+	return 0
+}
+
+func (x *Ch4Measurement) GetAverage() float32 {
+	if x != nil && x.Average != nil {
+		return *x.Average
+	}
+	return 0
+}
+
+func (x *Ch4Measurement) GetAverageUnc() float32 {
+	if x != nil && x.AverageUnc != nil {
+		return *x.AverageUnc
+	}
+	return 0
+}
+
+func (x *Ch4Measurement) GetTrend() float32 {
+	if x != nil && x.Trend != nil {
+		return *x.Trend
+	}
+	return 0
+}
+
+func (x *Ch4Measurement) GetTrendUnc() float32 {
+	if x != nil && x.TrendUnc != nil {
+		return *x.TrendUnc
+	}
+	return 0
+}
+
+func (x *Ch4Measurement) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+var File_planetpulse_v1_planetpulse_proto protoreflect.FileDescriptor
+
+var file_planetpulse_v1_planetpulse_proto_rawDesc = []byte{
+	0x0a, 0x20, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x2f, 0x76, 0x31,
+	0x2f, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x0e, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x2e,
+	0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0xc9, 0x01, 0x0a, 0x07, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x12,
+	0x12, 0x0a, 0x04, 0x79, 0x65, 0x61, 0x72, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x79,
+	0x65, 0x61, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x6e, 0x74, 0x68, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x05, 0x6d, 0x6f, 0x6e, 0x74, 0x68, 0x12, 0x13, 0x0a, 0x02, 0x67, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x02, 0x67, 0x74, 0x88, 0x01, 0x01, 0x12, 0x13,
+	0x0a, 0x02, 0x6c, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x02, 0x6c, 0x74,
+	0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x67, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x02, 0x52, 0x03, 0x67, 0x74, 0x65, 0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x6c, 0x74,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x48, 0x03, 0x52, 0x03, 0x6c, 0x74, 0x65, 0x88, 0x01,
+	0x01, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x67, 0x74, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x6c, 0x74, 0x42,
+	0x06, 0x0a, 0x04, 0x5f, 0x67, 0x74, 0x65, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x6c, 0x74, 0x65, 0x22,
+	0x48, 0x0a, 0x04, 0x50, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6f,
+	0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x22, 0xb1, 0x01, 0x0a, 0x0a, 0x43, 0x6f,
+	0x32, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74,
+	0x70, 0x75, 0x6c, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x32, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x31, 0x0a, 0x07, 0x66, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x70, 0x6c, 0x61, 0x6e,
+	0x65, 0x74, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65,
+	0x72, 0x73, 0x52, 0x07, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x12, 0x28, 0x0a, 0x04, 0x70,
+	0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x6c, 0x61, 0x6e,
+	0x65, 0x74, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x67, 0x65, 0x52,
+	0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x22, 0x83, 0x01,
+	0x0a, 0x0b, 0x43, 0x6f, 0x32, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x38, 0x0a,
+	0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e,
+	0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x6f, 0x32, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x07,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70,
+	0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6e, 0x65, 0x78, 0x74, 0x50,
+	0x61, 0x67, 0x65, 0x22, 0xa0, 0x03, 0x0a, 0x0e, 0x43, 0x6f, 0x32, 0x4d, 0x65, 0x61, 0x73, 0x75,
+	0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x79, 0x65, 0x61, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x79, 0x65, 0x61, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f,
+	0x6e, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6d, 0x6f, 0x6e, 0x74, 0x68,
+	0x12, 0x10, 0x0a, 0x03, 0x64, 0x61, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x64,
+	0x61, 0x79, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x64, 0x65, 0x63, 0x69, 0x6d,
+	0x61, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0b, 0x64, 0x61, 0x74, 0x65, 0x44, 0x65,
+	0x63, 0x69, 0x6d, 0x61, 0x6c, 0x12, 0x1d, 0x0a, 0x07, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x02, 0x48, 0x00, 0x52, 0x07, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67,
+	0x65, 0x88, 0x01, 0x01, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x64, 0x61, 0x79, 0x73, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x6e, 0x64, 0x61, 0x79, 0x73, 0x12, 0x25, 0x0a, 0x0c, 0x6f, 0x6e,
+	0x65, 0x5f, 0x79, 0x65, 0x61, 0x72, 0x5f, 0x61, 0x67, 0x6f, 0x18, 0x07, 0x20, 0x01, 0x28, 0x02,
+	0x48, 0x01, 0x52, 0x0a, 0x6f, 0x6e, 0x65, 0x59, 0x65, 0x61, 0x72, 0x41, 0x67, 0x6f, 0x88, 0x01,
+	0x01, 0x12, 0x27, 0x0a, 0x0d, 0x74, 0x65, 0x6e, 0x5f, 0x79, 0x65, 0x61, 0x72, 0x73, 0x5f, 0x61,
+	0x67, 0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x02, 0x48, 0x02, 0x52, 0x0b, 0x74, 0x65, 0x6e, 0x59,
+	0x65, 0x61, 0x72, 0x73, 0x41, 0x67, 0x6f, 0x88, 0x01, 0x01, 0x12, 0x33, 0x0a, 0x13, 0x69, 0x6e,
+	0x63, 0x72, 0x65, 0x61, 0x73, 0x65, 0x5f, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x5f, 0x31, 0x38, 0x30,
+	0x30, 0x18, 0x09, 0x20, 0x01, 0x28, 0x02, 0x48, 0x03, 0x52, 0x11, 0x69, 0x6e, 0x63, 0x72, 0x65,
+	0x61, 0x73, 0x65, 0x53, 0x69, 0x6e, 0x63, 0x65, 0x31, 0x38, 0x30, 0x30, 0x88, 0x01, 0x01, 0x12,
+	0x2e, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x42,
+	0x0a, 0x0a, 0x08, 0x5f, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x42, 0x0f, 0x0a, 0x0d, 0x5f,
+	0x6f, 0x6e, 0x65, 0x5f, 0x79, 0x65, 0x61, 0x72, 0x5f, 0x61, 0x67, 0x6f, 0x42, 0x10, 0x0a, 0x0e,
+	0x5f, 0x74, 0x65, 0x6e, 0x5f, 0x79, 0x65, 0x61, 0x72, 0x73, 0x5f, 0x61, 0x67, 0x6f, 0x42, 0x16,
+	0x0a, 0x14, 0x5f, 0x69, 0x6e, 0x63, 0x72, 0x65, 0x61, 0x73, 0x65, 0x5f, 0x73, 0x69, 0x6e, 0x63,
+	0x65, 0x5f, 0x31, 0x38, 0x30, 0x30, 0x22, 0xb1, 0x01, 0x0a, 0x0a, 0x43, 0x68, 0x34, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x70, 0x75, 0x6c,
+	0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x34, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x31, 0x0a, 0x07, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x70,
+	0x75, 0x6c, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x52,
+	0x07, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x12, 0x28, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x70,
+	0x75, 0x6c, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x67, 0x65, 0x52, 0x04, 0x70, 0x61,
+	0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x06, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x22, 0x83, 0x01, 0x0a, 0x0b, 0x43,
+	0x68, 0x34, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x38, 0x0a, 0x07, 0x72, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x70, 0x6c,
+	0x61, 0x6e, 0x65, 0x74, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x34,
+	0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65,
+	0x22, 0xc3, 0x02, 0x0a, 0x0e, 0x43, 0x68, 0x34, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x79, 0x65, 0x61, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x04, 0x79, 0x65, 0x61, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x6e, 0x74, 0x68,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6d, 0x6f, 0x6e, 0x74, 0x68, 0x12, 0x21, 0x0a,
+	0x0c, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x02, 0x52, 0x0b, 0x64, 0x61, 0x74, 0x65, 0x44, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c,
+	0x12, 0x1d, 0x0a, 0x07, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x02, 0x48, 0x00, 0x52, 0x07, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x88, 0x01, 0x01, 0x12,
+	0x24, 0x0a, 0x0b, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x75, 0x6e, 0x63, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x02, 0x48, 0x01, 0x52, 0x0a, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x55,
+	0x6e, 0x63, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05, 0x74, 0x72, 0x65, 0x6e, 0x64, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x02, 0x48, 0x02, 0x52, 0x05, 0x74, 0x72, 0x65, 0x6e, 0x64, 0x88, 0x01, 0x01,
+	0x12, 0x20, 0x0a, 0x09, 0x74, 0x72, 0x65, 0x6e, 0x64, 0x5f, 0x75, 0x6e, 0x63, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x02, 0x48, 0x03, 0x52, 0x08, 0x74, 0x72, 0x65, 0x6e, 0x64, 0x55, 0x6e, 0x63, 0x88,
+	0x01, 0x01, 0x12, 0x2e, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x64, 0x61,
+	0x74, 0x65, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x42, 0x0e,
+	0x0a, 0x0c, 0x5f, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x75, 0x6e, 0x63, 0x42, 0x08,
+	0x0a, 0x06, 0x5f, 0x74, 0x72, 0x65, 0x6e, 0x64, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x74, 0x72, 0x65,
+	0x6e, 0x64, 0x5f, 0x75, 0x6e, 0x63, 0x2a, 0x44, 0x0a, 0x08, 0x43, 0x6f, 0x32, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x4f, 0x32, 0x5f, 0x56, 0x41, 0x4c, 0x55, 0x45, 0x5f,
+	0x41, 0x56, 0x45, 0x52, 0x41, 0x47, 0x45, 0x10, 0x00, 0x12, 0x21, 0x0a, 0x1d, 0x43, 0x4f, 0x32,
+	0x5f, 0x56, 0x41, 0x4c, 0x55, 0x45, 0x5f, 0x49, 0x4e, 0x43, 0x52, 0x45, 0x41, 0x53, 0x45, 0x5f,
+	0x53, 0x49, 0x4e, 0x43, 0x45, 0x5f, 0x31, 0x38, 0x30, 0x30, 0x10, 0x01, 0x2a, 0x36, 0x0a, 0x08,
+	0x43, 0x68, 0x34, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x48, 0x34, 0x5f,
+	0x56, 0x41, 0x4c, 0x55, 0x45, 0x5f, 0x41, 0x56, 0x45, 0x52, 0x41, 0x47, 0x45, 0x10, 0x00, 0x12,
+	0x13, 0x0a, 0x0f, 0x43, 0x48, 0x34, 0x5f, 0x56, 0x41, 0x4c, 0x55, 0x45, 0x5f, 0x54, 0x52, 0x45,
+	0x4e, 0x44, 0x10, 0x01, 0x32, 0xab, 0x02, 0x0a, 0x0b, 0x50, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x50,
+	0x75, 0x6c, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x07, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6f, 0x32, 0x12,
+	0x1a, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x6f, 0x32, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x70, 0x6c,
+	0x61, 0x6e, 0x65, 0x74, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x32,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49, 0x0a, 0x09, 0x45, 0x78, 0x70, 0x6f,
+	0x72, 0x74, 0x43, 0x6f, 0x32, 0x12, 0x1a, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x70, 0x75,
+	0x6c, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x32, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1e, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x6f, 0x32, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x30, 0x01, 0x12, 0x42, 0x0a, 0x07, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x68, 0x34, 0x12, 0x1a,
+	0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x68, 0x34, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x70, 0x6c, 0x61,
+	0x6e, 0x65, 0x74, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x34, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49, 0x0a, 0x09, 0x45, 0x78, 0x70, 0x6f, 0x72,
+	0x74, 0x43, 0x68, 0x34, 0x12, 0x1a, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x70, 0x75, 0x6c,
+	0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x34, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1e, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x74, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x43, 0x68, 0x34, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x30, 0x01, 0x42, 0x16, 0x5a, 0x14, 0x61, 0x70, 0x69, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2f,
+	0x70, 0x6b, 0x67, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_planetpulse_v1_planetpulse_proto_rawDescOnce sync.Once
+	file_planetpulse_v1_planetpulse_proto_rawDescData = file_planetpulse_v1_planetpulse_proto_rawDesc
+)
+
+func file_planetpulse_v1_planetpulse_proto_rawDescGZIP() []byte {
+	file_planetpulse_v1_planetpulse_proto_rawDescOnce.Do(func() {
+		file_planetpulse_v1_planetpulse_proto_rawDescData = protoimpl.X.CompressGZIP(file_planetpulse_v1_planetpulse_proto_rawDescData)
+	})
+	return file_planetpulse_v1_planetpulse_proto_rawDescData
+}
+
+var file_planetpulse_v1_planetpulse_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_planetpulse_v1_planetpulse_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_planetpulse_v1_planetpulse_proto_goTypes = []interface{}{
+	(Co2Value)(0),                 // 0: planetpulse.v1.Co2Value
+	(Ch4Value)(0),                 // 1: planetpulse.v1.Ch4Value
+	(*Filters)(nil),               // 2: planetpulse.v1.Filters
+	(*Page)(nil),                  // 3: planetpulse.v1.Page
+	(*Co2Request)(nil),            // 4: planetpulse.v1.Co2Request
+	(*Co2Response)(nil),           // 5: planetpulse.v1.Co2Response
+	(*Co2Measurement)(nil),        // 6: planetpulse.v1.Co2Measurement
+	(*Ch4Request)(nil),            // 7: planetpulse.v1.Ch4Request
+	(*Ch4Response)(nil),           // 8: planetpulse.v1.Ch4Response
+	(*Ch4Measurement)(nil),        // 9: planetpulse.v1.Ch4Measurement
+	(*timestamppb.Timestamp)(nil), // 10: google.protobuf.Timestamp
+}
+var file_planetpulse_v1_planetpulse_proto_depIdxs = []int32{
+	0,  // 0: planetpulse.v1.Co2Request.value:type_name -> planetpulse.v1.Co2Value
+	2,  // 1: planetpulse.v1.Co2Request.filters:type_name -> planetpulse.v1.Filters
+	3,  // 2: planetpulse.v1.Co2Request.page:type_name -> planetpulse.v1.Page
+	6,  // 3: planetpulse.v1.Co2Response.results:type_name -> planetpulse.v1.Co2Measurement
+	10, // 4: planetpulse.v1.Co2Measurement.date:type_name -> google.protobuf.Timestamp
+	1,  // 5: planetpulse.v1.Ch4Request.value:type_name -> planetpulse.v1.Ch4Value
+	2,  // 6: planetpulse.v1.Ch4Request.filters:type_name -> planetpulse.v1.Filters
+	3,  // 7: planetpulse.v1.Ch4Request.page:type_name -> planetpulse.v1.Page
+	9,  // 8: planetpulse.v1.Ch4Response.results:type_name -> planetpulse.v1.Ch4Measurement
+	10, // 9: planetpulse.v1.Ch4Measurement.date:type_name -> google.protobuf.Timestamp
+	4,  // 10: planetpulse.v1.PlanetPulse.ListCo2:input_type -> planetpulse.v1.Co2Request
+	4,  // 11: planetpulse.v1.PlanetPulse.ExportCo2:input_type -> planetpulse.v1.Co2Request
+	7,  // 12: planetpulse.v1.PlanetPulse.ListCh4:input_type -> planetpulse.v1.Ch4Request
+	7,  // 13: planetpulse.v1.PlanetPulse.ExportCh4:input_type -> planetpulse.v1.Ch4Request
+	5,  // 14: planetpulse.v1.PlanetPulse.ListCo2:output_type -> planetpulse.v1.Co2Response
+	6,  // 15: planetpulse.v1.PlanetPulse.ExportCo2:output_type -> planetpulse.v1.Co2Measurement
+	8,  // 16: planetpulse.v1.PlanetPulse.ListCh4:output_type -> planetpulse.v1.Ch4Response
+	9,  // 17: planetpulse.v1.PlanetPulse.ExportCh4:output_type -> planetpulse.v1.Ch4Measurement
+	14, // [14:18] is the sub-list for method output_type
+	10, // [10:14] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_planetpulse_v1_planetpulse_proto_init() }
+func file_planetpulse_v1_planetpulse_proto_init() {
+	if File_planetpulse_v1_planetpulse_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_planetpulse_v1_planetpulse_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Filters); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_planetpulse_v1_planetpulse_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Page); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_planetpulse_v1_planetpulse_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Co2Request); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_planetpulse_v1_planetpulse_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Co2Response); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_planetpulse_v1_planetpulse_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Co2Measurement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_planetpulse_v1_planetpulse_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Ch4Request); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_planetpulse_v1_planetpulse_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Ch4Response); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_planetpulse_v1_planetpulse_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Ch4Measurement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_planetpulse_v1_planetpulse_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	file_planetpulse_v1_planetpulse_proto_msgTypes[4].OneofWrappers = []interface{}{}
+	file_planetpulse_v1_planetpulse_proto_msgTypes[7].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_planetpulse_v1_planetpulse_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_planetpulse_v1_planetpulse_proto_goTypes,
+		DependencyIndexes: file_planetpulse_v1_planetpulse_proto_depIdxs,
+		EnumInfos:         file_planetpulse_v1_planetpulse_proto_enumTypes,
+		MessageInfos:      file_planetpulse_v1_planetpulse_proto_msgTypes,
+	}.Build()
+	File_planetpulse_v1_planetpulse_proto = out.File
+	file_planetpulse_v1_planetpulse_proto_rawDesc = nil
+	file_planetpulse_v1_planetpulse_proto_goTypes = nil
+	file_planetpulse_v1_planetpulse_proto_depIdxs = nil
+}
@@ -0,0 +1,276 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.1
+// source: planetpulse/v1/planetpulse.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// PlanetPulseClient is the client API for PlanetPulse service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PlanetPulseClient interface {
+	// ListCo2 returns a page of weekly CO2 measurements taken at Mauna Loa Observatory.
+	ListCo2(ctx context.Context, in *Co2Request, opts ...grpc.CallOption) (*Co2Response, error)
+	// ExportCo2 streams every matching weekly CO2 measurement, unless a limit is requested.
+	ExportCo2(ctx context.Context, in *Co2Request, opts ...grpc.CallOption) (PlanetPulse_ExportCo2Client, error)
+	// ListCh4 returns a page of monthly global CH4 measurements.
+	ListCh4(ctx context.Context, in *Ch4Request, opts ...grpc.CallOption) (*Ch4Response, error)
+	// ExportCh4 streams every matching monthly CH4 measurement, unless a limit is requested.
+	ExportCh4(ctx context.Context, in *Ch4Request, opts ...grpc.CallOption) (PlanetPulse_ExportCh4Client, error)
+}
+
+type planetPulseClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPlanetPulseClient(cc grpc.ClientConnInterface) PlanetPulseClient {
+	return &planetPulseClient{cc}
+}
+
+func (c *planetPulseClient) ListCo2(ctx context.Context, in *Co2Request, opts ...grpc.CallOption) (*Co2Response, error) {
+	out := new(Co2Response)
+	err := c.cc.Invoke(ctx, "/planetpulse.v1.PlanetPulse/ListCo2", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planetPulseClient) ExportCo2(ctx context.Context, in *Co2Request, opts ...grpc.CallOption) (PlanetPulse_ExportCo2Client, error) {
+	stream, err := c.cc.NewStream(ctx, &PlanetPulse_ServiceDesc.Streams[0], "/planetpulse.v1.PlanetPulse/ExportCo2", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &planetPulseExportCo2Client{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PlanetPulse_ExportCo2Client interface {
+	Recv() (*Co2Measurement, error)
+	grpc.ClientStream
+}
+
+type planetPulseExportCo2Client struct {
+	grpc.ClientStream
+}
+
+func (x *planetPulseExportCo2Client) Recv() (*Co2Measurement, error) {
+	m := new(Co2Measurement)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *planetPulseClient) ListCh4(ctx context.Context, in *Ch4Request, opts ...grpc.CallOption) (*Ch4Response, error) {
+	out := new(Ch4Response)
+	err := c.cc.Invoke(ctx, "/planetpulse.v1.PlanetPulse/ListCh4", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planetPulseClient) ExportCh4(ctx context.Context, in *Ch4Request, opts ...grpc.CallOption) (PlanetPulse_ExportCh4Client, error) {
+	stream, err := c.cc.NewStream(ctx, &PlanetPulse_ServiceDesc.Streams[1], "/planetpulse.v1.PlanetPulse/ExportCh4", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &planetPulseExportCh4Client{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PlanetPulse_ExportCh4Client interface {
+	Recv() (*Ch4Measurement, error)
+	grpc.ClientStream
+}
+
+type planetPulseExportCh4Client struct {
+	grpc.ClientStream
+}
+
+func (x *planetPulseExportCh4Client) Recv() (*Ch4Measurement, error) {
+	m := new(Ch4Measurement)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PlanetPulseServer is the server API for PlanetPulse service.
+// All implementations must embed UnimplementedPlanetPulseServer
+// for forward compatibility
+type PlanetPulseServer interface {
+	// ListCo2 returns a page of weekly CO2 measurements taken at Mauna Loa Observatory.
+	ListCo2(context.Context, *Co2Request) (*Co2Response, error)
+	// ExportCo2 streams every matching weekly CO2 measurement, unless a limit is requested.
+	ExportCo2(*Co2Request, PlanetPulse_ExportCo2Server) error
+	// ListCh4 returns a page of monthly global CH4 measurements.
+	ListCh4(context.Context, *Ch4Request) (*Ch4Response, error)
+	// ExportCh4 streams every matching monthly CH4 measurement, unless a limit is requested.
+	ExportCh4(*Ch4Request, PlanetPulse_ExportCh4Server) error
+	mustEmbedUnimplementedPlanetPulseServer()
+}
+
+// UnimplementedPlanetPulseServer must be embedded to have forward compatible implementations.
+type UnimplementedPlanetPulseServer struct {
+}
+
+func (UnimplementedPlanetPulseServer) ListCo2(context.Context, *Co2Request) (*Co2Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCo2 not implemented")
+}
+func (UnimplementedPlanetPulseServer) ExportCo2(*Co2Request, PlanetPulse_ExportCo2Server) error {
+	return status.Errorf(codes.Unimplemented, "method ExportCo2 not implemented")
+}
+func (UnimplementedPlanetPulseServer) ListCh4(context.Context, *Ch4Request) (*Ch4Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCh4 not implemented")
+}
+func (UnimplementedPlanetPulseServer) ExportCh4(*Ch4Request, PlanetPulse_ExportCh4Server) error {
+	return status.Errorf(codes.Unimplemented, "method ExportCh4 not implemented")
+}
+func (UnimplementedPlanetPulseServer) mustEmbedUnimplementedPlanetPulseServer() {}
+
+// UnsafePlanetPulseServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PlanetPulseServer will
+// result in compilation errors.
+type UnsafePlanetPulseServer interface {
+	mustEmbedUnimplementedPlanetPulseServer()
+}
+
+func RegisterPlanetPulseServer(s grpc.ServiceRegistrar, srv PlanetPulseServer) {
+	s.RegisterService(&PlanetPulse_ServiceDesc, srv)
+}
+
+func _PlanetPulse_ListCo2_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Co2Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanetPulseServer).ListCo2(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/planetpulse.v1.PlanetPulse/ListCo2",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanetPulseServer).ListCo2(ctx, req.(*Co2Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanetPulse_ExportCo2_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Co2Request)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PlanetPulseServer).ExportCo2(m, &planetPulseExportCo2Server{stream})
+}
+
+type PlanetPulse_ExportCo2Server interface {
+	Send(*Co2Measurement) error
+	grpc.ServerStream
+}
+
+type planetPulseExportCo2Server struct {
+	grpc.ServerStream
+}
+
+func (x *planetPulseExportCo2Server) Send(m *Co2Measurement) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _PlanetPulse_ListCh4_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Ch4Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanetPulseServer).ListCh4(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/planetpulse.v1.PlanetPulse/ListCh4",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanetPulseServer).ListCh4(ctx, req.(*Ch4Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanetPulse_ExportCh4_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Ch4Request)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PlanetPulseServer).ExportCh4(m, &planetPulseExportCh4Server{stream})
+}
+
+type PlanetPulse_ExportCh4Server interface {
+	Send(*Ch4Measurement) error
+	grpc.ServerStream
+}
+
+type planetPulseExportCh4Server struct {
+	grpc.ServerStream
+}
+
+func (x *planetPulseExportCh4Server) Send(m *Ch4Measurement) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// PlanetPulse_ServiceDesc is the grpc.ServiceDesc for PlanetPulse service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PlanetPulse_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "planetpulse.v1.PlanetPulse",
+	HandlerType: (*PlanetPulseServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListCo2",
+			Handler:    _PlanetPulse_ListCo2_Handler,
+		},
+		{
+			MethodName: "ListCh4",
+			Handler:    _PlanetPulse_ListCh4_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportCo2",
+			Handler:       _PlanetPulse_ExportCo2_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportCh4",
+			Handler:       _PlanetPulse_ExportCh4_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "planetpulse/v1/planetpulse.proto",
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package rpc
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/rpc/pb"
+	"context"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+var co2Columns = []string{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd"}
+
+var ch4Columns = []string{"year", "month", "day", "date_decimal", "average", "average_unc", "trend", "trend_unc", "yyyymmdd"}
+
+// mockCo2Rows returns n consecutive weekly rows starting on 2020-05-03. The one year ago value of the first row is missing.
+func mockCo2Rows(n int) *sqlmock.Rows {
+	rows := sqlmock.NewRows(co2Columns)
+	for i := 0; i < n; i++ {
+		date := time.Date(2020, 5, 3, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 7*i)
+		oneYearAgo := float32(414.1 + 0.1*float32(i))
+		if i == 0 {
+			oneYearAgo = -999.99
+		}
+		rows.AddRow(date.Year(), int(date.Month()), date.Day(), float32(2020.3), float32(417.1+0.1*float32(i)), 7, oneYearAgo, float32(391.2), float32(137.3), date)
+	}
+	return rows
+}
+
+// newTestClient serves the PlanetPulse service on an in-process listener backed by a mock database.
+func newTestClient(t *testing.T) (pb.PlanetPulseClient, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error generating mock database: %s", err.Error())
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(&database.Database{DB: db})
+	go server.Serve(listener)
+
+	conn, err := grpc.DialContext(context.Background(), "bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatalf("error dialing test server: %s", err.Error())
+	}
+
+	return pb.NewPlanetPulseClient(conn), mock, func() {
+		conn.Close()
+		server.Stop()
+		db.Close()
+	}
+}
+
+func TestListCo2(t *testing.T) {
+	client, mock, done := newTestClient(t)
+	defer done()
+
+	sqlString := `SELECT * FROM public.co2_weekly_mlo WHERE average >= 417.00 AND year in ('2020') ORDER BY year,month,day LIMIT 2 OFFSET 2`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlString)).WillReturnRows(mockCo2Rows(2))
+
+	gte := 417.0
+	req := &pb.Co2Request{
+		Filters: &pb.Filters{Year: []string{"2020"}, Gte: &gte},
+		Page:    &pb.Page{Limit: 2, Page: 2},
+	}
+
+	var header metadata.MD
+	resp, err := client.ListCo2(context.Background(), req, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("Unexpected error from ListCo2: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("there were unfulfilled expectations: %s", err)
+	}
+
+	if len(resp.Results) != 2 {
+		t.Fatalf("Wanted 2 results, Got: %v", resp.Results)
+	}
+	first := resp.Results[0]
+	if first.Year != 2020 || first.Month != 5 || first.Day != 3 || first.GetAverage() != float32(417.1) || !first.Date.AsTime().Equal(time.Date(2020, 5, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected first measurement: %v", first)
+	}
+	if first.OneYearAgo != nil || resp.Results[1].OneYearAgo == nil {
+		t.Errorf("Wanted missing measurements to be unset and others set, Got: %v, %v", first.OneYearAgo, resp.Results[1].OneYearAgo)
+	}
+	if resp.NextPage != 3 {
+		t.Errorf("Wanted the next page to be 3, Got: %v", resp.NextPage)
+	}
+	if ids := header.Get(RequestIdHeader); len(ids) != 1 || ids[0] != resp.RequestId || resp.RequestId == "" {
+		t.Errorf("Wanted the request ID '%v' in the response header, Got: %v", resp.RequestId, ids)
+	}
+}
+
+func TestListCo2Simple(t *testing.T) {
+	client, mock, done := newTestClient(t)
+	defer done()
+
+	sqlString := `SELECT year, month, day, average, increase_since_1800 FROM public.co2_weekly_mlo WHERE increase_since_1800 < 140.00 ORDER BY year,month,day LIMIT 10`
+	rows := sqlmock.NewRows([]string{"year", "month", "day", "average", "increase_since_1800"}).AddRow(2020, 5, 3, float32(417.1), float32(137.3))
+	mock.ExpectQuery(regexp.QuoteMeta(sqlString)).WillReturnRows(rows)
+
+	lt := 140.0
+	req := &pb.Co2Request{Value: pb.Co2Value_CO2_VALUE_INCREASE_SINCE_1800, Filters: &pb.Filters{Lt: &lt}, Simple: true}
+	resp, err := client.ListCo2(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error from ListCo2: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("there were unfulfilled expectations: %s", err)
+	}
+
+	want := &pb.Co2Measurement{Year: 2020, Month: 5, Day: 3, Average: proto.Float32(417.1), IncreaseSince_1800: proto.Float32(137.3)}
+	if len(resp.Results) != 1 || !proto.Equal(resp.Results[0], want) {
+		t.Errorf("Wanted only the simple fields %v, Got: %v", want, resp.Results)
+	}
+	if resp.NextPage != 0 {
+		t.Errorf("Wanted no next page, Got: %v", resp.NextPage)
+	}
+}
+
+func TestExportCo2(t *testing.T) {
+	client, mock, done := newTestClient(t)
+	defer done()
+
+	sqlString := `SELECT * FROM public.co2_weekly_mlo WHERE (average >= $1 AND month IN ($2, $3)) ORDER BY year,month,day`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlString)).WithArgs(410.0, 5, 6).WillReturnRows(mockCo2Rows(25))
+
+	stream, err := client.ExportCo2(context.Background(), &pb.Co2Request{Filters: &pb.Filters{Expression: "average >= 410 and month in (5,6)"}})
+	if err != nil {
+		t.Fatalf("Unexpected error from ExportCo2: %v", err)
+	}
+
+	var count int
+	for {
+		measurement, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error receiving export: %v", err)
+		}
+		if measurement.Date == nil || measurement.Average == nil {
+			t.Errorf("Unexpected measurement: %v", measurement)
+		}
+		count++
+	}
+	if count != 25 {
+		t.Errorf("Wanted every row to be streamed, Got %v.", count)
+	}
+	if header, _ := stream.Header(); len(header.Get(RequestIdHeader)) != 1 {
+		t.Errorf("Wanted a request ID in the stream header, Got: %v", header)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestListCh4(t *testing.T) {
+	client, mock, done := newTestClient(t)
+	defer done()
+
+	sqlString := `SELECT * FROM public.ch4_mm_gl WHERE trend > 1800.00 AND month not in ('1', '2') ORDER BY year,month LIMIT 10`
+	rows := sqlmock.NewRows(ch4Columns).
+		AddRow(2020, 3, 1, float32(2020.208), float32(1873.2), float32(-999.99), float32(1874.5), float32(0.7), time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC))
+	mock.ExpectQuery(regexp.QuoteMeta(sqlString)).WillReturnRows(rows)
+
+	gt := 1800.0
+	req := &pb.Ch4Request{Value: pb.Ch4Value_CH4_VALUE_TREND, Filters: &pb.Filters{Month: []string{"!1,2"}, Gt: &gt}}
+	resp, err := client.ListCh4(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error from ListCh4: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("there were unfulfilled expectations: %s", err)
+	}
+
+	if len(resp.Results) != 1 {
+		t.Fatalf("Wanted 1 result, Got: %v", resp.Results)
+	}
+	result := resp.Results[0]
+	if result.GetTrend() != float32(1874.5) || result.AverageUnc != nil || result.Year != 2020 || result.Month != 3 {
+		t.Errorf("Unexpected measurement: %v", result)
+	}
+}
+
+func TestExportCh4Limit(t *testing.T) {
+	client, mock, done := newTestClient(t)
+	defer done()
+
+	sqlString := `SELECT year, month, average, trend FROM public.ch4_mm_gl ORDER BY year,month LIMIT 1`
+	rows := sqlmock.NewRows([]string{"year", "month", "average", "trend"}).AddRow(1983, 7, float32(1625.4), float32(1634.5))
+	mock.ExpectQuery(regexp.QuoteMeta(sqlString)).WillReturnRows(rows)
+
+	stream, err := client.ExportCh4(context.Background(), &pb.Ch4Request{Page: &pb.Page{Limit: 1}, Simple: true})
+	if err != nil {
+		t.Fatalf("Unexpected error from ExportCh4: %v", err)
+	}
+	measurement, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Unexpected error receiving export: %v", err)
+	}
+	want := &pb.Ch4Measurement{Year: 1983, Month: 7, Average: proto.Float32(1625.4), Trend: proto.Float32(1634.5)}
+	if !proto.Equal(measurement, want) {
+		t.Errorf("Wanted %v, Got: %v", want, measurement)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Wanted the export to end after the limit, Got: %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	client, mock, done := newTestClient(t)
+	defer done()
+
+	testVals := []struct {
+		req     *pb.Co2Request
+		code    codes.Code
+		message string
+	}{
+		{&pb.Co2Request{Filters: &pb.Filters{Month: []string{"13"}}}, codes.InvalidArgument, "month=[13]"},
+		{&pb.Co2Request{Page: &pb.Page{Limit: -5}}, codes.InvalidArgument, "integer value cannot be less than 0: limit=[-5]"},
+		{&pb.Co2Request{Filters: &pb.Filters{Expression: "average >"}}, codes.InvalidArgument, "filter=[average >]"},
+		{&pb.Co2Request{}, codes.Internal, "internal database error"},
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM public.co2_weekly_mlo`)).WillReturnError(io.ErrUnexpectedEOF)
+
+	for _, testVal := range testVals {
+		_, err := client.ListCo2(context.Background(), testVal.req)
+		if status.Code(err) != testVal.code || !strings.Contains(status.Convert(err).Message(), testVal.message) {
+			t.Errorf("Wanted a %v error containing '%v' for %v, Got: %v", testVal.code, testVal.message, testVal.req, err)
+		}
+	}
+}
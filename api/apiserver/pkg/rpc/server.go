@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package rpc
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/rpc/pb"
+	utils "apiserver/pkg/utils"
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIdHeader is the metadata key the request ID of a call is returned in.
+const RequestIdHeader = "x-request-id"
+
+// Service implements the PlanetPulse gRPC service.
+type Service struct {
+	pb.UnimplementedPlanetPulseServer
+
+	Database *database.Database
+}
+
+// NewServer returns a gRPC server with the PlanetPulse service registered. Every call is given a request ID
+// and logged like requests to the REST API.
+func NewServer(db *database.Database) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(unaryInterceptor),
+		grpc.StreamInterceptor(streamInterceptor),
+	)
+	pb.RegisterPlanetPulseServer(server, &Service{Database: db})
+	return server
+}
+
+// unaryInterceptor attaches a request ID to a unary call and logs the call once it completes.
+func unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	ctx, id := utils.WithReqId(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIdHeader, id))
+
+	resp, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, start, id, err)
+	return resp, err
+}
+
+// requestStream overrides the context of a server stream with one holding a request ID.
+type requestStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *requestStream) Context() context.Context {
+	return stream.ctx
+}
+
+// streamInterceptor attaches a request ID to a streaming call and logs the call once it completes.
+func streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx, id := utils.WithReqId(stream.Context())
+	stream.SetHeader(metadata.Pairs(RequestIdHeader, id))
+
+	err := handler(srv, &requestStream{stream, ctx})
+	logCall(ctx, info.FullMethod, start, id, err)
+	return err
+}
+
+// logCall logs a completed call in the format used for HTTP requests.
+func logCall(ctx context.Context, method string, start time.Time, id string, err error) {
+	ip := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		ip = p.Addr.String()
+	}
+
+	log.Infof(
+		"GRPC %s %s %s - RequestID: %s, Client IP: %s",
+		method,
+		status.Code(err),
+		time.Since(start),
+		id,
+		ip,
+	)
+}
+
+// statusError logs a ServerError and converts it to the gRPC status closest to its HTTP status code.
+func statusError(err *utils.ServerError) error {
+	utils.ErrorLog(err)
+
+	code := codes.Internal
+	switch err.HttpCode {
+	case 400:
+		code = codes.InvalidArgument
+	case 404:
+		code = codes.NotFound
+	}
+	return status.Error(code, err.Message)
+}
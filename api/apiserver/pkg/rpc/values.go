@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package rpc
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/rpc/pb"
+	"database/sql"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// requestValues translates the filters and pagination of a request into the query parameters of the
+// equivalent REST request, so that they are validated by the same code.
+func requestValues(filters *pb.Filters, page *pb.Page, simple bool) url.Values {
+	values := url.Values{}
+
+	// Repeated fields are expanded like comma-separated query parameters
+	expand := func(key string, params []string) {
+		for _, param := range params {
+			values[key] = append(values[key], strings.Split(param, ",")...)
+		}
+	}
+	bound := func(key string, val *float64) {
+		if val != nil {
+			values.Set(key, strconv.FormatFloat(*val, 'f', -1, 64))
+		}
+	}
+
+	if filters != nil {
+		expand("year", filters.Year)
+		expand("month", filters.Month)
+		bound("gt", filters.Gt)
+		bound("lt", filters.Lt)
+		bound("gte", filters.Gte)
+		bound("lte", filters.Lte)
+		if filters.Expression != "" {
+			values.Set("filter", filters.Expression)
+		}
+	}
+
+	// Unset pagination fields are left to the defaults of the query
+	if page != nil {
+		if page.Limit != 0 {
+			values.Set("limit", strconv.Itoa(int(page.Limit)))
+		}
+		if page.Offset != 0 {
+			values.Set("offset", strconv.Itoa(int(page.Offset)))
+		}
+		if page.Page != 0 {
+			values.Set("page", strconv.Itoa(int(page.Page)))
+		}
+	}
+
+	if simple {
+		values.Set("simple", "true")
+	}
+	return values
+}
+
+// nextPage returns the number of the page following a query, numbered from 1, or 0 when the query
+// returned its last page.
+func nextPage(query database.DBQuery, count int) int32 {
+	if query.Limit > 0 && count >= query.Limit {
+		return int32(query.Page + 2)
+	}
+	return 0
+}
+
+// columns returns the columns loaded by a query of a dataset.
+func columns(dataset models.Dataset, query database.DBQuery) []models.Column {
+	if len(query.Fields) != 0 {
+		return query.Fields
+	}
+	return dataset.Columns
+}
+
+// rowSender is a models.DataObject that sends each row to the client as soon as it has been loaded,
+// so that exports never need to be held in memory.
+type rowSender struct {
+	// dataObject loads a single row into table
+	dataObject models.DataObject
+	table      *[]interface{}
+
+	send func(entry interface{}) error
+}
+
+// Load loads a row with the wrapped DataObject and sends it.
+func (rs *rowSender) Load(rows *sql.Rows, simple bool) error {
+	if err := rs.dataObject.Load(rows, simple); err != nil {
+		return err
+	}
+	for _, entry := range *rs.table {
+		if err := rs.send(entry); err != nil {
+			return err
+		}
+	}
+	*rs.table = (*rs.table)[:0]
+	return nil
+}
+
+func intValue(val interface{}) int32 {
+	v, _ := val.(int)
+	return int32(v)
+}
+
+func floatValue(val interface{}) float32 {
+	v, _ := val.(float32)
+	return v
+}
+
+// measurementValue returns a measurement, or nil when NOAA could not make it.
+func measurementValue(dataset models.Dataset, val interface{}) *float32 {
+	v, ok := val.(float32)
+	if !ok || dataset.IsMissing(v) {
+		return nil
+	}
+	return &v
+}
+
+func dateValue(val interface{}) *timestamppb.Timestamp {
+	v, ok := val.(time.Time)
+	if !ok {
+		return nil
+	}
+	return timestamppb.New(v)
+}
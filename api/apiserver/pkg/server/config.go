@@ -27,6 +27,7 @@ import (
 	"apiserver/pkg/database"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	log "github.com/sirupsen/logrus"
@@ -50,7 +51,22 @@ func (apiserver *ApiServer) configure() error {
 	apiserver.Config = &ApiConfig{
 		HttpPort:  yamlConfig.HttpPort,
 		HttpsPort: yamlConfig.HttpsPort,
+		GrpcPort:  yamlConfig.GrpcPort,
 		LogLevel:  yamlConfig.LogLevel,
+
+		StreamNotifier:     yamlConfig.StreamNotifier,
+		StreamPollInterval: yamlConfig.StreamPollInterval,
+
+		CatalogCacheTTL: yamlConfig.CatalogCacheTTL,
+
+		UnitsTemperature: yamlConfig.UnitsTemperature,
+		UnitsPressure:    yamlConfig.UnitsPressure,
+
+		ValidateRequests:  yamlConfig.ValidateRequests,
+		ValidateResponses: yamlConfig.ValidateResponses,
+
+		V1Deprecation: parseDate(yamlConfig.V1Deprecation),
+		V1Sunset:      parseDate(yamlConfig.V1Sunset),
 	}
 
 	// Configure the database
@@ -74,8 +90,16 @@ func yamlConfig() (*YamlConfig, error) {
 	// Defaults
 	viper.SetDefault("HttpPort", "8080")
 	viper.SetDefault("HttpsPort", "8443")
+	viper.SetDefault("GrpcPort", "9090")
 	viper.SetDefault("LogLevel", "4")
 	viper.SetDefault("DBConnTimeout", "5")
+	viper.SetDefault("StreamNotifier", "poll")
+	viper.SetDefault("StreamPollInterval", "60")
+	viper.SetDefault("CatalogCacheTTL", "3600")
+	viper.SetDefault("UnitsTemperature", "273.15")
+	viper.SetDefault("UnitsPressure", "101325")
+	viper.SetDefault("ValidateRequests", "false")
+	viper.SetDefault("ValidateResponses", "off")
 
 	err := viper.ReadInConfig()
 	if err != nil {
@@ -112,6 +136,12 @@ func envConfig() (*EnvConfig, error) {
 	return &envConfig, err
 }
 
+// parseDate parses a date of the config, already validated as yyyy-mm-dd. Dates left out are zero.
+func parseDate(date string) time.Time {
+	t, _ := time.Parse("2006-01-02", date)
+	return t
+}
+
 func validateConfig(config interface{}) error {
 	validate := validator.New()
 	err := validate.Struct(config)
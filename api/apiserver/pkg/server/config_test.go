@@ -27,12 +27,13 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
 var configData = []byte(
-	"HttpPort: 8080\nLogLevel: 5\nDBConnTimeout: 0",
+	"HttpPort: 8080\nLogLevel: 5\nDBConnTimeout: 0\nV1Sunset: 2027-05-01",
 )
 
 func TestConfig(t *testing.T) {
@@ -94,4 +95,11 @@ func TestConfig(t *testing.T) {
 		t.Error("Configuration failed during testing.")
 		return
 	}
+
+	if !apiserver.Config.V1Deprecation.IsZero() || !apiserver.Config.V1Sunset.Equal(time.Date(2027, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected /v1 to be retired on 2027-05-01 without a deprecation date, got %v and %v.", apiserver.Config.V1Sunset, apiserver.Config.V1Deprecation)
+	}
+	if apiserver.Config.UnitsTemperature != 273.15 || apiserver.Config.UnitsPressure != 101325 {
+		t.Errorf("Expected mass concentrations at 273.15 K and 101325 Pa by default, got %v and %v.", apiserver.Config.UnitsTemperature, apiserver.Config.UnitsPressure)
+	}
 }
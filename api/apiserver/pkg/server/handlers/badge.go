@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package handlers
+
+import (
+	"apiserver/pkg/chart"
+	"apiserver/pkg/database/models"
+	utils "apiserver/pkg/utils"
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+const (
+	// badgeColor is the message color of a badge showing an up to date dataset
+	badgeColor = "#007ec6"
+
+	// badgeStaleColor is the message color of a badge showing a dataset that has missed an expected observation
+	badgeStaleColor = "#9f9f9f"
+
+	// badgeMinAge is the shortest time a badge may be cached for, so that embedding pages cannot flood the database
+	badgeMinAge = time.Hour
+)
+
+// LatestBadge describes the latest value of a dataset and its change from the value a year earlier.
+// The value a year earlier is NaN when it is missing, in which case the change is left out.
+func LatestBadge(label string, units string, latest float64, yearAgo float64, freshness models.Freshness) chart.Badge {
+	badge := chart.Badge{Label: label, Color: badgeColor}
+	if freshness.Stale {
+		badge.Color = badgeStaleColor
+	}
+
+	switch {
+	case math.IsNaN(latest):
+		badge.Message = "no data"
+		badge.Color = badgeStaleColor
+	case math.IsNaN(yearAgo):
+		badge.Message = fmt.Sprintf("%.2f %v", latest, units)
+	default:
+		badge.Message = fmt.Sprintf("%.2f %v (%+.2f y/y)", latest, units, latest-yearAgo)
+	}
+	return badge
+}
+
+// BadgeMaxAge is the time a badge may be cached for. Badges are cached until the dataset's next observation is
+// expected, but no longer than a seventh of its cadence so that late publications show up promptly. Badges of
+// stale datasets are cached for the shortest time, as the missing observations may be published at any moment.
+func BadgeMaxAge(freshness models.Freshness, now time.Time) time.Duration {
+	if freshness.Stale {
+		return badgeMinAge
+	}
+
+	period := freshness.Cadence.Next(freshness.Observed).Sub(freshness.Observed)
+	maxAge := freshness.Expected.Sub(now)
+	if maxAge > period/7 {
+		maxAge = period / 7
+	}
+	if maxAge < badgeMinAge {
+		maxAge = badgeMinAge
+	}
+	return maxAge.Truncate(time.Second)
+}
+
+// WriteBadge renders a badge as SVG, with cache headers tuned to the cadence of the dataset it shows.
+func WriteBadge(w http.ResponseWriter, r *http.Request, badge chart.Badge, freshness models.Freshness, now time.Time) *utils.ServerError {
+	// Parse RequestID param
+	id, idError := utils.GetReqId(r)
+	if idError != nil {
+		return utils.NewError(idError, "cannot extract request ID", 500, false)
+	}
+
+	var buf bytes.Buffer
+	if err := chart.RenderBadge(&buf, badge); err != nil {
+		return utils.NewError(err, "error rendering badge", 500, false)
+	}
+
+	modified := freshness.Observed
+	if freshness.Ingested != nil {
+		modified = *freshness.Ingested
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("X-Request-Id", id)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(BadgeMaxAge(freshness, now).Seconds())))
+	w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	if _, err := buf.WriteTo(w); err != nil {
+		return utils.NewError(err, "error writing badge", 500, false)
+	}
+	return nil
+}
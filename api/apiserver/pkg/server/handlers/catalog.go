@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package handlers
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/notify"
+	"context"
+	"sync"
+	"time"
+)
+
+// Catalog caches the Coverage of the datasets, which is computed from every row of their tables. Entries expire
+// after TTL, and are dropped as soon as the dataset changes while the catalog runs (see Run). A nil Catalog
+// computes the coverage of every request.
+type Catalog struct {
+	// TTL is how long the coverage of a dataset is cached for
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]catalogEntry
+
+	// generations counts the invalidations of each dataset, and generation those of every dataset, so that a
+	// coverage computed before an invalidation is not cached after it
+	generations map[string]uint64
+	generation  uint64
+
+	// now returns the current time. It is replaced in tests.
+	now func() time.Time
+}
+
+// catalogEntry is the cached coverage of a dataset.
+type catalogEntry struct {
+	coverage models.Coverage
+	expires  time.Time
+}
+
+// NewCatalog returns a Catalog caching the coverage of each dataset for ttl.
+func NewCatalog(ttl time.Duration) *Catalog {
+	return &Catalog{TTL: ttl, entries: map[string]catalogEntry{}, generations: map[string]uint64{}, now: time.Now}
+}
+
+// Coverage returns the coverage of a dataset, from the cache while it is fresh.
+func (catalog *Catalog) Coverage(db *database.Database, dataset models.Dataset) (models.Coverage, error) {
+	if catalog == nil {
+		return db.Coverage(dataset)
+	}
+
+	catalog.mu.Lock()
+	entry, ok := catalog.entries[dataset.Id]
+	generation, generations := catalog.generation, catalog.generations[dataset.Id]
+	catalog.mu.Unlock()
+	if ok && catalog.now().Before(entry.expires) {
+		return entry.coverage, nil
+	}
+
+	// The coverage is computed without holding the lock, so that a slow query does not delay the other datasets.
+	// Concurrent requests may compute it more than once.
+	coverage, err := db.Coverage(dataset)
+	if err != nil {
+		return models.Coverage{}, err
+	}
+
+	expires := catalog.now().Add(catalog.TTL)
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	// The dataset may have changed while its coverage was computed, which is then only good for this request
+	if catalog.generation == generation && catalog.generations[dataset.Id] == generations {
+		catalog.entries[dataset.Id] = catalogEntry{coverage: coverage, expires: expires}
+	}
+	return coverage, nil
+}
+
+// Invalidate drops the cached coverage of a dataset, or of every dataset when id is empty.
+func (catalog *Catalog) Invalidate(id string) {
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	if id == "" {
+		catalog.entries = map[string]catalogEntry{}
+		catalog.generation++
+		return
+	}
+	delete(catalog.entries, id)
+	catalog.generations[id]++
+}
+
+// Run drops the cached coverage of each dataset the broker publishes a change to, until ctx is done.
+func (catalog *Catalog) Run(ctx context.Context, broker *notify.Broker) error {
+	for {
+		sub := broker.Subscribe(nil, 0, false)
+
+	receive:
+		for {
+			select {
+			case event, ok := <-sub.Events:
+				if !ok {
+					break receive
+				}
+				catalog.Invalidate(event.Dataset)
+			case <-ctx.Done():
+				broker.Unsubscribe(sub)
+				return ctx.Err()
+			}
+		}
+
+		// Changes may have been missed while the catalog fell behind the broker
+		broker.Unsubscribe(sub)
+		catalog.Invalidate("")
+	}
+}
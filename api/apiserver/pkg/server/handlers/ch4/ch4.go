@@ -29,61 +29,58 @@ import (
 	"apiserver/pkg/server/handlers"
 	"apiserver/pkg/utils"
 	"context"
-	"encoding/json"
 	"net/http"
+	"strings"
 )
 
-// Get is an ApiHandlerFunc type. It queries the database for requested ch4weekly data and returns a JSON representation of the data
-// to the client.
+// Get is an ApiHandlerFunc type. It queries the database for requested ch4weekly data and returns the data to
+// the client in the format it negotiated (see handlers.NegotiateFormat).
 func Get(ctx context.Context, handlerConfig *handlers.ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	format, formatErr := handlers.NegotiateFormat(r)
+	if formatErr != nil {
+		return formatErr
+	}
 
-	query := database.NewQuery("public.ch4_mm_gl", []string{"*"}, "year,month")
+	dataset := models.Ch4MmGl
+	query := database.NewQuery(dataset.Table, []string{"*"}, strings.Join(dataset.Order, ","))
 
-	filters, internalArgs, err := ParseParams(r, handlerConfig.PathParam, handlerConfig.SortBy)
+	conversion, filterUnits, err := handlers.ParseUnits(r, handlerConfig, dataset)
 	if err != nil {
 		return err
 	}
 
-	if len(internalArgs) != 0 {
-		ParseInternalArgs(internalArgs, &query)
+	filters, internalArgs, err := ParseParams(r, handlerConfig.PathParam, handlerConfig.SortBy, filterUnits)
+	if err != nil {
+		return err
 	}
 
 	query.Where = filters
 
-	ch4Table := models.Ch4Table{}
-	dberr := handlerConfig.Database.Query(query, &ch4Table)
-	if dberr != nil {
-		return utils.NewError(dberr, "internal database error", 500, false)
+	if len(internalArgs) != 0 {
+		ParseInternalArgs(internalArgs, &query)
 	}
 
-	// This prevents the 'Results' part of the response from being omitted if
-	// there are no results.
-	if len(ch4Table) == 0 {
-		ch4Table = models.Ch4Table{
-			nil,
-		}
+	if err := handlers.ValidateLimit(format, query); err != nil {
+		return err
 	}
 
-	// Parse RequestID param
-	id, idError := utils.GetReqId(r)
-	if idError != nil {
-		return utils.NewError(idError, "cannot extract request ID", 500, false)
+	ch4Table := models.Ch4Table{}
+	var dataObject models.DataObject = &ch4Table
+	if len(query.Fields) != 0 {
+		dataObject = &models.FieldsTable{Columns: query.Fields, Entries: (*[]interface{})(&ch4Table)}
 	}
-
-	resp := models.ServerResp{
-		Results:   ch4Table,
-		Status:    "OK",
-		RequestId: id,
-		Error:     nil,
+	if conversion.Unit != dataset.Unit {
+		dataObject = &models.ConvertedTable{DataObject: dataObject, Entries: (*[]interface{})(&ch4Table), Conversion: conversion}
 	}
 
-	enc := json.NewEncoder(w)
-	if query.Pretty {
-		enc.SetIndent("", "    ")
+	if format == handlers.NDJSON {
+		return handlers.StreamResults(w, r, handlerConfig.Database, query, dataObject, (*[]interface{})(&ch4Table))
 	}
-	if err := enc.Encode(resp); err != nil {
-		return utils.NewError(err, "error encoding data as json", 500, false)
+
+	dberr := handlerConfig.Database.Query(query, dataObject)
+	if dberr != nil {
+		return utils.NewError(dberr, "internal database error", 500, false)
 	}
-	return nil
+
+	return handlers.WriteResults(w, r, handlerConfig, format, conversion.Dataset, query, ch4Table)
 }
@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package ch4
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCh4GetBadge(t *testing.T) {
+	observed := time.Date(1984, 6, 1, 0, 0, 0, 0, time.UTC)
+	ingested := time.Date(1984, 10, 5, 6, 0, 0, 0, time.UTC)
+
+	w := runRequest(t, "/v1/badge/ch4.svg", withHandler(GetBadge), withMock(func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(yyyymmdd) FROM public.ch4_mm_gl`)).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(observed))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(ingested_at) FROM public.ingest_log WHERE dataset = $1`)).
+			WithArgs("ch4_mm_gl").
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(ingested))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT yyyymmdd, average FROM public.ch4_mm_gl WHERE year = 1984 AND month = 6 ORDER BY year,month LIMIT 1`)).
+			WillReturnRows(sqlmock.NewRows([]string{"yyyymmdd", "average"}).AddRow(observed, 1641.39))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT yyyymmdd, average FROM public.ch4_mm_gl WHERE year = 1983 AND month = 6 ORDER BY year,month LIMIT 1`)).
+			WillReturnRows(sqlmock.NewRows([]string{"yyyymmdd", "average"}).AddRow(observed.AddDate(-1, 0, 0), 1631.8))
+	}))
+
+	resp := w.Result()
+	if contentType := resp.Header.Get("Content-Type"); contentType != "image/svg+xml" {
+		t.Errorf("Wanted an image/svg+xml Content-Type, Got: '%v'.", contentType)
+	}
+	if modified := resp.Header.Get("Last-Modified"); modified != "Fri, 05 Oct 1984 06:00:00 GMT" {
+		t.Errorf("Wanted the ingestion time as Last-Modified, Got: '%v'.", modified)
+	}
+	if cacheControl := resp.Header.Get("Cache-Control"); cacheControl != "public, max-age=3600" {
+		t.Errorf("Wanted a stale badge to be cached for an hour, Got: '%v'.", cacheControl)
+	}
+
+	body := w.Body.String()
+	if !strings.Contains(body, ">CH₄ global</text>") || !strings.Contains(body, ">1641.39 ppb (+9.59 y/y)</text>") {
+		t.Errorf("Wanted a badge showing the latest CH4 average and its change, Got: %v", body)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package ch4
+
+import (
+	"apiserver/pkg/server/handlers"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCh4GetChart(t *testing.T) {
+	testVals := []struct {
+		query   string
+		sortBy  string
+		sql     string
+		columns []string
+		title   string
+		legend  string
+	}{
+		{
+			"/v1/ch4/monthly/chart.svg?year=1983-1984",
+			"average",
+			`SELECT yyyymmdd, average FROM public.ch4_mm_gl WHERE year between '1983' and '1984' ORDER BY year,month`,
+			[]string{"yyyymmdd", "average"},
+			"Monthly global CH4 average",
+			"",
+		},
+		{
+			"/v1/ch4/monthly/chart.svg?trend=true",
+			"average",
+			`SELECT yyyymmdd, average, trend FROM public.ch4_mm_gl ORDER BY year,month`,
+			[]string{"yyyymmdd", "average", "trend"},
+			"Monthly global CH4 average",
+			">Trend</text>",
+		},
+		{
+			"/v1/ch4/monthly/trend/chart.svg?trend=true&limit=50",
+			"trend",
+			`SELECT yyyymmdd, trend FROM public.ch4_mm_gl ORDER BY year,month LIMIT 50`,
+			[]string{"yyyymmdd", "trend"},
+			"Monthly global CH4 trend",
+			">12 month average</text>",
+		},
+	}
+
+	for _, testVal := range testVals {
+		sortBy := testVal.sortBy
+		w := runRequest(t, testVal.query, withHandler(GetChart), expectQuery(testVal.sql, mockRows(testVal.columns, GetMockCh4Rows())),
+			withConfig(func(config *handlers.ApiHandlerConfig) { config.SortBy = sortBy }))
+
+		if contentType := w.Result().Header.Get("Content-Type"); contentType != "image/svg+xml" {
+			t.Errorf("Wanted an image/svg+xml Content-Type, Got: '%v'.", contentType)
+		}
+
+		body := w.Body.String()
+		polylines := 0
+		dec := xml.NewDecoder(strings.NewReader(body))
+		for {
+			token, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Chart for '%v' is not well-formed SVG: %v", testVal.query, err)
+			}
+			if elem, ok := token.(xml.StartElement); ok && elem.Name.Local == "polyline" {
+				polylines++
+			}
+		}
+		if polylines == 0 {
+			t.Errorf("Expected the series to be drawn for '%v', Got: %v", testVal.query, body)
+		}
+		if !strings.Contains(body, testVal.title) || !strings.Contains(body, ">ppb</text>") {
+			t.Errorf("Expected the title '%v' and ppb units for '%v', Got: %v", testVal.title, testVal.query, body)
+		}
+		if testVal.legend != "" && !strings.Contains(body, testVal.legend) {
+			t.Errorf("Expected the legend '%v' for '%v', Got: %v", testVal.legend, testVal.query, body)
+		}
+	}
+}
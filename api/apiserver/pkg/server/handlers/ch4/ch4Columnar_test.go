@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package ch4
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+)
+
+func TestCh4GetArrowFields(t *testing.T) {
+	data := GetMockCh4Rows()[:2]
+	data[1].Trend = -999.99
+	rows := mockRows([]string{"trend", "year"}, data)
+	w := runRequest(t, "/v1/ch4/monthly?fields=trend,year&format=arrow",
+		expectQuery(`SELECT trend, year FROM public.ch4_mm_gl ORDER BY year,month LIMIT 10`, rows))
+
+	rdr, err := ipc.NewReader(w.Result().Body)
+	if err != nil {
+		t.Fatalf("Response is not an Arrow IPC stream: %v", err)
+	}
+	defer rdr.Release()
+
+	schema := rdr.Schema()
+	if len(schema.Fields()) != 2 || schema.Field(0).Name != "Trend" || schema.Field(1).Name != "Year" {
+		t.Fatalf("Wanted the requested fields in order, Got: %v.", schema)
+	}
+	if !arrow.TypeEqual(schema.Field(0).Type, arrow.PrimitiveTypes.Float32) || !arrow.TypeEqual(schema.Field(1).Type, arrow.PrimitiveTypes.Int32) {
+		t.Errorf("Unexpected field types, Got: %v.", schema)
+	}
+	if i := schema.Metadata().FindKey("dataset"); i < 0 || schema.Metadata().Values()[i] != "ch4_mm_gl" {
+		t.Errorf("Wanted dataset 'ch4_mm_gl' in the schema metadata, Got: '%v'.", schema.Metadata())
+	}
+
+	if !rdr.Next() {
+		t.Fatal("Expected a record batch in the Arrow stream.")
+	}
+	trend := rdr.Record().Column(0).(*array.Float32)
+	if trend.IsNull(0) || trend.Value(0) != data[0].Trend || !trend.IsNull(1) {
+		t.Errorf("Wanted trend values [%v null], Got: %v.", data[0].Trend, trend)
+	}
+}
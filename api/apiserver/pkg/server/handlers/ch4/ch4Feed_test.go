@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package ch4
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCh4GetFeed(t *testing.T) {
+	// Entries link to the JSON resource of the version the feed is requested from
+	for _, version := range []string{"/v1", "/v2"} {
+		testCh4GetFeed(t, version)
+	}
+}
+
+func testCh4GetFeed(t *testing.T, version string) {
+	observed := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	data := []mockCh4Row{
+		{Year: 2024, Month: 2, Average: 1931.27, Timestamp: observed},
+		{Year: 2024, Month: 1, Average: 1930.84, Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Year: 2023, Month: 2, Average: 1921.04, Timestamp: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	w := runRequest(t, "http://localhost:8080"+version+"/ch4/feed.atom", withHandler(GetFeed), withMock(func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(yyyymmdd) FROM public.ch4_mm_gl`)).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(observed))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(ingested_at) FROM public.ingest_log WHERE dataset = $1`)).
+			WithArgs("ch4_mm_gl").
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM public.ch4_mm_gl ORDER BY year DESC,month DESC LIMIT 32`)).
+			WillReturnRows(mockRows(mockColumns, data))
+	}))
+
+	resp := w.Result()
+	if modified := resp.Header.Get("Last-Modified"); modified != "Thu, 01 Feb 2024 00:00:00 GMT" {
+		t.Errorf("Wanted the date of the latest observation as Last-Modified, Got: '%v'.", modified)
+	}
+
+	body := w.Body.String()
+	for _, s := range []string{
+		`<id>tag:planetpulse.io,2021:ch4_mm_gl/20240201</id>`,
+		`href="http://localhost:8080` + version + `/ch4/monthly"`,
+		`href="http://localhost:8080` + version + `/ch4/monthly?filter=yyyymmdd+%3D+%272024-02-01%27"`,
+		`1931.27 ppb for the period starting 2024-02-01, +10.23 ppb since last year.`,
+		`1930.84 ppb for the period starting 2024-01-01. No measurement was made a year earlier.`,
+	} {
+		if !strings.Contains(body, s) {
+			t.Errorf("Wanted the feed to contain '%v', Got: %v", s, body)
+		}
+	}
+}
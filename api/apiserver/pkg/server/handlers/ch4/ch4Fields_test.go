@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package ch4
+
+import (
+	"apiserver/pkg/database/models"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestCh4GetFields(t *testing.T) {
+	testVals := []struct {
+		query   string
+		sql     string
+		columns []string
+		body    string
+	}{
+		{
+			"/v1/ch4/monthly?fields=trend,year&pretty=false",
+			`SELECT trend, year FROM public.ch4_mm_gl ORDER BY year,month LIMIT 10`,
+			[]string{"trend", "year"},
+			`"Results":[{"Trend":1634.5,"Year":1983}]`,
+		},
+		{
+			"/v1/ch4/monthly?simple=true&pretty=false",
+			`SELECT year, month, average, trend FROM public.ch4_mm_gl ORDER BY year,month LIMIT 10`,
+			[]string{"year", "month", "average", "trend"},
+			`"Results":[{"Year":1983,"Month":7,"Average":1625.4,"Trend":1634.5}]`,
+		},
+	}
+
+	for _, testVal := range testVals {
+		w := runRequest(t, testVal.query, expectQuery(testVal.sql, mockRows(testVal.columns, GetMockCh4Rows()[:1])))
+
+		body, _ := ioutil.ReadAll(w.Result().Body)
+		if !strings.Contains(string(body), testVal.body) {
+			t.Errorf("Wanted response containing '%v', Got: '%v'.", testVal.body, string(body))
+		}
+	}
+}
+
+func TestCh4FieldsErrors(t *testing.T) {
+	var resp models.ServerResp
+	w := runRequest(t, "/v1/ch4/monthly?fields=year,ndays", failing(), decodeInto(&resp))
+	if w.Code != 400 || resp.Error == nil || !strings.Contains(resp.Error.Message, "trend_unc") {
+		t.Errorf("Expected a 400 error listing the allowed fields, Got: '%v - %+v'.", w.Code, resp.Error)
+	}
+}
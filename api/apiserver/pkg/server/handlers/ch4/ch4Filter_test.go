@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package ch4
+
+import (
+	"apiserver/pkg/database/models"
+	"database/sql/driver"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCh4GetFilter(t *testing.T) {
+	testVals := []struct {
+		query     string
+		sqlString string
+		args      []driver.Value
+	}{
+		{
+			"/v1/ch4/monthly?filter=" + url.QueryEscape("average >= 1850 and (month in (5,6) or trend_unc < 0.5)"),
+			`SELECT * FROM public.ch4_mm_gl WHERE (average >= $1 AND (month IN ($2, $3) OR trend_unc < $4)) ORDER BY year,month LIMIT 10`,
+			[]driver.Value{1850.0, int64(5), int64(6), 0.5},
+		},
+		{
+			"/v1/ch4/monthly?year=2020&filter=" + url.QueryEscape("not month in (1,2)") + "&filter=" + url.QueryEscape("average > 1800"),
+			`SELECT * FROM public.ch4_mm_gl WHERE year in ('2020') AND NOT month IN ($1, $2) AND average > $3 ORDER BY year,month LIMIT 10`,
+			[]driver.Value{int64(1), int64(2), 1800.0},
+		},
+	}
+
+	for _, testVal := range testVals {
+		runRequest(t, testVal.query, expectQuery(testVal.sqlString, sqlmock.NewRows([]string{"year"}), testVal.args...))
+	}
+}
+
+func TestCh4FilterErrors(t *testing.T) {
+	testVals := []struct {
+		filter  string
+		message string
+	}{
+		{"average >= 1850 and (month = 5", "position 31"},
+		{"ndays > 4", "trend_unc"},
+		{"year = 'recent'", "position 8"},
+		{"average > 1800; DROP TABLE ch4_mm_gl", "position 15"},
+	}
+
+	for _, testVal := range testVals {
+		query := "/v1/ch4/monthly?filter=" + url.QueryEscape(testVal.filter)
+		var resp models.ServerResp
+		w := runRequest(t, query, failing(), decodeInto(&resp))
+		if w.Code != 400 {
+			t.Errorf("Response status code '%v' does not match expected code '400'.", w.Code)
+		}
+		if resp.Error == nil || !strings.Contains(resp.Error.Message, testVal.message) {
+			t.Errorf("Expected '%v' in the error message, Got: '%+v'.", testVal.message, resp.Error)
+		}
+	}
+}
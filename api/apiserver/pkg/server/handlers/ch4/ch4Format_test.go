@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package ch4
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCh4GetCsv(t *testing.T) {
+	testVals := []struct {
+		query  string
+		accept string
+		sql    string
+		body   string
+	}{
+		{
+			"/v1/ch4/monthly?fields=year,month,average,yyyymmdd&format=csv",
+			"",
+			`SELECT year, month, average, yyyymmdd FROM public.ch4_mm_gl ORDER BY year,month LIMIT 10`,
+			"Year,Month,Average,Timestamp\n1983,7,1625.4,1983-07-01\n1983,8,,1983-08-01\n",
+		},
+		{
+			"/v1/ch4/monthly/trend?fields=year,month,average,yyyymmdd",
+			"text/tab-separated-values",
+			`SELECT year, month, average, yyyymmdd FROM public.ch4_mm_gl ORDER BY year,month LIMIT 10`,
+			"Year\tMonth\tAverage\tTimestamp\n1983\t7\t1625.4\t1983-07-01\n1983\t8\t\t1983-08-01\n",
+		},
+	}
+
+	// The second row is missing its average
+	data := GetMockCh4Rows()[:2]
+	data[1].Average = -999.99
+
+	for _, testVal := range testVals {
+		rows := mockRows([]string{"year", "month", "average", "yyyymmdd"}, data)
+		w := runRequest(t, testVal.query, withHeader("Accept", testVal.accept), expectQuery(testVal.sql, rows))
+
+		if body := w.Body.String(); body != testVal.body {
+			t.Errorf("Wanted body:\n%v\nGot:\n%v", testVal.body, body)
+		}
+		if disposition := w.Result().Header.Get("Content-Disposition"); !strings.HasPrefix(disposition, `attachment; filename="ch4_mm_gl.`) {
+			t.Errorf("Unexpected Content-Disposition, Got: '%v'.", disposition)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package ch4
+
+import (
+	"apiserver/pkg/database/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCh4GetLatest(t *testing.T) {
+	data := GetMockCh4Rows()
+	latest, yearAgo, tenYearsAgo := data[7], data[7], data[5]
+	observed := time.Date(2020, time.Month(11), 1, 0, 0, 0, 0, time.UTC)
+	yearAgo.Year, yearAgo.Average = 2019, 1873.2
+	tenYearsAgo.Year, tenYearsAgo.Month, tenYearsAgo.Average = 2010, 11, 1801.4
+
+	lookup := withMock(func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(yyyymmdd) FROM public.ch4_mm_gl`)).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(observed))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(ingested_at) FROM public.ingest_log WHERE dataset = $1`)).
+			WithArgs("ch4_mm_gl").
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+	})
+
+	resp := struct {
+		Results []struct {
+			Latest      models.Ch4Entry
+			OneYearAgo  models.Ch4Entry
+			TenYearsAgo models.Ch4Entry
+			Freshness   map[string]interface{}
+		}
+	}{}
+	runRequest(t, "/v1/ch4/latest?pretty=false", withHandler(GetLatest), lookup, decodeInto(&resp),
+		expectQuery(`SELECT * FROM public.ch4_mm_gl WHERE year = 2020 AND month = 11 ORDER BY year,month LIMIT 1`, mockRows(mockColumns, []mockCh4Row{latest})),
+		expectQuery(`SELECT * FROM public.ch4_mm_gl WHERE year = 2019 AND month = 11 ORDER BY year,month LIMIT 1`, mockRows(mockColumns, []mockCh4Row{yearAgo})),
+		expectQuery(`SELECT * FROM public.ch4_mm_gl WHERE year = 2010 AND month = 11 ORDER BY year,month LIMIT 1`, mockRows(mockColumns, []mockCh4Row{tenYearsAgo})))
+
+	result := resp.Results[0]
+	for _, c := range []struct {
+		got  models.Ch4Entry
+		want mockCh4Row
+	}{{result.Latest, latest}, {result.OneYearAgo, yearAgo}, {result.TenYearsAgo, tenYearsAgo}} {
+		if c.got.Year != c.want.Year || c.got.Month != c.want.Month || c.got.Average != c.want.Average {
+			t.Errorf("Wanted measurement '%v-%v: %v', Got: '%v-%v: %v'.", c.want.Year, c.want.Month, c.want.Average, c.got.Year, c.got.Month, c.got.Average)
+		}
+	}
+	if result.Freshness["Cadence"] != string(models.Monthly) {
+		t.Errorf("Wanted cadence '%v', Got: '%v'.", models.Monthly, result.Freshness["Cadence"])
+	}
+	if _, ok := result.Freshness["Ingested"]; ok {
+		t.Error("Ingestion time should be omitted when no ingestion has been logged.")
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package ch4
+
+import (
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/server/handlers"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// runMetadataTest requests query twice from a mock database holding no measurements, the dataset having last been
+// ingested at ingested. The time the dataset was last updated is looked up with its coverage, which the catalog
+// caches unless it cannot be computed. It returns the metadata of the response.
+func runMetadataTest(t *testing.T, query string, sqlString string, ingested interface{}) map[string]interface{} {
+	catalog := handlers.NewCatalog(time.Hour)
+	_, lookupFails := ingested.(error)
+	coverage := func(mock sqlmock.Sqlmock) {
+		expected := mock.ExpectQuery(regexp.QuoteMeta(`SELECT min(yyyymmdd), max(yyyymmdd), count(*) FROM public.ch4_mm_gl`))
+		if lookupFails {
+			expected.WillReturnError(ingested.(error))
+			return
+		}
+		expected.WillReturnRows(sqlmock.NewRows([]string{"min", "max", "count"}).AddRow(nil, nil, 0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(ingested_at) FROM public.ingest_log WHERE dataset = $1`)).WithArgs(models.Ch4MmGl.Id).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(ingested))
+	}
+
+	var resp [2]struct{ Metadata map[string]interface{} }
+	for i := range resp {
+		opts := []requestOption{
+			expectQuery(sqlString, sqlmock.NewRows(nil)),
+			withConfig(func(config *handlers.ApiHandlerConfig) { config.Catalog = catalog }),
+			decodeInto(&resp[i]),
+		}
+		if i == 0 || lookupFails {
+			opts = append(opts, withMock(coverage))
+		}
+		runRequest(t, query, opts...)
+	}
+
+	if !reflect.DeepEqual(resp[0].Metadata, resp[1].Metadata) {
+		t.Errorf("Wanted the same metadata from the cached coverage, Got: %v and %v.", resp[0].Metadata, resp[1].Metadata)
+	}
+	return resp[1].Metadata
+}
+
+func TestCh4Metadata(t *testing.T) {
+	ingested := time.Date(2021, 11, 12, 6, 30, 0, 0, time.UTC)
+	metadata := runMetadataTest(t, "/v1/ch4/monthly", `SELECT * FROM public.ch4_mm_gl ORDER BY year,month LIMIT 10`, ingested)
+
+	want := map[string]interface{}{
+		"Dataset":     models.Ch4MmGl.Id,
+		"Site":        "Global marine surface sites",
+		"Source":      "https://gml.noaa.gov/aftp/products/trends/ch4/ch4_mm_gl.txt",
+		"Citation":    models.Ch4MmGl.Citation,
+		"License":     models.Ch4MmGl.License,
+		"LastUpdated": "2021-11-12T06:30:00Z",
+		"Units":       map[string]interface{}{"DateDecimal": "year", "Average": "ppb", "AverageUncertainty": "ppb", "Trend": "ppb", "TrendUncertainty": "ppb"},
+	}
+	if !reflect.DeepEqual(metadata, want) {
+		t.Errorf("Wanted metadata %v, Got: %v.", want, metadata)
+	}
+}
+
+func TestCh4MetadataFields(t *testing.T) {
+	metadata := runMetadataTest(t, "/v1/ch4/monthly?fields=year,trend", `SELECT year, trend FROM public.ch4_mm_gl ORDER BY year,month LIMIT 10`, nil)
+
+	if want := map[string]interface{}{"Trend": "ppb"}; !reflect.DeepEqual(metadata["Units"], want) {
+		t.Errorf("Wanted the units of the requested fields only, %v, Got: %v.", want, metadata["Units"])
+	}
+	if metadata["LastUpdated"] != nil {
+		t.Errorf("Wanted no update time for a dataset that was never ingested, Got: %v.", metadata["LastUpdated"])
+	}
+}
+
+func TestCh4MetadataUnknownUpdate(t *testing.T) {
+	metadata := runMetadataTest(t, "/v1/ch4/monthly", `SELECT * FROM public.ch4_mm_gl ORDER BY year,month LIMIT 10`, fmt.Errorf("connection reset"))
+
+	if metadata["LastUpdated"] != nil || metadata["Source"] != "https://gml.noaa.gov/aftp/products/trends/ch4/ch4_mm_gl.txt" {
+		t.Errorf("Wanted the metadata without an update time when it cannot be looked up, Got: %v.", metadata)
+	}
+}
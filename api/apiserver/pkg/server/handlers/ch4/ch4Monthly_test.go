@@ -52,6 +52,46 @@ func TestCh4GetYear(t *testing.T) {
 	RunTest(t, t.Name(), testVal, sqlString, query, validDates, handlerConfig)
 }
 
+func TestCh4GetYearRange(t *testing.T) {
+	testVal := [2]int{1990, 2000}
+
+	sqlString := regexp.QuoteMeta(fmt.Sprintf(`SELECT * FROM public.ch4_mm_gl WHERE year between '%v' and '%v' ORDER BY year,month LIMIT 10`, testVal[0], testVal[1]))
+	query := fmt.Sprintf("/v1/ch4/monthly?year=%v..%v", testVal[0], testVal[1])
+	validDates := []string{"1990.042", "1990.125", "2000.042", "2000.125"}
+
+	RunTest(t, t.Name(), testVal, sqlString, query, validDates, handlerConfig)
+}
+
+func TestCh4DateParse(t *testing.T) {
+	testVals := []struct {
+		params  []string
+		section string
+		sql     string
+	}{
+		{[]string{"2020"}, "year", "year in ('2020')"},
+		{[]string{"2019", "2020"}, "year", "year in ('2019', '2020')"},
+		{[]string{"1990..2020"}, "year", "year between '1990' and '2020'"},
+		{[]string{"6-8"}, "month", "month between '6' and '8'"},
+		{[]string{"2000.."}, "year", "year >= '2000'"},
+		{[]string{"..1990"}, "year", "year <= '1990'"},
+		{[]string{"!1", "2"}, "month", "month not in ('1', '2')"},
+		{[]string{"!6-8"}, "month", "not (month between '6' and '8')"},
+		{[]string{"1", "6..8", "12"}, "month", "(month in ('1', '12') or month between '6' and '8')"},
+		{[]string{"!1990..2000", "2010.."}, "year", "not (year between '1990' and '2000' or year >= '2010')"},
+	}
+
+	for _, testVal := range testVals {
+		sql, err := dateParse(testVal.params, testVal.section)
+		if err != nil {
+			t.Errorf("Unexpected error parsing '%v': %v", testVal.params, err)
+			continue
+		}
+		if sql != testVal.sql {
+			t.Errorf("Wanted '%v', Got: '%v'.", testVal.sql, sql)
+		}
+	}
+}
+
 func TestCh4GetMonth(t *testing.T) {
 	testVal := 1
 
@@ -161,18 +201,39 @@ func TestCh4GetNull(t *testing.T) {
 	RunTest(t, t.Name(), float32(testVal), sqlString, query, validValues, handlerConfig)
 }
 
+func TestCh4GetSort(t *testing.T) {
+	testVal := 2020
+
+	sqlString := regexp.QuoteMeta(fmt.Sprintf(`SELECT * FROM public.ch4_mm_gl WHERE year in ('%v') ORDER BY year DESC,month DESC LIMIT 10`, testVal))
+	query := fmt.Sprintf("/v1/ch4/monthly?year=%v&sort=-year,-month", testVal)
+	validDates := []string{"2020.875", "2020.792"}
+
+	RunTest(t, t.Name(), testVal, sqlString, query, validDates, handlerConfig)
+}
+
 func TestCh4Errors(t *testing.T) {
 	testVals := []string{
 		"/v1/ch4/monthly?year=2020a",
 		"/v1/ch4/monthly?year=20200",
 		"/v1/ch4/monthly?month=1a",
 		"/v1/ch4/monthly?month=14",
+		"/v1/ch4/monthly?year=2020..1990",
+		"/v1/ch4/monthly?year=1990..3001",
+		"/v1/ch4/monthly?year=..",
+		"/v1/ch4/monthly?month=0-8",
+		"/v1/ch4/monthly?month=6-13",
+		"/v1/ch4/monthly?month=1,!2",
+		"/v1/ch4/monthly?month=!",
+		"/v1/ch4/monthly?year=-2000",
+		"/v1/ch4/monthly?month=6-",
 		"/v1/ch4/monthly?gt=400a",
 		"/v1/ch4/monthly?lt=400a",
 		"/v1/ch4/monthly?gte=400a",
 		"/v1/ch4/monthly?lte=400a",
 		"/v1/ch4/monthly?gt=40000",
 		"/v1/ch4/monthly?gt=-1",
+		"/v1/ch4/monthly?sort=ndays",
+		"/v1/ch4/monthly?sort=trend,-trend",
 	}
 
 	sqlString := ``
@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package ch4
+
+import (
+	"apiserver/pkg/server/handlers"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCh4GetNdjson(t *testing.T) {
+	data := GetMockCh4Rows()
+	sqlString := "^" + regexp.QuoteMeta(`SELECT * FROM public.ch4_mm_gl ORDER BY year,month`) + "$"
+	w := runRequest(t, "/v1/ch4/monthly/trend?limit=all", withHeader("Accept", "application/x-ndjson"), withMock(func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(sqlString).WillReturnRows(mockRows(mockColumns, data))
+	}), withConfig(func(config *handlers.ApiHandlerConfig) { config.SortBy = "trend" }))
+
+	resp := w.Result()
+	scanner := bufio.NewScanner(resp.Body)
+	lines := 0
+	for scanner.Scan() {
+		entry := make(map[string]interface{})
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Line %v is not a JSON object: %v", lines+1, err)
+		}
+		if float32(entry["Trend"].(float64)) != data[lines].Trend {
+			t.Errorf("Wanted trend '%v' on line %v, Got: '%v'.", data[lines].Trend, lines+1, entry["Trend"])
+		}
+		lines++
+	}
+	if lines != len(data) {
+		t.Errorf("Wanted %v lines, Got: %v.", len(data), lines)
+	}
+	if count := resp.Trailer.Get("X-Result-Count"); count != fmt.Sprint(len(data)) {
+		t.Errorf("Wanted X-Result-Count trailer '%v', Got: '%v'.", len(data), count)
+	}
+	if link := resp.Header.Get("Link"); link != "" {
+		t.Errorf("Expected no Link header for an unlimited query, Got: '%v'.", link)
+	}
+}
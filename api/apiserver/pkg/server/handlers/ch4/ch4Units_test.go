@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package ch4
+
+import (
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/utils"
+	"database/sql/driver"
+	"math"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// runUnitsTest requests query from a mock database expecting sqlString with args, which returns a single measurement
+// of 1900 ppb with a missing trend. It returns the measurement and the units of the response.
+func runUnitsTest(t *testing.T, query string, sqlString string, args []driver.Value) (map[string]interface{}, map[string]interface{}) {
+	rows := sqlmock.NewRows(mockColumns).AddRow(2020, 5, 1, 2020.375, 1900, 2.5, -999.99, 1.25, time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC))
+	resp := struct {
+		Results  []map[string]interface{}
+		Metadata struct{ Units map[string]interface{} }
+	}{}
+	runRequest(t, query, expectQuery(sqlString, rows, args...), decodeInto(&resp))
+
+	if len(resp.Results) != 1 {
+		t.Fatalf("Wanted a single measurement from '%v', Got: %v.", query, resp.Results)
+	}
+	return resp.Results[0], resp.Metadata.Units
+}
+
+func TestCh4Units(t *testing.T) {
+	testVals := []struct {
+		units   string
+		average float64
+		unc     float64
+	}{
+		{"ppb", 1900, 2.5},
+		{"ppm", 1.9, 0.0025},
+		{"ug/m3", 1.9 * 16.04 * 101325 / (8.314462618 * 273.15), 0.0025 * 16.04 * 101325 / (8.314462618 * 273.15)},
+	}
+
+	for _, testVal := range testVals {
+		query := "/v1/ch4/monthly?units=" + url.QueryEscape(testVal.units)
+		result, units := runUnitsTest(t, query, `SELECT * FROM public.ch4_mm_gl ORDER BY year,month LIMIT 10`, nil)
+
+		for field, want := range map[string]float64{"Average": testVal.average, "AverageUncertainty": testVal.unc, "Trend": -999.99} {
+			if got, _ := result[field].(float64); math.Abs(got-want) > math.Abs(want)*1e-6 {
+				t.Errorf("Wanted %v of %v from '%v', Got: %v.", field, want, query, result[field])
+			}
+		}
+		if units["Average"] != testVal.units || units["TrendUncertainty"] != testVal.units || units["DateDecimal"] != "year" {
+			t.Errorf("Wanted concentrations to be reported in %v for '%v', Got: %v.", testVal.units, query, units)
+		}
+	}
+}
+
+func TestCh4UnitsFilters(t *testing.T) {
+	runUnitsTest(t, "/v1/ch4/monthly?units=ppm&filter_units=requested&gte=1.85&filter="+url.QueryEscape("trend_unc < 0.002"),
+		`SELECT * FROM public.ch4_mm_gl WHERE average >= 1850 AND trend_unc < $1 ORDER BY year,month LIMIT 10`, []driver.Value{0.002 / 0.001})
+	runUnitsTest(t, "/v1/ch4/monthly?units=ppm&filter_units=canonical&gte=1850",
+		`SELECT * FROM public.ch4_mm_gl WHERE average >= 1850.00 ORDER BY year,month LIMIT 10`, nil)
+}
+
+// Bounds converted from another unit are compared at full precision. Rounded to hundredths of a ppb, a bound of
+// 1.850005 ppm would become 1850.01 ppb, and leave out measurements between 1850.005 and 1850.01 ppb.
+func TestCh4UnitsFilterPrecision(t *testing.T) {
+	runUnitsTest(t, "/v1/ch4/monthly?units=ppm&filter_units=requested&gt=1.850005",
+		`SELECT * FROM public.ch4_mm_gl WHERE average > 1850.0049999999999 ORDER BY year,month LIMIT 10`, nil)
+	runUnitsTest(t, "/v1/ch4/monthly?units=ppm&filter_units=requested&filter="+url.QueryEscape("average > 1.850005"),
+		`SELECT * FROM public.ch4_mm_gl WHERE average > $1 ORDER BY year,month LIMIT 10`, []driver.Value{1850.0049999999999})
+}
+
+func TestCh4UnitsErrors(t *testing.T) {
+	testVals := []struct {
+		query string
+		code  string
+		param string
+	}{
+		{"/v1/ch4/monthly?units=GtC", utils.CodeInvalidParam, "units"},
+		{"/v1/ch4/monthly?units=ppm&lt=1.9", utils.CodeInvalidParam, "lt"},
+		{"/v1/ch4/monthly?units=ppm&filter_units=requested&lt=3.5", utils.CodeOutOfRange, "lt"},
+	}
+
+	for _, testVal := range testVals {
+		problem := models.Problem{}
+		w := runRequest(t, testVal.query, failing(), withHeader("Accept", "application/problem+json"), decodeInto(&problem))
+		if w.Code != 400 || problem.Code != testVal.code || problem.Param != testVal.param {
+			t.Errorf("Wanted code '%v' for parameter '%v' from %v, Got: %v %+v", testVal.code, testVal.param, testVal.query, w.Code, problem)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package ch4
+
+import (
+	"apiserver/pkg/chart"
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/server/handlers"
+	"apiserver/pkg/utils"
+	"context"
+	"net/http"
+	"strings"
+)
+
+// chartMonthsPerYear is the window of the moving average drawn as a trend of the trend column.
+const chartMonthsPerYear = 12
+
+// GetChart is an ApiHandlerFunc type. It queries the database for requested ch4monthly data and returns an
+// SVG line chart of the column selected by the route. Charts accept the same filters as the JSON endpoints,
+// but return every matching observation unless a limit is requested.
+func GetChart(ctx context.Context, handlerConfig *handlers.ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	opts, optsErr := handlers.ParseChartOptions(r)
+	if optsErr != nil {
+		return optsErr
+	}
+
+	dataset := models.Ch4MmGl
+	query := database.NewQuery(dataset.Table, []string{"*"}, strings.Join(dataset.Order, ","))
+	query.Limit = -1
+
+	filters, internalArgs, err := ParseParams(r, false, handlerConfig.SortBy, nil)
+	if err != nil {
+		return err
+	}
+
+	query.Where = filters
+
+	if len(internalArgs) != 0 {
+		ParseInternalArgs(internalArgs, &query)
+	}
+
+	value, title := "average", "Monthly global CH4 average"
+	if handlerConfig.SortBy == "trend" {
+		value, title = "trend", "Monthly global CH4 trend"
+	}
+
+	// NOAA publishes a deseasonalized trend alongside the average, which is used as its overlay
+	names := []string{"yyyymmdd", value}
+	if opts.Trend && value != "trend" {
+		names = append(names, "trend")
+	}
+
+	// Charts always plot the same columns in chronological order
+	fields, _ := dataset.Fields(names)
+	query.Select(fields)
+	query.OrderBy = strings.Join(dataset.Order, ",")
+
+	ch4Table := models.Ch4Table{}
+	dataObject := &models.FieldsTable{Columns: fields, Entries: (*[]interface{})(&ch4Table)}
+
+	dberr := handlerConfig.Database.Query(query, dataObject)
+	if dberr != nil {
+		return utils.NewError(dberr, "internal database error", 500, false)
+	}
+
+	points, pointsErr := handlers.ChartPoints(dataset, ch4Table, fields, value)
+	if pointsErr != nil {
+		return utils.NewError(pointsErr, "error reading chart data", 500, false)
+	}
+
+	series := []chart.Series{{Name: "Monthly", Points: points}}
+	if opts.Trend {
+		trend := chart.Series{Name: "Trend", Dashed: true}
+		if value == "trend" {
+			trend.Name = "12 month average"
+			trend.Points = chart.MovingAverage(points, chartMonthsPerYear)
+		} else if trend.Points, pointsErr = handlers.ChartPoints(dataset, ch4Table, fields, "trend"); pointsErr != nil {
+			return utils.NewError(pointsErr, "error reading chart data", 500, false)
+		}
+		series = append(series, trend)
+	}
+
+	chartOpts := chart.Options{Title: title, Units: "ppb", Width: opts.Width, Height: opts.Height, Theme: opts.Theme}
+	return handlers.WriteChart(w, r, chartOpts, series...)
+}
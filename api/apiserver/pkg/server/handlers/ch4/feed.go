@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package ch4
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/server/handlers"
+	"apiserver/pkg/utils"
+	"context"
+	"net/http"
+)
+
+// GetFeed is an ApiHandlerFunc type. It publishes the most recent ch4monthly measurements as an Atom or RSS feed,
+// with one entry per month holding its average and the change since the same month a year earlier.
+func GetFeed(ctx context.Context, handlerConfig *handlers.ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	dataset := models.Ch4MmGl
+
+	if serverErr := handlers.ValidateFeedRequest(r); serverErr != nil {
+		return serverErr
+	}
+
+	updated, serverErr := handlers.FeedUpdated(handlerConfig.Database, dataset)
+	if serverErr != nil {
+		return serverErr
+	}
+
+	// The dataset has no column holding the value a year earlier, so a further year of months is read to look it up
+	orderBy, err := dataset.OrderBy([]string{"-year", "-month"})
+	if err != nil {
+		return utils.NewError(err, "error ordering feed entries", 500, false)
+	}
+	query := database.NewQuery(dataset.Table, []string{"*"}, orderBy)
+	query.Limit = handlers.FeedEntries + 12
+
+	ch4Table := models.Ch4Table{}
+	if dberr := handlerConfig.Database.Query(query, &ch4Table); dberr != nil {
+		return utils.NewError(dberr, "internal database error", 500, false)
+	}
+
+	averages := make(map[[2]int]float32, len(ch4Table))
+	for _, row := range ch4Table {
+		entry := row.(models.Ch4Entry)
+		averages[[2]int{entry.Year, entry.Month}] = entry.Average
+	}
+
+	feed := handlers.Feed{
+		Dataset:     dataset,
+		Title:       "Monthly global CH₄",
+		Description: "Monthly global average CH₄ mole fraction measured over marine surface sites, in parts per billion.",
+		Resource:    utils.GetVersion(r).Base() + "/ch4/monthly",
+		Units:       "ppb",
+		Updated:     updated,
+	}
+	for i, row := range ch4Table {
+		if i == handlers.FeedEntries {
+			break
+		}
+		entry := row.(models.Ch4Entry)
+		yearAgo, ok := averages[[2]int{entry.Year - 1, entry.Month}]
+		if !ok {
+			yearAgo = dataset.Missing
+		}
+		feed.Entries = append(feed.Entries, handlers.NewFeedEntry(dataset, entry.Timestamp, entry.Average, yearAgo))
+	}
+	return handlers.WriteFeed(w, r, feed)
+}
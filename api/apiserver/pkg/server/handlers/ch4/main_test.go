@@ -31,6 +31,7 @@ import (
 	"apiserver/test"
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -39,6 +40,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"testing"
@@ -138,12 +140,129 @@ func RunTest(t *testing.T, testName string, testVal interface{}, sqlString strin
 	}
 }
 
+// mockRequest is a request made by runRequest, as set up by its requestOptions.
+type mockRequest struct {
+	handler handlers.ApiHandlerFunc
+	config  handlers.ApiHandlerConfig
+	header  http.Header
+	expect  []func(mock sqlmock.Sqlmock)
+	fails   bool
+	decoded []interface{}
+}
+
+// requestOption sets up a request made by runRequest.
+type requestOption func(request *mockRequest)
+
+// withHeader sets a header of the request, unless value is empty.
+func withHeader(name string, value string) requestOption {
+	return func(request *mockRequest) {
+		if value != "" {
+			request.header.Set(name, value)
+		}
+	}
+}
+
+// expectQuery expects the handler to query sqlString with args, which returns rows.
+func expectQuery(sqlString string, rows *sqlmock.Rows, args ...driver.Value) requestOption {
+	return withMock(func(mock sqlmock.Sqlmock) {
+		query := mock.ExpectQuery(regexp.QuoteMeta(sqlString)).WillReturnRows(rows)
+		if len(args) != 0 {
+			query.WithArgs(args...)
+		}
+	})
+}
+
+// withMock sets further expectations on the mock database, in the order the handler meets them.
+func withMock(expect func(mock sqlmock.Sqlmock)) requestOption {
+	return func(request *mockRequest) {
+		request.expect = append(request.expect, expect)
+	}
+}
+
+// withConfig changes the configuration of the handler, which otherwise only sorts by average.
+func withConfig(configure func(config *handlers.ApiHandlerConfig)) requestOption {
+	return func(request *mockRequest) {
+		configure(&request.config)
+	}
+}
+
+// withHandler requests handler rather than Get.
+func withHandler(handler handlers.ApiHandlerFunc) requestOption {
+	return func(request *mockRequest) {
+		request.handler = handler
+	}
+}
+
+// failing expects the request to fail. The error is written to the response by HttpJsonError.
+func failing() requestOption {
+	return func(request *mockRequest) {
+		request.fails = true
+	}
+}
+
+// decodeInto decodes the JSON body of the response into v.
+func decodeInto(v interface{}) requestOption {
+	return func(request *mockRequest) {
+		request.decoded = append(request.decoded, v)
+	}
+}
+
+// runRequest requests query from a mock database as set up by opts. The test fails unless every expected query is
+// made and the request succeeds, or fails when it is expected to. It returns the response.
+func runRequest(t *testing.T, query string, opts ...requestOption) *httptest.ResponseRecorder {
+	t.Helper()
+	db, mock, _, _, err := newMockDb()
+	if err != nil {
+		t.Fatalf("error generating mock database: %s", err.Error())
+	}
+	defer db.Close()
+
+	request := mockRequest{handler: Get, config: handlers.ApiHandlerConfig{SortBy: "average"}, header: http.Header{}}
+	for _, opt := range opts {
+		opt(&request)
+	}
+	for _, expect := range request.expect {
+		expect(mock)
+	}
+	request.config.Database = &database.Database{DB: db}
+
+	req := test.SetReqIdTest(httptest.NewRequest("GET", query, nil))
+	for name, values := range request.header {
+		req.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+
+	serverErr := request.handler(context.Background(), &request.config, w, req)
+	switch {
+	case serverErr != nil && !request.fails:
+		test.ErrorLog(t, serverErr)
+		t.Fatalf("Unexpected error for query '%v'.", query)
+	case serverErr == nil && request.fails:
+		t.Fatalf("Wanted an error for query '%v'.", query)
+	case serverErr != nil:
+		utils.HttpJsonError(w, req, serverErr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("there were unfulfilled expectations for query '%v': %s", query, err)
+	}
+	if verbose {
+		test.PrintServerResponse(t, w.Result(), w.Body.Bytes())
+	}
+
+	for _, v := range request.decoded {
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Fatalf("Cannot decode the response to '%v': %v", query, err)
+		}
+	}
+	return w
+}
+
 func configureDbRows(t *testing.T, testName string, testVal interface{}, rows *sqlmock.Rows, data []mockCh4Row) error {
 	for i, v := range data {
 		switch testName {
 		case "TestCh4GetAll", "TestCh4TrendGetAll":
 			// Add all entries to mock database response
-			rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+			rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 		case "TestCh4GetYear", "TestCh4TrendGetYear":
 			if _, ok := testVal.(int); !ok {
 				return fmt.Errorf("Test value '%v' for test '%v' is not of type int.", testVal, testName)
@@ -151,7 +270,7 @@ func configureDbRows(t *testing.T, testName string, testVal interface{}, rows *s
 
 			// Add desired entries to mock database response
 			if v.Year == testVal.(int) {
-				rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+				rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 			}
 		case "TestCh4GetMonth", "TestCh4TrendGetMonth":
 			if _, ok := testVal.(int); !ok {
@@ -160,7 +279,7 @@ func configureDbRows(t *testing.T, testName string, testVal interface{}, rows *s
 
 			// Add desired entries to mock database response
 			if v.Month == testVal.(int) {
-				rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+				rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 			}
 		case "TestCh4GetGt", "TestCh4TrendGetGt":
 			if _, ok := testVal.(float32); !ok {
@@ -170,11 +289,11 @@ func configureDbRows(t *testing.T, testName string, testVal interface{}, rows *s
 			// Add desired entries to mock database response
 			if strings.Contains(testName, "Trend") {
 				if v.Trend > testVal.(float32) {
-					rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+					rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 				}
 			} else {
 				if v.Average > testVal.(float32) {
-					rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+					rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 				}
 			}
 		case "TestCh4GetGte", "TestCh4TrendGetGte":
@@ -185,11 +304,11 @@ func configureDbRows(t *testing.T, testName string, testVal interface{}, rows *s
 			// Add desired entries to mock database response
 			if strings.Contains(testName, "Trend") {
 				if v.Trend >= testVal.(float32) {
-					rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+					rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 				}
 			} else {
 				if v.Average >= testVal.(float32) {
-					rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+					rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 				}
 			}
 		case "TestCh4GetLt", "TestCh4TrendGetLt":
@@ -200,11 +319,11 @@ func configureDbRows(t *testing.T, testName string, testVal interface{}, rows *s
 			// Add desired entries to mock database response
 			if strings.Contains(testName, "Trend") {
 				if v.Trend < testVal.(float32) {
-					rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+					rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 				}
 			} else {
 				if v.Average < testVal.(float32) {
-					rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+					rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 				}
 			}
 		case "TestCh4GetLte", "TestCh4TrendGetLte":
@@ -215,11 +334,11 @@ func configureDbRows(t *testing.T, testName string, testVal interface{}, rows *s
 			// Add desired entries to mock database response
 			if strings.Contains(testName, "Trend") {
 				if v.Trend <= testVal.(float32) {
-					rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+					rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 				}
 			} else {
 				if v.Average <= testVal.(float32) {
-					rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+					rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 				}
 			}
 		case "TestCh4GetLimit", "TestCh4TrendGetLimit":
@@ -229,7 +348,7 @@ func configureDbRows(t *testing.T, testName string, testVal interface{}, rows *s
 
 			// Add desired entries to mock database response
 			if i < testVal.(int) {
-				rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+				rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 			}
 		case "TestCh4GetOffset", "TestCh4TrendGetOffset":
 			if _, ok := testVal.(int); !ok {
@@ -238,7 +357,7 @@ func configureDbRows(t *testing.T, testName string, testVal interface{}, rows *s
 
 			// Add desired entries to mock database response
 			if i+1 > testVal.(int) {
-				rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+				rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 			}
 		case "TestCh4GetPage", "TestCh4TrendGetPage":
 			if _, ok := testVal.(int); !ok {
@@ -247,7 +366,7 @@ func configureDbRows(t *testing.T, testName string, testVal interface{}, rows *s
 
 			// Add desired entries to mock database response
 			if i+1 > testVal.(int) && i+1 < 5 {
-				rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+				rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 			}
 		case "TestCh4GetCombo", "TestCh4TrendGetCombo":
 			if _, ok := testVal.([]float32); !ok {
@@ -257,11 +376,11 @@ func configureDbRows(t *testing.T, testName string, testVal interface{}, rows *s
 			// Add desired entries to mock database response
 			if strings.Contains(testName, "Trend") {
 				if fmt.Sprintf("%.2f", v.Trend) == fmt.Sprintf("%.2f", testVal.([]float32)[0]) || fmt.Sprintf("%.2f", v.Trend) == fmt.Sprintf("%.2f", testVal.([]float32)[1]) {
-					rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+					rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 				}
 			} else {
 				if fmt.Sprintf("%.2f", v.Average) == fmt.Sprintf("%.2f", testVal.([]float32)[0]) || fmt.Sprintf("%.2f", v.Average) == fmt.Sprintf("%.2f", testVal.([]float32)[1]) {
-					rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+					rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 				}
 			}
 		case "TestCh4GetNull", "TestCh4TrendGetNull":
@@ -272,13 +391,32 @@ func configureDbRows(t *testing.T, testName string, testVal interface{}, rows *s
 			// Add desired entries to mock database response
 			if strings.Contains(testName, "Trend") {
 				if v.Trend < testVal.(float32) {
-					rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+					rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 				}
 			} else {
 				if v.Average < testVal.(float32) {
-					rows.AddRow(v.Year, v.Month, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+					rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
 				}
 			}
+		case "TestCh4GetSort", "TestCh4TrendGetSort":
+			if _, ok := testVal.(int); !ok {
+				return fmt.Errorf("Test value '%v' for test '%v' is not of type int.", testVal, testName)
+			}
+
+			// Add desired entries to mock database response, newest first
+			v := data[len(data)-1-i]
+			if v.Year == testVal.(int) {
+				rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+			}
+		case "TestCh4GetYearRange", "TestCh4TrendGetYearRange":
+			if _, ok := testVal.([2]int); !ok {
+				return fmt.Errorf("Test value '%v' for test '%v' is not of type [2]int.", testVal, testName)
+			}
+
+			// Add desired entries to mock database response
+			if v.Year >= testVal.([2]int)[0] && v.Year <= testVal.([2]int)[1] {
+				rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.AverageUncertainty, v.Trend, v.TrendUncertainty, v.Timestamp)
+			}
 		case "TestCh4Errors", "TestCh4TrendErrors":
 			if testVal != nil {
 				return fmt.Errorf("Test value '%v' for test '%v' is not nil.", testVal, testName)
@@ -351,6 +489,7 @@ func validateErrorResponse(t *testing.T, r *http.Response, expectedCode []string
 type mockCh4Row struct {
 	Year               int
 	Month              int
+	Day                int
 	DateDecimal        float32
 	Average            float32
 	AverageUncertainty float32
@@ -359,6 +498,9 @@ type mockCh4Row struct {
 	Timestamp          time.Time
 }
 
+// mockColumns are the columns of the table, as the database returns them for 'SELECT *'.
+var mockColumns = []string{"year", "month", "day", "date_decimal", "average", "average_unc", "trend", "trend_unc", "yyyymmdd"}
+
 // newMockDb returns an sqlmock database to be used for unit tests.
 func newMockDb() (*sql.DB, sqlmock.Sqlmock, *sqlmock.Rows, []mockCh4Row, error) {
 	db, mock, err := sqlmock.New()
@@ -366,21 +508,37 @@ func newMockDb() (*sql.DB, sqlmock.Sqlmock, *sqlmock.Rows, []mockCh4Row, error)
 		return nil, nil, nil, nil, err
 	}
 
-	columns := []string{"year", "month", "date_decimal", "average", "average_unc", "trend", "trend_unc", "YYYYMMDD"}
-
-	rows := sqlmock.NewRows(columns)
+	rows := sqlmock.NewRows(mockColumns)
 
 	data := GetMockCh4Rows()
 
 	return db, mock, rows, data, nil
 }
 
+// mockRows returns the given columns of the mock measurements, as the database returns them.
+func mockRows(columns []string, data []mockCh4Row) *sqlmock.Rows {
+	rows := sqlmock.NewRows(columns)
+	for _, v := range data {
+		values := map[string]driver.Value{
+			"year": v.Year, "month": v.Month, "day": v.Day, "date_decimal": v.DateDecimal, "average": v.Average,
+			"average_unc": v.AverageUncertainty, "trend": v.Trend, "trend_unc": v.TrendUncertainty, "yyyymmdd": v.Timestamp,
+		}
+		row := make([]driver.Value, len(columns))
+		for i, col := range columns {
+			row[i] = values[strings.ToLower(col)]
+		}
+		rows.AddRow(row...)
+	}
+	return rows
+}
+
 // GetMockCh4Rows returns a list hardcoded Ch4 measurement data used to mock the database.
 func GetMockCh4Rows() []mockCh4Row {
 	return []mockCh4Row{
 		{
 			Year:               1983,
 			Month:              7,
+			Day:                1,
 			DateDecimal:        1983.542,
 			Average:            1625.4,
 			AverageUncertainty: 2.4,
@@ -391,6 +549,7 @@ func GetMockCh4Rows() []mockCh4Row {
 		{
 			Year:               1983,
 			Month:              8,
+			Day:                1,
 			DateDecimal:        1983.625,
 			Average:            1627.5,
 			AverageUncertainty: 2.9,
@@ -401,6 +560,7 @@ func GetMockCh4Rows() []mockCh4Row {
 		{
 			Year:               1990,
 			Month:              1,
+			Day:                1,
 			DateDecimal:        1990.042,
 			Average:            1712.1,
 			AverageUncertainty: 1.2,
@@ -411,6 +571,7 @@ func GetMockCh4Rows() []mockCh4Row {
 		{
 			Year:               1990,
 			Month:              2,
+			Day:                1,
 			DateDecimal:        1990.125,
 			Average:            1713.5,
 			AverageUncertainty: 1.3,
@@ -421,6 +582,7 @@ func GetMockCh4Rows() []mockCh4Row {
 		{
 			Year:               2000,
 			Month:              1,
+			Day:                1,
 			DateDecimal:        2000.042,
 			Average:            1776.1,
 			AverageUncertainty: 1.1,
@@ -431,6 +593,7 @@ func GetMockCh4Rows() []mockCh4Row {
 		{
 			Year:               2000,
 			Month:              2,
+			Day:                1,
 			DateDecimal:        2000.125,
 			Average:            1776,
 			AverageUncertainty: 1.4,
@@ -441,6 +604,7 @@ func GetMockCh4Rows() []mockCh4Row {
 		{
 			Year:               2020,
 			Month:              10,
+			Day:                1,
 			DateDecimal:        2020.792,
 			Average:            1890.1,
 			AverageUncertainty: -9.9,
@@ -451,6 +615,7 @@ func GetMockCh4Rows() []mockCh4Row {
 		{
 			Year:               2020,
 			Month:              11,
+			Day:                1,
 			DateDecimal:        2020.875,
 			Average:            1891.7,
 			AverageUncertainty: -9.9,
@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package handlers
+
+import (
+	"apiserver/pkg/chart"
+	"apiserver/pkg/database/models"
+	utils "apiserver/pkg/utils"
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	chartWidthMin  = 200
+	chartWidthMax  = 2000
+	chartHeightMin = 100
+	chartHeightMax = 1200
+)
+
+// ChartOptions holds the query parameters controlling how a chart is drawn. They are parsed separately
+// from the dataset filters, which charts share with the JSON endpoints.
+type ChartOptions struct {
+	Width  int
+	Height int
+	Theme  chart.Theme
+
+	// Trend overlays a smoothed series on the requested one
+	Trend bool
+}
+
+// ParseChartOptions parses the 'width', 'height', 'theme' and 'trend' query parameters of a chart request.
+func ParseChartOptions(r *http.Request) (ChartOptions, *utils.ServerError) {
+	opts := ChartOptions{Width: 800, Height: 400, Theme: chart.Themes["light"]}
+
+	for key, val := range r.URL.Query() {
+		param := strings.Join(val, ",")
+		var err error
+
+		switch key {
+		case "width":
+			opts.Width, err = chartSize(param, chartWidthMin, chartWidthMax)
+		case "height":
+			opts.Height, err = chartSize(param, chartHeightMin, chartHeightMax)
+		case "theme":
+			theme, ok := chart.Themes[strings.ToLower(param)]
+			if !ok {
+				err = fmt.Errorf("malformed query parameters, unknown theme. Allowed themes are: dark, light")
+			}
+			opts.Theme = theme
+		case "trend":
+			opts.Trend, err = strconv.ParseBool(param)
+			if err != nil {
+				err = fmt.Errorf("malformed query parameters, value must be a boolean")
+			}
+		}
+
+		if err != nil {
+			message := err.Error() + ": " + key + "=[" + param + "]"
+			return opts, utils.NewError(fmt.Errorf("error when parsing query parameters"), message, 400, false).ForParam(key, err)
+		}
+	}
+	return opts, nil
+}
+
+// chartSize parses a dimension of a chart in pixels.
+func chartSize(param string, min int, max int) (int, error) {
+	size, err := strconv.Atoi(param)
+	if err != nil {
+		return 0, fmt.Errorf("malformed query parameters, size must be an integer between %v and %v", min, max)
+	}
+	if size < min || size > max {
+		return 0, utils.OutOfRange("malformed query parameters, size must be an integer between %v and %v", min, max)
+	}
+	return size, nil
+}
+
+// ChartPoints reads the points of a series from the results of a dataset query. Each result must hold the
+// 'yyyymmdd' column and the value column. Missing measurements become gaps in the series.
+func ChartPoints(dataset models.Dataset, results []interface{}, columns []models.Column, value string) ([]chart.Point, error) {
+	timeIdx, valueIdx := -1, -1
+	for i, col := range columns {
+		switch col.Name {
+		case "yyyymmdd":
+			timeIdx = i
+		case value:
+			valueIdx = i
+		}
+	}
+	if timeIdx < 0 || valueIdx < 0 {
+		return nil, fmt.Errorf("results do not hold the columns 'yyyymmdd' and '%v'", value)
+	}
+
+	points := make([]chart.Point, 0, len(results))
+	for _, entry := range results {
+		values, err := models.Values(entry, columns)
+		if err != nil {
+			return nil, err
+		}
+		pt := chart.Point{Value: math.NaN()}
+		if t, ok := values[timeIdx].(time.Time); ok {
+			pt.Time = t
+		}
+		if v, ok := values[valueIdx].(float32); ok && !dataset.IsMissing(v) {
+			pt.Value = float64(v)
+		}
+		points = append(points, pt)
+	}
+
+	// A moving average is only meaningful over observations in chronological order
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	return points, nil
+}
+
+// WriteChart renders series as an SVG chart. The chart is rendered in full before writing, so that
+// rendering errors can still be returned to the client.
+func WriteChart(w http.ResponseWriter, r *http.Request, opts chart.Options, series ...chart.Series) *utils.ServerError {
+	// Parse RequestID param
+	id, idError := utils.GetReqId(r)
+	if idError != nil {
+		return utils.NewError(idError, "cannot extract request ID", 500, false)
+	}
+
+	var buf bytes.Buffer
+	if err := chart.Render(&buf, opts, series...); err != nil {
+		return utils.NewError(err, "error rendering chart", 500, false)
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("X-Request-Id", id)
+	if _, err := buf.WriteTo(w); err != nil {
+		return utils.NewError(err, "error writing chart", 500, false)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/server/handlers"
+	"apiserver/pkg/utils"
+	"context"
+	"database/sql"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GetBadge is an ApiHandlerFunc type. It returns an SVG badge showing the most recent co2weekly measurement
+// and its change from the same week a year earlier. The badge is grayed out when the dataset is stale.
+func GetBadge(ctx context.Context, handlerConfig *handlers.ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	dataset := models.Co2WeeklyMlo
+
+	observed, dberr := handlerConfig.Database.LatestObservation(dataset)
+	if dberr == sql.ErrNoRows {
+		return utils.NewError(dberr, "no co2 measurements available", 404, false)
+	} else if dberr != nil {
+		return utils.NewError(dberr, "internal database error", 500, false)
+	}
+
+	ingested, dberr := handlerConfig.Database.LastIngested(dataset)
+	if dberr != nil {
+		return utils.NewError(dberr, "internal database error", 500, false)
+	}
+
+	now := time.Now().UTC()
+	freshness := dataset.Freshness(observed, ingested, now)
+
+	fields, _ := dataset.Fields([]string{"yyyymmdd", "average"})
+	query := database.NewQuery(dataset.Table, []string{"*"}, strings.Join(dataset.Order, ","))
+	query.Select(fields)
+
+	values := []float64{math.NaN(), math.NaN()}
+	for years := range values {
+		co2Table := models.Co2Table{}
+		dataObject := &models.FieldsTable{Columns: fields, Entries: (*[]interface{})(&co2Table)}
+
+		dberr := handlerConfig.Database.QueryPeriod(dataset, query, observed.AddDate(-years, 0, 0), dataObject)
+		if dberr != nil {
+			return utils.NewError(dberr, "internal database error", 500, false)
+		}
+
+		points, err := handlers.ChartPoints(dataset, co2Table, fields, "average")
+		if err != nil {
+			return utils.NewError(err, "error reading badge data", 500, false)
+		}
+		if len(points) != 0 {
+			values[years] = points[0].Value
+		}
+	}
+
+	badge := handlers.LatestBadge("CO₂ Mauna Loa", "ppm", values[0], values[1], freshness)
+	return handlers.WriteBadge(w, r, badge, freshness, now)
+}
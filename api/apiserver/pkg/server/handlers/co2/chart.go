@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"apiserver/pkg/chart"
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/server/handlers"
+	"apiserver/pkg/utils"
+	"context"
+	"net/http"
+	"strings"
+)
+
+// chartWeeksPerYear is the window of the moving average drawn as a trend, so that the seasonal cycle is smoothed out.
+const chartWeeksPerYear = 52
+
+// GetChart is an ApiHandlerFunc type. It queries the database for requested co2weekly data and returns an
+// SVG line chart of the column selected by the route. Charts accept the same filters as the JSON endpoints,
+// but return every matching observation unless a limit is requested.
+func GetChart(ctx context.Context, handlerConfig *handlers.ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	opts, optsErr := handlers.ParseChartOptions(r)
+	if optsErr != nil {
+		return optsErr
+	}
+
+	dataset := models.Co2WeeklyMlo
+	query := database.NewQuery(dataset.Table, []string{"*"}, strings.Join(dataset.Order, ","))
+	query.Limit = -1
+
+	filters, internalArgs, err := ParseParams(r, false, handlerConfig.SortBy, nil)
+	if err != nil {
+		return err
+	}
+
+	query.Where = filters
+
+	if len(internalArgs) != 0 {
+		ParseInternalArgs(internalArgs, &query)
+	}
+
+	value, title := "average", "Weekly CO2 average at Mauna Loa"
+	if handlerConfig.SortBy == "increase" {
+		value, title = "increase_since_1800", "Weekly CO2 increase since 1800 at Mauna Loa"
+	}
+
+	// Charts always plot the same columns in chronological order
+	fields, _ := dataset.Fields([]string{"yyyymmdd", value})
+	query.Select(fields)
+	query.OrderBy = strings.Join(dataset.Order, ",")
+
+	co2Table := models.Co2Table{}
+	dataObject := &models.FieldsTable{Columns: fields, Entries: (*[]interface{})(&co2Table)}
+
+	dberr := handlerConfig.Database.Query(query, dataObject)
+	if dberr != nil {
+		return utils.NewError(dberr, "internal database error", 500, false)
+	}
+
+	points, pointsErr := handlers.ChartPoints(dataset, co2Table, fields, value)
+	if pointsErr != nil {
+		return utils.NewError(pointsErr, "error reading chart data", 500, false)
+	}
+
+	series := []chart.Series{{Name: "Weekly", Points: points}}
+	if opts.Trend {
+		series = append(series, chart.Series{Name: "52 week average", Points: chart.MovingAverage(points, chartWeeksPerYear), Dashed: true})
+	}
+
+	chartOpts := chart.Options{Title: title, Units: "ppm", Width: opts.Width, Height: opts.Height, Theme: opts.Theme}
+	return handlers.WriteChart(w, r, chartOpts, series...)
+}
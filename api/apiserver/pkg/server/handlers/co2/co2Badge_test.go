@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectBadgeQueries mocks the lookups of a badge for a dataset whose latest measurement was observed on the given date.
+func expectBadgeQueries(mock sqlmock.Sqlmock, observed time.Time, latest []mockCo2Row, yearAgo []mockCo2Row) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(yyyymmdd) FROM public.co2_weekly_mlo`)).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(observed))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(ingested_at) FROM public.ingest_log WHERE dataset = $1`)).
+		WithArgs("co2_weekly_mlo").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+
+	for i, period := range [][]mockCo2Row{latest, yearAgo} {
+		date := observed.AddDate(-i, 0, 0)
+		sql := `SELECT yyyymmdd, average FROM public.co2_weekly_mlo WHERE yyyymmdd BETWEEN '` + date.AddDate(0, 0, -3).Format("2006-01-02") +
+			`' AND '` + date.AddDate(0, 0, 3).Format("2006-01-02") + `' ORDER BY year,month,day LIMIT 1`
+		rows := sqlmock.NewRows([]string{"yyyymmdd", "average"})
+		for _, v := range period {
+			rows.AddRow(v.YYYYMMDD, v.Average)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta(sql)).WillReturnRows(rows)
+	}
+}
+
+func TestCo2GetBadge(t *testing.T) {
+	recent := time.Now().UTC().AddDate(0, 0, -3).Truncate(24 * time.Hour)
+	testVals := []struct {
+		name     string
+		observed time.Time
+		latest   []mockCo2Row
+		yearAgo  []mockCo2Row
+		message  string
+		color    string
+		maxAge   string
+	}{
+		{
+			"stale",
+			time.Date(1974, 6, 2, 0, 0, 0, 0, time.UTC),
+			[]mockCo2Row{{Average: 333.37, YYYYMMDD: time.Date(1974, 6, 2, 0, 0, 0, 0, time.UTC)}},
+			[]mockCo2Row{{Average: 330.12, YYYYMMDD: time.Date(1973, 6, 3, 0, 0, 0, 0, time.UTC)}},
+			"333.37 ppm (+3.25 y/y)",
+			"#9f9f9f",
+			"public, max-age=3600",
+		},
+		{
+			"fresh",
+			recent,
+			[]mockCo2Row{{Average: 421.08, YYYYMMDD: recent}},
+			[]mockCo2Row{{Average: -999.99, YYYYMMDD: recent.AddDate(-1, 0, 0)}},
+			"421.08 ppm<",
+			"#007ec6",
+			"public, max-age=86400",
+		},
+	}
+
+	for _, testVal := range testVals {
+		w := runRequest(t, "/v1/badge/co2.svg", withHandler(GetBadge), withMock(func(mock sqlmock.Sqlmock) {
+			expectBadgeQueries(mock, testVal.observed, testVal.latest, testVal.yearAgo)
+		}))
+
+		resp := w.Result()
+		if contentType := resp.Header.Get("Content-Type"); contentType != "image/svg+xml" {
+			t.Errorf("Wanted an image/svg+xml Content-Type, Got: '%v'.", contentType)
+		}
+		if cacheControl := resp.Header.Get("Cache-Control"); cacheControl != testVal.maxAge {
+			t.Errorf("Wanted Cache-Control '%v' for the %v dataset, Got: '%v'.", testVal.maxAge, testVal.name, cacheControl)
+		}
+		if modified := resp.Header.Get("Last-Modified"); modified != testVal.observed.Format(http.TimeFormat) {
+			t.Errorf("Wanted Last-Modified '%v', Got: '%v'.", testVal.observed.Format(http.TimeFormat), modified)
+		}
+
+		body := w.Body.String()
+		if !strings.Contains(body, "CO₂ Mauna Loa") || !strings.Contains(body, testVal.message) || !strings.Contains(body, testVal.color) {
+			t.Errorf("Wanted a badge showing '%v' in %v for the %v dataset, Got: %v", testVal.message, testVal.color, testVal.name, body)
+		}
+	}
+}
+
+func TestCo2GetBadgeEmpty(t *testing.T) {
+	w := runRequest(t, "/v1/badge/co2.svg", withHandler(GetBadge), failing(), withMock(func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(yyyymmdd) FROM public.co2_weekly_mlo`)).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+	}))
+	if w.Code != 404 {
+		t.Errorf("Wanted a 404 error for an empty dataset, Got: %v", w.Code)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/server/handlers"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// svgElements counts the elements of an SVG document by name, failing the test if the document is not well-formed.
+func svgElements(t *testing.T, body string) map[string]int {
+	counts := make(map[string]int)
+	dec := xml.NewDecoder(strings.NewReader(body))
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return counts
+		}
+		if err != nil {
+			t.Fatalf("Chart is not well-formed SVG: %v", err)
+		}
+		if elem, ok := token.(xml.StartElement); ok {
+			counts[elem.Name.Local]++
+		}
+	}
+}
+
+func TestCo2GetChart(t *testing.T) {
+	testVals := []struct {
+		query    string
+		sortBy   string
+		sql      string
+		column   string
+		title    string
+		polyline int
+	}{
+		{
+			"/v1/co2/weekly/chart.svg",
+			"average",
+			`SELECT yyyymmdd, average FROM public.co2_weekly_mlo ORDER BY year,month,day`,
+			"average",
+			"Weekly CO2 average at Mauna Loa",
+			1,
+		},
+		{
+			"/v1/co2/weekly/chart.svg?year=1974&limit=20&trend=true&theme=dark&width=400&height=200",
+			"average",
+			`SELECT yyyymmdd, average FROM public.co2_weekly_mlo WHERE year in ('1974') ORDER BY year,month,day LIMIT 20`,
+			"average",
+			"Weekly CO2 average at Mauna Loa",
+			1,
+		},
+		{
+			"/v1/co2/weekly/increase/chart.svg?gte=50&sort=-average",
+			"increase",
+			`SELECT yyyymmdd, increase_since_1800 FROM public.co2_weekly_mlo WHERE increase_since_1800 >= 50.00 ORDER BY year,month,day`,
+			"increase_since_1800",
+			"Weekly CO2 increase since 1800 at Mauna Loa",
+			1,
+		},
+	}
+
+	for _, testVal := range testVals {
+		rows := mockRows([]string{"yyyymmdd", testVal.column}, GetMockCo2Rows())
+		sortBy := testVal.sortBy
+		w := runRequest(t, testVal.query, withHandler(GetChart), expectQuery(testVal.sql, rows),
+			withConfig(func(config *handlers.ApiHandlerConfig) { config.SortBy = sortBy }))
+
+		resp := w.Result()
+		if contentType := resp.Header.Get("Content-Type"); contentType != "image/svg+xml" {
+			t.Errorf("Wanted an image/svg+xml Content-Type, Got: '%v'.", contentType)
+		}
+		if resp.Header.Get("X-Request-Id") == "" {
+			t.Error("Expected the request ID in the X-Request-Id header.")
+		}
+
+		body := w.Body.String()
+		elements := svgElements(t, body)
+		if elements["polyline"] < testVal.polyline {
+			t.Errorf("Expected the series to be drawn for '%v', Got: %v", testVal.query, body)
+		}
+		if !strings.Contains(body, testVal.title) || !strings.Contains(body, ">ppm</text>") {
+			t.Errorf("Expected the title '%v' and ppm units for '%v', Got: %v", testVal.title, testVal.query, body)
+		}
+		if strings.Contains(testVal.query, "trend=true") && !strings.Contains(body, "52 week average") {
+			t.Errorf("Expected a trend overlay for '%v', Got: %v", testVal.query, body)
+		}
+		if strings.Contains(testVal.query, "width=400") && !strings.Contains(body, `width="400" height="200"`) {
+			t.Errorf("Expected a 400x200 chart for '%v', Got: %v", testVal.query, body)
+		}
+	}
+}
+
+func TestCo2GetChartErrors(t *testing.T) {
+	testVals := []struct {
+		query   string
+		message string
+	}{
+		{"/v1/co2/weekly/chart.svg?width=10", "size must be an integer between 200 and 2000: width=[10]"},
+		{"/v1/co2/weekly/chart.svg?height=abc", "size must be an integer between 100 and 1200: height=[abc]"},
+		{"/v1/co2/weekly/chart.svg?theme=neon", "unknown theme. Allowed themes are: dark, light: theme=[neon]"},
+		{"/v1/co2/weekly/chart.svg?trend=maybe", "value must be a boolean: trend=[maybe]"},
+		{"/v1/co2/weekly/chart.svg?month=13", "month=[13]"},
+	}
+
+	for _, testVal := range testVals {
+		var resp models.ServerResp
+		w := runRequest(t, testVal.query, withHandler(GetChart), failing(), decodeInto(&resp))
+		if w.Code != 400 || resp.Error == nil || !strings.Contains(resp.Error.Message, testVal.message) {
+			t.Errorf("Wanted a 400 error containing '%v' for '%v', Got: %v %+v", testVal.message, testVal.query, w.Code, resp.Error)
+		}
+	}
+}
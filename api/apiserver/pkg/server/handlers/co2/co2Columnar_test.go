@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+// columnarRows returns the first three mock rows, the first two of which have no measurement from one year ago.
+func columnarRows() requestOption {
+	return expectQuery(`SELECT * FROM public.co2_weekly_mlo ORDER BY year,month,day LIMIT 3`, mockRows(mockColumns, GetMockCo2Rows()[:3]))
+}
+
+func TestCo2GetArrow(t *testing.T) {
+	w := runRequest(t, "/v1/co2/weekly?limit=3", withHeader("Accept", "application/vnd.apache.arrow.stream"), columnarRows())
+	resp := w.Result()
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/vnd.apache.arrow.stream" {
+		t.Errorf("Wanted an Arrow stream Content-Type, Got: '%v'.", contentType)
+	}
+
+	rdr, err := ipc.NewReader(resp.Body, ipc.WithAllocator(memory.DefaultAllocator))
+	if err != nil {
+		t.Fatalf("Response is not an Arrow IPC stream: %v", err)
+	}
+	defer rdr.Release()
+
+	wantFields := []arrow.Field{
+		{Name: "Year", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "Month", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "Day", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "DateDecimal", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+		{Name: "Average", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+		{Name: "NumDays", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "OneYearAgo", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+		{Name: "TenYearsAgo", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+		{Name: "IncSincePreIndustrial", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+		{Name: "Timestamp", Type: arrow.FixedWidthTypes.Date32},
+	}
+	fields := rdr.Schema().Fields()
+	if len(fields) != len(wantFields) {
+		t.Fatalf("Wanted %v fields, Got: %v.", len(wantFields), rdr.Schema())
+	}
+	for i, field := range fields {
+		if field.Name != wantFields[i].Name || !arrow.TypeEqual(field.Type, wantFields[i].Type) || field.Nullable != wantFields[i].Nullable {
+			t.Errorf("Wanted field '%v', Got: '%v'.", wantFields[i], field)
+		}
+	}
+
+	if !rdr.Next() {
+		t.Fatal("Expected a record batch in the Arrow stream.")
+	}
+	record := rdr.Record()
+	if record.NumRows() != 3 {
+		t.Fatalf("Wanted 3 rows, Got: %v.", record.NumRows())
+	}
+
+	data := GetMockCo2Rows()
+	oneYearAgo := record.Column(6).(*array.Float32)
+	for i := 0; i < 3; i++ {
+		if want := data[i].OneYearAgo == -999.99; oneYearAgo.IsNull(i) != want {
+			t.Errorf("Row %v: wanted OneYearAgo null to be %v.", i, want)
+		}
+	}
+	if got := record.Column(4).(*array.Float32).Value(2); got != float32(data[2].Average) {
+		t.Errorf("Wanted average '%v', Got: '%v'.", data[2].Average, got)
+	}
+	date := time.Unix(int64(record.Column(9).(*array.Date32).Value(0))*24*60*60, 0).UTC()
+	if !date.Equal(data[0].YYYYMMDD) {
+		t.Errorf("Wanted date '%v', Got: '%v'.", data[0].YYYYMMDD, date)
+	}
+}
+
+func TestCo2GetParquet(t *testing.T) {
+	w := runRequest(t, "/v1/co2/weekly?limit=3&format=parquet", columnarRows())
+	resp := w.Result()
+	if disposition := resp.Header.Get("Content-Disposition"); disposition != `attachment; filename="co2_weekly_mlo.parquet"` {
+		t.Errorf("Unexpected Content-Disposition, Got: '%v'.", disposition)
+	}
+
+	pf, err := buffer.NewBufferFile(w.Body.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := reader.NewParquetColumnReader(pf, 1)
+	if err != nil {
+		t.Fatalf("Response is not a Parquet file: %v", err)
+	}
+	defer pr.ReadStop()
+
+	if rows := pr.GetNumRows(); rows != 3 {
+		t.Fatalf("Wanted 3 rows, Got: %v.", rows)
+	}
+
+	// The first schema element is the root of the schema
+	elements := pr.SchemaHandler.SchemaElements[1:]
+	wantTypes := map[string]parquet.Type{"Year": parquet.Type_INT32, "Average": parquet.Type_FLOAT, "OneYearAgo": parquet.Type_FLOAT, "Timestamp": parquet.Type_INT32}
+	for _, el := range elements {
+		if want, ok := wantTypes[el.Name]; ok && el.GetType() != want {
+			t.Errorf("Wanted column '%v' of type '%v', Got: '%v'.", el.Name, want, el.GetType())
+		}
+	}
+	if el := elements[9]; el.GetConvertedType() != parquet.ConvertedType_DATE {
+		t.Errorf("Wanted column '%v' to be a date, Got: '%v'.", el.Name, el.GetConvertedType())
+	}
+	if el := elements[6]; el.GetRepetitionType() != parquet.FieldRepetitionType_OPTIONAL {
+		t.Errorf("Wanted column '%v' to be optional, Got: '%v'.", el.Name, el.GetRepetitionType())
+	}
+
+	data := GetMockCo2Rows()
+	values, _, _, err := pr.ReadColumnByIndex(6, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, val := range values {
+		if want := data[i].OneYearAgo == -999.99; (val == nil) != want {
+			t.Errorf("Row %v: wanted OneYearAgo null to be %v, Got: '%v'.", i, want, val)
+		}
+	}
+
+	dates, _, _, err := pr.ReadColumnByIndex(9, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int32(data[0].YYYYMMDD.Unix() / (24 * 60 * 60)); dates[0] != want {
+		t.Errorf("Wanted date '%v', Got: '%v'.", want, dates[0])
+	}
+}
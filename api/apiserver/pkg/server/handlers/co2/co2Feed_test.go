@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectFeedQueries mocks the lookups of a feed of the given rows, ingested at the given time.
+func expectFeedQueries(mock sqlmock.Sqlmock, ingested time.Time, data []mockCo2Row) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(yyyymmdd) FROM public.co2_weekly_mlo`)).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(data[0].YYYYMMDD))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(ingested_at) FROM public.ingest_log WHERE dataset = $1`)).
+		WithArgs("co2_weekly_mlo").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(ingested))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM public.co2_weekly_mlo ORDER BY year DESC,month DESC,day DESC LIMIT 20`)).
+		WillReturnRows(mockRows(mockColumns, data))
+}
+
+func TestCo2GetFeed(t *testing.T) {
+	ingested := time.Date(2024, 6, 12, 3, 0, 0, 0, time.UTC)
+	data := []mockCo2Row{
+		{Year: 2024, Month: 6, Day: 9, Average: -999.99, OneYearAgo: 423.68, YYYYMMDD: time.Date(2024, 6, 9, 0, 0, 0, 0, time.UTC)},
+		{Year: 2024, Month: 6, Day: 2, Average: 426.51, OneYearAgo: 423.56, YYYYMMDD: time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{Year: 2024, Month: 5, Day: 26, Average: 426.62, OneYearAgo: -999.99, YYYYMMDD: time.Date(2024, 5, 26, 0, 0, 0, 0, time.UTC)},
+	}
+
+	testVals := []struct {
+		path        string
+		contentType string
+		contains    []string
+	}{
+		{
+			"/v1/co2/feed.atom",
+			"application/atom+xml; charset=utf-8",
+			[]string{
+				`<feed xmlns="http://www.w3.org/2005/Atom">`,
+				`<id>tag:planetpulse.io,2021:co2_weekly_mlo</id>`,
+				`<updated>2024-06-12T03:00:00Z</updated>`,
+				`<link rel="self" type="application/atom+xml" href="https://api.planetpulse.io/v1/co2/feed.atom">`,
+				`<id>tag:planetpulse.io,2021:co2_weekly_mlo/20240602</id>`,
+				`<link rel="alternate" type="application/json" href="https://api.planetpulse.io/v1/co2/weekly?filter=yyyymmdd+%3D+%272024-06-02%27">`,
+				`<title>2024-06-02: 426.51 ppm</title>`,
+				`426.51 ppm for the period starting 2024-06-02, +2.95 ppm since last year.`,
+				`<title>2024-06-09: no measurement</title>`,
+				`426.62 ppm for the period starting 2024-05-26. No measurement was made a year earlier.`,
+			},
+		},
+		{
+			"/v1/co2/feed.rss",
+			"application/rss+xml; charset=utf-8",
+			[]string{
+				`<rss version="2.0">`,
+				`<lastBuildDate>Wed, 12 Jun 2024 03:00:00 +0000</lastBuildDate>`,
+				`<guid isPermaLink="false">tag:planetpulse.io,2021:co2_weekly_mlo/20240602</guid>`,
+				`<link>https://api.planetpulse.io/v1/co2/weekly?filter=yyyymmdd+%3D+%272024-06-02%27</link>`,
+				`<pubDate>Sun, 02 Jun 2024 00:00:00 +0000</pubDate>`,
+				`+2.95 ppm since last year.`,
+			},
+		},
+		{
+			"/v2/co2/feed.atom",
+			"application/atom+xml; charset=utf-8",
+			[]string{
+				`<link rel="self" type="application/atom+xml" href="https://api.planetpulse.io/v2/co2/feed.atom">`,
+				`<link rel="alternate" type="application/json" href="https://api.planetpulse.io/v2/co2/weekly">`,
+				`<link rel="alternate" type="application/json" href="https://api.planetpulse.io/v2/co2/weekly?filter=yyyymmdd+%3D+%272024-06-02%27">`,
+			},
+		},
+		{
+			"/v2/co2/feed.rss",
+			"application/rss+xml; charset=utf-8",
+			[]string{
+				`<link>https://api.planetpulse.io/v2/co2/weekly</link>`,
+				`<link>https://api.planetpulse.io/v2/co2/weekly?filter=yyyymmdd+%3D+%272024-06-02%27</link>`,
+			},
+		},
+	}
+
+	for _, testVal := range testVals {
+		w := runRequest(t, "http://api.planetpulse.io"+testVal.path, withHandler(GetFeed), withHeader("X-Forwarded-Proto", "https"),
+			withMock(func(mock sqlmock.Sqlmock) { expectFeedQueries(mock, ingested, data) }))
+
+		resp := w.Result()
+		if contentType := resp.Header.Get("Content-Type"); contentType != testVal.contentType {
+			t.Errorf("Wanted Content-Type '%v' for %v, Got: '%v'.", testVal.contentType, testVal.path, contentType)
+		}
+		if modified := resp.Header.Get("Last-Modified"); modified != ingested.Format(http.TimeFormat) {
+			t.Errorf("Wanted Last-Modified '%v', Got: '%v'.", ingested.Format(http.TimeFormat), modified)
+		}
+		if resp.Header.Get("ETag") == "" {
+			t.Errorf("Wanted an ETag for %v.", testVal.path)
+		}
+
+		body := w.Body.String()
+		for _, s := range testVal.contains {
+			if !strings.Contains(body, s) {
+				t.Errorf("Wanted the feed at %v to contain '%v', Got: %v", testVal.path, s, body)
+			}
+		}
+	}
+}
+
+func TestCo2GetFeedConditional(t *testing.T) {
+	ingested := time.Date(2024, 6, 12, 3, 0, 0, 0, time.UTC)
+	data := []mockCo2Row{
+		{Year: 2024, Month: 6, Day: 2, Average: 426.51, OneYearAgo: 423.56, YYYYMMDD: time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	get := func(header string, value string) *http.Response {
+		return runRequest(t, "/v1/co2/feed.atom", withHandler(GetFeed), withHeader(header, value),
+			withMock(func(mock sqlmock.Sqlmock) { expectFeedQueries(mock, ingested, data) })).Result()
+	}
+
+	etag := get("", "").Header.Get("ETag")
+	testVals := []struct {
+		header string
+		value  string
+		code   int
+	}{
+		{"If-None-Match", etag, 304},
+		{"If-None-Match", `W/"stale", ` + etag, 304},
+		{"If-None-Match", `"stale"`, 200},
+		{"If-Modified-Since", ingested.Format(http.TimeFormat), 304},
+		{"If-Modified-Since", ingested.Add(-time.Hour).Format(http.TimeFormat), 200},
+	}
+
+	for _, testVal := range testVals {
+		resp := get(testVal.header, testVal.value)
+		if resp.StatusCode != testVal.code {
+			t.Errorf("Wanted status %v for %v: %v, Got: %v", testVal.code, testVal.header, testVal.value, resp.StatusCode)
+		}
+		if resp.Header.Get("ETag") != etag {
+			t.Errorf("Wanted the ETag to be stable, Got: '%v' and '%v'", etag, resp.Header.Get("ETag"))
+		}
+	}
+}
+
+func TestCo2GetFeedParams(t *testing.T) {
+	w := runRequest(t, "/v1/co2/feed.rss?limit=5", withHandler(GetFeed), failing())
+	if w.Code != 400 {
+		t.Errorf("Wanted a 400 error for a feed with query parameters, Got: %v", w.Code)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/server/handlers"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// runFieldsTest requests query from a mock database expecting sqlString to select the given columns of the first two
+// mock rows. It returns the keys of each object in the response results, in the order they were encoded.
+func runFieldsTest(t *testing.T, query string, sqlString string, columns []string) [][]string {
+	resp := struct{ Results []json.RawMessage }{}
+	runRequest(t, query, expectQuery(sqlString, mockRows(columns, GetMockCo2Rows()[:2])), decodeInto(&resp))
+
+	var keys [][]string
+	for _, raw := range resp.Results {
+		dec := json.NewDecoder(strings.NewReader(string(raw)))
+		dec.Token() // Opening brace
+		var entry []string
+		for dec.More() {
+			key, _ := dec.Token()
+			entry = append(entry, key.(string))
+			var skip interface{}
+			dec.Decode(&skip)
+		}
+		keys = append(keys, entry)
+	}
+	return keys
+}
+
+func compareKeys(t *testing.T, got [][]string, want []string) {
+	if len(got) == 0 {
+		t.Fatal("No results returned from query.")
+	}
+	for _, keys := range got {
+		if strings.Join(keys, ",") != strings.Join(want, ",") {
+			t.Errorf("Wanted fields '%v', Got: '%v'.", want, keys)
+		}
+	}
+}
+
+func TestCo2GetFields(t *testing.T) {
+	sqlString := `SELECT year, month, average, one_year_ago FROM public.co2_weekly_mlo ORDER BY year,month,day LIMIT 10`
+	query := "/v1/co2/weekly?fields=year,month,average,one_year_ago"
+
+	keys := runFieldsTest(t, query, sqlString, []string{"year", "month", "average", "one_year_ago"})
+	compareKeys(t, keys, []string{"Year", "Month", "Average", "OneYearAgo"})
+}
+
+func TestCo2GetFieldsOrder(t *testing.T) {
+	sqlString := `SELECT yyyymmdd, average FROM public.co2_weekly_mlo ORDER BY year,month,day LIMIT 10`
+	query := "/v1/co2/weekly?fields=yyyymmdd&fields=average"
+
+	keys := runFieldsTest(t, query, sqlString, []string{"yyyymmdd", "average"})
+	compareKeys(t, keys, []string{"Timestamp", "Average"})
+}
+
+func TestCo2GetSimple(t *testing.T) {
+	sqlString := `SELECT year, month, day, average, increase_since_1800 FROM public.co2_weekly_mlo ORDER BY year,month,day LIMIT 10`
+	query := "/v1/co2/weekly?simple=true"
+
+	keys := runFieldsTest(t, query, sqlString, []string{"year", "month", "day", "average", "increase_since_1800"})
+	compareKeys(t, keys, []string{"Year", "Month", "Day", "Average", "IncSincePreIndustrial"})
+}
+
+func TestCo2GetSimpleFields(t *testing.T) {
+	sqlString := `SELECT average FROM public.co2_weekly_mlo ORDER BY year,month,day LIMIT 10`
+	query := "/v1/co2/weekly?simple=true&fields=average"
+
+	keys := runFieldsTest(t, query, sqlString, []string{"average"})
+	compareKeys(t, keys, []string{"Average"})
+}
+
+func TestCo2FieldsErrors(t *testing.T) {
+	testVals := []string{
+		"/v1/co2/weekly?fields=ppm",
+		"/v1/co2/weekly?fields=",
+		"/v1/co2/weekly?fields=year,year",
+		"/v1/co2/weekly/400.1?fields=Average",
+	}
+
+	for _, query := range testVals {
+		pathParam := strings.Contains(query, "400.1")
+		var resp models.ServerResp
+		w := runRequest(t, query, failing(), decodeInto(&resp), withConfig(func(config *handlers.ApiHandlerConfig) { config.PathParam = pathParam }))
+		if w.Code != 400 {
+			t.Errorf("Response status code '%v' does not match expected code '400'.", w.Code)
+		}
+		if strings.Contains(query, "ppm") && (resp.Error == nil || !strings.Contains(resp.Error.Message, "one_year_ago")) {
+			t.Errorf("Expected the allowed field names in the error message, Got: '%+v'.", resp.Error)
+		}
+	}
+}
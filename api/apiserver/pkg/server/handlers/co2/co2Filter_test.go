@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"apiserver/pkg/database/models"
+	"database/sql/driver"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCo2GetFilter(t *testing.T) {
+	testVals := []struct {
+		query     string
+		sqlString string
+		args      []driver.Value
+	}{
+		{
+			"/v1/co2/weekly?filter=" + url.QueryEscape("average >= 410 and (month in (5,6) or ndays < 5)"),
+			`SELECT * FROM public.co2_weekly_mlo WHERE (average >= $1 AND (month IN ($2, $3) OR ndays < $4)) ORDER BY year,month,day LIMIT 10`,
+			[]driver.Value{410.0, int64(5), int64(6), int64(5)},
+		},
+		{
+			"/v1/co2/weekly?year=2020&filter=" + url.QueryEscape("not month in (1,2)") + "&filter=" + url.QueryEscape("average > 400"),
+			`SELECT * FROM public.co2_weekly_mlo WHERE year in ('2020') AND NOT month IN ($1, $2) AND average > $3 ORDER BY year,month,day LIMIT 10`,
+			[]driver.Value{int64(1), int64(2), 400.0},
+		},
+	}
+
+	for _, testVal := range testVals {
+		runRequest(t, testVal.query, expectQuery(testVal.sqlString, sqlmock.NewRows([]string{"year"}), testVal.args...))
+	}
+}
+
+func TestCo2FilterErrors(t *testing.T) {
+	testVals := []struct {
+		filter  string
+		message string
+	}{
+		{"average >= 410 and (month = 5", "position 30"},
+		{"ppm > 400", "one_year_ago"},
+		{"year = 'recent'", "position 8"},
+		{"average > 400; DROP TABLE co2_weekly_mlo", "position 14"},
+	}
+
+	for _, testVal := range testVals {
+		query := "/v1/co2/weekly?filter=" + url.QueryEscape(testVal.filter)
+		var resp models.ServerResp
+		w := runRequest(t, query, failing(), decodeInto(&resp))
+		if w.Code != 400 {
+			t.Errorf("Response status code '%v' does not match expected code '400'.", w.Code)
+		}
+		if resp.Error == nil || !strings.Contains(resp.Error.Message, testVal.message) {
+			t.Errorf("Expected '%v' in the error message, Got: '%+v'.", testVal.message, resp.Error)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCo2GetCsv(t *testing.T) {
+	sqlString := `SELECT * FROM public.co2_weekly_mlo ORDER BY year,month,day LIMIT 2`
+	columns := []string{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd"}
+
+	for _, testVal := range []struct{ query, accept string }{
+		{"/v1/co2/weekly?limit=2&format=csv", ""},
+		{"/v1/co2/weekly?limit=2", "text/csv"},
+		{"/v1/co2/weekly?limit=2", "application/json;q=0.5, text/csv"},
+	} {
+		w := runRequest(t, testVal.query, withHeader("Accept", testVal.accept), expectQuery(sqlString, mockRows(columns, GetMockCo2Rows()[:2])))
+		resp := w.Result()
+
+		want := "Year,Month,Day,DateDecimal,Average,NumDays,OneYearAgo,TenYearsAgo,IncSincePreIndustrial,Timestamp\n" +
+			"1974,5,19,1974.3795,333.37,5,,,50.4,1974-05-19\n" +
+			"1974,5,26,1974.3986,332.95,6,,,50.06,1974-05-26\n"
+		if body := w.Body.String(); body != want {
+			t.Errorf("Wanted CSV body:\n%v\nGot:\n%v", want, body)
+		}
+		if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/csv") {
+			t.Errorf("Wanted a text/csv Content-Type, Got: '%v'.", contentType)
+		}
+		if disposition := resp.Header.Get("Content-Disposition"); disposition != `attachment; filename="co2_weekly_mlo.csv"` {
+			t.Errorf("Unexpected Content-Disposition, Got: '%v'.", disposition)
+		}
+		if resp.Header.Get("X-Request-Id") == "" {
+			t.Error("Expected the request ID in the X-Request-Id header.")
+		}
+		if link := resp.Header.Get("Link"); !regexp.MustCompile(`page=2[^>]*>; rel="next"`).MatchString(link) {
+			t.Errorf("Expected a link to the next page, Got: '%v'.", link)
+		}
+	}
+}
+
+func TestCo2GetTsvSimple(t *testing.T) {
+	sqlString := `SELECT year, month, day, average, increase_since_1800 FROM public.co2_weekly_mlo ORDER BY year,month,day LIMIT 2 OFFSET 2`
+	columns := []string{"year", "month", "day", "average", "increase_since_1800"}
+
+	w := runRequest(t, "/v1/co2/weekly?limit=2&page=2&simple=true", withHeader("Accept", "text/tab-separated-values"), expectQuery(sqlString, mockRows(columns, GetMockCo2Rows()[:2])))
+	resp := w.Result()
+
+	want := "Year\tMonth\tDay\tAverage\tIncSincePreIndustrial\n" +
+		"1974\t5\t19\t333.37\t50.4\n" +
+		"1974\t5\t26\t332.95\t50.06\n"
+	if body := w.Body.String(); body != want {
+		t.Errorf("Wanted TSV body:\n%v\nGot:\n%v", want, body)
+	}
+	if disposition := resp.Header.Get("Content-Disposition"); disposition != `attachment; filename="co2_weekly_mlo.tsv"` {
+		t.Errorf("Unexpected Content-Disposition, Got: '%v'.", disposition)
+	}
+
+	link := resp.Header.Get("Link")
+	for _, rel := range []string{`page=1[^>]*>; rel="first"`, `page=1[^>]*>; rel="prev"`, `page=3[^>]*>; rel="next"`} {
+		if !regexp.MustCompile(rel).MatchString(link) {
+			t.Errorf("Expected '%v' in the Link header, Got: '%v'.", rel, link)
+		}
+	}
+}
+
+func TestCo2GetJsonDefault(t *testing.T) {
+	sqlString := `SELECT * FROM public.co2_weekly_mlo ORDER BY year,month,day LIMIT 10`
+	columns := []string{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd"}
+
+	for _, accept := range []string{"", "text/html,application/xhtml+xml,*/*;q=0.8", "text/csv;q=0, application/json"} {
+		w := runRequest(t, "/v1/co2/weekly", withHeader("Accept", accept), expectQuery(sqlString, mockRows(columns, GetMockCo2Rows()[:2])))
+		resp := w.Result()
+
+		if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+			t.Errorf("Wanted an application/json Content-Type for Accept '%v', Got: '%v'.", accept, contentType)
+		}
+		if link := resp.Header.Get("Link"); link != "" {
+			t.Errorf("Expected no Link header for a partial page, Got: '%v'.", link)
+		}
+	}
+}
+
+func TestCo2FormatErrors(t *testing.T) {
+	testVals := []string{
+		"/v1/co2/weekly?format=xml",
+		"/v1/co2/weekly?format=csv&year=2020a",
+	}
+
+	for _, query := range testVals {
+		resp := runRequest(t, query, failing()).Result()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != 400 {
+			t.Errorf("Response status code '%v' does not match expected code '400'.", resp.StatusCode)
+		}
+		if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+			t.Errorf("Errors should be returned as JSON, Got Content-Type: '%v'.", contentType)
+		}
+		if resp.Header.Get("Content-Disposition") != "" {
+			t.Error("Errors should not be returned as attachments.")
+		}
+		if !json.Valid(body) {
+			t.Errorf("Errors should be returned as JSON, Got: '%s'.", body)
+		}
+	}
+}
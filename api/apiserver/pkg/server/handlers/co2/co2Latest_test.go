@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"apiserver/pkg/database/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+// latestResp mirrors models.ServerResp with the Results decoded as models.Latest values.
+type latestResp struct {
+	Results []struct {
+		Latest      map[string]interface{}
+		OneYearAgo  map[string]interface{}
+		TenYearsAgo map[string]interface{}
+		Freshness   models.Freshness
+	}
+	Status string
+}
+
+func TestCo2GetLatest(t *testing.T) {
+	data := GetMockCo2Rows()
+	latest, yearAgo := data[9], data[8]
+	ingested := time.Date(2020, time.Month(5), 30, 6, 0, 0, 0, time.UTC)
+
+	// Move the year ago measurement into the same week of the previous year
+	yearAgo.YYYYMMDD = time.Date(2019, time.Month(5), 26, 0, 0, 0, 0, time.UTC)
+
+	lookup := withMock(func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(yyyymmdd) FROM public.co2_weekly_mlo`)).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(latest.YYYYMMDD))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(ingested_at) FROM public.ingest_log WHERE dataset = $1`)).
+			WithArgs("co2_weekly_mlo").
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(ingested))
+	})
+
+	resp := latestResp{}
+	runRequest(t, "/v1/co2/latest", withHandler(GetLatest), lookup, decodeInto(&resp),
+		expectQuery(`SELECT * FROM public.co2_weekly_mlo WHERE yyyymmdd BETWEEN '2020-05-21' AND '2020-05-27' ORDER BY year,month,day LIMIT 1`, mockRows(mockColumns, []mockCo2Row{latest})),
+		expectQuery(`SELECT * FROM public.co2_weekly_mlo WHERE yyyymmdd BETWEEN '2019-05-21' AND '2019-05-27' ORDER BY year,month,day LIMIT 1`, mockRows(mockColumns, []mockCo2Row{yearAgo})),
+		expectQuery(`SELECT * FROM public.co2_weekly_mlo WHERE yyyymmdd BETWEEN '2010-05-21' AND '2010-05-27' ORDER BY year,month,day LIMIT 1`, mockRows(mockColumns, nil)))
+
+	if len(resp.Results) != 1 {
+		t.Fatalf("Expected exactly one result, got %v.", len(resp.Results))
+	}
+
+	result := resp.Results[0]
+	if average, _ := result.Latest["Average"].(float64); float32(average) != latest.Average {
+		t.Errorf("Wanted latest average '%v', Got: '%v'.", latest.Average, result.Latest["Average"])
+	}
+	if average, _ := result.OneYearAgo["Average"].(float64); float32(average) != yearAgo.Average {
+		t.Errorf("Wanted one year ago average '%v', Got: '%v'.", yearAgo.Average, result.OneYearAgo["Average"])
+	}
+	if result.TenYearsAgo != nil {
+		t.Errorf("Wanted no measurement ten years ago, Got: '%v'.", result.TenYearsAgo)
+	}
+	if !result.Freshness.Observed.Equal(latest.YYYYMMDD) || result.Freshness.Cadence != models.Weekly {
+		t.Errorf("Freshness does not describe the latest weekly measurement: %+v", result.Freshness)
+	}
+	if result.Freshness.Ingested == nil || !result.Freshness.Ingested.Equal(ingested) {
+		t.Errorf("Wanted ingestion time '%v', Got: '%v'.", ingested, result.Freshness.Ingested)
+	}
+	if !result.Freshness.Stale {
+		t.Error("A measurement from 2020 should be reported as stale.")
+	}
+}
+
+func TestCo2GetLatestNoIngestLog(t *testing.T) {
+	v := GetMockCo2Rows()[9]
+	resp := latestResp{}
+	runRequest(t, "/v1/co2/latest", withHandler(GetLatest), decodeInto(&resp), withMock(func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(yyyymmdd)`)).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(v.YYYYMMDD))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(ingested_at)`)).
+			WillReturnError(&pq.Error{Code: "42P01", Message: `relation "public.ingest_log" does not exist`})
+		mock.ExpectQuery(`SELECT .* LIMIT 1`).WillReturnRows(mockRows(mockColumns, []mockCo2Row{v}))
+		mock.ExpectQuery(`SELECT .* LIMIT 1`).WillReturnRows(sqlmock.NewRows(nil))
+		mock.ExpectQuery(`SELECT .* LIMIT 1`).WillReturnRows(sqlmock.NewRows(nil))
+	}))
+
+	if resp.Results[0].Freshness.Ingested != nil {
+		t.Errorf("Wanted no ingestion time without an ingest log, Got: '%v'.", resp.Results[0].Freshness.Ingested)
+	}
+}
+
+func TestCo2GetLatestEmpty(t *testing.T) {
+	w := runRequest(t, "/v1/co2/latest", withHandler(GetLatest), failing(), withMock(func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(yyyymmdd)`)).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+	}))
+	if w.Code != 404 {
+		t.Errorf("Response status code '%v' does not match expected code '404'.", w.Code)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/utils"
+	"encoding/json"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func TestCo2Problem(t *testing.T) {
+	testVals := []struct {
+		query  string
+		status int
+		code   string
+		param  string
+	}{
+		{"/v1/co2/weekly?gte=1001", 400, utils.CodeOutOfRange, "gte"},
+		{"/v1/co2/weekly?gte=abc", 400, utils.CodeInvalidParam, "gte"},
+		{"/v1/co2/weekly?year=3001", 400, utils.CodeOutOfRange, "year"},
+		{"/v1/co2/weekly?month=1..x", 400, utils.CodeInvalidParam, "month"},
+		{"/v1/co2/weekly?limit=10001", 400, utils.CodeOutOfRange, "limit"},
+		{"/v1/co2/weekly?pretty=maybe", 400, utils.CodeInvalidParam, "pretty"},
+	}
+
+	for _, testVal := range testVals {
+		w := runRequest(t, testVal.query, failing(), withHeader("Accept", "application/problem+json"))
+		if w.Code != testVal.status {
+			t.Errorf("Wanted status %v for %v, Got: %v", testVal.status, testVal.query, w.Code)
+		}
+		if got := w.Header().Get("Content-Type"); got != "application/problem+json; charset=utf-8" {
+			t.Errorf("Wanted problem details for %v, Got: '%v'", testVal.query, got)
+		}
+
+		var problem models.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("Cannot decode problem details: %v", err)
+		}
+		if problem.Code != testVal.code || problem.Param != testVal.param || problem.Status != testVal.status {
+			t.Errorf("Wanted code '%v' for parameter '%v' from %v, Got: %+v", testVal.code, testVal.param, testVal.query, problem)
+		}
+		if problem.Type != "/docs#"+testVal.code || problem.Instance != "/v1/co2/weekly" || problem.Detail == "" || problem.RequestId == "" {
+			t.Errorf("Wanted complete problem details from %v, Got: %+v", testVal.query, problem)
+		}
+	}
+}
+
+func TestCo2ProblemNotAccepted(t *testing.T) {
+	// The envelope stays the default, including for clients accepting anything
+	for _, accept := range []string{"", "application/json", "*/*", "application/problem+json;q=0"} {
+		w := runRequest(t, "/v1/co2/weekly?gte=1001", failing(), withHeader("Accept", accept))
+		if got := w.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+			t.Errorf("Wanted the envelope for 'Accept: %v', Got: '%v'", accept, got)
+		}
+
+		var resp models.ServerResp
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Cannot decode the envelope: %v", err)
+		}
+		if resp.Status != "ERROR" || resp.Error == nil || resp.Error.Description != "400 - Bad Request" {
+			t.Errorf("Wanted an error envelope for 'Accept: %v', Got: %+v", accept, resp)
+		}
+	}
+}
+
+func TestCo2ProblemDatabase(t *testing.T) {
+	testVals := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{&pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"}, 504, utils.CodeQueryTimeout},
+		{&pq.Error{Code: "08006", Message: "connection failure"}, 503, utils.CodeDbUnavailable},
+		{&pq.Error{Code: "42601", Message: "syntax error"}, 500, utils.CodeInternal},
+	}
+
+	for _, testVal := range testVals {
+		fail := func(mock sqlmock.Sqlmock) { mock.ExpectQuery("SELECT").WillReturnError(testVal.err) }
+		w := runRequest(t, "/v1/co2/weekly", failing(), withHeader("Accept", "application/problem+json"), withMock(fail))
+
+		var problem models.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("Cannot decode problem details: %v", err)
+		}
+		if w.Code != testVal.status || problem.Code != testVal.code || problem.Param != "" {
+			t.Errorf("Wanted status %v and code '%v' for '%v', Got: %v %+v", testVal.status, testVal.code, testVal.err, w.Code, problem)
+		}
+	}
+}
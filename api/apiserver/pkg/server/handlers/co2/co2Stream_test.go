@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"apiserver/pkg/database/models"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCo2GetNdjson(t *testing.T) {
+	testVals := []struct {
+		query  string
+		accept string
+		sql    string
+	}{
+		{"/v1/co2/weekly?limit=all", "application/x-ndjson", `SELECT * FROM public.co2_weekly_mlo ORDER BY year,month,day`},
+		{"/v1/co2/weekly?limit=all&format=ndjson&year=1974..", "", `SELECT * FROM public.co2_weekly_mlo WHERE year >= '1974' ORDER BY year,month,day`},
+	}
+
+	data := GetMockCo2Rows()
+	for _, testVal := range testVals {
+		sqlString := "^" + regexp.QuoteMeta(testVal.sql) + "$"
+		w := runRequest(t, testVal.query, withHeader("Accept", testVal.accept), withMock(func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(sqlString).WillReturnRows(mockRows(mockColumns, data))
+		}))
+
+		resp := w.Result()
+		if contentType := resp.Header.Get("Content-Type"); contentType != "application/x-ndjson" {
+			t.Errorf("Wanted an application/x-ndjson Content-Type, Got: '%v'.", contentType)
+		}
+		if resp.Header.Get("X-Request-Id") == "" {
+			t.Error("Expected the request ID in the X-Request-Id header.")
+		}
+		if count := resp.Trailer.Get("X-Result-Count"); count != fmt.Sprint(len(data)) {
+			t.Errorf("Wanted X-Result-Count trailer '%v', Got: '%v'.", len(data), count)
+		}
+
+		// Each line holds a single measurement without an envelope
+		scanner := bufio.NewScanner(resp.Body)
+		lines := 0
+		for scanner.Scan() {
+			entry := make(map[string]interface{})
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				t.Fatalf("Line %v is not a JSON object: %v", lines+1, err)
+			}
+			if _, ok := entry["Results"]; ok {
+				t.Error("Streamed rows should not be wrapped in an envelope.")
+			}
+			if entry["Year"] != float64(data[lines].Year) {
+				t.Errorf("Wanted year '%v' on line %v, Got: '%v'.", data[lines].Year, lines+1, entry["Year"])
+			}
+			lines++
+		}
+		if lines != len(data) {
+			t.Errorf("Wanted %v lines, Got: %v.", len(data), lines)
+		}
+	}
+}
+
+func TestCo2GetNdjsonFields(t *testing.T) {
+	sqlString := `SELECT average, year FROM public.co2_weekly_mlo ORDER BY year,month,day LIMIT 3 OFFSET 3`
+	rows := mockRows([]string{"average", "year"}, GetMockCo2Rows()[:3])
+	w := runRequest(t, "/v1/co2/weekly?fields=average,year&limit=3&page=2", withHeader("Accept", "application/x-ndjson"), expectQuery(sqlString, rows))
+
+	want := "{\"Average\":333.37,\"Year\":1974}\n{\"Average\":332.95,\"Year\":1974}\n{\"Average\":344.19,\"Year\":1984}\n"
+	if body := w.Body.String(); body != want {
+		t.Errorf("Wanted body:\n%v\nGot:\n%v", want, body)
+	}
+
+	resp := w.Result()
+	if page := resp.Header.Get("X-Page"); page != "2" {
+		t.Errorf("Wanted X-Page header '2', Got: '%v'.", page)
+	}
+	if limit := resp.Header.Get("X-Limit"); limit != "3" {
+		t.Errorf("Wanted X-Limit header '3', Got: '%v'.", limit)
+	}
+	if link := resp.Header.Get("Link"); !regexp.MustCompile(`page=1[^>]*>; rel="prev"`).MatchString(link) {
+		t.Errorf("Expected a link to the previous page, Got: '%v'.", link)
+	}
+}
+
+func TestCo2NdjsonErrors(t *testing.T) {
+	testVals := []struct {
+		query  string
+		accept string
+		code   int
+	}{
+		{"/v1/co2/weekly?limit=all", "", 400},
+		{"/v1/co2/weekly?limit=all", "text/csv", 400},
+		{"/v1/co2/weekly?limit=some", "application/x-ndjson", 400},
+		{"/v1/co2/weekly?limit=all", "application/x-ndjson", 500},
+	}
+
+	for _, testVal := range testVals {
+		opts := []requestOption{failing(), withHeader("Accept", testVal.accept)}
+		if testVal.code == 500 {
+			opts = append(opts, withMock(func(mock sqlmock.Sqlmock) { mock.ExpectQuery(".*").WillReturnError(fmt.Errorf("connection reset")) }))
+		}
+		// Nothing is written before an error is returned, so the response holds the error alone
+		var resp models.ServerResp
+		w := runRequest(t, testVal.query, append(opts, decodeInto(&resp))...)
+		if w.Code != testVal.code {
+			t.Errorf("Response status code '%v' does not match expected code '%v'.", w.Code, testVal.code)
+		}
+		if resp.Status != "ERROR" || len(resp.Results) != 0 {
+			t.Errorf("Wanted only an error for query '%v' with Accept '%v', Got: '%v'.", testVal.query, testVal.accept, w.Body.String())
+		}
+	}
+}
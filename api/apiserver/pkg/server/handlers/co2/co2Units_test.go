@@ -0,0 +1,219 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/server/handlers"
+	"apiserver/pkg/utils"
+	"database/sql/driver"
+	"encoding/json"
+	"math"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// unitsRows returns a single measurement of 400 ppm one year after a missing measurement.
+func unitsRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd"}).
+		AddRow(2020, 5, 3, 2020.3347, 400, 6, -999.99, 390.5, 120.25, time.Date(2020, 5, 3, 0, 0, 0, 0, time.UTC))
+}
+
+// runUnitsTest requests query from a mock database expecting sqlString with args, which returns rows. It returns the
+// single measurement and the units of the response.
+func runUnitsTest(t *testing.T, query string, sqlString string, args []driver.Value, rows *sqlmock.Rows, conditions models.Conditions) (map[string]interface{}, map[string]interface{}) {
+	resp := struct {
+		Results  []map[string]interface{}
+		Metadata struct{ Units map[string]interface{} }
+	}{}
+	runRequest(t, query, expectQuery(sqlString, rows, args...), withConfig(func(config *handlers.ApiHandlerConfig) { config.Conditions = conditions }), decodeInto(&resp))
+
+	if len(resp.Results) != 1 {
+		t.Fatalf("Wanted a single measurement from '%v', Got: %v.", query, resp.Results)
+	}
+	return resp.Results[0], resp.Metadata.Units
+}
+
+func TestCo2Units(t *testing.T) {
+	testVals := []struct {
+		units      string
+		conditions models.Conditions
+		average    float64
+		increase   float64
+	}{
+		{"ppm", models.Conditions{}, 400, 120.25},
+		{"ppb", models.Conditions{}, 400000, 120250},
+		{"GtC", models.Conditions{}, 849.6, 255.411},
+		{"ug/m3", models.Conditions{}, 400 * 44.01 * 101325 / (8.314462618 * 273.15), 120.25 * 44.01 * 101325 / (8.314462618 * 273.15)},
+		{"ug/m3", models.Conditions{Temperature: 298.15, Pressure: 100000}, 400 * 44.01 * 100000 / (8.314462618 * 298.15), 120.25 * 44.01 * 100000 / (8.314462618 * 298.15)},
+	}
+
+	for _, testVal := range testVals {
+		query := "/v1/co2/weekly?units=" + url.QueryEscape(testVal.units)
+		result, units := runUnitsTest(t, query, `SELECT * FROM public.co2_weekly_mlo ORDER BY year,month,day LIMIT 10`, nil, unitsRows(), testVal.conditions)
+
+		for field, want := range map[string]float64{"Average": testVal.average, "IncSincePreIndustrial": testVal.increase, "OneYearAgo": -999.99, "DateDecimal": 2020.3347} {
+			if got, _ := result[field].(float64); math.Abs(got-want) > math.Abs(want)*1e-6 {
+				t.Errorf("Wanted %v of %v from '%v', Got: %v.", field, want, query, result[field])
+			}
+		}
+		want := map[string]interface{}{"DateDecimal": "year", "Average": testVal.units, "NumDays": "days", "OneYearAgo": testVal.units, "TenYearsAgo": testVal.units, "IncSincePreIndustrial": testVal.units}
+		for field, unit := range want {
+			if units[field] != unit {
+				t.Errorf("Wanted %v to be reported in %v for '%v', Got: %v.", field, unit, query, units)
+			}
+		}
+	}
+}
+
+func TestCo2UnitsFields(t *testing.T) {
+	result, units := runUnitsTest(t, "/v1/co2/weekly?units=ppb&fields=year,average", `SELECT year, average FROM public.co2_weekly_mlo ORDER BY year,month,day LIMIT 10`, nil, sqlmock.NewRows([]string{"year", "average"}).AddRow(2020, 400), models.Conditions{})
+
+	if result["Average"] != 400000.0 || result["Year"] != 2020.0 {
+		t.Errorf("Wanted the requested fields converted to ppb, Got: %v.", result)
+	}
+	if len(units) != 1 || units["Average"] != "ppb" {
+		t.Errorf("Wanted the unit of the requested fields only, Got: %v.", units)
+	}
+}
+
+func TestCo2UnitsFilters(t *testing.T) {
+	testVals := []struct {
+		query     string
+		sqlString string
+		args      []driver.Value
+	}{
+		{
+			"/v1/co2/weekly?units=ppb&filter_units=requested&gt=415000&lte=420000",
+			`SELECT * FROM public.co2_weekly_mlo WHERE average > 415 AND average <= 420 ORDER BY year,month,day LIMIT 10`,
+			nil,
+		},
+		{
+			"/v1/co2/weekly?units=ppb&filter_units=canonical&gt=415",
+			`SELECT * FROM public.co2_weekly_mlo WHERE average > 415.00 ORDER BY year,month,day LIMIT 10`,
+			nil,
+		},
+		{
+			"/v1/co2/weekly?units=GtC&filter_units=requested&filter=" + url.QueryEscape("average >= 849.6 and ndays > 5"),
+			`SELECT * FROM public.co2_weekly_mlo WHERE (average >= $1 AND ndays > $2) ORDER BY year,month,day LIMIT 10`,
+			[]driver.Value{849.6 / 2.124, int64(5)},
+		},
+		{
+			"/v1/co2/weekly?units=ppm&filter_units=requested&gt=415",
+			`SELECT * FROM public.co2_weekly_mlo WHERE average > 415.00 ORDER BY year,month,day LIMIT 10`,
+			nil,
+		},
+	}
+
+	for _, testVal := range testVals {
+		runUnitsTest(t, testVal.query, testVal.sqlString, testVal.args, unitsRows(), models.Conditions{})
+	}
+}
+
+// Bounds converted from another unit are compared at full precision. Rounded to hundredths of a ppm, a bound of
+// 415005 ppb would become 415.01 ppm, and leave out a measurement of 415.008 ppm that the same filter expression keeps.
+func TestCo2UnitsFilterPrecision(t *testing.T) {
+	rows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"year", "average"}).AddRow(2020, 415.008)
+	}
+	testVals := []struct {
+		query     string
+		sqlString string
+		args      []driver.Value
+	}{
+		{
+			"/v1/co2/weekly?units=ppb&filter_units=requested&fields=year,average&gt=415005",
+			`SELECT year, average FROM public.co2_weekly_mlo WHERE average > 415.005 ORDER BY year,month,day LIMIT 10`,
+			nil,
+		},
+		{
+			"/v1/co2/weekly?units=ppb&filter_units=requested&fields=year,average&filter=" + url.QueryEscape("average > 415005"),
+			`SELECT year, average FROM public.co2_weekly_mlo WHERE average > $1 ORDER BY year,month,day LIMIT 10`,
+			[]driver.Value{415.005},
+		},
+		{
+			"/v1/co2/weekly?units=GtC&filter_units=requested&fields=year,average&gte=881.47",
+			`SELECT year, average FROM public.co2_weekly_mlo WHERE average >= 415.0047080979284 ORDER BY year,month,day LIMIT 10`,
+			nil,
+		},
+	}
+
+	for _, testVal := range testVals {
+		runUnitsTest(t, testVal.query, testVal.sqlString, testVal.args, rows(), models.Conditions{})
+	}
+}
+
+func TestCo2UnitsErrors(t *testing.T) {
+	testVals := []struct {
+		query string
+		code  string
+		param string
+	}{
+		{"/v1/co2/weekly?units=kg", utils.CodeInvalidParam, "units"},
+		{"/v1/co2/weekly?units=ppb&gt=415", utils.CodeInvalidParam, "gt"},
+		{"/v1/co2/weekly?units=ppb&filter=" + url.QueryEscape("average > 415"), utils.CodeInvalidParam, "filter"},
+		{"/v1/co2/weekly?units=ppb&filter_units=both&gt=415", utils.CodeInvalidParam, "filter_units"},
+		{"/v1/co2/weekly?units=ppb&filter_units=requested&gt=1000001", utils.CodeOutOfRange, "gt"},
+		{"/v1/co2/weekly?units=ppb&filter_units=canonical&gt=1001", utils.CodeOutOfRange, "gt"},
+	}
+
+	for _, testVal := range testVals {
+		w := runRequest(t, testVal.query, failing(), withHeader("Accept", "application/problem+json"))
+
+		var problem models.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("Cannot decode problem details: %v", err)
+		}
+		if problem.Status != 400 || problem.Code != testVal.code || problem.Param != testVal.param {
+			t.Errorf("Wanted code '%v' for parameter '%v' from %v, Got: %+v", testVal.code, testVal.param, testVal.query, problem)
+		}
+	}
+}
+
+func TestCo2UnitsLatest(t *testing.T) {
+	resp := struct {
+		Results  []struct{ Latest map[string]interface{} }
+		Metadata struct{ Units map[string]interface{} }
+	}{}
+	runRequest(t, "/v1/co2/latest?units=ppb", withHandler(GetLatest), decodeInto(&resp), withMock(func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(yyyymmdd) FROM public.co2_weekly_mlo`)).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(time.Date(2020, 5, 3, 0, 0, 0, 0, time.UTC)))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT max(ingested_at) FROM public.ingest_log WHERE dataset = $1`)).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+		mock.ExpectQuery(`SELECT \* FROM public.co2_weekly_mlo WHERE yyyymmdd BETWEEN '2020-04-30'`).WillReturnRows(unitsRows())
+		mock.ExpectQuery(`SELECT \* FROM public.co2_weekly_mlo WHERE yyyymmdd BETWEEN '2019-04-30'`).WillReturnRows(sqlmock.NewRows(nil))
+		mock.ExpectQuery(`SELECT \* FROM public.co2_weekly_mlo WHERE yyyymmdd BETWEEN '2010-04-30'`).WillReturnRows(sqlmock.NewRows(nil))
+	}))
+
+	if len(resp.Results) != 1 || resp.Results[0].Latest["Average"] != 400000.0 || resp.Results[0].Latest["OneYearAgo"] != -999.99 {
+		t.Errorf("Wanted the latest measurement converted to ppb, Got: %v.", resp.Results)
+	}
+	if resp.Metadata.Units["Average"] != "ppb" {
+		t.Errorf("Wanted the latest measurement reported in ppb, Got: %v.", resp.Metadata.Units)
+	}
+}
@@ -52,6 +52,46 @@ func TestCo2GetYear(t *testing.T) {
 	RunTest(t, t.Name(), testVal, sqlString, query, validDates, handlerConfig)
 }
 
+func TestCo2GetYearRange(t *testing.T) {
+	testVal := [2]int{1984, 2018}
+
+	sqlString := regexp.QuoteMeta(fmt.Sprintf(`SELECT * FROM public.co2_weekly_mlo WHERE year between '%v' and '%v' ORDER BY year,month,day LIMIT 10`, testVal[0], testVal[1]))
+	query := fmt.Sprintf("/v1/co2/weekly?year=%v..%v", testVal[0], testVal[1])
+	validDates := []string{"1984-01-01", "1984-01-08", "2000-01-02", "2000-01-09", "2018-09-02", "2018-10-07"}
+
+	RunTest(t, t.Name(), testVal, sqlString, query, validDates, handlerConfig)
+}
+
+func TestCo2DateParse(t *testing.T) {
+	testVals := []struct {
+		params  []string
+		section string
+		sql     string
+	}{
+		{[]string{"2020"}, "year", "year in ('2020')"},
+		{[]string{"2019", "2020"}, "year", "year in ('2019', '2020')"},
+		{[]string{"1990..2020"}, "year", "year between '1990' and '2020'"},
+		{[]string{"6-8"}, "month", "month between '6' and '8'"},
+		{[]string{"2000.."}, "year", "year >= '2000'"},
+		{[]string{"..1990"}, "year", "year <= '1990'"},
+		{[]string{"!1", "2"}, "month", "month not in ('1', '2')"},
+		{[]string{"!6-8"}, "month", "not (month between '6' and '8')"},
+		{[]string{"1", "6..8", "12"}, "month", "(month in ('1', '12') or month between '6' and '8')"},
+		{[]string{"!1990..2000", "2010.."}, "year", "not (year between '1990' and '2000' or year >= '2010')"},
+	}
+
+	for _, testVal := range testVals {
+		sql, err := dateParse(testVal.params, testVal.section)
+		if err != nil {
+			t.Errorf("Unexpected error parsing '%v': %v", testVal.params, err)
+			continue
+		}
+		if sql != testVal.sql {
+			t.Errorf("Wanted '%v', Got: '%v'.", testVal.sql, sql)
+		}
+	}
+}
+
 func TestCo2GetMonth(t *testing.T) {
 	testVal := 1
 
@@ -161,18 +201,40 @@ func TestCo2GetNull(t *testing.T) {
 	RunTest(t, t.Name(), float32(testVal), sqlString, query, validValues, handlerConfig)
 }
 
+func TestCo2GetSort(t *testing.T) {
+	testVal := 2020
+
+	sqlString := regexp.QuoteMeta(fmt.Sprintf(`SELECT * FROM public.co2_weekly_mlo WHERE year in ('%v') ORDER BY average DESC,year,month,day LIMIT 10`, testVal))
+	query := fmt.Sprintf("/v1/co2/weekly?year=%v&sort=-average", testVal)
+	validDates := []string{"2020-05-24", "2020-02-02"}
+
+	RunTest(t, t.Name(), testVal, sqlString, query, validDates, handlerConfig)
+}
+
 func TestCo2Errors(t *testing.T) {
 	testVals := []string{
 		"/v1/co2/weekly?year=2020a",
 		"/v1/co2/weekly?year=20200",
 		"/v1/co2/weekly?month=1a",
 		"/v1/co2/weekly?month=14",
+		"/v1/co2/weekly?year=2020..1990",
+		"/v1/co2/weekly?year=1990..3001",
+		"/v1/co2/weekly?year=..",
+		"/v1/co2/weekly?month=0-8",
+		"/v1/co2/weekly?month=6-13",
+		"/v1/co2/weekly?month=1,!2",
+		"/v1/co2/weekly?month=!",
+		"/v1/co2/weekly?year=-2000",
+		"/v1/co2/weekly?month=6-",
 		"/v1/co2/weekly?gt=400a",
 		"/v1/co2/weekly?lt=400a",
 		"/v1/co2/weekly?gte=400a",
 		"/v1/co2/weekly?lte=400a",
 		"/v1/co2/weekly?gt=40000",
 		"/v1/co2/weekly?gt=-1",
+		"/v1/co2/weekly?sort=ppm",
+		"/v1/co2/weekly?sort=-",
+		"/v1/co2/weekly?sort=year,-year",
 	}
 
 	sqlString := ``
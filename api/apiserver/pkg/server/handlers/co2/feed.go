@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/server/handlers"
+	"apiserver/pkg/utils"
+	"context"
+	"net/http"
+)
+
+// GetFeed is an ApiHandlerFunc type. It publishes the most recent co2weekly measurements as an Atom or RSS feed,
+// with one entry per week holding its average and the change since the same week a year earlier.
+func GetFeed(ctx context.Context, handlerConfig *handlers.ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	dataset := models.Co2WeeklyMlo
+
+	if serverErr := handlers.ValidateFeedRequest(r); serverErr != nil {
+		return serverErr
+	}
+
+	updated, serverErr := handlers.FeedUpdated(handlerConfig.Database, dataset)
+	if serverErr != nil {
+		return serverErr
+	}
+
+	orderBy, err := dataset.OrderBy([]string{"-year", "-month", "-day"})
+	if err != nil {
+		return utils.NewError(err, "error ordering feed entries", 500, false)
+	}
+	query := database.NewQuery(dataset.Table, []string{"*"}, orderBy)
+	query.Limit = handlers.FeedEntries
+
+	co2Table := models.Co2Table{}
+	if dberr := handlerConfig.Database.Query(query, &co2Table); dberr != nil {
+		return utils.NewError(dberr, "internal database error", 500, false)
+	}
+
+	feed := handlers.Feed{
+		Dataset:     dataset,
+		Title:       "Weekly CO₂ at Mauna Loa Observatory",
+		Description: "Weekly average CO₂ mole fraction measured at Mauna Loa Observatory, Hawaii, in parts per million.",
+		Resource:    utils.GetVersion(r).Base() + "/co2/weekly",
+		Units:       "ppm",
+		Updated:     updated,
+	}
+	for _, row := range co2Table {
+		entry := row.(models.Co2Entry)
+		feed.Entries = append(feed.Entries, handlers.NewFeedEntry(dataset, entry.Timestamp, entry.Average, entry.OneYearAgo))
+	}
+	return handlers.WriteFeed(w, r, feed)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package co2
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/server/handlers"
+	"apiserver/pkg/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GetLatest is an ApiHandlerFunc type. It queries the database for the most recent co2weekly measurement along with
+// the measurements for the same week one and ten years earlier, and reports how fresh the dataset is.
+func GetLatest(ctx context.Context, handlerConfig *handlers.ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	dataset := models.Co2WeeklyMlo
+	query := database.NewQuery(dataset.Table, []string{"*"}, strings.Join(dataset.Order, ","))
+
+	// Filters do not apply to the latest measurement, so only the formatting parameters are parsed
+	internalArgs := make(map[string]interface{})
+	for key, val := range utils.ParseQuery(r) {
+		if err := parseSingleResource(key, val, handlerConfig.SortBy, nil, internalArgs); err != nil {
+			message := err.Error() + ": " + key + "=[" + strings.Join(val, ",") + "]"
+			return utils.NewError(fmt.Errorf("error when parsing query parameters"), message, 400, false).ForParam(key, err)
+		}
+	}
+	ParseInternalArgs(internalArgs, &query)
+
+	conversion, _, err := handlers.ParseUnits(r, handlerConfig, dataset)
+	if err != nil {
+		return err
+	}
+
+	observed, dberr := handlerConfig.Database.LatestObservation(dataset)
+	if dberr == sql.ErrNoRows {
+		return utils.NewError(dberr, "no co2 measurements available", 404, false)
+	} else if dberr != nil {
+		return utils.NewError(dberr, "internal database error", 500, false)
+	}
+
+	ingested, dberr := handlerConfig.Database.LastIngested(dataset)
+	if dberr != nil {
+		return utils.NewError(dberr, "internal database error", 500, false)
+	}
+
+	latest := models.Latest{
+		Freshness: dataset.Freshness(observed, ingested, time.Now().UTC()),
+	}
+
+	periods := []struct {
+		years  int
+		result *interface{}
+	}{
+		{0, &latest.Latest},
+		{1, &latest.OneYearAgo},
+		{10, &latest.TenYearsAgo},
+	}
+
+	for _, period := range periods {
+		co2Table := models.Co2Table{}
+		var dataObject models.DataObject = &co2Table
+		if len(query.Fields) != 0 {
+			dataObject = &models.FieldsTable{Columns: query.Fields, Entries: (*[]interface{})(&co2Table)}
+		}
+
+		dberr := handlerConfig.Database.QueryPeriod(dataset, query, observed.AddDate(-period.years, 0, 0), dataObject)
+		if dberr != nil {
+			return utils.NewError(dberr, "internal database error", 500, false)
+		}
+		if len(co2Table) != 0 {
+			*period.result = conversion.Entry(co2Table[0])
+		}
+	}
+
+	columns := query.Fields
+	if len(columns) == 0 {
+		columns = dataset.Columns
+	}
+	metadata := conversion.Dataset.Metadata(columns, ingested)
+	return utils.WriteJson(w, r, []interface{}{latest}, utils.Meta{Dataset: &conversion.Dataset, Metadata: &metadata}, query.Pretty)
+}
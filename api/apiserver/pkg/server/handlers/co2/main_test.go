@@ -31,6 +31,7 @@ import (
 	"apiserver/test"
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -39,6 +40,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"testing"
@@ -138,6 +140,123 @@ func RunTest(t *testing.T, testName string, testVal interface{}, sqlString strin
 	}
 }
 
+// mockRequest is a request made by runRequest, as set up by its requestOptions.
+type mockRequest struct {
+	handler handlers.ApiHandlerFunc
+	config  handlers.ApiHandlerConfig
+	header  http.Header
+	expect  []func(mock sqlmock.Sqlmock)
+	fails   bool
+	decoded []interface{}
+}
+
+// requestOption sets up a request made by runRequest.
+type requestOption func(request *mockRequest)
+
+// withHeader sets a header of the request, unless value is empty.
+func withHeader(name string, value string) requestOption {
+	return func(request *mockRequest) {
+		if value != "" {
+			request.header.Set(name, value)
+		}
+	}
+}
+
+// expectQuery expects the handler to query sqlString with args, which returns rows.
+func expectQuery(sqlString string, rows *sqlmock.Rows, args ...driver.Value) requestOption {
+	return withMock(func(mock sqlmock.Sqlmock) {
+		query := mock.ExpectQuery(regexp.QuoteMeta(sqlString)).WillReturnRows(rows)
+		if len(args) != 0 {
+			query.WithArgs(args...)
+		}
+	})
+}
+
+// withMock sets further expectations on the mock database, in the order the handler meets them.
+func withMock(expect func(mock sqlmock.Sqlmock)) requestOption {
+	return func(request *mockRequest) {
+		request.expect = append(request.expect, expect)
+	}
+}
+
+// withConfig changes the configuration of the handler, which otherwise only sorts by average.
+func withConfig(configure func(config *handlers.ApiHandlerConfig)) requestOption {
+	return func(request *mockRequest) {
+		configure(&request.config)
+	}
+}
+
+// withHandler requests handler rather than Get.
+func withHandler(handler handlers.ApiHandlerFunc) requestOption {
+	return func(request *mockRequest) {
+		request.handler = handler
+	}
+}
+
+// failing expects the request to fail. The error is written to the response by HttpJsonError.
+func failing() requestOption {
+	return func(request *mockRequest) {
+		request.fails = true
+	}
+}
+
+// decodeInto decodes the JSON body of the response into v.
+func decodeInto(v interface{}) requestOption {
+	return func(request *mockRequest) {
+		request.decoded = append(request.decoded, v)
+	}
+}
+
+// runRequest requests query from a mock database as set up by opts. The test fails unless every expected query is
+// made and the request succeeds, or fails when it is expected to. It returns the response.
+func runRequest(t *testing.T, query string, opts ...requestOption) *httptest.ResponseRecorder {
+	t.Helper()
+	db, mock, _, _, err := newMockDb()
+	if err != nil {
+		t.Fatalf("error generating mock database: %s", err.Error())
+	}
+	defer db.Close()
+
+	request := mockRequest{handler: Get, config: handlers.ApiHandlerConfig{SortBy: "average"}, header: http.Header{}}
+	for _, opt := range opts {
+		opt(&request)
+	}
+	for _, expect := range request.expect {
+		expect(mock)
+	}
+	request.config.Database = &database.Database{DB: db}
+
+	req := test.SetReqIdTest(httptest.NewRequest("GET", query, nil))
+	for name, values := range request.header {
+		req.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+
+	serverErr := request.handler(context.Background(), &request.config, w, req)
+	switch {
+	case serverErr != nil && !request.fails:
+		test.ErrorLog(t, serverErr)
+		t.Fatalf("Unexpected error for query '%v'.", query)
+	case serverErr == nil && request.fails:
+		t.Fatalf("Wanted an error for query '%v'.", query)
+	case serverErr != nil:
+		utils.HttpJsonError(w, req, serverErr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("there were unfulfilled expectations for query '%v': %s", query, err)
+	}
+	if verbose {
+		test.PrintServerResponse(t, w.Result(), w.Body.Bytes())
+	}
+
+	for _, v := range request.decoded {
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Fatalf("Cannot decode the response to '%v': %v", query, err)
+		}
+	}
+	return w
+}
+
 func configureDbRows(t *testing.T, testName string, testVal interface{}, rows *sqlmock.Rows, data []mockCo2Row) error {
 	for i, v := range data {
 		switch testName {
@@ -279,6 +398,25 @@ func configureDbRows(t *testing.T, testName string, testVal interface{}, rows *s
 					rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.Ndays, v.OneYearAgo, v.TenYearsAgo, v.IncreaseSince1800, v.YYYYMMDD)
 				}
 			}
+		case "TestCo2GetSort", "TestCo2IncreaseGetSort":
+			if _, ok := testVal.(int); !ok {
+				return fmt.Errorf("Test value '%v' for test '%v' is not of type int.", testVal, testName)
+			}
+
+			// Add desired entries to mock database response, newest first
+			v := data[len(data)-1-i]
+			if v.Year == testVal.(int) {
+				rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.Ndays, v.OneYearAgo, v.TenYearsAgo, v.IncreaseSince1800, v.YYYYMMDD)
+			}
+		case "TestCo2GetYearRange", "TestCo2IncreaseGetYearRange":
+			if _, ok := testVal.([2]int); !ok {
+				return fmt.Errorf("Test value '%v' for test '%v' is not of type [2]int.", testVal, testName)
+			}
+
+			// Add desired entries to mock database response
+			if v.Year >= testVal.([2]int)[0] && v.Year <= testVal.([2]int)[1] {
+				rows.AddRow(v.Year, v.Month, v.Day, v.DateDecimal, v.Average, v.Ndays, v.OneYearAgo, v.TenYearsAgo, v.IncreaseSince1800, v.YYYYMMDD)
+			}
 		case "TestCo2Errors", "TestCo2IncreaseErrors":
 			if testVal != nil {
 				return fmt.Errorf("Test value '%v' for test '%v' is not nil.", testVal, testName)
@@ -389,6 +527,9 @@ type mockCo2Row struct {
 	YYYYMMDD          time.Time
 }
 
+// mockColumns are the columns of the table, as the database returns them for 'SELECT *'.
+var mockColumns = []string{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd"}
+
 // newMockDb returns an sqlmock database to be used for unit tests.
 func newMockDb() (*sql.DB, sqlmock.Sqlmock, *sqlmock.Rows, []mockCo2Row, error) {
 	db, mock, err := sqlmock.New()
@@ -396,15 +537,30 @@ func newMockDb() (*sql.DB, sqlmock.Sqlmock, *sqlmock.Rows, []mockCo2Row, error)
 		return nil, nil, nil, nil, err
 	}
 
-	columns := []string{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "Increase_since_1800", "YYYYMMDD"}
-
-	rows := sqlmock.NewRows(columns)
+	rows := sqlmock.NewRows(mockColumns)
 
 	data := GetMockCo2Rows()
 
 	return db, mock, rows, data, nil
 }
 
+// mockRows returns the given columns of the mock measurements, as the database returns them.
+func mockRows(columns []string, data []mockCo2Row) *sqlmock.Rows {
+	rows := sqlmock.NewRows(columns)
+	for _, v := range data {
+		values := map[string]driver.Value{
+			"year": v.Year, "month": v.Month, "day": v.Day, "date_decimal": v.DateDecimal, "average": v.Average, "ndays": v.Ndays,
+			"one_year_ago": v.OneYearAgo, "ten_years_ago": v.TenYearsAgo, "increase_since_1800": v.IncreaseSince1800, "yyyymmdd": v.YYYYMMDD,
+		}
+		row := make([]driver.Value, len(columns))
+		for i, col := range columns {
+			row[i] = values[strings.ToLower(col)]
+		}
+		rows.AddRow(row...)
+	}
+	return rows
+}
+
 // GetMockCo2Rows returns a list hardcoded co2 measurement data used to mock the database.
 func GetMockCo2Rows() []mockCo2Row {
 	return []mockCo2Row{
@@ -25,11 +25,14 @@ package co2
 
 import (
 	"apiserver/pkg/database"
+	"apiserver/pkg/database/filter"
 	"apiserver/pkg/database/models"
 	"apiserver/pkg/utils"
 	"fmt"
 	"net/http"
+	"net/url"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -39,30 +42,63 @@ import (
 // the parameters should be derived mainly from the query params (eg. '/v1/co2/weekly?year=2020&gte=417')
 // or the url path (eg. '/v1/co2/weekly/317.22?simple=true'). This is needed because when specifying
 // a specific resource in the url path, filters like gt,gte,lt,lte, etc. are not needed as only one
-// resource is returned.
-func ParseParams(r *http.Request, pathParam bool, sortBy string) ([]string, map[string]interface{}, *utils.ServerError) {
+// resource is returned. Values compared against concentrations are converted to the unit of the dataset by
+// filterUnits, unless it is nil (see handlers.ParseUnits).
+func ParseParams(r *http.Request, pathParam bool, sortBy string, filterUnits *models.Conversion) ([]string, map[string]interface{}, *utils.ServerError) {
 	params := utils.ParseQuery(r)
+
+	// Filter expressions may contain commas, so they are not expanded like other parameters
+	if val, ok := r.URL.Query()["filter"]; ok {
+		params["filter"] = val
+	}
+
+	if !pathParam {
+		return parseValues(params, sortBy, filterUnits)
+	}
+
 	var sqlFilters []string
 	internalArgs := make(map[string]interface{})
-	var err error
 
-	if pathParam {
-		err = parsePathParams(r.URL.Path, sortBy, &sqlFilters)
-		if err != nil {
-			message := err.Error() + ": " + path.Dir(r.URL.Path) + "=[" + path.Base(r.URL.Path) + "]"
-			return nil, nil, utils.NewError(fmt.Errorf("error when parsing path parameter"), message, 400, false)
-		}
+	err := parsePathParams(r.URL.Path, sortBy, &sqlFilters)
+	if err != nil {
+		message := err.Error() + ": " + path.Dir(r.URL.Path) + "=[" + path.Base(r.URL.Path) + "]"
+		return nil, nil, utils.NewError(fmt.Errorf("error when parsing path parameter"), message, 400, false).ForParam("ppm", err)
 	}
 
 	for key, val := range params {
-		if pathParam {
-			err = parseSingleResource(key, val, sortBy, &sqlFilters, internalArgs)
-		} else {
-			err = parseParam(key, val, sortBy, &sqlFilters, internalArgs)
+		err = parseSingleResource(key, val, sortBy, &sqlFilters, internalArgs)
+		if err != nil {
+			message := err.Error() + ": " + key + "=[" + strings.Join(val, ",") + "]"
+			return nil, nil, utils.NewError(fmt.Errorf("error when parsing query parameters"), message, 400, false).ForParam(key, err)
 		}
+	}
+	return sqlFilters, internalArgs, nil
+}
+
+// ParseValues returns a list of SQL WHERE directives and a map of internal arguments to the server, derived
+// from a set of already expanded query parameters. This allows servers other than the REST API to accept the
+// same parameters, with the same validation and error messages. Values are given in the unit of the dataset.
+func ParseValues(params url.Values, sortBy string) ([]string, map[string]interface{}, *utils.ServerError) {
+	return parseValues(params, sortBy, nil)
+}
+
+func parseValues(params url.Values, sortBy string, filterUnits *models.Conversion) ([]string, map[string]interface{}, *utils.ServerError) {
+	var sqlFilters []string
+	internalArgs := make(map[string]interface{})
+
+	// Parameters are parsed in a stable order so that equal requests build equal queries
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		val := params[key]
+		err := parseParam(key, val, sortBy, filterUnits, &sqlFilters, internalArgs)
 		if err != nil {
 			message := err.Error() + ": " + key + "=[" + strings.Join(val, ",") + "]"
-			return nil, nil, utils.NewError(fmt.Errorf("error when parsing query parameters"), message, 400, false)
+			return nil, nil, utils.NewError(fmt.Errorf("error when parsing query parameters"), message, 400, false).ForParam(key, err)
 		}
 	}
 	return sqlFilters, internalArgs, nil
@@ -70,8 +106,8 @@ func ParseParams(r *http.Request, pathParam bool, sortBy string) ([]string, map[
 
 // parseParam appends a single boolean expression to the sqlFilters list. This list of expressions is later passed
 // directly to the WHERE clause of an SQL query. parseParam also will add specific arguments to the internalArgs map
-// to be later used by the server.
-func parseParam(filterType string, params []string, sortBy string, sqlFilters *[]string, internalArgs map[string]interface{}) error {
+// to be later used by the server. Values compared against concentrations are converted by filterUnits unless it is nil.
+func parseParam(filterType string, params []string, sortBy string, filterUnits *models.Conversion, sqlFilters *[]string, internalArgs map[string]interface{}) error {
 
 	switch filterType {
 	case "year", "month":
@@ -81,7 +117,7 @@ func parseParam(filterType string, params []string, sortBy string, sqlFilters *[
 		}
 		*sqlFilters = append(*sqlFilters, result)
 	case "gt":
-		ppm, err := getPPM(params, true)
+		ppm, err := getPPM(params, true, filterUnits)
 		if err != nil {
 			return err
 		}
@@ -91,7 +127,7 @@ func parseParam(filterType string, params []string, sortBy string, sqlFilters *[
 		}
 		*sqlFilters = append(*sqlFilters, result)
 	case "lt":
-		ppm, err := getPPM(params, false)
+		ppm, err := getPPM(params, false, filterUnits)
 		if err != nil {
 			return err
 		}
@@ -101,7 +137,7 @@ func parseParam(filterType string, params []string, sortBy string, sqlFilters *[
 		}
 		*sqlFilters = append(*sqlFilters, result)
 	case "gte":
-		ppm, err := getPPM(params, true)
+		ppm, err := getPPM(params, true, filterUnits)
 		if err != nil {
 			return err
 		}
@@ -111,7 +147,7 @@ func parseParam(filterType string, params []string, sortBy string, sqlFilters *[
 		}
 		*sqlFilters = append(*sqlFilters, result)
 	case "lte":
-		ppm, err := getPPM(params, false)
+		ppm, err := getPPM(params, false, filterUnits)
 		if err != nil {
 			return err
 		}
@@ -127,7 +163,7 @@ func parseParam(filterType string, params []string, sortBy string, sqlFilters *[
 		}
 		internalArgs[filterType] = result
 	case "limit":
-		result, err := validateInt(params, 0, 10000)
+		result, err := validateLimit(params)
 		if err != nil {
 			return err
 		}
@@ -151,6 +187,31 @@ func parseParam(filterType string, params []string, sortBy string, sqlFilters *[
 			return err
 		}
 		internalArgs[filterType] = result
+	case "sort":
+		result, err := models.Co2WeeklyMlo.OrderBy(params)
+		if err != nil {
+			return err
+		}
+		internalArgs[filterType] = result
+	case "fields":
+		result, err := models.Co2WeeklyMlo.Fields(params)
+		if err != nil {
+			return err
+		}
+		internalArgs[filterType] = result
+	case "filter":
+		var result []filter.Node
+		for _, expr := range params {
+			node, err := filter.Parse(expr, models.Co2WeeklyMlo)
+			if err != nil {
+				return err
+			}
+			if filterUnits != nil {
+				filter.Convert(node, filterUnits.Converts, filterUnits.Canonical)
+			}
+			result = append(result, node)
+		}
+		internalArgs[filterType] = result
 	}
 
 	return nil
@@ -169,7 +230,7 @@ func parseSingleResource(filterType string, params []string, urlPath string, sql
 		}
 		internalArgs[filterType] = result
 	case "limit":
-		result, err := validateInt(params, 0, 10000)
+		result, err := validateLimit(params)
 		if err != nil {
 			return err
 		}
@@ -193,6 +254,18 @@ func parseSingleResource(filterType string, params []string, urlPath string, sql
 			return err
 		}
 		internalArgs[filterType] = result
+	case "sort":
+		result, err := models.Co2WeeklyMlo.OrderBy(params)
+		if err != nil {
+			return err
+		}
+		internalArgs[filterType] = result
+	case "fields":
+		result, err := models.Co2WeeklyMlo.Fields(params)
+		if err != nil {
+			return err
+		}
+		internalArgs[filterType] = result
 	}
 
 	return nil
@@ -203,7 +276,7 @@ func parsePathParams(urlPath string, sortBy string, sqlFilters *[]string) error
 
 	switch sortBy {
 	case "average", "increase":
-		_, err := validatePpm(val)
+		_, err := validatePpm(val, nil)
 		if err != nil {
 			return err
 		}
@@ -218,23 +291,103 @@ func parsePathParams(urlPath string, sortBy string, sqlFilters *[]string) error
 	return nil
 }
 
-// dateParse
+// dateParse builds a single boolean expression from the values of a year or month parameter.
+// Each value is either a single date (2020), an inclusive range (1990..2020 or 6-8) or an open
+// ended range (2000.. or ..1990). A leading '!' negates the whole list (eg. '!1,2').
+// Plain lists of dates are translated to 'in' expressions and ranges to 'between' expressions.
 func dateParse(params []string, section string) (string, error) {
-	result := section + " in ('"
-	numParams := len(params) - 1
-	for i, v := range params {
-		err := validateDate(v, section)
+	negate := false
+	if len(params) != 0 && strings.HasPrefix(params[0], "!") {
+		negate = true
+		params = append([]string{strings.TrimPrefix(params[0], "!")}, params[1:]...)
+	}
+
+	var dates, ranges []string
+	for _, v := range params {
+		if strings.HasPrefix(v, "!") {
+			return "", fmt.Errorf("malformed query parameters, '!' may only be used at the start of a list of dates")
+		}
+
+		low, high, isRange := dateRange(v)
+		if !isRange {
+			err := validateDate(v, section)
+			if err != nil {
+				return "", err
+			}
+			dates = append(dates, v)
+			continue
+		}
+
+		result, err := rangeParse(low, high, section)
 		if err != nil {
 			return "", err
 		}
+		ranges = append(ranges, result)
+	}
 
-		if i == numParams {
-			result += v + "')"
-			break
+	var terms []string
+	if len(dates) != 0 {
+		terms = append(terms, section+" in ('"+strings.Join(dates, "', '")+"')")
+	}
+	terms = append(terms, ranges...)
+
+	if len(terms) == 0 {
+		return "", fmt.Errorf("malformed query parameters, invalid date value")
+	}
+	if !negate {
+		if len(terms) == 1 {
+			return terms[0], nil
 		}
-		result += v + "', '"
+		return "(" + strings.Join(terms, " or ") + ")", nil
 	}
-	return result, nil
+	if len(ranges) == 0 {
+		return section + " not in ('" + strings.Join(dates, "', '") + "')", nil
+	}
+	return "not (" + strings.Join(terms, " or ") + ")", nil
+}
+
+// dateRange splits a range of dates such as '1990..2020', '2000..' or '6-8' into its endpoints.
+// An endpoint is empty when the range is open on that side.
+func dateRange(val string) (string, string, bool) {
+	if bounds := strings.SplitN(val, "..", 2); len(bounds) == 2 {
+		return bounds[0], bounds[1], true
+	}
+	// Ranges written with '-' must be closed, a leading '-' is left to validateDate so that
+	// negative dates are reported as out of range
+	if bounds := strings.SplitN(val, "-", 2); len(bounds) == 2 && bounds[0] != "" && bounds[1] != "" {
+		return bounds[0], bounds[1], true
+	}
+	return "", "", false
+}
+
+// rangeParse validates both endpoints of a range of dates and returns the matching boolean expression.
+func rangeParse(low string, high string, section string) (string, error) {
+	if low == "" && high == "" {
+		return "", fmt.Errorf("malformed query parameters, a range of dates needs at least one endpoint")
+	}
+	for _, v := range []string{low, high} {
+		if v == "" {
+			continue
+		}
+		err := validateDate(v, section)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	switch {
+	case low == "":
+		return section + " <= '" + high + "'", nil
+	case high == "":
+		return section + " >= '" + low + "'", nil
+	}
+
+	start, _ := strconv.Atoi(low)
+	end, _ := strconv.Atoi(high)
+	if start > end {
+		return "", fmt.Errorf("invalid %v range, the start of a range must not be after its end", section)
+	}
+	return section + " between '" + low + "' and '" + high + "'", nil
 }
 
 func ppmParse(ppm string, sortBy string, comparison string) (string, error) {
@@ -253,10 +406,26 @@ func ParseInternalArgs(internalArgs map[string]interface{}, query *database.DBQu
 	for key, val := range internalArgs {
 		switch key {
 		case "simple":
-			if result, ok := val.(bool); ok {
-				query.Cols = []string{"year", "month", "day", "average", "increase_since_1800"}
+			// Simple is a preset of fields. Fields requested explicitly take precedence over it.
+			if result, ok := val.(bool); ok && result {
+				if _, ok := internalArgs["fields"]; !ok {
+					fields, _ := models.Co2WeeklyMlo.Fields(models.Co2WeeklyMlo.Presets["simple"])
+					query.Select(fields)
+				}
 				query.Simple = result
 			}
+		case "fields":
+			if result, ok := val.([]models.Column); ok {
+				query.Select(result)
+			}
+		case "filter":
+			if result, ok := val.([]filter.Node); ok {
+				for _, node := range result {
+					var expr string
+					expr, query.Args = filter.Compile(node, query.Args)
+					query.Where = append(query.Where, expr)
+				}
+			}
 		case "limit":
 			if result, ok := val.(int); ok {
 				query.Limit = result
@@ -273,6 +442,10 @@ func ParseInternalArgs(internalArgs map[string]interface{}, query *database.DBQu
 			if result, ok := val.(bool); ok {
 				query.Pretty = result
 			}
+		case "sort":
+			if result, ok := val.(string); ok {
+				query.OrderBy = result
+			}
 		}
 	}
 	return nil
@@ -290,23 +463,23 @@ func validateDate(val string, section string) error {
 	switch section {
 	case "year":
 		if date < 0 || date > 3000 {
-			return fmt.Errorf("invalid year value. Years must be between 0 and 3000")
+			return utils.OutOfRange("invalid year value. Years must be between 0 and 3000")
 		}
 	case "month":
 		if date < 1 || date > 12 {
-			return fmt.Errorf("invalid month value. Months must be between 1 and 12")
+			return utils.OutOfRange("invalid month value. Months must be between 1 and 12")
 		}
 	}
 	return nil
 }
 
-func getPPM(array []string, max bool) (string, error) {
-	target, err := validatePpm(array[0])
+func getPPM(array []string, max bool, filterUnits *models.Conversion) (string, error) {
+	target, err := validatePpm(array[0], filterUnits)
 	if err != nil {
 		return "", err
 	}
 	for _, value := range array {
-		curr, err := validatePpm(value)
+		curr, err := validatePpm(value, filterUnits)
 		if err != nil {
 			return "", err
 		}
@@ -316,18 +489,36 @@ func getPPM(array []string, max bool) (string, error) {
 			target = curr
 		}
 	}
+
+	// Values converted from another unit keep their full precision, as rounding them would move the bound past
+	// measurements, and the same bound given in a filter expression is not rounded either
+	if filterUnits != nil {
+		return strconv.FormatFloat(target, 'f', -1, 64), nil
+	}
 	return strconv.FormatFloat(target, 'f', 2, 32), nil
 }
 
-// validatePpm validates a ppm parameter against the current API spec.
-func validatePpm(ppmStr string) (float64, error) {
-
-	ppm, err := strconv.ParseFloat(ppmStr, 32)
+// validatePpm validates a ppm parameter against the current API spec. Values given in the unit of filterUnits are
+// validated against the converted range and returned in ppm.
+func validatePpm(ppmStr string, filterUnits *models.Conversion) (float64, error) {
+	bitSize := 32
+	if filterUnits != nil {
+		bitSize = 64
+	}
+	ppm, err := strconv.ParseFloat(ppmStr, bitSize)
 	if err != nil {
 		return 0, fmt.Errorf("malformed query parameters, ppm value should be a decimal number")
 	}
-	if !(ppm <= models.Co2PpmMax && ppm >= models.Co2PpmMin) {
-		return 0, fmt.Errorf("malformed query parameters, ppm query range is %v to %v", models.Co2PpmMin, models.Co2PpmMax)
+
+	unit, min, max := "ppm", float64(models.Co2PpmMin), float64(models.Co2PpmMax)
+	if filterUnits != nil {
+		unit, min, max = filterUnits.Unit, filterUnits.Dataset.Min, filterUnits.Dataset.Max
+	}
+	if !(ppm <= max && ppm >= min) {
+		return 0, utils.OutOfRange("malformed query parameters, %v query range is %v to %v", unit, min, max)
+	}
+	if filterUnits != nil {
+		ppm = filterUnits.Canonical(ppm)
 	}
 	return ppm, nil
 }
@@ -345,6 +536,14 @@ func validateBool(param []string) (bool, error) {
 	return result, nil
 }
 
+// validateLimit validates a limit parameter. The limit may be lifted with 'all', which is returned as -1.
+func validateLimit(param []string) (int, error) {
+	if len(param) == 1 && param[0] == "all" {
+		return -1, nil
+	}
+	return validateInt(param, 0, 10000)
+}
+
 // validateBool validates an integer parameter.
 func validateInt(param []string, min int, max int) (int, error) {
 	if len(param) != 1 {
@@ -357,9 +556,9 @@ func validateInt(param []string, min int, max int) (int, error) {
 	}
 
 	if int(result) < min {
-		return 0, fmt.Errorf("malformed query parameters, integer value cannot be less than %v", min)
+		return 0, utils.OutOfRange("malformed query parameters, integer value cannot be less than %v", min)
 	} else if int(result) > max {
-		return 0, fmt.Errorf("malformed query parameters, integer value cannot be greater than %v", max)
+		return 0, utils.OutOfRange("malformed query parameters, integer value cannot be greater than %v", max)
 	}
 	return int(result), nil
 }
@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package handlers
+
+import (
+	"apiserver/pkg/database/models"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// arrowSchema derives the schema of a columnar export from the types of the fields of a dataset's Entry.
+// Whole numbers are stored as 32-bit integers, decimals as nullable 32-bit floats so that missing
+// measurements can be stored as nulls, and dates as days since the Unix epoch.
+func arrowSchema(dataset models.Dataset, columns []models.Column) (*arrow.Schema, error) {
+	entry := reflect.TypeOf(dataset.Entry)
+	if entry == nil || entry.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("dataset '%v' does not define an entry struct", dataset.Id)
+	}
+
+	fields := make([]arrow.Field, len(columns))
+	for i, col := range columns {
+		field, ok := entry.FieldByName(col.Field)
+		if !ok {
+			return nil, fmt.Errorf("entry of type '%v' has no field '%v'", entry, col.Field)
+		}
+
+		switch {
+		case field.Type.Kind() == reflect.Int:
+			fields[i] = arrow.Field{Name: col.Field, Type: arrow.PrimitiveTypes.Int32}
+		case field.Type.Kind() == reflect.Float32:
+			fields[i] = arrow.Field{Name: col.Field, Type: arrow.PrimitiveTypes.Float32, Nullable: true}
+		case field.Type == timeType:
+			fields[i] = arrow.Field{Name: col.Field, Type: arrow.FixedWidthTypes.Date32}
+		default:
+			return nil, fmt.Errorf("cannot export field '%v' of type '%v'", col.Field, field.Type)
+		}
+	}
+
+	metadata := arrow.NewMetadata([]string{"dataset"}, []string{dataset.Id})
+	return arrow.NewSchema(fields, &metadata), nil
+}
+
+// arrowRecord builds a single record batch holding the results of a query.
+func arrowRecord(dataset models.Dataset, columns []models.Column, results []interface{}) (array.Record, error) {
+	schema, err := arrowSchema(dataset, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+
+	for _, entry := range results {
+		values, err := models.Values(entry, columns)
+		if err != nil {
+			return nil, err
+		}
+		for i, val := range values {
+			switch b := builder.Field(i).(type) {
+			case *array.Int32Builder:
+				b.Append(int32(val.(int)))
+			case *array.Float32Builder:
+				if v := val.(float32); dataset.IsMissing(v) {
+					b.AppendNull()
+				} else {
+					b.Append(v)
+				}
+			case *array.Date32Builder:
+				b.Append(toDate32(val.(time.Time)))
+			}
+		}
+	}
+	return builder.NewRecord(), nil
+}
+
+// toDate32 converts a date to the number of days since the Unix epoch.
+func toDate32(date time.Time) arrow.Date32 {
+	y, m, d := date.Date()
+	days := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60)
+	return arrow.Date32(days)
+}
+
+// writeArrow writes the results of a query as an Arrow IPC stream.
+func writeArrow(w io.Writer, dataset models.Dataset, columns []models.Column, results []interface{}) error {
+	record, err := arrowRecord(dataset, columns, results)
+	if err != nil {
+		return err
+	}
+	defer record.Release()
+
+	writer := ipc.NewWriter(w, ipc.WithSchema(record.Schema()))
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// writeParquet writes the results of a query as a Parquet file with the same schema as an Arrow export.
+func writeParquet(w io.Writer, dataset models.Dataset, columns []models.Column, results []interface{}) error {
+	record, err := arrowRecord(dataset, columns, results)
+	if err != nil {
+		return err
+	}
+	defer record.Release()
+
+	metadata := make([]string, len(columns))
+	for i, field := range record.Schema().Fields() {
+		repetition := parquet.FieldRepetitionType_REQUIRED
+		if field.Nullable {
+			repetition = parquet.FieldRepetitionType_OPTIONAL
+		}
+		switch field.Type.ID() {
+		case arrow.INT32:
+			metadata[i] = fmt.Sprintf("name=%v, type=%v, convertedtype=%v, repetitiontype=%v", field.Name, parquet.Type_INT32, parquet.ConvertedType_INT_32, repetition)
+		case arrow.FLOAT32:
+			metadata[i] = fmt.Sprintf("name=%v, type=%v, repetitiontype=%v", field.Name, parquet.Type_FLOAT, repetition)
+		case arrow.DATE32:
+			metadata[i] = fmt.Sprintf("name=%v, type=%v, convertedtype=%v, repetitiontype=%v", field.Name, parquet.Type_INT32, parquet.ConvertedType_DATE, repetition)
+		}
+	}
+
+	pw, err := writer.NewCSVWriterFromWriter(metadata, w, 1)
+	if err != nil {
+		return err
+	}
+
+	for row := 0; row < int(record.NumRows()); row++ {
+		values := make([]interface{}, len(columns))
+		for i, col := range record.Columns() {
+			if col.IsNull(row) {
+				continue
+			}
+			switch arr := col.(type) {
+			case *array.Int32:
+				values[i] = arr.Value(row)
+			case *array.Float32:
+				values[i] = arr.Value(row)
+			case *array.Date32:
+				values[i] = int32(arr.Value(row))
+			}
+		}
+		if err := pw.Write(values); err != nil {
+			return err
+		}
+	}
+	return pw.WriteStop()
+}
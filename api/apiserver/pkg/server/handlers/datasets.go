@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package handlers
+
+import (
+	"apiserver/pkg/database/models"
+	utils "apiserver/pkg/utils"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GetDatasets is an ApiHandlerFunc type. It lists every dataset served by the API, along with the coverage of
+// the dataset in the database.
+func GetDatasets(ctx context.Context, handlerConfig *ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	results := make([]interface{}, len(models.Datasets))
+	for i, dataset := range models.Datasets {
+		info, err := datasetInfo(handlerConfig, dataset)
+		if err != nil {
+			return err
+		}
+		results[i] = info
+	}
+	return utils.WriteJson(w, r, results, utils.Meta{}, true)
+}
+
+// GetDataset is an ApiHandlerFunc type. It describes the dataset named in the path of the request.
+func GetDataset(ctx context.Context, handlerConfig *ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	id := datasetId(r)
+	dataset, ok := models.DatasetById(id)
+	if !ok {
+		ids := make([]string, len(models.Datasets))
+		for i, dataset := range models.Datasets {
+			ids[i] = dataset.Id
+		}
+		message := fmt.Sprintf("no dataset with ID '%v'. Datasets are: %v", id, strings.Join(ids, ", "))
+		return utils.NewError(fmt.Errorf("dataset not found"), message, 404, false)
+	}
+
+	info, err := datasetInfo(handlerConfig, dataset)
+	if err != nil {
+		return err
+	}
+	return utils.WriteJson(w, r, []interface{}{info}, utils.Meta{}, true)
+}
+
+// datasetInfo describes a dataset along with its coverage, cached by the catalog.
+func datasetInfo(handlerConfig *ApiHandlerConfig, dataset models.Dataset) (models.DatasetInfo, *utils.ServerError) {
+	coverage, err := handlerConfig.Catalog.Coverage(handlerConfig.Database, dataset)
+	if err != nil {
+		return models.DatasetInfo{}, utils.NewError(err, "internal database error", 500, false)
+	}
+	return dataset.Info(coverage), nil
+}
+
+// datasetId returns the ID of the dataset in the path of a request, eg. 'co2_weekly_mlo' in '/v1/datasets/co2_weekly_mlo'.
+func datasetId(r *http.Request) string {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for i, part := range parts {
+		if part == "datasets" && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package handlers
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/notify"
+	utils "apiserver/pkg/utils"
+	"apiserver/test"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectCoverage expects the coverage of a dataset to be computed once.
+func expectCoverage(mock sqlmock.Sqlmock, dataset models.Dataset, first time.Time, last time.Time, rows int) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT min(yyyymmdd), max(yyyymmdd), count(*) FROM " + dataset.Table)).
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max", "count"}).AddRow(first, last, rows))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT max(ingested_at) FROM public.ingest_log WHERE dataset = $1")).
+		WithArgs(dataset.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(last.AddDate(0, 0, 4)))
+}
+
+// getDatasets requests target from handler and decodes the datasets it describes.
+func getDatasets(t *testing.T, handler ApiHandlerFunc, config *ApiHandlerConfig, target string) ([]models.DatasetInfo, *utils.ServerError) {
+	req := test.SetReqIdTest(httptest.NewRequest("GET", target, nil))
+	w := httptest.NewRecorder()
+	if err := handler(context.Background(), config, w, req); err != nil {
+		return nil, err
+	}
+
+	resp := struct{ Results []models.DatasetInfo }{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Results, nil
+}
+
+func TestGetDatasets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error generating mock database: %v", err)
+	}
+	defer db.Close()
+
+	co2First, co2Last := time.Date(1974, 5, 19, 0, 0, 0, 0, time.UTC), time.Date(2021, 11, 7, 0, 0, 0, 0, time.UTC)
+	expectCoverage(mock, models.Co2WeeklyMlo, co2First, co2Last, 2475)
+	expectCoverage(mock, models.Ch4MmGl, time.Date(1983, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC), 457)
+
+	config := &ApiHandlerConfig{Database: &database.Database{DB: db}, Catalog: NewCatalog(time.Hour)}
+	datasets, serverErr := getDatasets(t, GetDatasets, config, "/v1/datasets")
+	if serverErr != nil {
+		t.Fatalf("Unexpected error listing the datasets: %v", serverErr.Message)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("there were unfulfilled expectations: %s", err)
+	}
+
+	if len(datasets) != len(models.Datasets) {
+		t.Fatalf("Wanted every dataset to be listed, Got: %+v.", datasets)
+	}
+	co2 := datasets[0]
+	if co2.Id != "co2_weekly_mlo" || co2.Cadence != models.Weekly || co2.Bounds != (models.Bounds{Min: models.Co2PpmMin, Max: models.Co2PpmMax}) {
+		t.Errorf("Wanted the CO2 dataset to be described, Got: %+v.", co2)
+	}
+	if co2.Coverage.Rows != 2475 || !co2.Coverage.First.Equal(co2First) || !co2.Coverage.Last.Equal(co2Last) || !co2.Coverage.LastIngested.Equal(co2Last.AddDate(0, 0, 4)) {
+		t.Errorf("Wanted the coverage of the CO2 dataset, Got: %+v.", co2.Coverage)
+	}
+	if len(co2.Fields) != len(models.Co2WeeklyMlo.Columns) || co2.Fields[4] != (models.FieldInfo{Name: "average", Type: models.Float, Unit: "ppm"}) {
+		t.Errorf("Wanted the fields of the CO2 dataset with their units, Got: %+v.", co2.Fields)
+	}
+	if ch4 := datasets[1]; ch4.Id != "ch4_mm_gl" || ch4.Fields[4].Unit != "ppb" || ch4.Coverage.Rows != 457 {
+		t.Errorf("Wanted the CH4 dataset to be described, Got: %+v.", ch4)
+	}
+
+	// The coverage is cached, so the database is not queried again
+	if _, serverErr := getDatasets(t, GetDataset, config, "/v1/datasets/co2_weekly_mlo"); serverErr != nil {
+		t.Fatalf("Unexpected error describing a cached dataset: %v", serverErr.Message)
+	}
+}
+
+func TestGetDataset(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error generating mock database: %v", err)
+	}
+	defer db.Close()
+
+	config := &ApiHandlerConfig{Database: &database.Database{DB: db}}
+	for _, testVal := range []struct {
+		target string
+		id     string
+		status int
+	}{
+		{"/v1/datasets/ch4_mm_gl", "ch4_mm_gl", 200},
+		{"/v1/datasets/co2_weekly_mlo/", "co2_weekly_mlo", 200},
+		{"/v1/datasets/co2_daily_mlo", "", 404},
+	} {
+		if testVal.status == 200 {
+			dataset, _ := models.DatasetById(testVal.id)
+			expectCoverage(mock, dataset, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), 13)
+		}
+
+		datasets, serverErr := getDatasets(t, GetDataset, config, testVal.target)
+		if testVal.status != 200 {
+			if serverErr == nil || serverErr.HttpCode != testVal.status || serverErr.ErrorCode() != utils.CodeNotFound {
+				t.Errorf("Wanted a %v error for %v, Got: %+v.", testVal.status, testVal.target, serverErr)
+			}
+			continue
+		}
+		if serverErr != nil {
+			t.Errorf("Unexpected error for %v: %v", testVal.target, serverErr.Message)
+			continue
+		}
+		if len(datasets) != 1 || datasets[0].Id != testVal.id || datasets[0].Coverage.Rows != 13 {
+			t.Errorf("Wanted the dataset '%v' to be described for %v, Got: %+v.", testVal.id, testVal.target, datasets)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCatalogExpiry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error generating mock database: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Date(2021, 11, 10, 0, 0, 0, 0, time.UTC)
+	catalog := NewCatalog(time.Hour)
+	catalog.now = func() time.Time { return now }
+	database := &database.Database{DB: db}
+
+	first, last := time.Date(1974, 5, 19, 0, 0, 0, 0, time.UTC), time.Date(2021, 11, 7, 0, 0, 0, 0, time.UTC)
+	expectCoverage(mock, models.Co2WeeklyMlo, first, last, 2475)
+	expectCoverage(mock, models.Co2WeeklyMlo, first, last.AddDate(0, 0, 7), 2476)
+	expectCoverage(mock, models.Co2WeeklyMlo, first, last.AddDate(0, 0, 14), 2477)
+
+	broker := notify.NewBroker(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- catalog.Run(ctx, broker) }()
+
+	testVals := []struct {
+		advance time.Duration
+		publish bool
+		rows    int
+	}{
+		{0, false, 2475},
+		{59 * time.Minute, false, 2475},
+		{2 * time.Minute, false, 2476},
+		{time.Minute, true, 2477},
+		{time.Minute, false, 2477},
+	}
+	for i, testVal := range testVals {
+		now = now.Add(testVal.advance)
+		if testVal.publish {
+			// The change is published until the catalog drops the coverage, as it may not have subscribed yet
+			for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+				broker.Publish(notify.Change{Dataset: models.Co2WeeklyMlo.Id, Date: last.AddDate(0, 0, 14)})
+				time.Sleep(time.Millisecond)
+				catalog.mu.Lock()
+				_, cached := catalog.entries[models.Co2WeeklyMlo.Id]
+				catalog.mu.Unlock()
+				if !cached {
+					break
+				}
+			}
+		}
+
+		coverage, err := catalog.Coverage(database, models.Co2WeeklyMlo)
+		if err != nil {
+			t.Fatalf("Unexpected error computing the coverage: %v", err)
+		}
+		if coverage.Rows != testVal.rows {
+			t.Errorf("Wanted the coverage of request %v to count %v rows, Got: %v.", i, testVal.rows, coverage.Rows)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Wanted the catalog to stop with the context, Got: %v", err)
+	}
+}
+
+func TestCatalogInvalidateDuringQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error generating mock database: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Date(2021, 11, 10, 0, 0, 0, 0, time.UTC)
+	catalog := NewCatalog(time.Hour)
+	database := &database.Database{DB: db}
+
+	// The cache is empty, so now is first called once the coverage is computed, before it is stored. The
+	// dataset changes at that point.
+	calls := 0
+	catalog.now = func() time.Time {
+		calls++
+		if calls == 1 {
+			catalog.Invalidate(models.Co2WeeklyMlo.Id)
+		}
+		return now
+	}
+
+	first, last := time.Date(1974, 5, 19, 0, 0, 0, 0, time.UTC), time.Date(2021, 11, 7, 0, 0, 0, 0, time.UTC)
+	expectCoverage(mock, models.Co2WeeklyMlo, first, last, 2475)
+	expectCoverage(mock, models.Co2WeeklyMlo, first, last.AddDate(0, 0, 7), 2476)
+
+	for i, rows := range []int{2475, 2476, 2476} {
+		coverage, err := catalog.Coverage(database, models.Co2WeeklyMlo)
+		if err != nil {
+			t.Fatalf("Unexpected error computing the coverage: %v", err)
+		}
+		if coverage.Rows != rows {
+			t.Errorf("Wanted the coverage of request %v to count %v rows, Got: %v.", i, rows, coverage.Rows)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
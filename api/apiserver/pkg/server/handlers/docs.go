@@ -0,0 +1,283 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package handlers
+
+import (
+	"apiserver/pkg/openapi"
+	utils "apiserver/pkg/utils"
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// docsFiles holds the documentation page template and the stylesheet and script it links to.
+//
+//go:embed docs
+var docsFiles embed.FS
+
+// docsAssets lists the files of docsFiles served at /docs/{asset}.
+var docsAssets = map[string]bool{"docs.css": true, "docs.js": true}
+
+// docsCSP replaces the API's Content Security Policy on the documentation page, which loads its own stylesheet
+// and script and requests the API from the "try it" forms.
+const docsCSP = "default-src 'none'; script-src 'self'; style-src 'self'; img-src 'self' blob:; connect-src 'self'; form-action 'self'; base-uri 'none'; frame-ancestors 'none';"
+
+var (
+	docsPage []byte
+	docsErr  error
+	docsOnce sync.Once
+)
+
+// docsView is the data the documentation page template is rendered with.
+type docsView struct {
+	Title       string
+	Description string
+	Version     string
+	SpecUrl     string
+	Datasets    []docsDataset
+	Tags        []docsTag
+	Errors      []utils.ErrorCode
+}
+
+// docsDataset is a dataset of the API, with the URL it is served at.
+type docsDataset struct {
+	openapi.Dataset
+	Url string
+}
+
+// docsTag groups the operations sharing their first tag.
+type docsTag struct {
+	Name       string
+	Operations []docsOperation
+}
+
+// docsOperation documents a single operation of the spec.
+type docsOperation struct {
+	Id          string
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Parameters  []docsParameter
+	Responses   []docsResponse
+	TryIt       bool
+
+	// Versions lists the other versions of the API serving the operation, eg. 'v2'
+	Versions []string
+}
+
+// docsParameter documents a single parameter of an operation.
+type docsParameter struct {
+	Name        string
+	In          string
+	Description string
+	Type        string
+	Default     string
+	Required    bool
+	Enum        []string
+}
+
+// docsResponse documents a single response of an operation.
+type docsResponse struct {
+	Status       string
+	Description  string
+	ContentTypes []string
+}
+
+// RenderDocs renders the HTML documentation page of a spec: its datasets, each of its operations grouped by
+// tag with a "try it" form for operations which can be requested from a browser, and the error catalog.
+func RenderDocs(spec *openapi.Spec) ([]byte, error) {
+	tmpl, err := template.ParseFS(docsFiles, "docs/index.html")
+	if err != nil {
+		return nil, err
+	}
+
+	base := spec.BasePath()
+	view := docsView{
+		Title:       spec.Info.Title,
+		Description: spec.Info.Description,
+		Version:     spec.Info.Version,
+		SpecUrl:     base + "/openapi.json",
+		Errors:      utils.ErrorCatalog,
+	}
+	for _, dataset := range spec.Info.Datasets {
+		view.Datasets = append(view.Datasets, docsDataset{Dataset: dataset, Url: base + dataset.Path})
+	}
+
+	tags := make(map[string]int)
+	for _, key := range spec.Operations() {
+		parts := strings.SplitN(key, " ", 2)
+		method, p := parts[0], parts[1]
+		op := spec.Operation(method, p)
+
+		url := base + p
+		if p == "/" {
+			url = base
+		}
+		doc := docsOperation{
+			Id:          op.OperationId,
+			Method:      method,
+			Path:        url,
+			Summary:     op.Summary,
+			Description: op.Description,
+			TryIt:       method == "GET",
+			Versions:    op.Versions,
+		}
+		for _, param := range op.Parameters {
+			doc.Parameters = append(doc.Parameters, docsParam(param))
+		}
+		for status, resp := range op.Responses {
+			res := docsResponse{Status: status, Description: resp.Description}
+			for contentType := range resp.Content {
+				res.ContentTypes = append(res.ContentTypes, contentType)
+				// Event streams never complete, so they cannot be shown in the page
+				if contentType == "text/event-stream" {
+					doc.TryIt = false
+				}
+			}
+			sort.Strings(res.ContentTypes)
+			doc.Responses = append(doc.Responses, res)
+		}
+		sort.Slice(doc.Responses, func(i, j int) bool { return doc.Responses[i].Status < doc.Responses[j].Status })
+
+		tag := "other"
+		if len(op.Tags) != 0 {
+			tag = op.Tags[0]
+		}
+		i, ok := tags[tag]
+		if !ok {
+			i = len(view.Tags)
+			tags[tag] = i
+			view.Tags = append(view.Tags, docsTag{Name: tag})
+		}
+		view.Tags[i].Operations = append(view.Tags[i].Operations, doc)
+	}
+
+	var page bytes.Buffer
+	if err := tmpl.Execute(&page, view); err != nil {
+		return nil, err
+	}
+	return page.Bytes(), nil
+}
+
+// docsParam documents a parameter from its schema. Parameters with alternative schemas are documented with the
+// types of each alternative.
+func docsParam(param *openapi.Parameter) docsParameter {
+	doc := docsParameter{
+		Name:        param.Name,
+		In:          param.In,
+		Description: param.Description,
+		Required:    param.Required,
+	}
+	if param.Schema == nil {
+		return doc
+	}
+
+	schemas := []*openapi.Schema{param.Schema}
+	if len(param.Schema.OneOf) != 0 {
+		schemas = param.Schema.OneOf
+	}
+	var types []string
+	for _, schema := range schemas {
+		t := schema.Type
+		if schema.Type == "array" && schema.Items != nil {
+			t = "array of " + schema.Items.Type
+		}
+		types = append(types, t)
+		for _, e := range schema.Enum {
+			doc.Enum = append(doc.Enum, fmt.Sprint(e))
+		}
+	}
+	doc.Type = strings.Join(types, " or ")
+	if doc.Type == "boolean" && len(doc.Enum) == 0 {
+		doc.Enum = []string{"true", "false"}
+	}
+	if param.Schema.Default != nil {
+		doc.Default = fmt.Sprint(param.Schema.Default)
+	}
+	return doc
+}
+
+// GetDocs returns the HTML documentation page of the API, rendered from the OpenAPI spec embedded in the server.
+func GetDocs(ctx context.Context, handlerConfig *ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	// Parse RequestID param
+	id, idError := utils.GetReqId(r)
+	if idError != nil {
+		return utils.NewError(idError, "cannot extract request ID", 500, false)
+	}
+
+	docsOnce.Do(func() {
+		spec, err := openapi.V1()
+		if err != nil {
+			docsErr = err
+			return
+		}
+		docsPage, docsErr = RenderDocs(spec)
+	})
+	if docsErr != nil {
+		return utils.NewError(docsErr, "error rendering the documentation", 500, false)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", docsCSP)
+	w.Header().Set("X-Request-Id", id)
+	if _, err := w.Write(docsPage); err != nil {
+		return utils.NewError(err, "error writing the documentation", 500, false)
+	}
+	return nil
+}
+
+// GetDocsAsset returns a stylesheet or script of the documentation page, eg. /docs/docs.css.
+func GetDocsAsset(ctx context.Context, handlerConfig *ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	// Parse RequestID param
+	id, idError := utils.GetReqId(r)
+	if idError != nil {
+		return utils.NewError(idError, "cannot extract request ID", 500, false)
+	}
+
+	name := path.Base(r.URL.Path)
+	if !docsAssets[name] {
+		return utils.NewError(fmt.Errorf("unknown asset '%v'", name), "no documentation asset named '"+name+"'", 404, false)
+	}
+	asset, err := docsFiles.ReadFile("docs/" + name)
+	if err != nil {
+		return utils.NewError(err, "error reading the documentation asset '"+name+"'", 500, false)
+	}
+
+	w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(name)))
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("X-Request-Id", id)
+	if _, err := w.Write(asset); err != nil {
+		return utils.NewError(err, "error writing the documentation asset '"+name+"'", 500, false)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Sends the "try it" forms of the documentation page and shows the responses below them.
+"use strict";
+
+function requestUrl(form) {
+  var path = form.dataset.path;
+  form.querySelectorAll("[data-path-param]").forEach(function (input) {
+    path = path.replace("{" + input.dataset.pathParam + "}", encodeURIComponent(input.value));
+  });
+
+  var query = new URLSearchParams();
+  form.querySelectorAll("[name]").forEach(function (input) {
+    if (input.value !== "") {
+      query.append(input.name, input.value);
+    }
+  });
+  var search = query.toString();
+  return search === "" ? path : path + "?" + search;
+}
+
+function showResponse(output, url, resp, body) {
+  output.textContent = "";
+
+  var status = document.createElement("p");
+  status.textContent = resp.status + " " + resp.statusText + " GET " + url;
+  output.appendChild(status);
+
+  var contentType = resp.headers.get("Content-Type") || "";
+  if (contentType.indexOf("image/") === 0) {
+    var img = document.createElement("img");
+    img.src = URL.createObjectURL(body);
+    output.appendChild(img);
+    return;
+  }
+
+  body.text().then(function (text) {
+    if (contentType.indexOf("json") !== -1) {
+      try {
+        text = JSON.stringify(JSON.parse(text), null, 2);
+      } catch (e) {
+        // Shown as it was received
+      }
+    }
+    var pre = document.createElement("pre");
+    pre.textContent = text;
+    output.appendChild(pre);
+  });
+}
+
+document.querySelectorAll("form.try").forEach(function (form) {
+  form.addEventListener("submit", function (event) {
+    event.preventDefault();
+    var output = form.querySelector(".response");
+    var url = requestUrl(form);
+    output.textContent = "Requesting " + url + "…";
+
+    fetch(url).then(function (resp) {
+      return resp.blob().then(function (body) {
+        showResponse(output, url, resp, body);
+      });
+    }).catch(function (err) {
+      output.textContent = "Request failed: " + err;
+    });
+  });
+});
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package handlers
+
+import (
+	"apiserver/pkg/openapi"
+	utils "apiserver/pkg/utils"
+	"apiserver/test"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serveDocs serves a documentation handler for a single request.
+func serveDocs(handler ApiHandlerFunc, path string) *httptest.ResponseRecorder {
+	req := test.SetReqIdTest(httptest.NewRequest("GET", path, nil))
+	rr := httptest.NewRecorder()
+	if err := handler(context.Background(), &ApiHandlerConfig{}, rr, req); err != nil {
+		utils.HttpJsonError(rr, req, err)
+	}
+	return rr
+}
+
+func TestRenderDocs(t *testing.T) {
+	spec, err := openapi.V1()
+	if err != nil {
+		t.Fatalf("cannot parse the embedded OpenAPI spec: %v", err)
+	}
+	page, err := RenderDocs(spec)
+	if err != nil {
+		t.Fatalf("cannot render the documentation: %v", err)
+	}
+	html := string(page)
+
+	// Every dataset is listed with its units and source
+	for _, dataset := range spec.Info.Datasets {
+		for _, want := range []string{dataset.Name, "<td>" + dataset.Units + "</td>", `href="` + dataset.Source + `"`, `href="/v1` + dataset.Path + `"`} {
+			if !strings.Contains(html, want) {
+				t.Errorf("Wanted the documentation of dataset '%v' to contain '%v'", dataset.Id, want)
+			}
+		}
+	}
+
+	// Every operation is documented
+	for _, key := range spec.Operations() {
+		parts := strings.SplitN(key, " ", 2)
+		path := "/v1" + parts[1]
+		if parts[1] == "/" {
+			path = "/v1"
+		}
+		want := `<span class="method ` + parts[0] + `">` + parts[0] + `</span> <code>` + path + `</code>`
+		if !strings.Contains(html, want) {
+			t.Errorf("Wanted the documentation to contain '%v'", key)
+		}
+	}
+
+	// Every code of the error catalog is documented under the anchor problem types point to
+	for _, entry := range utils.ErrorCatalog {
+		if !strings.Contains(html, `<tr id="`+entry.Code+`">`) {
+			t.Errorf("Wanted the documentation to describe the error code '%v'", entry.Code)
+		}
+	}
+
+	testVals := map[string]bool{
+		`<form class="try" method="get" action="/v1/co2/weekly" data-path="/v1/co2/weekly">`:                 true,
+		`<form class="try" method="get" action="/v1/co2/weekly/%7bppm%7d" data-path="/v1/co2/weekly/{ppm}">`: true,
+		`<input data-path-param="ppm" required>`:                                                             true,
+		`<form class="try" method="get" action="/v1/stream" data-path="/v1/stream">`:                         false, // Event streams never complete
+		`<form class="try" method="get" action="/v1/subscriptions" data-path="/v1/subscriptions">`:           false, // Only GET requests can be tried
+	}
+	for want, ok := range testVals {
+		if strings.Contains(html, want) != ok {
+			t.Errorf("Wanted the documentation to contain '%v': %v", want, ok)
+		}
+	}
+}
+
+func TestGetDocs(t *testing.T) {
+	rr := serveDocs(GetDocs, "/docs")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Wanted status 200, Got: %v: %v", rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+		t.Errorf("Wanted an HTML page, Got: '%v'", got)
+	}
+	if got := rr.Header().Get("Content-Security-Policy"); !strings.Contains(got, "script-src 'self'") {
+		t.Errorf("Wanted the page to be allowed to load its script, Got: '%v'", got)
+	}
+	if !strings.Contains(rr.Body.String(), `<script src="/docs/docs.js" defer></script>`) {
+		t.Errorf("Wanted the page to load its script")
+	}
+}
+
+func TestGetDocsAsset(t *testing.T) {
+	testVals := map[string]struct {
+		Status      int
+		ContentType string
+	}{
+		"/docs/docs.css":   {200, "text/css; charset=utf-8"},
+		"/docs/docs.js":    {200, "text/javascript; charset=utf-8"},
+		"/docs/index.html": {404, "application/json; charset=utf-8"},
+		"/docs/secret.txt": {404, "application/json; charset=utf-8"},
+	}
+	for path, want := range testVals {
+		rr := serveDocs(GetDocsAsset, path)
+		if rr.Code != want.Status {
+			t.Errorf("Wanted status %v for '%v', Got: %v", want.Status, path, rr.Code)
+		}
+		if got := rr.Header().Get("Content-Type"); got != want.ContentType {
+			t.Errorf("Wanted Content-Type '%v' for '%v', Got: '%v'", want.ContentType, path, got)
+		}
+	}
+}
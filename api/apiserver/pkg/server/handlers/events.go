@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package handlers
+
+import (
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/notify"
+	utils "apiserver/pkg/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// eventRetry is the delay in milliseconds clients wait before reconnecting to a dropped stream
+	eventRetry = 10000
+
+	// lastEventIdParam may be used in place of the Last-Event-ID header, which browsers cannot set
+	// on the first connection of an EventSource
+	lastEventIdParam = "lastEventId"
+)
+
+// eventHeartbeat is the time between two comments sent to keep an idle stream open through proxies
+var eventHeartbeat = 15 * time.Second
+
+// observationEvent is the data of an 'observation' event
+type observationEvent struct {
+	Dataset string      `json:"dataset"`
+	Date    string      `json:"date"`
+	Revised bool        `json:"revised"`
+	Entry   interface{} `json:"entry"`
+}
+
+// GetEvents is an ApiHandlerFunc type. It streams Server-Sent Events announcing each observation that is
+// added to or revised in the datasets. Clients may subscribe to some datasets only with the 'dataset'
+// parameter, and resume a dropped stream with the Last-Event-ID header. When events were missed, eg.
+// because the server restarted, a 'reset' event tells the client to reload the datasets.
+func GetEvents(ctx context.Context, handlerConfig *ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	if handlerConfig.Events == nil {
+		return utils.NewError(fmt.Errorf("no event broker configured"), "event stream unavailable", 503, false)
+	}
+
+	var datasets []string
+	lastId, resume := uint64(0), false
+	for key, val := range utils.ParseQuery(r) {
+		switch key {
+		case "dataset":
+			for _, id := range val {
+				if _, ok := models.DatasetById(id); !ok {
+					message := fmt.Sprintf("unknown dataset '%v': dataset=[%v]", id, strings.Join(val, ","))
+					return utils.NewError(fmt.Errorf("error when parsing query parameters"), message, 400, false).ForParam(key, nil)
+				}
+			}
+			datasets = val
+		case lastEventIdParam:
+		default:
+			message := fmt.Sprintf("unknown parameter for event streams: %v=[%v]", key, strings.Join(val, ","))
+			return utils.NewError(fmt.Errorf("error when parsing query parameters"), message, 400, false).ForParam(key, nil)
+		}
+	}
+
+	last := r.Header.Get("Last-Event-ID")
+	if last == "" {
+		last = r.URL.Query().Get(lastEventIdParam)
+	}
+	if last != "" {
+		id, err := strconv.ParseUint(last, 10, 64)
+		if err != nil {
+			return utils.NewError(fmt.Errorf("error when parsing last event ID"), "Last-Event-ID must be the ID of an event: Last-Event-ID=["+last+"]", 400, false).ForParam("Last-Event-ID", err)
+		}
+		lastId, resume = id, true
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return utils.NewError(fmt.Errorf("response writer cannot be flushed"), "event streams are not supported by this server", 500, false)
+	}
+
+	// Parse RequestID param
+	id, idError := utils.GetReqId(r)
+	if idError != nil {
+		return utils.NewError(idError, "cannot extract request ID", 500, false)
+	}
+
+	sub := handlerConfig.Events.Subscribe(datasets, lastId, resume)
+	defer handlerConfig.Events.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.Header().Set("X-Request-Id", id)
+	fmt.Fprintf(w, "retry: %d\n\n", eventRetry)
+
+	if sub.Missed {
+		fmt.Fprintf(w, "event: reset\ndata: {\"reason\":\"events were missed since the last event received, reload the datasets\"}\n\n")
+	}
+	for _, event := range sub.Replay {
+		if err := writeEvent(w, event); err != nil {
+			return nil
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+
+	// The stream ends when the client disconnects. The handler context is not tied to the request.
+	for {
+		select {
+		case event, ok := <-sub.Events:
+			if !ok {
+				// The client fell behind and will resume from the history when it reconnects
+				return nil
+			}
+			if err := writeEvent(w, event); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		case <-r.Context().Done():
+			return nil
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes an 'observation' event.
+func writeEvent(w http.ResponseWriter, event notify.Event) error {
+	data, err := json.Marshal(observationEvent{
+		Dataset: event.Dataset,
+		Date:    event.Date.Format("2006-01-02"),
+		Revised: event.Revised,
+		Entry:   event.Entry,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: observation\ndata: %s\n\n", event.Id, data)
+	return err
+}
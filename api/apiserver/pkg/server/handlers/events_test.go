@@ -0,0 +1,174 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package handlers
+
+import (
+	"apiserver/pkg/notify"
+	utils "apiserver/pkg/utils"
+	"apiserver/test"
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// eventServer serves GetEvents from a broker.
+func eventServer(broker *notify.Broker) *httptest.Server {
+	config := &ApiHandlerConfig{Events: broker}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = test.SetReqIdTest(r)
+		if err := GetEvents(context.Background(), config, w, r); err != nil {
+			utils.HttpJsonError(w, r, err)
+		}
+	}))
+}
+
+// readEvent reads the lines of the next event or comment of a stream.
+func readEvent(t *testing.T, reader *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("error reading event stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func co2Change(day int) notify.Change {
+	return notify.Change{Dataset: "co2_weekly_mlo", Date: time.Date(2021, 5, day, 0, 0, 0, 0, time.UTC), Entry: map[string]float32{"Average": 419.5}}
+}
+
+func TestGetEvents(t *testing.T) {
+	broker := notify.NewBroker(10)
+	first := broker.Publish(co2Change(2))
+
+	server := eventServer(broker)
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"?dataset=co2_weekly_mlo", nil)
+	req.Header.Set("Last-Event-ID", strconv.FormatUint(first.Id-1, 10))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("Wanted a text/event-stream Content-Type, Got: '%v'.", contentType)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	if lines := readEvent(t, reader); len(lines) != 1 || lines[0] != "retry: 10000" {
+		t.Errorf("Wanted the stream to start with a retry delay, Got: %v", lines)
+	}
+
+	// The event published before the client connected is replayed
+	want := []string{"id: " + strconv.FormatUint(first.Id, 10), "event: observation", `data: {"dataset":"co2_weekly_mlo","date":"2021-05-02","revised":false,"entry":{"Average":419.5}}`}
+	if lines := readEvent(t, reader); strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Wanted the replayed event %v, Got: %v", want, lines)
+	}
+
+	// Events of other datasets are filtered out
+	broker.Publish(notify.Change{Dataset: "ch4_mm_gl", Date: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)})
+	revised := co2Change(2)
+	revised.Revised = true
+	second := broker.Publish(revised)
+
+	lines := readEvent(t, reader)
+	if len(lines) != 3 || lines[0] != "id: "+strconv.FormatUint(second.Id, 10) || !strings.Contains(lines[2], `"revised":true`) {
+		t.Errorf("Wanted the revised co2 event, Got: %v", lines)
+	}
+}
+
+func TestGetEventsReset(t *testing.T) {
+	server := eventServer(notify.NewBroker(10))
+	defer server.Close()
+
+	// Events published before the broker started cannot be replayed
+	resp, err := http.Get(server.URL + "?lastEventId=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent(t, reader)
+	if lines := readEvent(t, reader); len(lines) != 2 || lines[0] != "event: reset" {
+		t.Errorf("Wanted a reset event, Got: %v", lines)
+	}
+}
+
+func TestGetEventsHeartbeat(t *testing.T) {
+	defer func(heartbeat time.Duration) { eventHeartbeat = heartbeat }(eventHeartbeat)
+	eventHeartbeat = 10 * time.Millisecond
+
+	server := eventServer(notify.NewBroker(10))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent(t, reader)
+	if lines := readEvent(t, reader); len(lines) != 1 || lines[0] != ": heartbeat" {
+		t.Errorf("Wanted a heartbeat comment, Got: %v", lines)
+	}
+}
+
+func TestGetEventsErrors(t *testing.T) {
+	testVals := []struct {
+		config *ApiHandlerConfig
+		query  string
+		header string
+		code   int
+	}{
+		{&ApiHandlerConfig{}, "", "", 503},
+		{&ApiHandlerConfig{Events: notify.NewBroker(10)}, "?dataset=co2", "", 400},
+		{&ApiHandlerConfig{Events: notify.NewBroker(10)}, "?year=2020", "", 400},
+		{&ApiHandlerConfig{Events: notify.NewBroker(10)}, "", "latest", 400},
+	}
+
+	for _, testVal := range testVals {
+		req := test.SetReqIdTest(httptest.NewRequest("GET", "/v1/stream"+testVal.query, nil))
+		if testVal.header != "" {
+			req.Header.Set("Last-Event-ID", testVal.header)
+		}
+		err := GetEvents(context.Background(), testVal.config, httptest.NewRecorder(), req)
+		if err == nil || err.HttpCode != testVal.code {
+			t.Errorf("Wanted a %v error for '%v' with Last-Event-ID '%v', Got: %v", testVal.code, testVal.query, testVal.header, err)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package handlers
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	utils "apiserver/pkg/utils"
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// FeedEntries is the number of most recent observations published in a feed
+	FeedEntries = 20
+
+	// feedTagAuthority is the authority of the tag URIs identifying feeds and their entries. The date must never
+	// change, or every entry would appear new to feed readers.
+	feedTagAuthority = "planetpulse.io,2021"
+
+	// atomNamespace is the XML namespace of Atom feeds
+	atomNamespace = "http://www.w3.org/2005/Atom"
+)
+
+// Feed describes a feed of the most recent observations of a dataset.
+type Feed struct {
+	// Dataset is the dataset the feed publishes observations of
+	Dataset models.Dataset
+
+	// Title is the title of the feed
+	Title string
+
+	// Description describes the observations published in the feed
+	Description string
+
+	// Resource is the path of the JSON resource serving the dataset. Entries link to their observation in this resource.
+	Resource string
+
+	// Units are the units of the published values
+	Units string
+
+	// Updated is when the feed last changed
+	Updated time.Time
+
+	// Entries lists the published observations from newest to oldest
+	Entries []FeedEntry
+}
+
+// FeedEntry describes a single observation published in a feed.
+type FeedEntry struct {
+	// Date is the date of the observation
+	Date time.Time
+
+	// Value is the measured value, or NaN when the measurement is missing
+	Value float64
+
+	// YearAgo is the value measured a year before the observation, or NaN when it is missing
+	YearAgo float64
+}
+
+// NewFeedEntry returns a FeedEntry for an observation, treating the dataset's missing value as NaN.
+func NewFeedEntry(dataset models.Dataset, date time.Time, value float32, yearAgo float32) FeedEntry {
+	entry := FeedEntry{Date: date, Value: math.NaN(), YearAgo: math.NaN()}
+	if !dataset.IsMissing(value) {
+		entry.Value = float64(value)
+	}
+	if !dataset.IsMissing(yearAgo) {
+		entry.YearAgo = float64(yearAgo)
+	}
+	return entry
+}
+
+// ValidateFeedRequest rejects feed requests with query parameters. Feeds always publish the same entries,
+// so that every reader sees the same entity and conditional requests stay meaningful.
+func ValidateFeedRequest(r *http.Request) *utils.ServerError {
+	if len(r.URL.Query()) != 0 {
+		return utils.NewError(fmt.Errorf("unexpected query parameters"), "malformed query parameters, feeds take no query parameters", 400, false)
+	}
+	return nil
+}
+
+// FeedUpdated returns when a dataset last changed: when it was last ingested, or the date of its most recent
+// observation when unknown. A 404 error is returned if the dataset is empty.
+func FeedUpdated(db *database.Database, dataset models.Dataset) (time.Time, *utils.ServerError) {
+	observed, dberr := db.LatestObservation(dataset)
+	if dberr == sql.ErrNoRows {
+		return time.Time{}, utils.NewError(dberr, "no measurements available", 404, false)
+	} else if dberr != nil {
+		return time.Time{}, utils.NewError(dberr, "internal database error", 500, false)
+	}
+
+	ingested, dberr := db.LastIngested(dataset)
+	if dberr != nil {
+		return time.Time{}, utils.NewError(dberr, "internal database error", 500, false)
+	}
+	if ingested != nil {
+		return *ingested, nil
+	}
+	return observed, nil
+}
+
+// Id returns a tag URI identifying an entry. The URI only depends on the date of the observation,
+// so revised observations keep their identity.
+func (feed Feed) Id(entry *FeedEntry) string {
+	if entry == nil {
+		return fmt.Sprintf("tag:%v:%v", feedTagAuthority, feed.Dataset.Id)
+	}
+	return fmt.Sprintf("tag:%v:%v/%v", feedTagAuthority, feed.Dataset.Id, entry.Date.Format("20060102"))
+}
+
+// Permalink returns the absolute URL of the JSON resource holding an entry's observation.
+func (feed Feed) Permalink(base string, entry FeedEntry) string {
+	filter := url.Values{"filter": {fmt.Sprintf("yyyymmdd = '%v'", entry.Date.Format("2006-01-02"))}}
+	return base + feed.Resource + "?" + filter.Encode()
+}
+
+// Title returns the title of an entry.
+func (entry FeedEntry) Title(feed Feed) string {
+	if math.IsNaN(entry.Value) {
+		return fmt.Sprintf("%v: no measurement", entry.Date.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("%v: %.2f %v", entry.Date.Format("2006-01-02"), entry.Value, feed.Units)
+}
+
+// Summary returns a sentence describing an entry's value and its change since the same period a year earlier.
+func (entry FeedEntry) Summary(feed Feed) string {
+	switch {
+	case math.IsNaN(entry.Value):
+		return fmt.Sprintf("No measurement was made for the period starting %v.", entry.Date.Format("2006-01-02"))
+	case math.IsNaN(entry.YearAgo):
+		return fmt.Sprintf("%.2f %v for the period starting %v. No measurement was made a year earlier.",
+			entry.Value, feed.Units, entry.Date.Format("2006-01-02"))
+	default:
+		return fmt.Sprintf("%.2f %v for the period starting %v, %+.2f %v since last year.",
+			entry.Value, feed.Units, entry.Date.Format("2006-01-02"), entry.Value-entry.YearAgo, feed.Units)
+	}
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title     string   `xml:"title"`
+	Id        string   `xml:"id"`
+	Link      atomLink `xml:"link"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+	Summary   string   `xml:"summary"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	Id       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Author   string      `xml:"author>name"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	Guid        rssGuid `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssFeed struct {
+	XMLName       xml.Name  `xml:"rss"`
+	Version       string    `xml:"version,attr"`
+	Title         string    `xml:"channel>title"`
+	Link          string    `xml:"channel>link"`
+	Description   string    `xml:"channel>description"`
+	LastBuildDate string    `xml:"channel>lastBuildDate"`
+	Items         []rssItem `xml:"channel>item"`
+}
+
+// atom renders a feed as an Atom 1.0 document.
+func (feed Feed) atom(base string, self string) interface{} {
+	doc := atomFeed{
+		Xmlns:    atomNamespace,
+		Title:    feed.Title,
+		Subtitle: feed.Description,
+		Id:       feed.Id(nil),
+		Updated:  feed.Updated.UTC().Format(time.RFC3339),
+		Author:   "PlanetPulse",
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: base + self},
+			{Rel: "alternate", Type: "application/json", Href: base + feed.Resource},
+		},
+	}
+	for i := range feed.Entries {
+		entry := feed.Entries[i]
+		date := entry.Date.UTC().Format(time.RFC3339)
+		doc.Entries = append(doc.Entries, atomEntry{
+			Title:     entry.Title(feed),
+			Id:        feed.Id(&entry),
+			Link:      atomLink{Rel: "alternate", Type: "application/json", Href: feed.Permalink(base, entry)},
+			Published: date,
+			Updated:   date,
+			Summary:   entry.Summary(feed),
+		})
+	}
+	return doc
+}
+
+// rss renders a feed as an RSS 2.0 document.
+func (feed Feed) rss(base string) interface{} {
+	doc := rssFeed{
+		Version:       "2.0",
+		Title:         feed.Title,
+		Link:          base + feed.Resource,
+		Description:   feed.Description,
+		LastBuildDate: feed.Updated.UTC().Format(time.RFC1123Z),
+	}
+	for i := range feed.Entries {
+		entry := feed.Entries[i]
+		doc.Items = append(doc.Items, rssItem{
+			Title:       entry.Title(feed),
+			Link:        feed.Permalink(base, entry),
+			Description: entry.Summary(feed),
+			Guid:        rssGuid{IsPermaLink: false, Value: feed.Id(&entry)},
+			PubDate:     entry.Date.UTC().Format(time.RFC1123Z),
+		})
+	}
+	return doc
+}
+
+// BaseUrl returns the scheme and host a request was made to, honoring the X-Forwarded-Proto header
+// set by TLS terminating proxies.
+func BaseUrl(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// WriteFeed renders a feed as Atom or RSS, depending on whether the requested path ends in '.rss'.
+// Conditional requests are answered with '304 Not Modified' when the feed has not changed.
+func WriteFeed(w http.ResponseWriter, r *http.Request, feed Feed) *utils.ServerError {
+	// Parse RequestID param
+	id, idError := utils.GetReqId(r)
+	if idError != nil {
+		return utils.NewError(idError, "cannot extract request ID", 500, false)
+	}
+
+	base := BaseUrl(r)
+	doc, contentType := feed.atom(base, r.URL.Path), "application/atom+xml; charset=utf-8"
+	if strings.HasSuffix(r.URL.Path, ".rss") {
+		doc, contentType = feed.rss(base), "application/rss+xml; charset=utf-8"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(doc); err != nil {
+		return utils.NewError(err, "error rendering feed", 500, false)
+	}
+
+	// The tag is derived from the rendered document, so that revised observations invalidate cached feeds
+	sum := sha256.Sum256(buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("X-Request-Id", id)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", feed.Updated.UTC().Format(http.TimeFormat))
+	if notModified(r, etag, feed.Updated) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if _, err := buf.WriteTo(w); err != nil {
+		return utils.NewError(err, "error writing feed", 500, false)
+	}
+	return nil
+}
+
+// notModified evaluates the If-None-Match and If-Modified-Since headers of a request against the current
+// entity tag and modification time of a resource. As required by RFC 7232, If-Modified-Since is ignored
+// when If-None-Match is present. http.ServeContent is not used as it sets a Content-Length that the
+// gzip middleware would invalidate.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
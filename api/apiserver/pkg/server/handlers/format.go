@@ -0,0 +1,301 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package handlers
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	utils "apiserver/pkg/utils"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Format represents an encoding of dataset query results that may be requested by a client.
+type Format string
+
+const (
+	// JSON encodes results inside the envelope of the version of the API (see utils.Serializer). This is the default format.
+	JSON Format = "json"
+
+	// CSV encodes results as comma-separated values with a header row
+	CSV Format = "csv"
+
+	// TSV encodes results as tab-separated values with a header row
+	TSV Format = "tsv"
+
+	// NDJSON streams results as one JSON object per line, without an envelope
+	NDJSON Format = "ndjson"
+
+	// Arrow encodes results as an Arrow IPC stream holding a single record batch
+	Arrow Format = "arrow"
+
+	// Parquet encodes results as a Parquet file
+	Parquet Format = "parquet"
+)
+
+// mediaTypes maps the media types accepted in an Accept header to the format they select.
+var mediaTypes = map[string]Format{
+	"application/json":                    JSON,
+	"application/*":                       JSON,
+	"*/*":                                 JSON,
+	"text/csv":                            CSV,
+	"text/*":                              CSV,
+	"text/tab-separated-values":           TSV,
+	"application/x-ndjson":                NDJSON,
+	"application/vnd.apache.arrow.stream": Arrow,
+	"application/vnd.apache.parquet":      Parquet,
+	"application/x-parquet":               Parquet,
+}
+
+// contentTypes maps a format to the Content-Type header of responses encoded in that format.
+var contentTypes = map[Format]string{
+	JSON:    "application/json; charset=UTF-8",
+	CSV:     "text/csv; charset=utf-8; header=present",
+	TSV:     "text/tab-separated-values; charset=utf-8",
+	NDJSON:  "application/x-ndjson",
+	Arrow:   "application/vnd.apache.arrow.stream",
+	Parquet: "application/vnd.apache.parquet",
+}
+
+// extensions maps a format to the extension of the file name suggested to clients.
+// Responses in formats without an extension are displayed inline.
+var extensions = map[Format]string{
+	CSV:     "csv",
+	TSV:     "tsv",
+	Arrow:   "arrows",
+	Parquet: "parquet",
+}
+
+// NegotiateFormat selects the format of a response. The 'format' query parameter takes precedence over
+// the Accept header. JSON is returned when neither selects a supported format.
+func NegotiateFormat(r *http.Request) (Format, *utils.ServerError) {
+	if param, ok := r.URL.Query()["format"]; ok {
+		format := Format(strings.ToLower(strings.Join(param, ",")))
+		if _, ok := contentTypes[format]; !ok {
+			message := fmt.Sprintf("malformed query parameters, unknown format. Allowed formats are: %v, %v, %v, %v, %v, %v: format=[%v]", JSON, CSV, TSV, NDJSON, Arrow, Parquet, strings.Join(param, ","))
+			return "", utils.NewError(fmt.Errorf("error when parsing query parameters"), message, 400, false).ForParam("format", nil)
+		}
+		return format, nil
+	}
+
+	type accepted struct {
+		format Format
+		q      float64
+	}
+	var formats []accepted
+	for _, mediaRange := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		format, ok := mediaTypes[mediaType]
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if val, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(val, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			formats = append(formats, accepted{format, q})
+		}
+	}
+
+	// Media types of equal quality are preferred in the order they were listed
+	sort.SliceStable(formats, func(i, j int) bool { return formats[i].q > formats[j].q })
+	if len(formats) == 0 {
+		return JSON, nil
+	}
+	return formats[0].format, nil
+}
+
+// WriteResults encodes the results of a dataset query in the requested format. Pagination links to the
+// previous and next pages of results are returned in the Link header for every format, as the
+// delimited formats have no envelope to hold them. JSON responses describe the dataset in their metadata.
+func WriteResults(w http.ResponseWriter, r *http.Request, handlerConfig *ApiHandlerConfig, format Format, dataset models.Dataset, query database.DBQuery, results []interface{}) *utils.ServerError {
+	// Parse RequestID param
+	id, idError := utils.GetReqId(r)
+	if idError != nil {
+		return utils.NewError(idError, "cannot extract request ID", 500, false)
+	}
+
+	setHeaders(w, r, format, id)
+	setPaginationHeaders(w, r, query, len(results))
+
+	if ext, ok := extensions[format]; ok {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%v.%v\"", dataset.Id, ext))
+	}
+
+	columns := query.Fields
+	if len(columns) == 0 {
+		columns = dataset.Columns
+	}
+
+	switch format {
+	case CSV, TSV:
+		if err := writeDelimited(w, format, dataset, columns, results); err != nil {
+			return utils.NewError(err, "error encoding data as "+string(format), 500, false)
+		}
+		return nil
+	case Arrow, Parquet:
+		// Columnar formats are encoded in full before writing, so that encoding errors can still be returned to the client
+		var buf bytes.Buffer
+		encode := writeArrow
+		if format == Parquet {
+			encode = writeParquet
+		}
+		if err := encode(&buf, dataset, columns, results); err != nil {
+			w.Header().Del("Content-Disposition")
+			return utils.NewError(err, "error encoding data as "+string(format), 500, false)
+		}
+		if _, err := buf.WriteTo(w); err != nil {
+			return utils.NewError(err, "error writing "+string(format)+" data", 500, false)
+		}
+		return nil
+	}
+
+	metadata := DatasetMetadata(handlerConfig, dataset, columns)
+	return utils.WriteJson(w, r, results, utils.Meta{Dataset: &dataset, Metadata: &metadata}, query.Pretty)
+}
+
+// DatasetMetadata returns the metadata of a dataset for results holding the given columns. The time the dataset was
+// last updated is read from the coverage cached by the catalog. It is left out when there is no catalog or the
+// coverage cannot be computed, as the results are still worth returning without it.
+func DatasetMetadata(handlerConfig *ApiHandlerConfig, dataset models.Dataset, columns []models.Column) models.Metadata {
+	if handlerConfig.Catalog == nil {
+		return dataset.Metadata(columns, nil)
+	}
+	coverage, err := handlerConfig.Catalog.Coverage(handlerConfig.Database, dataset)
+	if err != nil {
+		log.Warnf("Cannot look up when dataset '%v' was last updated: %v", dataset.Id, err)
+	}
+	return dataset.Metadata(columns, coverage.LastIngested)
+}
+
+// setHeaders sets the headers common to responses in every format.
+func setHeaders(w http.ResponseWriter, r *http.Request, format Format, id string) {
+	w.Header().Set("Content-Type", contentTypes[format])
+	w.Header().Add("Vary", "Accept")
+	w.Header().Set("X-Request-Id", id)
+}
+
+// ValidateLimit checks that a query is only left unlimited when its results are streamed.
+func ValidateLimit(format Format, query database.DBQuery) *utils.ServerError {
+	if query.Limit < 0 && format != NDJSON {
+		message := fmt.Sprintf("malformed query parameters, a limit of 'all' is only allowed when streaming %v: limit=[all]", contentTypes[NDJSON])
+		return utils.NewError(fmt.Errorf("error when parsing query parameters"), message, 400, false).ForParam("limit", nil)
+	}
+	return nil
+}
+
+// setPaginationHeaders links to the neighbouring pages of a query. A next page is only linked when
+// the current page is full, and a previous page when the query is not on the first page.
+// Pages are numbered from 1 in query parameters and headers.
+func setPaginationHeaders(w http.ResponseWriter, r *http.Request, query database.DBQuery, count int) {
+	if query.Limit < 0 {
+		return
+	}
+	page := query.Page + 1
+	w.Header().Set("X-Page", strconv.Itoa(page))
+	w.Header().Set("X-Limit", strconv.Itoa(query.Limit))
+
+	link := func(page int, rel string) string {
+		params := r.URL.Query()
+		params.Set("page", strconv.Itoa(page))
+		return fmt.Sprintf("<%v?%v>; rel=\"%v\"", r.URL.Path, params.Encode(), rel)
+	}
+
+	var links []string
+	if page > 1 {
+		links = append(links, link(1, "first"), link(page-1, "prev"))
+	}
+	if query.Limit > 0 && count >= query.Limit {
+		links = append(links, link(page+1, "next"))
+	}
+	if len(links) != 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// writeDelimited writes a header row holding the field name of each column followed by a row for each result.
+// Missing measurements are left blank.
+func writeDelimited(w http.ResponseWriter, format Format, dataset models.Dataset, columns []models.Column, results []interface{}) error {
+	writer := csv.NewWriter(w)
+	if format == TSV {
+		writer.Comma = '\t'
+	}
+
+	record := make([]string, len(columns))
+	for i, col := range columns {
+		record[i] = col.Field
+	}
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+
+	for _, entry := range results {
+		values, err := models.Values(entry, columns)
+		if err != nil {
+			return err
+		}
+		for i, val := range values {
+			record[i] = formatValue(dataset, val)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatValue formats a single value of a result for a delimited response.
+func formatValue(dataset models.Dataset, val interface{}) string {
+	switch v := val.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case float32:
+		if dataset.IsMissing(v) {
+			return ""
+		}
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case time.Time:
+		return v.Format("2006-01-02")
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
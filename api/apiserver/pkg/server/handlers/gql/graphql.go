@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package gql
+
+import (
+	"apiserver/pkg/server/handlers"
+	"apiserver/pkg/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// maxBodySize is the largest request body accepted, in bytes.
+const maxBodySize = 1 << 20
+
+// request represents a GraphQL request, sent either as the query parameters of a GET request or as the JSON body
+// of a POST request.
+type request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// Serve is an ApiHandlerFunc type. It executes a GraphQL query against the datasets and writes the result as JSON,
+// with the request ID in the 'extensions' of the response. Queries that are too deep or too complex are rejected
+// before any data is read.
+func Serve(ctx context.Context, handlerConfig *handlers.ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	req, err := parseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	// Parse RequestID param
+	id, idError := utils.GetReqId(r)
+	if idError != nil {
+		return utils.NewError(idError, "cannot extract request ID", 500, false)
+	}
+
+	var result *graphql.Result
+	if doc, parseErr := parser.Parse(parser.ParseParams{Source: req.Query}); parseErr == nil {
+		if limitErr := checkLimits(doc, req.OperationName, req.Variables); limitErr != nil {
+			result = &graphql.Result{Errors: gqlerrors.FormatErrors(limitErr)}
+		}
+	}
+	if result == nil {
+		result = graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Context:        withDatabase(r.Context(), handlerConfig.Database),
+		})
+	}
+	result.Extensions = map[string]interface{}{"requestId": id}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	// Requests that could not be executed at all are client errors. Errors of individual fields are reported
+	// alongside the data that could be resolved.
+	if result.Data == nil && result.HasErrors() {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		return utils.NewError(err, "error encoding data as json", 500, false)
+	}
+	return nil
+}
+
+// parseRequest reads a GraphQL request from the query parameters of a GET request, or from the body of a POST
+// request encoded either as JSON or as a bare 'application/graphql' query.
+func parseRequest(r *http.Request) (request, *utils.ServerError) {
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		params := r.URL.Query()
+		req.Query = params.Get("query")
+		req.OperationName = params.Get("operationName")
+		if variables := params.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				message := "variables must be a JSON object: variables=[" + variables + "]"
+				return req, utils.NewError(fmt.Errorf("error when parsing query parameters"), message, 400, false).ForParam("variables", err)
+			}
+		}
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+		if err != nil {
+			return req, utils.NewError(err, "error reading request body", 400, false).WithCode(utils.CodeInvalidBody)
+		}
+		if len(body) > maxBodySize {
+			return req, utils.NewError(fmt.Errorf("request body too large"), fmt.Sprintf("request body must not exceed %d bytes", maxBodySize), 413, false)
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == "application/graphql" {
+			req.Query = string(body)
+		} else if err := json.Unmarshal(body, &req); err != nil {
+			return req, utils.NewError(err, "request body must be a JSON object with a 'query' field", 400, false).WithCode(utils.CodeInvalidBody)
+		}
+	default:
+		return req, utils.NewError(fmt.Errorf("method not allowed"), "graphql requests must use GET or POST", 405, false)
+	}
+
+	if req.Query == "" {
+		return req, utils.NewError(fmt.Errorf("missing graphql query"), "a graphql query must be provided in the 'query' field", 400, false).ForParam("query", nil)
+	}
+	return req, nil
+}
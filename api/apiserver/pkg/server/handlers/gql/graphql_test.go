@@ -0,0 +1,255 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package gql
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/server/handlers"
+	"apiserver/test"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+var (
+	co2Columns = []string{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd"}
+	ch4Columns = []string{"year", "month", "day", "date_decimal", "average", "average_unc", "trend", "trend_unc", "yyyymmdd"}
+)
+
+func co2Row(rows *sqlmock.Rows, year, month, day int, average float32) *sqlmock.Rows {
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	increase := float32(-999.99)
+	if average != -999.99 {
+		increase = average - 280
+	}
+	return rows.AddRow(year, month, day, float32(year), average, 7, float32(-999.99), float32(-999.99), increase, date)
+}
+
+func ch4Row(rows *sqlmock.Rows, year, month int, average float32) *sqlmock.Rows {
+	date := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	return rows.AddRow(year, month, 1, float32(year), average, float32(0.5), average-1, float32(0.5), date)
+}
+
+// graphqlResult is the decoded body of a GraphQL response
+type graphqlResult struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+// serve executes a GraphQL request against a mock database and decodes the response.
+func serve(t *testing.T, mock func(sqlmock.Sqlmock), method string, target string, body string) (int, graphqlResult) {
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error generating mock database: %s", err.Error())
+	}
+	defer db.Close()
+	mock(dbMock)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = test.SetReqIdTest(req)
+	w := httptest.NewRecorder()
+
+	if err := Serve(context.Background(), &handlers.ApiHandlerConfig{Database: &database.Database{DB: db}}, w, req); err != nil {
+		test.ErrorLog(t, err)
+		t.Fatalf("Unexpected error from Serve for the query %v", body)
+	}
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	var result graphqlResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Response is not valid JSON: %v, %v", err, w.Body.String())
+	}
+	if id, _ := result.Extensions["requestId"].(string); id == "" {
+		t.Errorf("Wanted the request ID in the response extensions, Got: %v", w.Body.String())
+	}
+	return w.Code, result
+}
+
+func TestServeCo2(t *testing.T) {
+	code, result := serve(t, func(mock sqlmock.Sqlmock) {
+		rows := sqlmock.NewRows(co2Columns)
+		co2Row(rows, 2020, 6, 7, 417.5)
+		co2Row(rows, 2020, 6, 14, -999.99)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM public.co2_weekly_mlo WHERE year in ('2020') ORDER BY year,month,day LIMIT 2`)).WillReturnRows(rows)
+	}, "POST", "/v1/graphql", `{"query": "query($limit: Int) { co2(year: [\"2020\"], limit: $limit) { year day average incSincePreIndustrial timestamp } }", "variables": {"limit": 2}}`)
+
+	if code != 200 || len(result.Errors) != 0 {
+		t.Fatalf("Wanted a successful query, Got: %v, %+v", code, result)
+	}
+
+	got, _ := json.Marshal(result.Data)
+	want := `{"co2":[{"average":417.5,"day":7,"incSincePreIndustrial":137.5,"timestamp":"2020-06-07","year":2020},{"average":null,"day":14,"incSincePreIndustrial":null,"timestamp":"2020-06-14","year":2020}]}`
+	if string(got) != want {
+		t.Errorf("Wanted data %v, Got: %v", want, string(got))
+	}
+}
+
+func TestServeCh4Bounds(t *testing.T) {
+	code, result := serve(t, func(mock sqlmock.Sqlmock) {
+		rows := sqlmock.NewRows(ch4Columns)
+		ch4Row(rows, 2021, 1, 1893.41)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM public.ch4_mm_gl WHERE trend > 1890.00 ORDER BY year,month LIMIT 10`)).WillReturnRows(rows)
+	}, "GET", "/v1/graphql?query="+url.QueryEscape(`{ ch4(gt: 1890, value: TREND) { month trend } }`), "")
+
+	if code != 200 || len(result.Errors) != 0 {
+		t.Fatalf("Wanted a successful query, Got: %v, %+v", code, result)
+	}
+	got, _ := json.Marshal(result.Data)
+	if want := `{"ch4":[{"month":1,"trend":1892.41}]}`; string(got) != want {
+		t.Errorf("Wanted data %v, Got: %v", want, string(got))
+	}
+}
+
+func TestServeMonths(t *testing.T) {
+	code, result := serve(t, func(mock sqlmock.Sqlmock) {
+		co2Rows := sqlmock.NewRows(co2Columns)
+		co2Row(co2Rows, 2020, 1, 5, 413.0)
+		co2Row(co2Rows, 2020, 1, 12, 414.0)
+		co2Row(co2Rows, 2020, 2, 2, -999.99)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM public.co2_weekly_mlo WHERE year in ('2020') ORDER BY year,month,day`) + `$`).WillReturnRows(co2Rows)
+
+		ch4Rows := sqlmock.NewRows(ch4Columns)
+		ch4Row(ch4Rows, 2020, 1, 1874.5)
+		ch4Row(ch4Rows, 2020, 3, 1877.0)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM public.ch4_mm_gl WHERE year in ('2020') ORDER BY year,month`) + `$`).WillReturnRows(ch4Rows)
+	}, "POST", "/v1/graphql", `{"query": "{ months(year: [\"2020\"], offset: 1, limit: 5) { year month co2Average co2 { day } ch4 { average } } }"}`)
+
+	if code != 200 || len(result.Errors) != 0 {
+		t.Fatalf("Wanted a successful query, Got: %v, %+v", code, result)
+	}
+
+	// January is skipped by the offset, February has no CH4 and March has no CO2
+	got, _ := json.Marshal(result.Data)
+	want := `{"months":[{"ch4":null,"co2":[{"day":2}],"co2Average":null,"month":2,"year":2020},{"ch4":{"average":1877},"co2":[],"co2Average":null,"month":3,"year":2020}]}`
+	if string(got) != want {
+		t.Errorf("Wanted data %v, Got: %v", want, string(got))
+	}
+}
+
+func TestCo2Average(t *testing.T) {
+	weeks := []map[string]interface{}{{"average": 413.0}, {"average": nil}, {"average": 414.5}}
+	if avg := co2Average(weeks); avg != 413.75 {
+		t.Errorf("Wanted an average of 413.75, Got: %v", avg)
+	}
+	if avg := co2Average(nil); avg != nil {
+		t.Errorf("Wanted no average without measurements, Got: %v", avg)
+	}
+}
+
+func TestServeInvalidArguments(t *testing.T) {
+	code, result := serve(t, func(mock sqlmock.Sqlmock) {}, "POST", "/v1/graphql", `{"query": "{ co2(month: [\"13\"]) { year } }"}`)
+	if code != 400 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, "month=[13]") {
+		t.Errorf("Wanted a 400 error naming the invalid month, Got: %v, %+v", code, result)
+	}
+
+	code, result = serve(t, func(mock sqlmock.Sqlmock) {}, "POST", "/v1/graphql", `{"query": "{ co2 { co2 } }"}`)
+	if code != 400 || len(result.Errors) == 0 {
+		t.Errorf("Wanted a 400 error for an unknown field, Got: %v, %+v", code, result)
+	}
+}
+
+func TestServeLimits(t *testing.T) {
+	testVals := []struct {
+		body  string
+		error string
+	}{
+		{`{"query": "{ months(limit: 10000) { co2 { year month day average } } }"}`, "too complex"},
+		{`{"query": "query($n: Int) { months(limit: $n) { co2 { year month day average } } }", "variables": {"n": 10000}}`, "too complex"},
+		{`{"query": "{ ...a } fragment a on Query { months(limit: 10000) { ...b } } fragment b on Month { co2 { year month day average } }"}`, "too complex"},
+	}
+
+	for _, testVal := range testVals {
+		code, result := serve(t, func(mock sqlmock.Sqlmock) {}, "POST", "/v1/graphql", testVal.body)
+		if code != 400 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, testVal.error) {
+			t.Errorf("Wanted a 400 error containing '%v' for %v, Got: %v, %+v", testVal.error, testVal.body, code, result)
+		}
+	}
+}
+
+func TestCheckLimits(t *testing.T) {
+	testVals := []struct {
+		query string
+		valid bool
+	}{
+		{`{ co2(limit: 10000) { year month day dateDecimal average numDays oneYearAgo tenYearsAgo incSincePreIndustrial timestamp } }`, true},
+		{`{ months(limit: 100) { co2 { year month day average } ch4 { average } } }`, true},
+		{`{ __schema { types { fields { type { ofType { ofType { name } } } } } } }`, true},
+		{`{ a { b { c { d { e { f } } } } } }`, false},
+		{`{ ...a } fragment a on Query { ...a }`, true},
+	}
+
+	for _, testVal := range testVals {
+		doc, err := parser.Parse(parser.ParseParams{Source: testVal.query})
+		if err != nil {
+			t.Fatalf("error parsing %v: %v", testVal.query, err)
+		}
+		if err := checkLimits(doc, "", nil); (err == nil) != testVal.valid {
+			t.Errorf("Wanted valid=%v for %v, Got: %v", testVal.valid, testVal.query, err)
+		}
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	testVals := []struct {
+		method      string
+		target      string
+		contentType string
+		body        string
+		code        int
+	}{
+		{"GET", "/v1/graphql", "", "", 400},
+		{"GET", "/v1/graphql?query={co2{year}}&variables=[1]", "", "", 400},
+		{"POST", "/v1/graphql", "application/json", `{"query": `, 400},
+		{"POST", "/v1/graphql", "application/json", `{"query": "` + strings.Repeat(" ", maxBodySize) + `"}`, 413},
+		{"POST", "/v1/graphql", "application/graphql", `{ co2 { year } }`, 0},
+	}
+
+	for _, testVal := range testVals {
+		req := httptest.NewRequest(testVal.method, testVal.target, strings.NewReader(testVal.body))
+		req.Header.Set("Content-Type", testVal.contentType)
+		_, err := parseRequest(req)
+		if code := 0; err != nil {
+			code = err.HttpCode
+			if code != testVal.code {
+				t.Errorf("Wanted status %v for %v %v, Got: %v", testVal.code, testVal.method, testVal.target, code)
+			}
+		} else if testVal.code != 0 {
+			t.Errorf("Wanted status %v for %v %v, Got no error", testVal.code, testVal.method, testVal.target)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package gql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// maxDepth is the deepest selection a query may make, eg. 'months { co2 { average } }' has a depth of 3.
+	maxDepth = 5
+
+	// maxComplexity bounds the number of fields a query may resolve. Every field costs 1, multiplied by the
+	// number of results of the lists enclosing it, so that all the fields of 10000 weekly CO2 measurements
+	// may be requested at once but not 10000 months of them.
+	maxComplexity = 200000
+)
+
+// listSizes holds the most results a list field may return when its size is not limited by an argument,
+// keyed by the name of the parent field and then the list field. The top level fields have no parent.
+var listSizes = map[string]map[string]int{
+	"":       {"co2": defaultLimit, "ch4": defaultLimit, "months": defaultLimit},
+	"months": {"co2": weeksPerMonth},
+}
+
+// checkLimits rejects queries that are too deep or too complex before any of their fields are resolved.
+// Introspection fields are not limited. Queries that cannot be parsed are left to graphql.Do, which reports
+// syntax errors with their location.
+func checkLimits(doc *ast.Document, operationName string, variables map[string]interface{}) error {
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, def := range doc.Definitions {
+		if fragment, ok := def.(*ast.FragmentDefinition); ok && fragment.Name != nil {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok || (operationName != "" && (op.Name == nil || op.Name.Value != operationName)) {
+			continue
+		}
+
+		limiter := &limiter{fragments: fragments, variables: variables, visiting: map[string]bool{}}
+		depth, complexity := limiter.selectionSet(op.SelectionSet, "", 1)
+		if depth > maxDepth {
+			return fmt.Errorf("query is too deep: it has a depth of %d but at most %d is allowed", depth, maxDepth)
+		}
+		if complexity > maxComplexity {
+			return fmt.Errorf("query is too complex: it may resolve %d fields but at most %d are allowed, request fewer results or fields", complexity, maxComplexity)
+		}
+	}
+	return nil
+}
+
+type limiter struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+
+	// visiting holds the fragments being expanded, so that cyclic fragments (rejected by validation) terminate
+	visiting map[string]bool
+}
+
+// selectionSet returns the depth and complexity of a selection set, where each field costs multiplier.
+func (l *limiter) selectionSet(set *ast.SelectionSet, parent string, multiplier int) (int, int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	depth, complexity := 0, 0
+	add := func(d, c int) {
+		if d > depth {
+			depth = d
+		}
+		complexity = saturate(complexity + c)
+	}
+
+	for _, selection := range set.Selections {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if sel.Name == nil || strings.HasPrefix(sel.Name.Value, "__") {
+				continue
+			}
+			size := l.listSize(parent, sel)
+			d, c := l.selectionSet(sel.SelectionSet, sel.Name.Value, saturate(multiplier*size))
+			add(d+1, multiplier+c)
+		case *ast.InlineFragment:
+			add(l.selectionSet(sel.SelectionSet, parent, multiplier))
+		case *ast.FragmentSpread:
+			fragment, ok := l.fragments[sel.Name.Value]
+			if !ok || l.visiting[sel.Name.Value] {
+				continue
+			}
+			l.visiting[sel.Name.Value] = true
+			add(l.selectionSet(fragment.SelectionSet, parent, multiplier))
+			delete(l.visiting, sel.Name.Value)
+		}
+	}
+	return depth, complexity
+}
+
+// listSize returns the most results a field may return: its limit argument when it is a limited list,
+// its fixed size when it is an unlimited list, and 1 otherwise.
+func (l *limiter) listSize(parent string, field *ast.Field) int {
+	size, ok := listSizes[parent][field.Name.Value]
+	if !ok {
+		return 1
+	}
+	if parent != "" {
+		return size
+	}
+
+	for _, arg := range field.Arguments {
+		if arg.Name == nil || arg.Name.Value != "limit" {
+			continue
+		}
+		switch val := arg.Value.(type) {
+		case *ast.IntValue:
+			if limit, err := strconv.Atoi(val.Value); err == nil {
+				size = limit
+			}
+		case *ast.Variable:
+			// Variables decoded from JSON are float64
+			switch limit := l.variables[val.Name.Value].(type) {
+			case float64:
+				size = int(limit)
+			case int:
+				size = limit
+			}
+		}
+	}
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
+// saturate caps intermediate complexities so that deeply nested lists cannot overflow.
+func saturate(n int) int {
+	if n > maxComplexity+1 || n < 0 {
+		return maxComplexity + 1
+	}
+	return n
+}
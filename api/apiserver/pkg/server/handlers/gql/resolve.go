@@ -0,0 +1,314 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package gql
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/server/handlers/ch4"
+	"apiserver/pkg/server/handlers/co2"
+	utils "apiserver/pkg/utils"
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// defaultLimit is the number of results returned by a list when no limit is requested, as in the REST API.
+const defaultLimit = 10
+
+type contextKey int
+
+// dbKey is the context key of the database queried by resolvers
+const dbKey contextKey = iota
+
+// withDatabase returns a context from which resolvers read the database to query.
+func withDatabase(ctx context.Context, db *database.Database) context.Context {
+	return context.WithValue(ctx, dbKey, db)
+}
+
+func ctxDatabase(ctx context.Context) (*database.Database, error) {
+	db, ok := ctx.Value(dbKey).(*database.Database)
+	if !ok || db == nil {
+		return nil, fmt.Errorf("no database in the request context")
+	}
+	return db, nil
+}
+
+// queryParser parses query parameters into SQL filters and internal arguments, like co2.ParseValues.
+type queryParser func(params url.Values, sortBy string) ([]string, map[string]interface{}, *utils.ServerError)
+
+// argumentValues translates the arguments of a field into the query parameters of the equivalent REST request,
+// so that they are validated by the same code and produce the same errors.
+func argumentValues(args map[string]interface{}) url.Values {
+	values := url.Values{}
+	for key, arg := range args {
+		switch val := arg.(type) {
+		case []interface{}:
+			for _, v := range val {
+				if s, ok := v.(string); ok {
+					values[key] = append(values[key], strings.Split(s, ",")...)
+				}
+			}
+		case string:
+			if key != "value" {
+				values.Set(key, val)
+			}
+		case int:
+			values.Set(key, strconv.Itoa(val))
+		case float64:
+			values.Set(key, strconv.FormatFloat(val, 'f', -1, 64))
+		}
+	}
+	return values
+}
+
+// argumentError converts a parsing error into an error message naming the GraphQL argument at fault.
+func argumentError(err *utils.ServerError) error {
+	return fmt.Errorf("%v", err.Message)
+}
+
+// listQuery builds the database query serving a list of measurements of a dataset.
+func listQuery(dataset models.Dataset, parse queryParser, parseInternal func(map[string]interface{}, *database.DBQuery) error, args map[string]interface{}) (database.DBQuery, error) {
+	query := database.NewQuery(dataset.Table, []string{"*"}, strings.Join(dataset.Order, ","))
+
+	sortBy, _ := args["value"].(string)
+	filters, internalArgs, err := parse(argumentValues(args), sortBy)
+	if err != nil {
+		return query, argumentError(err)
+	}
+
+	query.Where = filters
+	parseInternal(internalArgs, &query)
+	return query, nil
+}
+
+// rows converts the entries of a dataset table into GraphQL objects keyed by field name.
+func rows(dataset models.Dataset, table []interface{}) ([]map[string]interface{}, error) {
+	results := make([]map[string]interface{}, 0, len(table))
+	for _, entry := range table {
+		values, err := models.Values(entry, dataset.Columns)
+		if err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(values))
+		for i, col := range dataset.Columns {
+			row[fieldName(col)] = fieldValue(dataset, values[i])
+		}
+		results = append(results, row)
+	}
+	return results, nil
+}
+
+// fieldValue converts a value loaded from the database into its GraphQL representation. Missing measurements
+// are null and single precision floats are rounded to the shortest decimal that represents them, eg. 417.29
+// rather than 417.290008544921875.
+func fieldValue(dataset models.Dataset, val interface{}) interface{} {
+	switch v := val.(type) {
+	case float32:
+		if dataset.IsMissing(v) {
+			return nil
+		}
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'f', -1, 32), 64)
+		return f
+	case time.Time:
+		return v.Format("2006-01-02")
+	}
+	return val
+}
+
+func resolveCo2(p graphql.ResolveParams) (interface{}, error) {
+	dataset := models.Co2WeeklyMlo
+	db, err := ctxDatabase(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := listQuery(dataset, co2.ParseValues, co2.ParseInternalArgs, p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	co2Table := models.Co2Table{}
+	if err := db.Query(query, &co2Table); err != nil {
+		return nil, fmt.Errorf("internal database error")
+	}
+	return rows(dataset, co2Table)
+}
+
+func resolveCh4(p graphql.ResolveParams) (interface{}, error) {
+	dataset := models.Ch4MmGl
+	db, err := ctxDatabase(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := listQuery(dataset, ch4.ParseValues, ch4.ParseInternalArgs, p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	ch4Table := models.Ch4Table{}
+	if err := db.Query(query, &ch4Table); err != nil {
+		return nil, fmt.Errorf("internal database error")
+	}
+	return rows(dataset, ch4Table)
+}
+
+// month holds the measurements of both gases during a calendar month.
+type month struct {
+	year, month int
+
+	co2 []map[string]interface{}
+	ch4 map[string]interface{}
+}
+
+// resolveMonths joins the CO2 and CH4 datasets by calendar month. Both datasets are filtered by the year and month
+// arguments, then the joined months are paginated.
+func resolveMonths(p graphql.ResolveParams) (interface{}, error) {
+	db, err := ctxDatabase(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, offset := defaultLimit, 0
+	monthArgs := map[string]interface{}{"value": "average"}
+	for key, val := range p.Args {
+		switch key {
+		case "limit":
+			limit = val.(int)
+		case "offset":
+			offset = val.(int)
+		}
+		monthArgs[key] = val
+	}
+
+	// Pagination applies to the joined months, so it is validated here but the gases are queried in full
+	co2Query, err := listQuery(models.Co2WeeklyMlo, co2.ParseValues, co2.ParseInternalArgs, monthArgs)
+	if err != nil {
+		return nil, err
+	}
+	ch4Query, err := listQuery(models.Ch4MmGl, ch4.ParseValues, ch4.ParseInternalArgs, monthArgs)
+	if err != nil {
+		return nil, err
+	}
+	co2Query.Limit, co2Query.Offset = -1, 0
+	ch4Query.Limit, ch4Query.Offset = -1, 0
+
+	co2Table, ch4Table := models.Co2Table{}, models.Ch4Table{}
+	if err := db.Query(co2Query, &co2Table); err != nil {
+		return nil, fmt.Errorf("internal database error")
+	}
+	if err := db.Query(ch4Query, &ch4Table); err != nil {
+		return nil, fmt.Errorf("internal database error")
+	}
+
+	co2Rows, err := rows(models.Co2WeeklyMlo, co2Table)
+	if err != nil {
+		return nil, err
+	}
+	ch4Rows, err := rows(models.Ch4MmGl, ch4Table)
+	if err != nil {
+		return nil, err
+	}
+
+	months := joinMonths(co2Rows, ch4Rows)
+	if offset > len(months) {
+		offset = len(months)
+	}
+	months = months[offset:]
+	if limit < len(months) {
+		months = months[:limit]
+	}
+
+	results := make([]map[string]interface{}, len(months))
+	for i, m := range months {
+		results[i] = map[string]interface{}{
+			"year":       m.year,
+			"month":      m.month,
+			"co2":        m.co2,
+			"co2Average": co2Average(m.co2),
+			"ch4":        nil,
+		}
+		// A nil map would resolve to an object of null fields rather than null
+		if m.ch4 != nil {
+			results[i]["ch4"] = m.ch4
+		}
+	}
+	return results, nil
+}
+
+// joinMonths merges rows of both gases, each ordered by date, into a list of months ordered by date.
+func joinMonths(co2Rows, ch4Rows []map[string]interface{}) []*month {
+	var months []*month
+	index := map[[2]int]*month{}
+	get := func(row map[string]interface{}) *month {
+		key := [2]int{row["year"].(int), row["month"].(int)}
+		if m, ok := index[key]; ok {
+			return m
+		}
+		m := &month{year: key[0], month: key[1], co2: []map[string]interface{}{}}
+		index[key] = m
+		months = append(months, m)
+		return m
+	}
+
+	for _, row := range co2Rows {
+		m := get(row)
+		m.co2 = append(m.co2, row)
+	}
+	for _, row := range ch4Rows {
+		get(row).ch4 = row
+	}
+
+	// Months measured only for CH4 were appended after the CO2 months
+	sort.SliceStable(months, func(i, j int) bool {
+		a, b := months[i], months[j]
+		return a.year < b.year || (a.year == b.year && a.month < b.month)
+	})
+	return months
+}
+
+// co2Average returns the mean of the weekly averages of a month, or nil when none were measured.
+func co2Average(weeks []map[string]interface{}) interface{} {
+	var sum float64
+	var count int
+	for _, week := range weeks {
+		if avg, ok := week["average"].(float64); ok {
+			sum += avg
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(sum/float64(count), 'f', 2, 64), 64)
+	return f
+}
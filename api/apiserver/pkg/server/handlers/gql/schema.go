@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package gql
+
+import (
+	"apiserver/pkg/database/models"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/graphql-go/graphql"
+)
+
+// schema is built once from the dataset definitions. Building it can only fail if the definitions are invalid.
+var schema = mustSchema()
+
+// weeksPerMonth is the most weekly observations a month can hold. It bounds the list of weeks in the complexity of a query.
+const weeksPerMonth = 5
+
+var (
+	co2Type = datasetType("Co2", models.Co2WeeklyMlo, "A weekly average CO2 measurement taken at Mauna Loa Observatory, in ppm.")
+	ch4Type = datasetType("Ch4", models.Ch4MmGl, "A monthly global average CH4 measurement, in ppb.")
+
+	co2ValueEnum = graphql.NewEnum(graphql.EnumConfig{
+		Name:        "Co2Value",
+		Description: "The CO2 measurement compared by the gt, lt, gte and lte arguments.",
+		Values: graphql.EnumValueConfigMap{
+			"AVERAGE":  &graphql.EnumValueConfig{Value: "average"},
+			"INCREASE": &graphql.EnumValueConfig{Value: "increase", Description: "The increase since 1800"},
+		},
+	})
+
+	ch4ValueEnum = graphql.NewEnum(graphql.EnumConfig{
+		Name:        "Ch4Value",
+		Description: "The CH4 measurement compared by the gt, lt, gte and lte arguments.",
+		Values: graphql.EnumValueConfigMap{
+			"AVERAGE": &graphql.EnumValueConfig{Value: "average"},
+			"TREND":   &graphql.EnumValueConfig{Value: "trend"},
+		},
+	})
+
+	monthType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Month",
+		Description: "The CO2 and CH4 measurements of a calendar month.",
+		Fields: graphql.Fields{
+			"year":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"month":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"co2":        &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(co2Type))), Description: "The weekly CO2 measurements taken during the month."},
+			"co2Average": &graphql.Field{Type: graphql.Float, Description: "The mean of the weekly CO2 averages of the month, or null when none were measured."},
+			"ch4":        &graphql.Field{Type: ch4Type, Description: "The CH4 measurement of the month, or null when it has not been published."},
+		},
+	})
+)
+
+// datasetType generates a GraphQL object type from the columns of a dataset. Fields are named after the fields of
+// the dataset's entries in lower camel case, eg. 'IncSincePreIndustrial' becomes 'incSincePreIndustrial'. Missing
+// measurements are null and dates are formatted as YYYY-MM-DD.
+func datasetType(name string, dataset models.Dataset, description string) *graphql.Object {
+	fields := graphql.Fields{}
+	for _, col := range dataset.Columns {
+		var fieldType graphql.Output
+		switch col.Type {
+		case models.Integer:
+			fieldType = graphql.NewNonNull(graphql.Int)
+		case models.Float:
+			fieldType = graphql.Float
+		case models.Date:
+			fieldType = graphql.NewNonNull(graphql.String)
+		}
+		fields[fieldName(col)] = &graphql.Field{Type: fieldType, Description: fmt.Sprintf("The '%v' column.", col.Name)}
+	}
+	return graphql.NewObject(graphql.ObjectConfig{Name: name, Description: description, Fields: fields})
+}
+
+// fieldName returns the name of the GraphQL field serving a column.
+func fieldName(col models.Column) string {
+	runes := []rune(col.Field)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// filterArgs returns the arguments shared by every list of measurements. They mirror the REST query parameters.
+func filterArgs() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"year":   &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String)), Description: "Years to match, eg. '2020', '1990..2000' or '!2020', as in the REST 'year' parameter."},
+		"month":  &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String)), Description: "Months to match, eg. '6', '6-8' or '!1', as in the REST 'month' parameter."},
+		"limit":  &graphql.ArgumentConfig{Type: graphql.Int, Description: "The maximum number of results, 10 by default."},
+		"offset": &graphql.ArgumentConfig{Type: graphql.Int, Description: "The number of results to skip."},
+	}
+}
+
+// measurementArgs returns the arguments of a list of measurements of a dataset, which may also be bounded by value.
+func measurementArgs(valueEnum *graphql.Enum) graphql.FieldConfigArgument {
+	args := filterArgs()
+	for _, bound := range []string{"gt", "lt", "gte", "lte"} {
+		args[bound] = &graphql.ArgumentConfig{Type: graphql.Float, Description: fmt.Sprintf("Bounds the measurement selected by 'value', as in the REST '%v' parameter.", bound)}
+	}
+	args["value"] = &graphql.ArgumentConfig{Type: valueEnum, DefaultValue: "average"}
+	args["filter"] = &graphql.ArgumentConfig{Type: graphql.String, Description: "A filter expression, as in the REST 'filter' parameter."}
+	return args
+}
+
+func mustSchema() graphql.Schema {
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"co2": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(co2Type))),
+				Description: "Weekly CO2 measurements, from oldest to newest.",
+				Args:        measurementArgs(co2ValueEnum),
+				Resolve:     resolveCo2,
+			},
+			"ch4": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(ch4Type))),
+				Description: "Monthly CH4 measurements, from oldest to newest.",
+				Args:        measurementArgs(ch4ValueEnum),
+				Resolve:     resolveCh4,
+			},
+			"months": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(monthType))),
+				Description: "CO2 and CH4 measurements joined by calendar month, from oldest to newest. Months with measurements of either gas are returned.",
+				Args:        filterArgs(),
+				Resolve:     resolveMonths,
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: query})
+	if err != nil {
+		panic(strings.Join([]string{"invalid graphql schema", err.Error()}, ": "))
+	}
+	return schema
+}
@@ -25,6 +25,8 @@ package handlers
 
 import (
 	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/notify"
 	utils "apiserver/pkg/utils"
 	"context"
 	"net/http"
@@ -45,6 +47,15 @@ type ApiHandlerConfig struct {
 	Database  *database.Database
 	PathParam bool
 	SortBy    string
+
+	// Events publishes the changes to the datasets to streaming clients
+	Events *notify.Broker
+
+	// Catalog caches the coverage of the datasets
+	Catalog *Catalog
+
+	// Conditions are the temperature and pressure concentrations requested in ug/m3 are computed at
+	Conditions models.Conditions
 }
 
 // ApiHandlerFunc represents an http handler used to serve data at a specific URL path.
@@ -24,10 +24,8 @@ Contact: planetpulse.api@gmail.com
 package handlers
 
 import (
-	"apiserver/pkg/database/models"
 	utils "apiserver/pkg/utils"
 	"context"
-	"encoding/json"
 	"net/http"
 )
 
@@ -38,24 +36,5 @@ func GetHealth(ctx context.Context, handlerConfig *ApiHandlerConfig, w http.Resp
 	if err := handlerConfig.Database.ProbeConnection(); err != nil {
 		return utils.NewError(err, "failed to connect to database", 500, false)
 	}
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "    ")
-
-	// Parse RequestID param
-	id, idError := utils.GetReqId(r)
-	if idError != nil {
-		return utils.NewError(idError, "cannot extract request ID", 500, false)
-	}
-
-	resp := models.ServerResp{
-		Results:   nil,
-		Status:    "OK",
-		RequestId: id,
-		Error:     nil,
-	}
-
-	if err := enc.Encode(resp); err != nil {
-		return utils.NewError(err, "error encoding data as json", 500, false)
-	}
-	return nil
+	return utils.WriteJson(w, r, nil, utils.Meta{}, true)
 }
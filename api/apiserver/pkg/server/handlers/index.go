@@ -29,9 +29,8 @@ import (
 	"net/http"
 )
 
-// GetIndex currently redirects users to version 1 of the API.
-// Later this can be expanded to support multiple API versions.
+// GetIndex redirects users to the documentation of the API, which links to each of its versions.
 func GetIndex(ctx context.Context, handlerConfig *ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
-	http.Redirect(w, r, "/v1", http.StatusMovedPermanently)
+	http.Redirect(w, r, "/docs", http.StatusMovedPermanently)
 	return nil
 }
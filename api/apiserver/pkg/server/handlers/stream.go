@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package handlers
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	utils "apiserver/pkg/utils"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// streamFlushRows is the number of rows written to a stream between flushes of the response.
+const streamFlushRows = 500
+
+// rowWriter is a models.DataObject that writes each row to the client as soon as it has been loaded,
+// so that the results of a query never need to be held in memory.
+type rowWriter struct {
+	// dataObject loads a single row into table
+	dataObject models.DataObject
+	table      *[]interface{}
+
+	enc     *json.Encoder
+	flusher http.Flusher
+	count   int
+}
+
+// Load loads a row with the wrapped DataObject and writes it as a line of JSON.
+func (rw *rowWriter) Load(rows *sql.Rows, simple bool) error {
+	if err := rw.dataObject.Load(rows, simple); err != nil {
+		return err
+	}
+	for _, entry := range *rw.table {
+		if err := rw.enc.Encode(entry); err != nil {
+			return err
+		}
+		rw.count++
+	}
+	*rw.table = (*rw.table)[:0]
+
+	if rw.flusher != nil && rw.count%streamFlushRows == 0 {
+		rw.flusher.Flush()
+	}
+	return nil
+}
+
+// StreamResults queries the database and streams the results to the client as newline-delimited JSON.
+// Rows are written as they are read from the database cursor. dataObject must load rows into table.
+// As the status of the response is sent with the first row, errors that occur later are reported in
+// the X-Stream-Error trailer. The number of rows written is reported in the X-Result-Count trailer.
+func StreamResults(w http.ResponseWriter, r *http.Request, db *database.Database, query database.DBQuery, dataObject models.DataObject, table *[]interface{}) *utils.ServerError {
+	// Parse RequestID param
+	id, idError := utils.GetReqId(r)
+	if idError != nil {
+		return utils.NewError(idError, "cannot extract request ID", 500, false)
+	}
+
+	setHeaders(w, r, NDJSON, id)
+	setPaginationHeaders(w, r, query, 0)
+	w.Header().Set("Trailer", "X-Result-Count, X-Stream-Error")
+
+	rw := &rowWriter{dataObject: dataObject, table: table, enc: json.NewEncoder(w)}
+	rw.flusher, _ = w.(http.Flusher)
+
+	dberr := db.Query(query, rw)
+	if dberr != nil && rw.count == 0 {
+		w.Header().Del("Trailer")
+		return utils.NewError(dberr, "internal database error", 500, false)
+	}
+	if dberr != nil {
+		log.Errorf("error streaming results after %v rows - RequestID: %s: %v", rw.count, id, dberr)
+		w.Header().Set("X-Stream-Error", "internal database error")
+	}
+	w.Header().Set("X-Result-Count", strconv.Itoa(rw.count))
+	return nil
+}
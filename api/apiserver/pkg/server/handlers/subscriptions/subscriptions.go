@@ -0,0 +1,176 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+// Package subscriptions serves the webhook subscriptions registered by clients, and their delivery logs.
+package subscriptions
+
+import (
+	"apiserver/pkg/server/handlers"
+	"apiserver/pkg/utils"
+	"apiserver/pkg/webhook"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxBodySize is the largest subscription accepted, in bytes
+	maxBodySize = 1 << 16
+
+	// defaultDeliveries and maxDeliveries bound the number of attempts returned from a delivery log
+	defaultDeliveries = 50
+	maxDeliveries     = 1000
+)
+
+// subscriptionRequest holds the fields of a subscription set by clients. The other fields are set by the server.
+type subscriptionRequest struct {
+	Url       string
+	Dataset   string
+	Event     webhook.Event
+	Condition *webhook.Condition
+}
+
+// Create is an ApiHandlerFunc type. It registers a webhook subscription from the JSON body of the request. The
+// secret used to sign deliveries is only returned in this response.
+func Create(ctx context.Context, handlerConfig *handlers.ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	var req subscriptionRequest
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return utils.NewError(err, "request body must be a subscription encoded as JSON: "+err.Error(), 400, false).WithCode(utils.CodeInvalidBody)
+	}
+
+	sub := webhook.Subscription{Url: req.Url, Dataset: req.Dataset, Event: req.Event, Condition: req.Condition}
+	if err := sub.Validate(); err != nil {
+		return utils.NewError(fmt.Errorf("invalid subscription"), "malformed subscription, "+err.Error(), 400, false).WithCode(utils.CodeInvalidBody)
+	}
+
+	store := webhook.Store{Database: handlerConfig.Database}
+	if err := store.Create(&sub); err != nil {
+		return utils.NewError(err, "internal database error", 500, false)
+	}
+
+	w.Header().Set("Location", "/v1/subscriptions/"+sub.Id)
+	return writeResults(w, r, http.StatusCreated, sub)
+}
+
+// Get is an ApiHandlerFunc type. It returns a webhook subscription, without its secret.
+func Get(ctx context.Context, handlerConfig *handlers.ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	store := webhook.Store{Database: handlerConfig.Database}
+	sub, err := store.Get(subscriptionId(r))
+	if err == sql.ErrNoRows {
+		return utils.NewError(err, "no subscription with ID '"+subscriptionId(r)+"'", 404, false)
+	} else if err != nil {
+		return utils.NewError(err, "internal database error", 500, false)
+	}
+
+	sub.Secret = ""
+	return writeResults(w, r, http.StatusOK, sub)
+}
+
+// Delete is an ApiHandlerFunc type. It deletes a webhook subscription and its delivery log.
+func Delete(ctx context.Context, handlerConfig *handlers.ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	store := webhook.Store{Database: handlerConfig.Database}
+	err := store.Delete(subscriptionId(r))
+	if err == sql.ErrNoRows {
+		return utils.NewError(err, "no subscription with ID '"+subscriptionId(r)+"'", 404, false)
+	} else if err != nil {
+		return utils.NewError(err, "internal database error", 500, false)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// GetDeliveries is an ApiHandlerFunc type. It returns the most recent delivery attempts of a webhook subscription,
+// newest first. The number of attempts returned may be set with the 'limit' parameter.
+func GetDeliveries(ctx context.Context, handlerConfig *handlers.ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	limit := defaultDeliveries
+	for key, val := range utils.ParseQuery(r) {
+		var err error
+		switch key {
+		case "limit":
+			if len(val) != 1 {
+				err = fmt.Errorf("malformed query parameters, a single limit is allowed")
+			} else if limit, err = strconv.Atoi(val[0]); err != nil {
+				err = fmt.Errorf("malformed query parameters, limit must be between 1 and %v", maxDeliveries)
+			} else if limit < 1 || limit > maxDeliveries {
+				err = utils.OutOfRange("malformed query parameters, limit must be between 1 and %v", maxDeliveries)
+			}
+		default:
+			err = fmt.Errorf("unknown parameter for delivery logs")
+		}
+		if err != nil {
+			message := err.Error() + ": " + key + "=[" + strings.Join(val, ",") + "]"
+			return utils.NewError(fmt.Errorf("error when parsing query parameters"), message, 400, false).ForParam(key, err)
+		}
+	}
+
+	store := webhook.Store{Database: handlerConfig.Database}
+	id := subscriptionId(r)
+	if _, err := store.Get(id); err == sql.ErrNoRows {
+		return utils.NewError(err, "no subscription with ID '"+id+"'", 404, false)
+	} else if err != nil {
+		return utils.NewError(err, "internal database error", 500, false)
+	}
+
+	deliveries, err := store.Deliveries(id, limit)
+	if err != nil {
+		return utils.NewError(err, "internal database error", 500, false)
+	}
+
+	results := make([]interface{}, len(deliveries))
+	for i, delivery := range deliveries {
+		results[i] = delivery
+	}
+	return writeResults(w, r, http.StatusOK, results...)
+}
+
+// subscriptionId returns the subscription Id in the path of a request, eg. '/v1/subscriptions/{id}/deliveries'.
+func subscriptionId(r *http.Request) string {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for i, part := range parts {
+		if part == "subscriptions" && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+func writeResults(w http.ResponseWriter, r *http.Request, status int, results ...interface{}) *utils.ServerError {
+	// Parse RequestID param
+	id, idError := utils.GetReqId(r)
+	if idError != nil {
+		return utils.NewError(idError, "cannot extract request ID", 500, false)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("X-Request-Id", id)
+	w.WriteHeader(status)
+	return utils.WriteJson(w, r, results, utils.Meta{}, true)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package subscriptions
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/server/handlers"
+	"apiserver/pkg/utils"
+	"apiserver/test"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const selectSubscription = `SELECT id, url, secret, dataset, event, field, op, threshold, enabled, failures, created_at FROM public.webhook_subscriptions WHERE id = $1`
+
+var subscriptionRows = []string{"id", "url", "secret", "dataset", "event", "field", "op", "threshold", "enabled", "failures", "created_at"}
+
+// serve runs a handler against a mock database and returns the status and decoded body of the response.
+func serve(t *testing.T, handler handlers.ApiHandlerFunc, mock func(sqlmock.Sqlmock), method string, target string, body string) (int, map[string]interface{}) {
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error generating mock database: %s", err.Error())
+	}
+	defer db.Close()
+	mock(dbMock)
+
+	req := test.SetReqIdTest(httptest.NewRequest(method, target, strings.NewReader(body)))
+	w := httptest.NewRecorder()
+	if err := handler(context.Background(), &handlers.ApiHandlerConfig{Database: &database.Database{DB: db}}, w, req); err != nil {
+		utils.HttpJsonError(w, req, err)
+	}
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations for %v %v: %s", method, target, err)
+	}
+
+	var resp map[string]interface{}
+	if w.Body.Len() != 0 {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Response is not valid JSON: %v", w.Body.String())
+		}
+	}
+	return w.Code, resp
+}
+
+func result(resp map[string]interface{}) map[string]interface{} {
+	results, _ := resp["Results"].([]interface{})
+	if len(results) != 1 {
+		return nil
+	}
+	res, _ := results[0].(map[string]interface{})
+	return res
+}
+
+func TestCreate(t *testing.T) {
+	body := `{"Url": "https://example.com/hook", "Dataset": "co2_weekly_mlo", "Event": "threshold", "Condition": {"Field": "average", "Op": "gt", "Value": 425}}`
+	code, resp := serve(t, Create, func(mock sqlmock.Sqlmock) {
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO public.webhook_subscriptions (id, url, secret, dataset, event, field, op, threshold, enabled, failures, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`)).
+			WithArgs(sqlmock.AnyArg(), "https://example.com/hook", sqlmock.AnyArg(), "co2_weekly_mlo", "threshold", "average", "gt", 425.0, true, 0, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}, "POST", "/v1/subscriptions", body)
+
+	sub := result(resp)
+	if code != 201 || sub == nil {
+		t.Fatalf("Wanted the created subscription, Got: %v, %v", code, resp)
+	}
+	if secret, _ := sub["Secret"].(string); len(secret) != 64 {
+		t.Errorf("Wanted the secret of the new subscription, Got: %v", sub["Secret"])
+	}
+	if id, _ := sub["Id"].(string); len(id) != 32 || sub["Enabled"] != true {
+		t.Errorf("Wanted a new enabled subscription, Got: %v", sub)
+	}
+}
+
+func TestCreateInvalid(t *testing.T) {
+	testVals := []struct {
+		body    string
+		message string
+	}{
+		{`{"Url": "https://example.com/hook", "Dataset": "co2_weekly_mlo", "Event": "new_data"`, "JSON"},
+		{`{"Url": "https://example.com/hook", "Dataset": "co2_weekly_mlo", "Event": "new_data", "Secret": "mine"}`, "unknown field"},
+		{`{"Url": "https://example.com/hook", "Dataset": "co2_weekly_mlo", "Event": "new_data", "Threshold": 425}`, "unknown field"},
+		{`{"Url": "https://example.com/hook", "Dataset": "co2_weekly_mlo", "Event": "threshold", "Condition": {"Field": "average", "Op": "over", "Value": 425}}`, "Condition.Op=[over]"},
+	}
+
+	for _, testVal := range testVals {
+		code, resp := serve(t, Create, func(sqlmock.Sqlmock) {}, "POST", "/v1/subscriptions", testVal.body)
+		message, _ := resp["Error"].(map[string]interface{})["Message"].(string)
+		if code != 400 || !strings.Contains(message, testVal.message) {
+			t.Errorf("Wanted a 400 error mentioning '%v' for %v, Got: %v, %v", testVal.message, testVal.body, code, message)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	code, resp := serve(t, Get, func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(regexp.QuoteMeta(selectSubscription)).WithArgs("abc").WillReturnRows(
+			sqlmock.NewRows(subscriptionRows).AddRow("abc", "https://example.com/hook", "s3cret", "ch4_mm_gl", "new_data", nil, nil, nil, false, 5, time.Now()),
+		)
+	}, "GET", "/v1/subscriptions/abc", "")
+
+	sub := result(resp)
+	if code != 200 || sub == nil || sub["Id"] != "abc" || sub["Enabled"] != false || sub["Failures"] != 5.0 {
+		t.Fatalf("Wanted the disabled subscription, Got: %v, %v", code, resp)
+	}
+	if _, ok := sub["Secret"]; ok {
+		t.Errorf("Wanted the secret of the subscription to be withheld, Got: %v", sub["Secret"])
+	}
+
+	code, _ = serve(t, Get, func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(regexp.QuoteMeta(selectSubscription)).WithArgs("missing").WillReturnRows(sqlmock.NewRows(subscriptionRows))
+	}, "GET", "/v1/subscriptions/missing", "")
+	if code != 404 {
+		t.Errorf("Wanted a 404 error for an unknown subscription, Got: %v", code)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	code, _ := serve(t, Delete, func(mock sqlmock.Sqlmock) {
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM public.webhook_subscriptions WHERE id = $1`)).WithArgs("abc").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM public.webhook_deliveries WHERE subscription_id = $1`)).WithArgs("abc").WillReturnResult(sqlmock.NewResult(0, 3))
+	}, "DELETE", "/v1/subscriptions/abc", "")
+	if code != 204 {
+		t.Errorf("Wanted a 204 response, Got: %v", code)
+	}
+
+	code, _ = serve(t, Delete, func(mock sqlmock.Sqlmock) {
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM public.webhook_subscriptions WHERE id = $1`)).WithArgs("abc").WillReturnResult(sqlmock.NewResult(0, 0))
+	}, "DELETE", "/v1/subscriptions/abc", "")
+	if code != 404 {
+		t.Errorf("Wanted a 404 error for an unknown subscription, Got: %v", code)
+	}
+}
+
+func TestGetDeliveries(t *testing.T) {
+	delivered := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	code, resp := serve(t, GetDeliveries, func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(regexp.QuoteMeta(selectSubscription)).WithArgs("abc").WillReturnRows(
+			sqlmock.NewRows(subscriptionRows).AddRow("abc", "https://example.com/hook", "s3cret", "ch4_mm_gl", "new_data", nil, nil, nil, true, 0, time.Now()),
+		)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT subscription_id, event_id, attempt, status_code, error, delivered_at FROM public.webhook_deliveries WHERE subscription_id = $1 AND delivered_at IS NOT NULL ORDER BY delivered_at DESC, attempt DESC LIMIT $2`)).
+			WithArgs("abc", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"subscription_id", "event_id", "attempt", "status_code", "error", "delivered_at"}).
+				AddRow("abc", "1622505600000", 2, 200, nil, delivered.Add(time.Minute)).
+				AddRow("abc", "1622505600000", 1, nil, "connection refused", delivered))
+	}, "GET", "/v1/subscriptions/abc/deliveries?limit=2", "")
+
+	results, _ := resp["Results"].([]interface{})
+	if code != 200 || len(results) != 2 {
+		t.Fatalf("Wanted 2 delivery attempts, Got: %v, %v", code, resp)
+	}
+	if failed := results[1].(map[string]interface{}); failed["StatusCode"] != 0.0 || failed["Error"] != "connection refused" {
+		t.Errorf("Wanted the failed attempt without a status, Got: %v", failed)
+	}
+
+	code, _ = serve(t, GetDeliveries, func(sqlmock.Sqlmock) {}, "GET", "/v1/subscriptions/abc/deliveries?limit=5000", "")
+	if code != 400 {
+		t.Errorf("Wanted a 400 error for a limit out of range, Got: %v", code)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package handlers
+
+import (
+	"apiserver/pkg/database/models"
+	utils "apiserver/pkg/utils"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// filterParams lists the query parameters whose values are compared against concentrations.
+var filterParams = []string{"gt", "gte", "lt", "lte", "filter"}
+
+// ParseUnits reads the 'units' and 'filter_units' query parameters of a request for the concentrations of a
+// dataset. It returns the conversion of the results to the requested unit, and the conversion of the values of
+// filters, which is nil when filters are given in the unit of the dataset. Filters are only ambiguous when results
+// are converted, in which case 'filter_units' must select either the 'requested' unit or the 'canonical' unit
+// of the dataset.
+func ParseUnits(r *http.Request, handlerConfig *ApiHandlerConfig, dataset models.Dataset) (models.Conversion, *models.Conversion, *utils.ServerError) {
+	query := r.URL.Query()
+
+	unit := dataset.Unit
+	if val, ok := query["units"]; ok {
+		unit = strings.Join(val, ",")
+	}
+	conversion, err := dataset.Conversion(unit, handlerConfig.Conditions)
+	if err != nil {
+		message := err.Error() + ": units=[" + unit + "]"
+		return models.Conversion{}, nil, utils.NewError(fmt.Errorf("error when parsing query parameters"), message, 400, false).ForParam("units", err)
+	}
+
+	val, ok := query["filter_units"]
+	if !ok {
+		if conversion.Unit == dataset.Unit {
+			return conversion, nil, nil
+		}
+		for _, param := range filterParams {
+			if _, ok := query[param]; ok {
+				message := fmt.Sprintf("malformed query parameters, filters are ambiguous when results are converted to %v. Select the unit of filters with filter_units=requested (%v) or filter_units=canonical (%v): %v=[%v]", conversion.Unit, conversion.Unit, dataset.Unit, param, strings.Join(query[param], ","))
+				return models.Conversion{}, nil, utils.NewError(fmt.Errorf("error when parsing query parameters"), message, 400, false).ForParam(param, nil)
+			}
+		}
+		return conversion, nil, nil
+	}
+
+	switch strings.Join(val, ",") {
+	case "requested":
+		if conversion.Unit == dataset.Unit {
+			return conversion, nil, nil
+		}
+		return conversion, &conversion, nil
+	case "canonical":
+		return conversion, nil, nil
+	}
+	message := "malformed query parameters, filter_units must be either 'requested' or 'canonical': filter_units=[" + strings.Join(val, ",") + "]"
+	return models.Conversion{}, nil, utils.NewError(fmt.Errorf("error when parsing query parameters"), message, 400, false).ForParam("filter_units", nil)
+}
@@ -25,16 +25,15 @@ package server
 
 import (
 	"apiserver/pkg/server/handlers"
-	"apiserver/pkg/server/handlers/ch4"
-	"apiserver/pkg/server/handlers/co2"
 	utils "apiserver/pkg/utils"
 	"context"
-	"net/http"
 	"strings"
 
 	"github.com/gorilla/mux"
 )
 
+//go:generate go run ../../cmd/routegen -spec ../openapi/spec-v1.json -out routes_gen.go
+
 // NewRouter generates a new gorilla mux router to be used instead of the default golang http router.
 func (apiserver *ApiServer) NewRouter(ctx context.Context, routes Routes) *mux.Router {
 	router := mux.NewRouter().StrictSlash(true)
@@ -44,6 +43,12 @@ func (apiserver *ApiServer) NewRouter(ctx context.Context, routes Routes) *mux.R
 	router.Use(utils.SetCORSHeaders) // Sets headers used to allow requests from any origin
 	router.Use(utils.Gzip)           // Sets headers used to tell client response is compressed
 	router.Use(utils.SetReqId)       // Generates UUID value for each new request
+	if apiserver.Config != nil {
+		router.Use(utils.Deprecation(utils.V1, apiserver.Config.V1Deprecation, apiserver.Config.V1Sunset)) // Announces the retirement of /v1
+	}
+	if apiserver.Validator != nil {
+		router.Use(apiserver.Validator.Middleware) // Checks requests and responses against the OpenAPI spec
+	}
 
 	for _, route := range routes {
 		router.
@@ -53,17 +58,20 @@ func (apiserver *ApiServer) NewRouter(ctx context.Context, routes Routes) *mux.R
 			Handler(handlers.NewHandler(ctx, route.Handler, route.Name))
 	}
 
-	// Force 404 responses to go through all the middleware
-	router.NotFoundHandler = router.NewRoute().HandlerFunc(http.NotFound).GetHandler()
+	// Force 404 and 405 responses to go through all the middleware. The last route matches every request,
+	// so that mux never answers with its plain text errors.
+	router.NotFoundHandler = router.NewRoute().
+		Name("unmatched").
+		Handler(handlers.NewHandler(ctx, newUnmatched(routes).Handler(), "unmatched")).
+		GetHandler()
 
 	return router
 }
 
-// CreateRoutes returns a Routes list representing all routes on the server.
-// This is broken out as a function to potentially allow autogeneration from the
-// API Spec in the future. Currently this is manually edited to mirror the spec.
+// CreateRoutes returns a Routes list representing all routes on the server. The routes of the API are generated
+// from the operations of the OpenAPI spec (see routes_gen.go); only the routes outside of the API are listed here.
 func (apiserver *ApiServer) CreateRoutes() Routes {
-	return Routes{
+	routes := Routes{
 		Route{
 			"favicon",
 			strings.ToUpper("Get"),
@@ -76,18 +84,6 @@ func (apiserver *ApiServer) CreateRoutes() Routes {
 			},
 		},
 
-		Route{
-			"heatlh",
-			strings.ToUpper("Get"),
-			"/v1/health",
-			handlers.ApiHandler{
-				Handler: handlers.GetHealth,
-				Config: &handlers.ApiHandlerConfig{
-					Database: apiserver.Database,
-				},
-			},
-		},
-
 		Route{
 			"index",
 			strings.ToUpper("Get"),
@@ -99,105 +95,24 @@ func (apiserver *ApiServer) CreateRoutes() Routes {
 				},
 			},
 		},
-
-		Route{
-			"v1",
-			strings.ToUpper("Get"),
-			"/v1",
-			handlers.ApiHandler{
-				Handler: co2.Get,
-				Config: &handlers.ApiHandlerConfig{
-					Database: apiserver.Database,
-					SortBy:   "average",
-				},
-			},
-		},
-
-		Route{
-			"co2",
-			strings.ToUpper("Get"),
-			"/v1/co2",
-			handlers.ApiHandler{
-				Handler: co2.Get,
-				Config: &handlers.ApiHandlerConfig{
-					Database: apiserver.Database,
-					SortBy:   "average",
-				},
-			},
-		},
-
-		Route{
-			"co2Weekly",
-			strings.ToUpper("Get"),
-			"/v1/co2/weekly",
-			handlers.ApiHandler{
-				Handler: co2.Get,
-				Config: &handlers.ApiHandlerConfig{
-					Database: apiserver.Database,
-					SortBy:   "average",
-				},
-			},
-		},
-		Route{
-			"co2Weekly",
-			strings.ToUpper("Get"),
-			"/v1/co2/weekly/increase",
-			handlers.ApiHandler{
-				Handler: co2.Get,
-				Config: &handlers.ApiHandlerConfig{
-					Database: apiserver.Database,
-					SortBy:   "increase",
-				},
-			},
-		},
-		Route{
-			"co2Weekly",
-			strings.ToUpper("Get"),
-			"/v1/co2/weekly/{ppm}",
-			handlers.ApiHandler{
-				Handler: co2.Get,
-				Config: &handlers.ApiHandlerConfig{
-					Database:  apiserver.Database,
-					PathParam: true,
-					SortBy:    "average",
-				},
-			},
-		},
-		Route{
-			"ch4Monthly",
-			strings.ToUpper("Get"),
-			"/v1/ch4",
-			handlers.ApiHandler{
-				Handler: ch4.Get,
-				Config: &handlers.ApiHandlerConfig{
-					Database: apiserver.Database,
-					SortBy:   "average",
-				},
-			},
-		},
 		Route{
-			"ch4Monthly",
+			"docs",
 			strings.ToUpper("Get"),
-			"/v1/ch4/monthly",
+			"/docs",
 			handlers.ApiHandler{
-				Handler: ch4.Get,
-				Config: &handlers.ApiHandlerConfig{
-					Database: apiserver.Database,
-					SortBy:   "average",
-				},
+				Handler: handlers.GetDocs,
+				Config:  &handlers.ApiHandlerConfig{},
 			},
 		},
 		Route{
-			"ch4MonthlyTrend",
+			"docsAsset",
 			strings.ToUpper("Get"),
-			"/v1/ch4/monthly/trend",
+			"/docs/{asset}",
 			handlers.ApiHandler{
-				Handler: ch4.Get,
-				Config: &handlers.ApiHandlerConfig{
-					Database: apiserver.Database,
-					SortBy:   "trend",
-				},
+				Handler: handlers.GetDocsAsset,
+				Config:  &handlers.ApiHandlerConfig{},
 			},
 		},
 	}
+	return append(routes, apiserver.specRoutes()...)
 }
@@ -23,83 +23,291 @@ Contact: planetpulse.api@gmail.com
 package server
 
 import (
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/openapi"
+	utils "apiserver/pkg/utils"
+	"bytes"
+	"context"
 	"encoding/json"
 	"io/ioutil"
-	"os"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"sort"
 	"strings"
 	"testing"
-)
 
-var apiSpecPath = "../../../openapi/spec-v1.json"
-
-type Path map[string]interface{}
-
-type PathSpec struct {
-	Ref         string
-	Summary     string
-	Description string
-	Get         string
-	Put         string
-	Post        string
-	Delete      string
-	Options     string
-	Head        string
-	Patch       string
-	Trace       string
-	Servers     []string
-	Parameters  []string
-}
+	"github.com/gorilla/mux"
+)
 
+// TestRoutes fails when a route under the base path of the OpenAPI spec has no operation in the spec, or an
+// operation of the spec has no route. Routes outside the base path, eg. '/favicon.ico', are not part of the API.
 func TestRoutes(t *testing.T) {
-
-	jsonFile, err := os.Open(apiSpecPath)
+	spec, err := openapi.V1()
 	if err != nil {
-		t.Error(err)
-		t.Errorf("Tried to open the spec at this location: '%v'", apiSpecPath)
-		return
+		t.Fatalf("cannot parse the embedded OpenAPI spec: %v", err)
+	}
+	base := spec.BasePath()
+
+	routed := make(map[string]bool)
+	for _, route := range (&ApiServer{}).CreateRoutes() {
+		if route.Pattern != base && !strings.HasPrefix(route.Pattern, base+"/") {
+			continue
+		}
+		path := strings.TrimPrefix(route.Pattern, base)
+		if path == "" {
+			path = "/"
+		}
+		routed[route.Method+" "+path] = true
+	}
+
+	documented := make(map[string]bool)
+	for _, op := range spec.Operations() {
+		documented[op] = true
+		if !routed[op] {
+			t.Errorf("The operation '%v' is defined in the OpenAPI spec but has no route in router.go", op)
+		}
+	}
+
+	var undocumented []string
+	for op := range routed {
+		if !documented[op] {
+			undocumented = append(undocumented, op)
+		}
 	}
-	defer jsonFile.Close()
+	sort.Strings(undocumented)
+	for _, op := range undocumented {
+		t.Errorf("The route '%v' is defined in router.go but has no operation in the OpenAPI spec", strings.Replace(op, " ", " "+base, 1))
+	}
+}
 
-	bytes, err := ioutil.ReadAll(jsonFile)
+// TestRoutesGenerated fails when routes_gen.go is out of date with the OpenAPI spec. Run 'go generate' to update it.
+func TestRoutesGenerated(t *testing.T) {
+	spec, err := openapi.V1()
+	if err != nil {
+		t.Fatalf("cannot parse the embedded OpenAPI spec: %v", err)
+	}
+	want, err := openapi.GenerateRoutes(spec, openapi.DefaultRouteConfig)
 	if err != nil {
-		t.Error(err)
-		return
+		t.Fatalf("cannot generate the routes: %v", err)
 	}
+	got, err := ioutil.ReadFile("routes_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("routes_gen.go is out of date with the OpenAPI spec, run 'go generate ./pkg/server'")
+	}
+}
 
-	specTable := make(map[string]Path)
-	json.Unmarshal(bytes, &specTable)
+// TestRoutesEquivalent checks that the generated routes are equivalent to the routes that were maintained by
+// hand before they were generated: each path and method is served by the same handler with the same configuration.
+func TestRoutesEquivalent(t *testing.T) {
+	type handledRoute struct {
+		Handler   string
+		SortBy    string
+		PathParam bool
+	}
+	handlersPkg := "apiserver/pkg/server/handlers"
+	want := map[string]handledRoute{
+		"GET /favicon.ico":                      {handlersPkg + ".GetFavicon", "", false},
+		"GET /":                                 {handlersPkg + ".GetIndex", "", false},
+		"GET /docs":                             {handlersPkg + ".GetDocs", "", false},
+		"GET /docs/{asset}":                     {handlersPkg + ".GetDocsAsset", "", false},
+		"GET /v1/health":                        {handlersPkg + ".GetHealth", "", false},
+		"GET /v1/openapi.json":                  {handlersPkg + ".GetOpenApi", "", false},
+		"GET /v1/datasets":                      {handlersPkg + ".GetDatasets", "", false},
+		"GET /v1/datasets/{id}":                 {handlersPkg + ".GetDataset", "", false},
+		"GET /v1":                               {handlersPkg + "/co2.Get", "average", false},
+		"GET /v1/co2":                           {handlersPkg + "/co2.Get", "average", false},
+		"GET /v1/co2/weekly":                    {handlersPkg + "/co2.Get", "average", false},
+		"GET /v1/co2/weekly/increase":           {handlersPkg + "/co2.Get", "increase", false},
+		"GET /v1/co2/chart.svg":                 {handlersPkg + "/co2.GetChart", "average", false},
+		"GET /v1/co2/weekly/chart.svg":          {handlersPkg + "/co2.GetChart", "average", false},
+		"GET /v1/co2/weekly/increase/chart.svg": {handlersPkg + "/co2.GetChart", "increase", false},
+		"GET /v1/co2/weekly/{ppm}":              {handlersPkg + "/co2.Get", "average", true},
+		"GET /v1/co2/latest":                    {handlersPkg + "/co2.GetLatest", "", false},
+		"GET /v1/ch4":                           {handlersPkg + "/ch4.Get", "average", false},
+		"GET /v1/ch4/monthly":                   {handlersPkg + "/ch4.Get", "average", false},
+		"GET /v1/ch4/monthly/trend":             {handlersPkg + "/ch4.Get", "trend", false},
+		"GET /v1/ch4/chart.svg":                 {handlersPkg + "/ch4.GetChart", "average", false},
+		"GET /v1/ch4/monthly/chart.svg":         {handlersPkg + "/ch4.GetChart", "average", false},
+		"GET /v1/ch4/monthly/trend/chart.svg":   {handlersPkg + "/ch4.GetChart", "trend", false},
+		"GET /v1/ch4/latest":                    {handlersPkg + "/ch4.GetLatest", "", false},
+		"GET /v1/badge/co2.svg":                 {handlersPkg + "/co2.GetBadge", "", false},
+		"GET /v1/badge/ch4.svg":                 {handlersPkg + "/ch4.GetBadge", "", false},
+		"GET /v1/co2/feed.atom":                 {handlersPkg + "/co2.GetFeed", "", false},
+		"GET /v1/co2/feed.rss":                  {handlersPkg + "/co2.GetFeed", "", false},
+		"GET /v1/ch4/feed.atom":                 {handlersPkg + "/ch4.GetFeed", "", false},
+		"GET /v1/ch4/feed.rss":                  {handlersPkg + "/ch4.GetFeed", "", false},
+		"GET /v1/stream":                        {handlersPkg + ".GetEvents", "", false},
+		"POST /v1/subscriptions":                {handlersPkg + "/subscriptions.Create", "", false},
+		"GET /v1/subscriptions/{id}":            {handlersPkg + "/subscriptions.Get", "", false},
+		"DELETE /v1/subscriptions/{id}":         {handlersPkg + "/subscriptions.Delete", "", false},
+		"GET /v1/subscriptions/{id}/deliveries": {handlersPkg + "/subscriptions.GetDeliveries", "", false},
+		"GET /v1/graphql":                       {handlersPkg + "/gql.Serve", "", false},
+		"POST /v1/graphql":                      {handlersPkg + "/gql.Serve", "", false},
+		"GET /v2/health":                        {handlersPkg + ".GetHealth", "", false},
+		"GET /v2/datasets":                      {handlersPkg + ".GetDatasets", "", false},
+		"GET /v2/datasets/{id}":                 {handlersPkg + ".GetDataset", "", false},
+		"GET /v2":                               {handlersPkg + "/co2.Get", "average", false},
+		"GET /v2/co2":                           {handlersPkg + "/co2.Get", "average", false},
+		"GET /v2/co2/weekly":                    {handlersPkg + "/co2.Get", "average", false},
+		"GET /v2/co2/weekly/increase":           {handlersPkg + "/co2.Get", "increase", false},
+		"GET /v2/co2/chart.svg":                 {handlersPkg + "/co2.GetChart", "average", false},
+		"GET /v2/co2/weekly/chart.svg":          {handlersPkg + "/co2.GetChart", "average", false},
+		"GET /v2/co2/weekly/increase/chart.svg": {handlersPkg + "/co2.GetChart", "increase", false},
+		"GET /v2/co2/weekly/{ppm}":              {handlersPkg + "/co2.Get", "average", true},
+		"GET /v2/co2/latest":                    {handlersPkg + "/co2.GetLatest", "", false},
+		"GET /v2/ch4":                           {handlersPkg + "/ch4.Get", "average", false},
+		"GET /v2/ch4/monthly":                   {handlersPkg + "/ch4.Get", "average", false},
+		"GET /v2/ch4/monthly/trend":             {handlersPkg + "/ch4.Get", "trend", false},
+		"GET /v2/ch4/chart.svg":                 {handlersPkg + "/ch4.GetChart", "average", false},
+		"GET /v2/ch4/monthly/chart.svg":         {handlersPkg + "/ch4.GetChart", "average", false},
+		"GET /v2/ch4/monthly/trend/chart.svg":   {handlersPkg + "/ch4.GetChart", "trend", false},
+		"GET /v2/ch4/latest":                    {handlersPkg + "/ch4.GetLatest", "", false},
+		"GET /v2/badge/co2.svg":                 {handlersPkg + "/co2.GetBadge", "", false},
+		"GET /v2/badge/ch4.svg":                 {handlersPkg + "/ch4.GetBadge", "", false},
+		"GET /v2/co2/feed.atom":                 {handlersPkg + "/co2.GetFeed", "", false},
+		"GET /v2/co2/feed.rss":                  {handlersPkg + "/co2.GetFeed", "", false},
+		"GET /v2/ch4/feed.atom":                 {handlersPkg + "/ch4.GetFeed", "", false},
+		"GET /v2/ch4/feed.rss":                  {handlersPkg + "/ch4.GetFeed", "", false},
+	}
+
+	got := make(map[string]handledRoute)
+	names := make(map[string]bool)
+	for _, route := range (&ApiServer{}).CreateRoutes() {
+		if names[route.Name] {
+			t.Errorf("The route name '%v' is used more than once", route.Name)
+		}
+		names[route.Name] = true
+
+		got[route.Method+" "+route.Pattern] = handledRoute{
+			Handler:   runtime.FuncForPC(reflect.ValueOf(route.Handler.Handler).Pointer()).Name(),
+			SortBy:    route.Handler.Config.SortBy,
+			PathParam: route.Handler.Config.PathParam,
+		}
+	}
 
+	for key, route := range want {
+		if got[key] != route {
+			t.Errorf("Wanted '%v' to be served by %+v, Got: %+v", key, route, got[key])
+		}
+	}
+	for key := range got {
+		if _, ok := want[key]; !ok {
+			t.Errorf("Unexpected route '%v'", key)
+		}
+	}
+}
+
+// TestRoutesOrder checks that literal paths are matched before templated paths that would also match them.
+func TestRoutesOrder(t *testing.T) {
+	apiserver := &ApiServer{}
+	router := apiserver.NewRouter(context.Background(), apiserver.CreateRoutes())
+
+	testVals := map[string]string{
+		"/v1/co2/weekly/increase":  "getCo2WeeklyIncrease",
+		"/v1/co2/weekly/chart.svg": "getCo2WeeklyChart",
+		"/v1/co2/weekly/415":       "getCo2PPM",
+		"/v1/subscriptions/abc":    "getSubscription",
+		"/v2/co2/weekly/415":       "v2.getCo2PPM",
+	}
+	for path, name := range testVals {
+		var match mux.RouteMatch
+		if !router.Match(httptest.NewRequest("GET", path, nil), &match) {
+			t.Errorf("Wanted '%v' to match a route", path)
+			continue
+		}
+		if got := match.Route.GetName(); got != name {
+			t.Errorf("Wanted '%v' to match the route '%v', Got: '%v'", path, name, got)
+		}
+	}
+}
+
+func TestUnmatched(t *testing.T) {
 	apiserver := &ApiServer{}
-	routes := apiserver.CreateRoutes()
-	routesTable := make(map[string]Route)
+	router := apiserver.NewRouter(context.Background(), apiserver.CreateRoutes())
 
-	for _, route := range routes {
-		routesTable[route.Pattern] = route
+	testVals := []struct {
+		method  string
+		target  string
+		status  int
+		allow   string
+		message string
+	}{
+		{"GET", "/v1/co2/weekley", 404, "", "no route matches '/v1/co2/weekley', did you mean '/v1/co2/weekly'?"},
+		{"GET", "/v1/c02/latest", 404, "", "no route matches '/v1/c02/latest', did you mean '/v1/co2/latest' or '/v1/ch4/latest'?"},
+		{"GET", "/v1/co2/weeky/400", 404, "", "no route matches '/v1/co2/weeky/400', did you mean '/v1/co2/weekly/{ppm}' or '/v1/co2/weekly'?"},
+		{"GET", "/nothing/like/any/route/at/all", 404, "", "no route matches '/nothing/like/any/route/at/all'"},
+		{"PUT", "/v1/co2/weekly", 405, "GET", "method PUT is not allowed on '/v1/co2/weekly', allowed methods are GET"},
+		{"PUT", "/v1/subscriptions/abc", 405, "GET, DELETE", "method PUT is not allowed on '/v1/subscriptions/abc', allowed methods are GET, DELETE"},
+		{"DELETE", "/v1/graphql", 405, "GET, POST", "method DELETE is not allowed on '/v1/graphql', allowed methods are GET, POST"},
 	}
 
-	problemDetected := false
+	for _, testVal := range testVals {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(testVal.method, testVal.target, nil))
 
-	for k := range specTable["paths"] {
-		if _, ok := routesTable["/v1"+k]; !ok {
-			problemDetected = true
-			t.Logf("WARNING - The following path is defined in the OpenAPI spec file but is not defined in the Routes list: /v1%v", k)
+		if w.Code != testVal.status {
+			t.Errorf("Wanted status %v for %v %v, Got: %v", testVal.status, testVal.method, testVal.target, w.Code)
+		}
+		if got := w.Header().Get("Allow"); got != testVal.allow {
+			t.Errorf("Wanted 'Allow: %v' for %v %v, Got: '%v'", testVal.allow, testVal.method, testVal.target, got)
+		}
+		if got := w.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+			t.Errorf("Wanted a JSON error for %v %v, Got: '%v'", testVal.method, testVal.target, got)
+		}
+
+		var resp models.ServerResp
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Cannot decode the error of %v %v: %v", testVal.method, testVal.target, err)
+		}
+		if resp.RequestId == "" || resp.Error == nil || resp.Error.Message != testVal.message {
+			t.Errorf("Wanted the error '%v' for %v %v, Got: %+v", testVal.message, testVal.method, testVal.target, resp.Error)
 		}
 	}
+}
 
-	for k := range routesTable {
-		if k == "/" || k == "/v1" { // Skip over the top level routes for now
-			continue
+func TestUnmatchedProblem(t *testing.T) {
+	apiserver := &ApiServer{}
+	router := apiserver.NewRouter(context.Background(), apiserver.CreateRoutes())
+
+	testVals := map[string]string{
+		"GET":  utils.CodeNotFound,
+		"POST": utils.CodeMethodNotAllowed,
+	}
+	for method, code := range testVals {
+		req := httptest.NewRequest(method, "/v1/co2/weekly/increase/x", nil)
+		if method == "POST" {
+			req = httptest.NewRequest(method, "/v1/co2/weekly/increase", nil)
 		}
-		trimmed := strings.Replace(k, "/v1", "", 1)
+		req.Header.Set("Accept", utils.ProblemMediaType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
 
-		if _, ok := specTable["paths"][trimmed]; !ok {
-			problemDetected = true
-			t.Logf("WARNING - The following path is defined in the Routes list but is not defined in the OpenAPI spec file: %v", k)
+		var problem models.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || problem.Code != code {
+			t.Errorf("Wanted %v %v reported as %v, Got: %v", method, req.URL.Path, code, w.Body.String())
 		}
 	}
-	if problemDetected {
-		// For now, this problem is logged but won't cause test failures.
-		// When the API is more concrete, this should trigger a failure.
-		t.Skipf("There is a mismatch between the OpenAPI spec file (%v) and the routes defined in router.go", apiSpecPath)
+}
+
+func TestEditDistance(t *testing.T) {
+	testVals := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"weekly", "weekly", 0},
+		{"weekley", "weekly", 1},
+		{"c02", "co2", 1},
+		{"", "abc", 3},
+		{"CO₂", "CO2", 1},
+	}
+	for _, testVal := range testVals {
+		if got := utils.EditDistance(testVal.a, testVal.b); got != testVal.want {
+			t.Errorf("Wanted the distance between '%v' and '%v' to be %v, Got: %v", testVal.a, testVal.b, testVal.want, got)
+		}
 	}
 }
@@ -0,0 +1,1182 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+// Code generated by routegen from spec-v1.json. DO NOT EDIT.
+
+package server
+
+import (
+	"apiserver/pkg/openapi"
+	"apiserver/pkg/server/handlers"
+	"apiserver/pkg/server/handlers/ch4"
+	"apiserver/pkg/server/handlers/co2"
+	"apiserver/pkg/server/handlers/gql"
+	"apiserver/pkg/server/handlers/subscriptions"
+)
+
+// specRoutes returns a route for each operation of the OpenAPI spec, in the order they must be matched in.
+func (apiserver *ApiServer) specRoutes() Routes {
+	return Routes{
+		Route{
+			"co2WeeklyViaRoot",
+			"GET",
+			"/v1",
+			handlers.ApiHandler{
+				Handler: co2.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"getCh4Badge",
+			"GET",
+			"/v1/badge/ch4.svg",
+			handlers.ApiHandler{
+				Handler: ch4.GetBadge,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getCo2Badge",
+			"GET",
+			"/v1/badge/co2.svg",
+			handlers.ApiHandler{
+				Handler: co2.GetBadge,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getCh4",
+			"GET",
+			"/v1/ch4",
+			handlers.ApiHandler{
+				Handler: ch4.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"getCh4Chart",
+			"GET",
+			"/v1/ch4/chart.svg",
+			handlers.ApiHandler{
+				Handler: ch4.GetChart,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"getCh4FeedAtom",
+			"GET",
+			"/v1/ch4/feed.atom",
+			handlers.ApiHandler{
+				Handler: ch4.GetFeed,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getCh4FeedRss",
+			"GET",
+			"/v1/ch4/feed.rss",
+			handlers.ApiHandler{
+				Handler: ch4.GetFeed,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getCh4Latest",
+			"GET",
+			"/v1/ch4/latest",
+			handlers.ApiHandler{
+				Handler: ch4.GetLatest,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getCh4Monthly",
+			"GET",
+			"/v1/ch4/monthly",
+			handlers.ApiHandler{
+				Handler: ch4.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"getCh4MonthlyChart",
+			"GET",
+			"/v1/ch4/monthly/chart.svg",
+			handlers.ApiHandler{
+				Handler: ch4.GetChart,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"getCh4MonthlyTrend",
+			"GET",
+			"/v1/ch4/monthly/trend",
+			handlers.ApiHandler{
+				Handler: ch4.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "trend",
+				},
+			},
+		},
+		Route{
+			"getCh4MonthlyTrendChart",
+			"GET",
+			"/v1/ch4/monthly/trend/chart.svg",
+			handlers.ApiHandler{
+				Handler: ch4.GetChart,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "trend",
+				},
+			},
+		},
+		Route{
+			"co2Weekly",
+			"GET",
+			"/v1/co2",
+			handlers.ApiHandler{
+				Handler: co2.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"getCo2Chart",
+			"GET",
+			"/v1/co2/chart.svg",
+			handlers.ApiHandler{
+				Handler: co2.GetChart,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"getCo2FeedAtom",
+			"GET",
+			"/v1/co2/feed.atom",
+			handlers.ApiHandler{
+				Handler: co2.GetFeed,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getCo2FeedRss",
+			"GET",
+			"/v1/co2/feed.rss",
+			handlers.ApiHandler{
+				Handler: co2.GetFeed,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getCo2Latest",
+			"GET",
+			"/v1/co2/latest",
+			handlers.ApiHandler{
+				Handler: co2.GetLatest,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getCo2Weekly",
+			"GET",
+			"/v1/co2/weekly",
+			handlers.ApiHandler{
+				Handler: co2.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"getCo2WeeklyChart",
+			"GET",
+			"/v1/co2/weekly/chart.svg",
+			handlers.ApiHandler{
+				Handler: co2.GetChart,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"getCo2WeeklyIncrease",
+			"GET",
+			"/v1/co2/weekly/increase",
+			handlers.ApiHandler{
+				Handler: co2.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "increase",
+				},
+			},
+		},
+		Route{
+			"getCo2WeeklyIncreaseChart",
+			"GET",
+			"/v1/co2/weekly/increase/chart.svg",
+			handlers.ApiHandler{
+				Handler: co2.GetChart,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "increase",
+				},
+			},
+		},
+		Route{
+			"getCo2PPM",
+			"GET",
+			"/v1/co2/weekly/{ppm}",
+			handlers.ApiHandler{
+				Handler: co2.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					PathParam:  true,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"getDatasets",
+			"GET",
+			"/v1/datasets",
+			handlers.ApiHandler{
+				Handler: handlers.GetDatasets,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getDataset",
+			"GET",
+			"/v1/datasets/{id}",
+			handlers.ApiHandler{
+				Handler: handlers.GetDataset,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getGraphql",
+			"GET",
+			"/v1/graphql",
+			handlers.ApiHandler{
+				Handler: gql.Serve,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"postGraphql",
+			"POST",
+			"/v1/graphql",
+			handlers.ApiHandler{
+				Handler: gql.Serve,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getServerHealth",
+			"GET",
+			"/v1/health",
+			handlers.ApiHandler{
+				Handler: handlers.GetHealth,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getOpenApiSpec",
+			"GET",
+			"/v1/openapi.json",
+			handlers.ApiHandler{
+				Handler: handlers.GetOpenApi,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getStream",
+			"GET",
+			"/v1/stream",
+			handlers.ApiHandler{
+				Handler: handlers.GetEvents,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"createSubscription",
+			"POST",
+			"/v1/subscriptions",
+			handlers.ApiHandler{
+				Handler: subscriptions.Create,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getSubscription",
+			"GET",
+			"/v1/subscriptions/{id}",
+			handlers.ApiHandler{
+				Handler: subscriptions.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"deleteSubscription",
+			"DELETE",
+			"/v1/subscriptions/{id}",
+			handlers.ApiHandler{
+				Handler: subscriptions.Delete,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"getSubscriptionDeliveries",
+			"GET",
+			"/v1/subscriptions/{id}/deliveries",
+			handlers.ApiHandler{
+				Handler: subscriptions.GetDeliveries,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"v2.co2WeeklyViaRoot",
+			"GET",
+			"/v2",
+			handlers.ApiHandler{
+				Handler: co2.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"v2.getCh4Badge",
+			"GET",
+			"/v2/badge/ch4.svg",
+			handlers.ApiHandler{
+				Handler: ch4.GetBadge,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"v2.getCo2Badge",
+			"GET",
+			"/v2/badge/co2.svg",
+			handlers.ApiHandler{
+				Handler: co2.GetBadge,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"v2.getCh4",
+			"GET",
+			"/v2/ch4",
+			handlers.ApiHandler{
+				Handler: ch4.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"v2.getCh4Chart",
+			"GET",
+			"/v2/ch4/chart.svg",
+			handlers.ApiHandler{
+				Handler: ch4.GetChart,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"v2.getCh4FeedAtom",
+			"GET",
+			"/v2/ch4/feed.atom",
+			handlers.ApiHandler{
+				Handler: ch4.GetFeed,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"v2.getCh4FeedRss",
+			"GET",
+			"/v2/ch4/feed.rss",
+			handlers.ApiHandler{
+				Handler: ch4.GetFeed,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"v2.getCh4Latest",
+			"GET",
+			"/v2/ch4/latest",
+			handlers.ApiHandler{
+				Handler: ch4.GetLatest,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"v2.getCh4Monthly",
+			"GET",
+			"/v2/ch4/monthly",
+			handlers.ApiHandler{
+				Handler: ch4.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"v2.getCh4MonthlyChart",
+			"GET",
+			"/v2/ch4/monthly/chart.svg",
+			handlers.ApiHandler{
+				Handler: ch4.GetChart,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"v2.getCh4MonthlyTrend",
+			"GET",
+			"/v2/ch4/monthly/trend",
+			handlers.ApiHandler{
+				Handler: ch4.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "trend",
+				},
+			},
+		},
+		Route{
+			"v2.getCh4MonthlyTrendChart",
+			"GET",
+			"/v2/ch4/monthly/trend/chart.svg",
+			handlers.ApiHandler{
+				Handler: ch4.GetChart,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "trend",
+				},
+			},
+		},
+		Route{
+			"v2.co2Weekly",
+			"GET",
+			"/v2/co2",
+			handlers.ApiHandler{
+				Handler: co2.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"v2.getCo2Chart",
+			"GET",
+			"/v2/co2/chart.svg",
+			handlers.ApiHandler{
+				Handler: co2.GetChart,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"v2.getCo2FeedAtom",
+			"GET",
+			"/v2/co2/feed.atom",
+			handlers.ApiHandler{
+				Handler: co2.GetFeed,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"v2.getCo2FeedRss",
+			"GET",
+			"/v2/co2/feed.rss",
+			handlers.ApiHandler{
+				Handler: co2.GetFeed,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"v2.getCo2Latest",
+			"GET",
+			"/v2/co2/latest",
+			handlers.ApiHandler{
+				Handler: co2.GetLatest,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"v2.getCo2Weekly",
+			"GET",
+			"/v2/co2/weekly",
+			handlers.ApiHandler{
+				Handler: co2.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"v2.getCo2WeeklyChart",
+			"GET",
+			"/v2/co2/weekly/chart.svg",
+			handlers.ApiHandler{
+				Handler: co2.GetChart,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"v2.getCo2WeeklyIncrease",
+			"GET",
+			"/v2/co2/weekly/increase",
+			handlers.ApiHandler{
+				Handler: co2.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "increase",
+				},
+			},
+		},
+		Route{
+			"v2.getCo2WeeklyIncreaseChart",
+			"GET",
+			"/v2/co2/weekly/increase/chart.svg",
+			handlers.ApiHandler{
+				Handler: co2.GetChart,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					SortBy:     "increase",
+				},
+			},
+		},
+		Route{
+			"v2.getCo2PPM",
+			"GET",
+			"/v2/co2/weekly/{ppm}",
+			handlers.ApiHandler{
+				Handler: co2.Get,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+					PathParam:  true,
+					SortBy:     "average",
+				},
+			},
+		},
+		Route{
+			"v2.getDatasets",
+			"GET",
+			"/v2/datasets",
+			handlers.ApiHandler{
+				Handler: handlers.GetDatasets,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"v2.getDataset",
+			"GET",
+			"/v2/datasets/{id}",
+			handlers.ApiHandler{
+				Handler: handlers.GetDataset,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+		Route{
+			"v2.getServerHealth",
+			"GET",
+			"/v2/health",
+			handlers.ApiHandler{
+				Handler: handlers.GetHealth,
+				Config: &handlers.ApiHandlerConfig{
+					Database:   apiserver.Database,
+					Events:     apiserver.Events,
+					Catalog:    apiserver.Catalog,
+					Conditions: apiserver.Conditions,
+				},
+			},
+		},
+	}
+}
+
+// specParams holds the validators of the query and path parameters of the routes returned by specRoutes, by route name.
+var specParams = map[string]openapi.Params{
+	"co2WeeklyViaRoot": openapi.NewParams(
+		&openapi.Parameter{Name: "year", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "month", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "gt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "gt"},
+		&openapi.Parameter{Name: "lt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "lt"},
+		&openapi.Parameter{Name: "gte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "gte"},
+		&openapi.Parameter{Name: "lte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "lte"},
+		&openapi.Parameter{Name: "simple", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "pretty", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{OneOf: []*openapi.Schema{&openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}, &openapi.Schema{Type: "string", Enum: []interface{}{"all"}}}}},
+		&openapi.Parameter{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(1), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "sort", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd", "-year", "-month", "-day", "-date_decimal", "-average", "-ndays", "-one_year_ago", "-ten_years_ago", "-increase_since_1800", "-yyyymmdd"}}}},
+		&openapi.Parameter{Name: "fields", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd"}}}},
+		&openapi.Parameter{Name: "filter", In: "query", Explode: openapi.Bool(true), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", MaxLength: openapi.Int(1024)}}},
+		&openapi.Parameter{Name: "format", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"json", "csv", "tsv", "ndjson", "arrow", "parquet"}}},
+		&openapi.Parameter{Name: "units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"ppm", "ppb", "ug/m3", "GtC"}}},
+		&openapi.Parameter{Name: "filter_units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"requested", "canonical"}}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getCh4Badge": openapi.NewParams(),
+	"getCo2Badge": openapi.NewParams(),
+	"getCh4": openapi.NewParams(
+		&openapi.Parameter{Name: "year", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "month", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "gt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "gt"},
+		&openapi.Parameter{Name: "lt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "lt"},
+		&openapi.Parameter{Name: "gte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "gte"},
+		&openapi.Parameter{Name: "lte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "lte"},
+		&openapi.Parameter{Name: "simple", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "pretty", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{OneOf: []*openapi.Schema{&openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}, &openapi.Schema{Type: "string", Enum: []interface{}{"all"}}}}},
+		&openapi.Parameter{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(1), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "sort", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "average_unc", "trend", "trend_unc", "yyyymmdd", "-year", "-month", "-day", "-date_decimal", "-average", "-average_unc", "-trend", "-trend_unc", "-yyyymmdd"}}}},
+		&openapi.Parameter{Name: "fields", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "average_unc", "trend", "trend_unc", "yyyymmdd"}}}},
+		&openapi.Parameter{Name: "filter", In: "query", Explode: openapi.Bool(true), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", MaxLength: openapi.Int(1024)}}},
+		&openapi.Parameter{Name: "format", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"json", "csv", "tsv", "ndjson", "arrow", "parquet"}}},
+		&openapi.Parameter{Name: "units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"ppb", "ppm", "ug/m3"}}},
+		&openapi.Parameter{Name: "filter_units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"requested", "canonical"}}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getCh4Chart": openapi.NewParams(
+		&openapi.Parameter{Name: "year", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "month", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "gt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(3000)}, Bound: "gt"},
+		&openapi.Parameter{Name: "lt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(3000)}, Bound: "lt"},
+		&openapi.Parameter{Name: "gte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(3000)}, Bound: "gte"},
+		&openapi.Parameter{Name: "lte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(3000)}, Bound: "lte"},
+		&openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{OneOf: []*openapi.Schema{&openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}, &openapi.Schema{Type: "string", Enum: []interface{}{"all"}}}}},
+		&openapi.Parameter{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(1), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "filter", In: "query", Explode: openapi.Bool(true), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", MaxLength: openapi.Int(1024)}}},
+		&openapi.Parameter{Name: "width", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Float(200), Maximum: openapi.Float(2000)}},
+		&openapi.Parameter{Name: "height", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Float(100), Maximum: openapi.Float(1200)}},
+		&openapi.Parameter{Name: "theme", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"light", "dark"}}},
+		&openapi.Parameter{Name: "trend", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getCh4FeedAtom": openapi.NewParams(),
+	"getCh4FeedRss":  openapi.NewParams(),
+	"getCh4Latest": openapi.NewParams(
+		&openapi.Parameter{Name: "simple", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "pretty", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "fields", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "average_unc", "trend", "trend_unc", "yyyymmdd"}}}},
+		&openapi.Parameter{Name: "units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"ppb", "ppm", "ug/m3"}}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getCh4Monthly": openapi.NewParams(
+		&openapi.Parameter{Name: "year", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "month", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "gt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "gt"},
+		&openapi.Parameter{Name: "lt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "lt"},
+		&openapi.Parameter{Name: "gte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "gte"},
+		&openapi.Parameter{Name: "lte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "lte"},
+		&openapi.Parameter{Name: "simple", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "pretty", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{OneOf: []*openapi.Schema{&openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}, &openapi.Schema{Type: "string", Enum: []interface{}{"all"}}}}},
+		&openapi.Parameter{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(1), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "sort", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "average_unc", "trend", "trend_unc", "yyyymmdd", "-year", "-month", "-day", "-date_decimal", "-average", "-average_unc", "-trend", "-trend_unc", "-yyyymmdd"}}}},
+		&openapi.Parameter{Name: "fields", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "average_unc", "trend", "trend_unc", "yyyymmdd"}}}},
+		&openapi.Parameter{Name: "filter", In: "query", Explode: openapi.Bool(true), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", MaxLength: openapi.Int(1024)}}},
+		&openapi.Parameter{Name: "format", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"json", "csv", "tsv", "ndjson", "arrow", "parquet"}}},
+		&openapi.Parameter{Name: "units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"ppb", "ppm", "ug/m3"}}},
+		&openapi.Parameter{Name: "filter_units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"requested", "canonical"}}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getCh4MonthlyChart": openapi.NewParams(
+		&openapi.Parameter{Name: "year", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "month", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "gt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(3000)}, Bound: "gt"},
+		&openapi.Parameter{Name: "lt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(3000)}, Bound: "lt"},
+		&openapi.Parameter{Name: "gte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(3000)}, Bound: "gte"},
+		&openapi.Parameter{Name: "lte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(3000)}, Bound: "lte"},
+		&openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{OneOf: []*openapi.Schema{&openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}, &openapi.Schema{Type: "string", Enum: []interface{}{"all"}}}}},
+		&openapi.Parameter{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(1), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "filter", In: "query", Explode: openapi.Bool(true), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", MaxLength: openapi.Int(1024)}}},
+		&openapi.Parameter{Name: "width", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Float(200), Maximum: openapi.Float(2000)}},
+		&openapi.Parameter{Name: "height", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Float(100), Maximum: openapi.Float(1200)}},
+		&openapi.Parameter{Name: "theme", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"light", "dark"}}},
+		&openapi.Parameter{Name: "trend", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getCh4MonthlyTrend": openapi.NewParams(
+		&openapi.Parameter{Name: "year", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "month", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "gt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "gt"},
+		&openapi.Parameter{Name: "lt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "lt"},
+		&openapi.Parameter{Name: "gte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "gte"},
+		&openapi.Parameter{Name: "lte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "lte"},
+		&openapi.Parameter{Name: "simple", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "pretty", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{OneOf: []*openapi.Schema{&openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}, &openapi.Schema{Type: "string", Enum: []interface{}{"all"}}}}},
+		&openapi.Parameter{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(1), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "sort", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "average_unc", "trend", "trend_unc", "yyyymmdd", "-year", "-month", "-day", "-date_decimal", "-average", "-average_unc", "-trend", "-trend_unc", "-yyyymmdd"}}}},
+		&openapi.Parameter{Name: "fields", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "average_unc", "trend", "trend_unc", "yyyymmdd"}}}},
+		&openapi.Parameter{Name: "filter", In: "query", Explode: openapi.Bool(true), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", MaxLength: openapi.Int(1024)}}},
+		&openapi.Parameter{Name: "format", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"json", "csv", "tsv", "ndjson", "arrow", "parquet"}}},
+		&openapi.Parameter{Name: "units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"ppb", "ppm", "ug/m3"}}},
+		&openapi.Parameter{Name: "filter_units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"requested", "canonical"}}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getCh4MonthlyTrendChart": openapi.NewParams(
+		&openapi.Parameter{Name: "year", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "month", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "gt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(3000)}, Bound: "gt"},
+		&openapi.Parameter{Name: "lt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(3000)}, Bound: "lt"},
+		&openapi.Parameter{Name: "gte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(3000)}, Bound: "gte"},
+		&openapi.Parameter{Name: "lte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(3000)}, Bound: "lte"},
+		&openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{OneOf: []*openapi.Schema{&openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}, &openapi.Schema{Type: "string", Enum: []interface{}{"all"}}}}},
+		&openapi.Parameter{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(1), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "filter", In: "query", Explode: openapi.Bool(true), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", MaxLength: openapi.Int(1024)}}},
+		&openapi.Parameter{Name: "width", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Float(200), Maximum: openapi.Float(2000)}},
+		&openapi.Parameter{Name: "height", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Float(100), Maximum: openapi.Float(1200)}},
+		&openapi.Parameter{Name: "theme", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"light", "dark"}}},
+		&openapi.Parameter{Name: "trend", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"co2Weekly": openapi.NewParams(
+		&openapi.Parameter{Name: "year", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "month", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "gt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "gt"},
+		&openapi.Parameter{Name: "lt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "lt"},
+		&openapi.Parameter{Name: "gte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "gte"},
+		&openapi.Parameter{Name: "lte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "lte"},
+		&openapi.Parameter{Name: "simple", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "pretty", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{OneOf: []*openapi.Schema{&openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}, &openapi.Schema{Type: "string", Enum: []interface{}{"all"}}}}},
+		&openapi.Parameter{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(1), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "sort", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd", "-year", "-month", "-day", "-date_decimal", "-average", "-ndays", "-one_year_ago", "-ten_years_ago", "-increase_since_1800", "-yyyymmdd"}}}},
+		&openapi.Parameter{Name: "fields", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd"}}}},
+		&openapi.Parameter{Name: "filter", In: "query", Explode: openapi.Bool(true), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", MaxLength: openapi.Int(1024)}}},
+		&openapi.Parameter{Name: "format", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"json", "csv", "tsv", "ndjson", "arrow", "parquet"}}},
+		&openapi.Parameter{Name: "units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"ppm", "ppb", "ug/m3", "GtC"}}},
+		&openapi.Parameter{Name: "filter_units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"requested", "canonical"}}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getCo2Chart": openapi.NewParams(
+		&openapi.Parameter{Name: "year", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "month", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "gt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(1000)}, Bound: "gt"},
+		&openapi.Parameter{Name: "lt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(1000)}, Bound: "lt"},
+		&openapi.Parameter{Name: "gte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(1000)}, Bound: "gte"},
+		&openapi.Parameter{Name: "lte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(1000)}, Bound: "lte"},
+		&openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{OneOf: []*openapi.Schema{&openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}, &openapi.Schema{Type: "string", Enum: []interface{}{"all"}}}}},
+		&openapi.Parameter{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(1), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "filter", In: "query", Explode: openapi.Bool(true), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", MaxLength: openapi.Int(1024)}}},
+		&openapi.Parameter{Name: "width", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Float(200), Maximum: openapi.Float(2000)}},
+		&openapi.Parameter{Name: "height", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Float(100), Maximum: openapi.Float(1200)}},
+		&openapi.Parameter{Name: "theme", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"light", "dark"}}},
+		&openapi.Parameter{Name: "trend", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getCo2FeedAtom": openapi.NewParams(),
+	"getCo2FeedRss":  openapi.NewParams(),
+	"getCo2Latest": openapi.NewParams(
+		&openapi.Parameter{Name: "simple", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "pretty", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "fields", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd"}}}},
+		&openapi.Parameter{Name: "units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"ppm", "ppb", "ug/m3", "GtC"}}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getCo2Weekly": openapi.NewParams(
+		&openapi.Parameter{Name: "year", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "month", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "gt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "gt"},
+		&openapi.Parameter{Name: "lt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "lt"},
+		&openapi.Parameter{Name: "gte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "gte"},
+		&openapi.Parameter{Name: "lte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "lte"},
+		&openapi.Parameter{Name: "simple", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "pretty", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{OneOf: []*openapi.Schema{&openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}, &openapi.Schema{Type: "string", Enum: []interface{}{"all"}}}}},
+		&openapi.Parameter{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(1), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "sort", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd", "-year", "-month", "-day", "-date_decimal", "-average", "-ndays", "-one_year_ago", "-ten_years_ago", "-increase_since_1800", "-yyyymmdd"}}}},
+		&openapi.Parameter{Name: "fields", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd"}}}},
+		&openapi.Parameter{Name: "filter", In: "query", Explode: openapi.Bool(true), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", MaxLength: openapi.Int(1024)}}},
+		&openapi.Parameter{Name: "format", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"json", "csv", "tsv", "ndjson", "arrow", "parquet"}}},
+		&openapi.Parameter{Name: "units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"ppm", "ppb", "ug/m3", "GtC"}}},
+		&openapi.Parameter{Name: "filter_units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"requested", "canonical"}}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getCo2WeeklyChart": openapi.NewParams(
+		&openapi.Parameter{Name: "year", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "month", In: "query", Schema: &openapi.Schema{Type: "string", Pattern: "^!?[0-9.-]+(,[0-9.-]+)*$"}, Range: true},
+		&openapi.Parameter{Name: "gt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(1000)}, Bound: "gt"},
+		&openapi.Parameter{Name: "lt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(1000)}, Bound: "lt"},
+		&openapi.Parameter{Name: "gte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(1000)}, Bound: "gte"},
+		&openapi.Parameter{Name: "lte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(1000)}, Bound: "lte"},
+		&openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{OneOf: []*openapi.Schema{&openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}, &openapi.Schema{Type: "string", Enum: []interface{}{"all"}}}}},
+		&openapi.Parameter{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(1), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "filter", In: "query", Explode: openapi.Bool(true), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", MaxLength: openapi.Int(1024)}}},
+		&openapi.Parameter{Name: "width", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Float(200), Maximum: openapi.Float(2000)}},
+		&openapi.Parameter{Name: "height", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Float(100), Maximum: openapi.Float(1200)}},
+		&openapi.Parameter{Name: "theme", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"light", "dark"}}},
+		&openapi.Parameter{Name: "trend", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getCo2WeeklyIncrease": openapi.NewParams(
+		&openapi.Parameter{Name: "gt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "gt"},
+		&openapi.Parameter{Name: "lt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "lt"},
+		&openapi.Parameter{Name: "gte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "gte"},
+		&openapi.Parameter{Name: "lte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0)}, Bound: "lte"},
+		&openapi.Parameter{Name: "simple", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "pretty", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{OneOf: []*openapi.Schema{&openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}, &openapi.Schema{Type: "string", Enum: []interface{}{"all"}}}}},
+		&openapi.Parameter{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(1), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "sort", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd", "-year", "-month", "-day", "-date_decimal", "-average", "-ndays", "-one_year_ago", "-ten_years_ago", "-increase_since_1800", "-yyyymmdd"}}}},
+		&openapi.Parameter{Name: "fields", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd"}}}},
+		&openapi.Parameter{Name: "filter", In: "query", Explode: openapi.Bool(true), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", MaxLength: openapi.Int(1024)}}},
+		&openapi.Parameter{Name: "format", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"json", "csv", "tsv", "ndjson", "arrow", "parquet"}}},
+		&openapi.Parameter{Name: "units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"ppm", "ppb", "ug/m3", "GtC"}}},
+		&openapi.Parameter{Name: "filter_units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"requested", "canonical"}}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getCo2WeeklyIncreaseChart": openapi.NewParams(
+		&openapi.Parameter{Name: "gt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(1000)}, Bound: "gt"},
+		&openapi.Parameter{Name: "lt", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(1000)}, Bound: "lt"},
+		&openapi.Parameter{Name: "gte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(1000)}, Bound: "gte"},
+		&openapi.Parameter{Name: "lte", In: "query", Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(1000)}, Bound: "lte"},
+		&openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{OneOf: []*openapi.Schema{&openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}, &openapi.Schema{Type: "string", Enum: []interface{}{"all"}}}}},
+		&openapi.Parameter{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(0), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(1), Maximum: openapi.Float(10000)}},
+		&openapi.Parameter{Name: "filter", In: "query", Explode: openapi.Bool(true), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", MaxLength: openapi.Int(1024)}}},
+		&openapi.Parameter{Name: "width", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Float(200), Maximum: openapi.Float(2000)}},
+		&openapi.Parameter{Name: "height", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Float(100), Maximum: openapi.Float(1200)}},
+		&openapi.Parameter{Name: "theme", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"light", "dark"}}},
+		&openapi.Parameter{Name: "trend", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getCo2PPM": openapi.NewParams(
+		&openapi.Parameter{Name: "ppm", In: "path", Required: true, Schema: &openapi.Schema{Type: "number", Format: "float", Minimum: openapi.Float(0), Maximum: openapi.Float(1000)}},
+		&openapi.Parameter{Name: "simple", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "pretty", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		&openapi.Parameter{Name: "sort", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd", "-year", "-month", "-day", "-date_decimal", "-average", "-ndays", "-one_year_ago", "-ten_years_ago", "-increase_since_1800", "-yyyymmdd"}}}},
+		&openapi.Parameter{Name: "fields", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd"}}}},
+		&openapi.Parameter{Name: "format", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"json", "csv", "tsv", "ndjson", "arrow", "parquet"}}},
+		&openapi.Parameter{Name: "units", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"ppm", "ppb", "ug/m3", "GtC"}}},
+		&openapi.Parameter{Name: "strict", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+	),
+	"getDatasets": openapi.NewParams(),
+	"getDataset": openapi.NewParams(
+		&openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}},
+	),
+	"getGraphql": openapi.NewParams(
+		&openapi.Parameter{Name: "query", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}},
+		&openapi.Parameter{Name: "variables", In: "query", Schema: &openapi.Schema{Type: "string"}},
+		&openapi.Parameter{Name: "operationName", In: "query", Schema: &openapi.Schema{Type: "string"}},
+	),
+	"postGraphql":     openapi.NewParams(),
+	"getServerHealth": openapi.NewParams(),
+	"getOpenApiSpec":  openapi.NewParams(),
+	"getStream": openapi.NewParams(
+		&openapi.Parameter{Name: "dataset", In: "query", Explode: openapi.Bool(false), Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []interface{}{"co2_weekly_mlo", "ch4_mm_gl"}}}},
+		&openapi.Parameter{Name: "lastEventId", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int64", Minimum: openapi.Float(0)}},
+	),
+	"createSubscription": openapi.NewParams(),
+	"getSubscription": openapi.NewParams(
+		&openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}},
+	),
+	"deleteSubscription": openapi.NewParams(
+		&openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}},
+	),
+	"getSubscriptionDeliveries": openapi.NewParams(
+		&openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}},
+		&openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Float(1), Maximum: openapi.Float(1000)}},
+	),
+}
@@ -24,15 +24,26 @@ Contact: planetpulse.api@gmail.com
 package server
 
 import (
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/notify"
+	"apiserver/pkg/openapi"
+	"apiserver/pkg/rpc"
+	"apiserver/pkg/server/handlers"
 	utils "apiserver/pkg/utils"
+	"apiserver/pkg/webhook"
 	"context"
+	"net"
 	"net/http"
 	"strconv"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
-// Start initializes the API server and begins listening on the configured port.
+// eventHistory is the number of recent dataset changes kept for event stream clients that reconnect
+const eventHistory = 1000
+
+// Start initializes the API server and begins listening on the configured HTTP and gRPC ports.
 func (apiserver *ApiServer) Start() {
 	if err := apiserver.ServerInit(); err != nil {
 		utils.ErrorLog(err)
@@ -41,12 +52,56 @@ func (apiserver *ApiServer) Start() {
 	defer apiserver.Database.DB.Close()
 	log.Info("Server started.")
 
+	go apiserver.serveGrpc()
+	go apiserver.watchDatasets()
+	go apiserver.deliverWebhooks()
+	go apiserver.refreshCatalog()
+
 	log.Fatal(http.ListenAndServe(":"+strconv.Itoa(apiserver.Config.HttpPort), apiserver.Router))
 }
 
+// serveGrpc serves the gRPC API on its own port, next to the HTTP server.
+func (apiserver *ApiServer) serveGrpc() {
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(apiserver.Config.GrpcPort))
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Fatal(apiserver.Grpc.Serve(listener))
+}
+
+// watchDatasets publishes the changes to the datasets to event stream subscribers. Changes are detected by
+// the notifier selected in config.yaml.
+func (apiserver *ApiServer) watchDatasets() {
+	interval := time.Duration(apiserver.Config.StreamPollInterval) * time.Second
+
+	var notifier notify.Notifier = &notify.PollNotifier{Database: apiserver.Database, Interval: interval}
+	if apiserver.Config.StreamNotifier == "listen" {
+		notifier = &notify.ListenNotifier{Database: apiserver.Database, Interval: interval}
+	}
+
+	if err := apiserver.Events.Run(context.Background(), notifier); err != nil {
+		utils.ErrorLog(utils.NewError(err, "stopped watching datasets for changes", 500, false))
+	}
+}
+
+// deliverWebhooks delivers the changes to the datasets to the webhook subscriptions.
+func (apiserver *ApiServer) deliverWebhooks() {
+	if err := webhook.NewDispatcher(apiserver.Database).Run(context.Background(), apiserver.Events); err != nil {
+		utils.ErrorLog(utils.NewError(err, "stopped delivering webhooks", 500, false))
+	}
+}
+
+// refreshCatalog drops the cached coverage of the datasets as they change.
+func (apiserver *ApiServer) refreshCatalog() {
+	if err := apiserver.Catalog.Run(context.Background(), apiserver.Events); err != nil {
+		utils.ErrorLog(utils.NewError(err, "stopped refreshing the dataset catalog", 500, false))
+	}
+}
+
 // ServerInit initializes the API server. The initialization process loads configuration data
-// from config.yaml and environment variables, configures the logger, creates a top level context, establishes
-// a database connection, and generates a router to forward requests to handler functions.
+// from config.yaml and environment variables, configures the logger, creates a top level context, loads the OpenAPI
+// spec, establishes a database connection, generates a router to forward requests to handler functions, and creates
+// the gRPC server.
 func (apiserver *ApiServer) ServerInit() *utils.ServerError {
 	// Configure server parameters. If this fails, a fatal log.Fatal will be called
 	// and the server process will be terminated
@@ -65,9 +120,34 @@ func (apiserver *ApiServer) ServerInit() *utils.ServerError {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Changes to the datasets are published to event streams through the broker
+	apiserver.Events = notify.NewBroker(eventHistory)
+
+	// The coverage of the datasets is computed from every row of their tables, so it is cached between requests
+	apiserver.Catalog = handlers.NewCatalog(time.Duration(apiserver.Config.CatalogCacheTTL) * time.Second)
+
+	// Concentrations requested in ug/m3 are computed at the temperature and pressure configured for the environment
+	apiserver.Conditions = models.Conditions{Temperature: apiserver.Config.UnitsTemperature, Pressure: apiserver.Config.UnitsPressure}
+
+	// Requests and responses are validated against the embedded OpenAPI spec as configured for the environment
+	spec, specErr := openapi.V1()
+	if specErr != nil {
+		return utils.NewError(specErr, "cannot load the OpenAPI spec", 500, true)
+	}
+	apiserver.Validator = &openapi.Validator{
+		Spec:      spec,
+		Requests:  apiserver.Config.ValidateRequests,
+		Responses: openapi.ResponseMode(apiserver.Config.ValidateResponses),
+		Params:    specParams,
+		Versions:  []openapi.Version{{Name: string(utils.V2), Strict: true}},
+	}
+
 	// Generate routes
 	apiserver.Router = apiserver.NewRouter(ctx, apiserver.CreateRoutes())
 
+	// The gRPC server shares the database connection with the HTTP handlers
+	apiserver.Grpc = rpc.NewServer(apiserver.Database)
+
 	// Establish database connection. If this fails the server will recover and
 	// begin serving, but will only return error messages to the client until a
 	// db connection is established.
@@ -25,9 +25,14 @@ package server
 
 import (
 	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/notify"
+	"apiserver/pkg/openapi"
 	"apiserver/pkg/server/handlers"
+	"time"
 
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
 )
 
 // ApiServer provides a way to interact with server components and underlying server methods.
@@ -35,6 +40,18 @@ type ApiServer struct {
 	Config   *ApiConfig
 	Database *database.Database
 	Router   *mux.Router
+	Grpc     *grpc.Server
+	Events   *notify.Broker
+
+	// Catalog caches the coverage of the datasets listed by /v1/datasets, and the time they were last updated reported
+	// in the metadata of dataset responses
+	Catalog *handlers.Catalog
+
+	// Conditions are the temperature and pressure concentrations are converted to mass concentrations at
+	Conditions models.Conditions
+
+	// Validator checks requests and responses against the embedded OpenAPI spec
+	Validator *openapi.Validator
 }
 
 // ApiConfig represents configuration parameters for the API server
@@ -45,8 +62,38 @@ type ApiConfig struct {
 	// (OPTIONAL) The port the server will listen for HTTPS traffic on
 	HttpsPort int
 
+	// (OPTIONAL) The port the server will listen for gRPC traffic on
+	GrpcPort int
+
 	// (OPTIONAL) The global server log level
 	LogLevel int
+
+	// (OPTIONAL) How changes to the datasets are detected for event streams, either "poll" or "listen"
+	StreamNotifier string
+
+	// (OPTIONAL) The interval in seconds between two checks of the datasets for changes
+	StreamPollInterval int
+
+	// (OPTIONAL) How long in seconds the coverage of a dataset listed by /v1/datasets is cached for
+	CatalogCacheTTL int
+
+	// (OPTIONAL) The temperature in kelvin concentrations requested in ug/m3 are computed at
+	UnitsTemperature float64
+
+	// (OPTIONAL) The pressure in pascals concentrations requested in ug/m3 are computed at
+	UnitsPressure float64
+
+	// (OPTIONAL) Whether query and path parameters are validated against the OpenAPI spec
+	ValidateRequests bool
+
+	// (OPTIONAL) What is done with responses that do not match the OpenAPI spec, either "off", "log" or "enforce"
+	ValidateResponses string
+
+	// (OPTIONAL) The date /v1 was deprecated on, announced in the Deprecation header of its responses
+	V1Deprecation time.Time
+
+	// (OPTIONAL) The date /v1 will stop being served on, announced in the Sunset header of its responses
+	V1Sunset time.Time
 }
 
 // Route represents an HTTP route (a mapping from a URL path to a handler function).
@@ -68,9 +115,39 @@ type YamlConfig struct {
 	// (OPTIONAL) The port the server will listen for HTTPS traffic on
 	HttpsPort int `env:"false" name:"HttpsPort" validate:"gte=0,lte=65535"`
 
+	// (OPTIONAL) The port the server will listen for gRPC traffic on
+	GrpcPort int `env:"false" name:"GrpcPort" validate:"gte=0,lte=65535"`
+
 	// (OPTIONAL) The global server log level
 	LogLevel int `env:"false" name:"LogLevel" validate:"gte=0,lte=6"`
 
+	// (OPTIONAL) How changes to the datasets are detected for event streams, either "poll" or "listen"
+	StreamNotifier string `env:"false" name:"StreamNotifier" validate:"oneof=poll listen"`
+
+	// (OPTIONAL) The interval in seconds between two checks of the datasets for changes
+	StreamPollInterval int `env:"false" name:"StreamPollInterval" validate:"gte=1,lte=86400"`
+
+	// (OPTIONAL) How long in seconds the coverage of a dataset listed by /v1/datasets is cached for
+	CatalogCacheTTL int `env:"false" name:"CatalogCacheTTL" validate:"gte=0,lte=86400"`
+
+	// (OPTIONAL) The temperature in kelvin concentrations requested in ug/m3 are computed at
+	UnitsTemperature float64 `env:"false" name:"UnitsTemperature" validate:"gt=0,lte=400"`
+
+	// (OPTIONAL) The pressure in pascals concentrations requested in ug/m3 are computed at
+	UnitsPressure float64 `env:"false" name:"UnitsPressure" validate:"gt=0,lte=200000"`
+
+	// (OPTIONAL) Whether query and path parameters are validated against the OpenAPI spec
+	ValidateRequests bool `env:"false" name:"ValidateRequests"`
+
+	// (OPTIONAL) What is done with responses that do not match the OpenAPI spec, either "off", "log" or "enforce"
+	ValidateResponses string `env:"false" name:"ValidateResponses" validate:"oneof=off log enforce"`
+
+	// (OPTIONAL) The date /v1 was deprecated on, as yyyy-mm-dd
+	V1Deprecation string `env:"false" name:"V1Deprecation" validate:"omitempty,datetime=2006-01-02"`
+
+	// (OPTIONAL) The date /v1 will stop being served on, as yyyy-mm-dd
+	V1Sunset string `env:"false" name:"V1Sunset" validate:"omitempty,datetime=2006-01-02"`
+
 	// (OPTIONAL) The connection timeout in seconds used when connecting to the database
 	DBConnTimeout int `env:"false" name:"DBConnTimeout" validate:"gte=0,lte=120"`
 }
@@ -0,0 +1,191 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package server
+
+import (
+	"apiserver/pkg/openapi"
+	"apiserver/pkg/server/handlers"
+	utils "apiserver/pkg/utils"
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// maxSuggestions bounds the number of routes suggested for a path no route matches.
+const maxSuggestions = 3
+
+// unmatched answers the requests no route matches. Requests for a path some route matches with another method
+// are answered with a 405 error listing the allowed methods, other requests with a 404 error suggesting the
+// closest routes.
+type unmatched struct {
+	patterns []routePattern
+}
+
+// routePattern is a path pattern of the router with the methods routed on it.
+type routePattern struct {
+	Pattern string
+	Methods []string
+
+	regexp *regexp.Regexp
+}
+
+// newUnmatched returns the unmatched handler of a set of routes. Routes with an invalid pattern are ignored, as
+// the router itself rejects them.
+func newUnmatched(routes Routes) *unmatched {
+	byPattern := make(map[string]*routePattern)
+	var patterns []*routePattern
+	for _, route := range routes {
+		pattern, ok := byPattern[route.Pattern]
+		if !ok {
+			re, err := mux.NewRouter().Path(route.Pattern).GetPathRegexp()
+			if err != nil {
+				continue
+			}
+			pattern = &routePattern{Pattern: route.Pattern, regexp: regexp.MustCompile(re)}
+			byPattern[route.Pattern] = pattern
+			patterns = append(patterns, pattern)
+		}
+		pattern.Methods = append(pattern.Methods, strings.ToUpper(route.Method))
+	}
+
+	u := &unmatched{}
+	for _, pattern := range patterns {
+		sortMethods(pattern.Methods)
+		u.patterns = append(u.patterns, *pattern)
+	}
+	return u
+}
+
+// Handler returns the ApiHandler answering unmatched requests.
+func (u *unmatched) Handler() handlers.ApiHandler {
+	return handlers.ApiHandler{Handler: u.serve, Config: &handlers.ApiHandlerConfig{}}
+}
+
+func (u *unmatched) serve(ctx context.Context, handlerConfig *handlers.ApiHandlerConfig, w http.ResponseWriter, r *http.Request) *utils.ServerError {
+	if allowed := u.Allowed(r.URL.Path); len(allowed) != 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		message := fmt.Sprintf("method %v is not allowed on '%v', allowed methods are %v", r.Method, r.URL.Path, strings.Join(allowed, ", "))
+		return utils.NewError(fmt.Errorf("method not allowed"), message, 405, false)
+	}
+
+	message := fmt.Sprintf("no route matches '%v'", r.URL.Path)
+	if suggestions := u.Suggest(r.URL.Path); len(suggestions) != 0 {
+		quoted := make([]string, len(suggestions))
+		for i, suggestion := range suggestions {
+			quoted[i] = "'" + suggestion + "'"
+		}
+		message += ", did you mean " + strings.Join(quoted, " or ") + "?"
+	}
+	return utils.NewError(fmt.Errorf("route not found"), message, 404, false)
+}
+
+// Allowed returns the methods routed on a path, in the order of openapi.Methods.
+func (u *unmatched) Allowed(path string) []string {
+	var allowed []string
+	for _, pattern := range u.patterns {
+		if !pattern.regexp.MatchString(path) {
+			continue
+		}
+		for _, method := range pattern.Methods {
+			if !contains(allowed, method) {
+				allowed = append(allowed, method)
+			}
+		}
+	}
+	sortMethods(allowed)
+	return allowed
+}
+
+// Suggest returns the patterns closest to a path by edit distance, closest first. Templated segments of a pattern
+// stand for the segment of the path at the same position, so that eg. '/v1/co2/weekly/{ppm}' is close to
+// '/v1/co2/weeky/400'. Only patterns within a third of the length of the path are suggested, and only patterns of
+// the version of the API the path is under.
+func (u *unmatched) Suggest(path string) []string {
+	type suggestion struct {
+		pattern  string
+		distance int
+	}
+
+	limit := len(path) / 3
+	if limit < 2 {
+		limit = 2
+	}
+
+	version, versioned := utils.PathVersion(path)
+
+	var suggestions []suggestion
+	for _, pattern := range u.patterns {
+		if patternVersion, ok := utils.PathVersion(pattern.Pattern); versioned && ok && patternVersion != version {
+			continue
+		}
+		if d := utils.EditDistance(path, instantiate(pattern.Pattern, path)); d <= limit {
+			suggestions = append(suggestions, suggestion{pattern.Pattern, d})
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool { return suggestions[i].distance < suggestions[j].distance })
+
+	var patterns []string
+	for i := 0; i < len(suggestions) && i < maxSuggestions; i++ {
+		patterns = append(patterns, suggestions[i].pattern)
+	}
+	return patterns
+}
+
+// instantiate replaces the templated segments of a pattern with the segments of a path at the same position.
+func instantiate(pattern string, path string) string {
+	patternSegments, pathSegments := strings.Split(pattern, "/"), strings.Split(path, "/")
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "{") && i < len(pathSegments) {
+			patternSegments[i] = pathSegments[i]
+		}
+	}
+	return strings.Join(patternSegments, "/")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sortMethods sorts HTTP methods in the order of openapi.Methods.
+func sortMethods(methods []string) {
+	index := func(method string) int {
+		for i, m := range openapi.Methods {
+			if m == method {
+				return i
+			}
+		}
+		return len(openapi.Methods)
+	}
+	sort.SliceStable(methods, func(i, j int) bool { return index(methods[i]) < index(methods[j]) })
+}
@@ -0,0 +1,405 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package server
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/openapi"
+	utils "apiserver/pkg/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// conformanceCase is a request served by every version of the API. The path is relative to the base path of a version.
+type conformanceCase struct {
+	path   string
+	status int
+	expect func(mock sqlmock.Sqlmock)
+}
+
+var co2Columns = []string{"year", "month", "day", "date_decimal", "average", "ndays", "one_year_ago", "ten_years_ago", "increase_since_1800", "yyyymmdd"}
+
+func co2Rows(dates ...time.Time) *sqlmock.Rows {
+	rows := sqlmock.NewRows(co2Columns)
+	for _, date := range dates {
+		rows.AddRow(date.Year(), int(date.Month()), date.Day(), 2021.85, 414.99, 7, 412.53, -999.99, 134.67, date)
+	}
+	return rows
+}
+
+var conformanceCases = []conformanceCase{
+	{"/co2/weekly?limit=2", 200, func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("SELECT \\* FROM public.co2_weekly_mlo").WillReturnRows(co2Rows(time.Date(2021, 11, 7, 0, 0, 0, 0, time.UTC), time.Date(2021, 11, 14, 0, 0, 0, 0, time.UTC)))
+	}},
+	{"/co2/weekly?simple=true", 200, func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("SELECT year, month, day, average, increase_since_1800 FROM public.co2_weekly_mlo").
+			WillReturnRows(sqlmock.NewRows([]string{"year", "month", "day", "average", "increase_since_1800"}).AddRow(2021, 11, 7, 414.99, -999.99))
+	}},
+	{"/co2/weekly?fields=yyyymmdd,ten_years_ago", 200, func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("SELECT yyyymmdd, ten_years_ago FROM public.co2_weekly_mlo").
+			WillReturnRows(sqlmock.NewRows([]string{"yyyymmdd", "ten_years_ago"}).AddRow(time.Date(2021, 11, 7, 0, 0, 0, 0, time.UTC), -999.99))
+	}},
+	{"/co2/weekly?year=1900", 200, func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("SELECT \\* FROM public.co2_weekly_mlo").WillReturnRows(co2Rows())
+	}},
+	{"/co2/latest", 200, func(mock sqlmock.Sqlmock) {
+		observed := time.Date(2021, 11, 7, 0, 0, 0, 0, time.UTC)
+		mock.ExpectQuery("SELECT max\\(yyyymmdd\\)").WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(observed))
+		mock.ExpectQuery("SELECT max\\(ingested_at\\)").WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(observed.AddDate(0, 0, 3)))
+		mock.ExpectQuery("SELECT \\* FROM public.co2_weekly_mlo").WillReturnRows(co2Rows(observed))
+		mock.ExpectQuery("SELECT \\* FROM public.co2_weekly_mlo").WillReturnRows(co2Rows(observed.AddDate(-1, 0, 0)))
+		mock.ExpectQuery("SELECT \\* FROM public.co2_weekly_mlo").WillReturnRows(co2Rows())
+	}},
+	{"/datasets/ch4_mm_gl", 200, func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("SELECT min\\(yyyymmdd\\), max\\(yyyymmdd\\), count\\(\\*\\) FROM public.ch4_mm_gl").
+			WillReturnRows(sqlmock.NewRows([]string{"min", "max", "count"}).AddRow(time.Date(1983, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC), 457))
+		mock.ExpectQuery("SELECT max\\(ingested_at\\)").WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+	}},
+	{"/co2/weekly?units=ug/m3&filter_units=requested&gte=800000", 200, func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("SELECT \\* FROM public.co2_weekly_mlo WHERE average >= 407.").WillReturnRows(co2Rows(time.Date(2021, 11, 7, 0, 0, 0, 0, time.UTC)))
+	}},
+	{"/datasets/ch4_mm_yearly", 404, nil},
+	{"/co2/weekly?units=ppb&gt=415000", 400, nil},
+	{"/co2/weekly?units=kg", 400, nil},
+	{"/co2/weekly?gt=1001", 400, nil},
+	{"/co2/weekly/1200", 400, nil},
+	{"/co2/weekly?limit=all", 400, nil},
+	{"/co2/weakly", 404, nil},
+}
+
+// serveVersion serves a conformance case under a version of the API, with the database mocked.
+func serveVersion(t *testing.T, version utils.Version, testVal conformanceCase, config *ApiConfig) *httptest.ResponseRecorder {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error generating mock database: %v", err)
+	}
+	defer db.Close()
+	if testVal.expect != nil {
+		testVal.expect(mock)
+	}
+
+	spec, err := openapi.V1()
+	if err != nil {
+		t.Fatalf("cannot parse the embedded OpenAPI spec: %v", err)
+	}
+	apiserver := &ApiServer{
+		Config:   config,
+		Database: &database.Database{DB: db},
+		Validator: &openapi.Validator{
+			Spec:      spec,
+			Requests:  true,
+			Responses: openapi.ResponsesLog,
+			Params:    specParams,
+			Versions:  []openapi.Version{{Name: string(utils.V2), Strict: true}},
+		},
+	}
+	router := apiserver.NewRouter(context.Background(), apiserver.CreateRoutes())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", version.Base()+testVal.path, nil))
+	if testVal.status == 200 {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("There were unfulfilled expectations for %v: %v", version.Base()+testVal.path, err)
+		}
+	}
+	return w
+}
+
+// TestVersionsConformance serves the same requests under every version of the API. Each response must follow the
+// envelope of its version, and every version must return the same status and the same values.
+func TestVersionsConformance(t *testing.T) {
+	spec, err := openapi.V1()
+	if err != nil {
+		t.Fatalf("cannot parse the embedded OpenAPI spec: %v", err)
+	}
+
+	for _, testVal := range conformanceCases {
+		responses := make(map[utils.Version]interface{})
+		for _, version := range utils.Versions {
+			w := serveVersion(t, version, testVal, nil)
+			if w.Code != testVal.status {
+				t.Errorf("Wanted status %v for %v, Got: %v: %v", testVal.status, version.Base()+testVal.path, w.Code, w.Body.String())
+				continue
+			}
+
+			var body interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Errorf("Wanted a JSON body for %v, Got: %v", version.Base()+testVal.path, err)
+				continue
+			}
+			responses[version] = body
+
+			switch version {
+			case utils.V1:
+				// v1 pads empty results with a null, which its spec does not describe
+				if results, _ := body.(map[string]interface{})["Results"].([]interface{}); len(results) == 1 && results[0] == nil {
+					break
+				}
+				if op := spec.Operation("GET", strings.SplitN(testVal.path, "?", 2)[0]); op != nil {
+					if err := spec.ValidateResponse(op, w.Code, w.Header().Get("Content-Type"), w.Body.Bytes()); err != nil {
+						t.Errorf("Wanted the response of %v to match the spec, Got: %v", version.Base()+testVal.path, err)
+					}
+				}
+			case utils.V2:
+				checkV2(t, testVal.path, w.Code, body)
+			}
+		}
+
+		if len(responses) != len(utils.Versions) || testVal.status != 200 {
+			continue
+		}
+		v1 := responses[utils.V1].(map[string]interface{})["Results"].([]interface{})
+		v2 := responses[utils.V2].(map[string]interface{})["data"].([]interface{})
+		if len(v1) == 1 && v1[0] == nil {
+			v1 = nil
+		}
+		if len(v1) != len(v2) {
+			t.Errorf("Wanted as many results from every version for %v, Got: %v and %v", testVal.path, len(v1), len(v2))
+			continue
+		}
+		for i := range v1 {
+			if problem := sameValues(v1[i], v2[i], fmt.Sprintf("results[%v]", i)); problem != "" {
+				t.Errorf("Wanted every version to return the same values for %v, Got: %v", testVal.path, problem)
+			}
+		}
+	}
+}
+
+var snakeCase = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// checkV2 checks that a response follows the v2 envelope.
+func checkV2(t *testing.T, path string, status int, body interface{}) {
+	resp, ok := body.(map[string]interface{})
+	if !ok {
+		t.Errorf("Wanted an object from /v2%v, Got: %v", path, body)
+		return
+	}
+	for key := range resp {
+		if key != "data" && key != "meta" && key != "error" {
+			t.Errorf("Unexpected member '%v' in the envelope of /v2%v", key, path)
+		}
+	}
+	if key := nonSnakeKey(body); key != "" {
+		t.Errorf("Wanted every member of /v2%v to be named in lower_snake case, Got: '%v'", path, key)
+	}
+
+	meta, _ := resp["meta"].(map[string]interface{})
+	if meta["api_version"] != "v2" || meta["request_id"] == "" {
+		t.Errorf("Wanted the meta of /v2%v to describe the response, Got: %v", path, meta)
+	}
+
+	if status != 200 {
+		e, _ := resp["error"].(map[string]interface{})
+		code, _ := e["code"].(string)
+		if _, ok := utils.LookupErrorCode(code); !ok || e["status"] != float64(status) || meta["status"] != "error" || resp["data"] != nil {
+			t.Errorf("Wanted an error with a code of the error catalog from /v2%v, Got: %v", path, resp)
+		}
+		return
+	}
+
+	data, ok := resp["data"].([]interface{})
+	if !ok || meta["count"] != float64(len(data)) || meta["status"] != "ok" || resp["error"] != nil {
+		t.Errorf("Wanted the data of /v2%v to be counted in its meta, Got: %v", path, resp)
+	}
+}
+
+// nonSnakeKey returns a member of a decoded JSON value that is not named in lower_snake case, if any.
+func nonSnakeKey(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if !snakeCase.MatchString(key) {
+				return key
+			}
+			if key := nonSnakeKey(item); key != "" {
+				return key
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if key := nonSnakeKey(item); key != "" {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+// sameValues compares a result of v1 to the same result of v2, describing the first difference. Members are matched
+// by name: the column of a measurement, or the lower_snake case of the v1 name. Missing measurements of v1 are null
+// in v2, and dates are written without a time.
+func sameValues(v1 interface{}, v2 interface{}, at string) string {
+	switch a := v1.(type) {
+	case map[string]interface{}:
+		b, ok := v2.(map[string]interface{})
+		if !ok {
+			return at + " is not an object in v2"
+		}
+		for key, value := range a {
+			if problem := sameValues(value, b[v2Name(key)], at+"."+key); problem != "" {
+				return problem
+			}
+		}
+		if len(a) != len(b) {
+			for key, value := range b {
+				if _, ok := a[v1Name(key)]; !ok && value != nil {
+					return at + "." + key + " is only returned by v2"
+				}
+			}
+		}
+		return ""
+	case []interface{}:
+		b, ok := v2.([]interface{})
+		if !ok || len(a) != len(b) {
+			return at + " is " + toJson(v1) + " in v1 and " + toJson(v2) + " in v2"
+		}
+		for i := range a {
+			if problem := sameValues(a[i], b[i], fmt.Sprintf("%v[%v]", at, i)); problem != "" {
+				return problem
+			}
+		}
+		return ""
+	case float64:
+		if float32(a) == models.Co2WeeklyMlo.Missing && v2 == nil {
+			return ""
+		}
+	case string:
+		if date, err := time.Parse(time.RFC3339, a); err == nil && v2 == date.Format("2006-01-02") {
+			return ""
+		}
+	}
+	if !reflect.DeepEqual(v1, v2) {
+		return at + " is " + toJson(v1) + " in v1 and " + toJson(v2) + " in v2"
+	}
+	return ""
+}
+
+func v2Name(v1 string) string {
+	if col, ok := fieldColumn(v1); ok {
+		return col.Name
+	}
+	var name []rune
+	for i, r := range v1 {
+		if unicode.IsUpper(r) && i > 0 {
+			name = append(name, '_')
+		}
+		name = append(name, unicode.ToLower(r))
+	}
+	return string(name)
+}
+
+func v1Name(v2 string) string {
+	for _, col := range models.Co2WeeklyMlo.Columns {
+		if col.Name == v2 {
+			return col.Field
+		}
+	}
+	var name []rune
+	upper := true
+	for _, r := range v2 {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+		}
+		name, upper = append(name, r), false
+	}
+	return string(name)
+}
+
+func fieldColumn(field string) (models.Column, bool) {
+	for _, col := range models.Co2WeeklyMlo.Columns {
+		if col.Field == field {
+			return col, true
+		}
+	}
+	return models.Column{}, false
+}
+
+func toJson(value interface{}) string {
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+// TestVersionsDeprecation checks that only the responses of /v1 announce its deprecation, as configured.
+func TestVersionsDeprecation(t *testing.T) {
+	config := &ApiConfig{
+		V1Deprecation: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		V1Sunset:      time.Date(2027, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+	testVal := conformanceCase{"/co2/weekly?gt=1001", 400, nil}
+
+	w := serveVersion(t, utils.V1, testVal, config)
+	if got := w.Header().Get("Deprecation"); got != "@1793491200" {
+		t.Errorf("Wanted /v1 to be deprecated on 2026-11-01, Got: '%v'", got)
+	}
+	if got := w.Header().Get("Sunset"); got != "Sat, 01 May 2027 00:00:00 GMT" {
+		t.Errorf("Wanted /v1 to be retired on 2027-05-01, Got: '%v'", got)
+	}
+
+	w = serveVersion(t, utils.V2, testVal, config)
+	if w.Header().Get("Deprecation") != "" || w.Header().Get("Sunset") != "" {
+		t.Errorf("Wanted /v2 not to be deprecated, Got: %v", w.Header())
+	}
+
+	w = serveVersion(t, utils.V1, testVal, &ApiConfig{})
+	if w.Header().Get("Deprecation") != "" || w.Header().Get("Sunset") != "" {
+		t.Errorf("Wanted /v1 not to be deprecated by default, Got: %v", w.Header())
+	}
+}
+
+// TestVersionsStrict checks that requests to /v2 are in strict mode unless they turn it off.
+func TestVersionsStrict(t *testing.T) {
+	testVals := []struct {
+		version utils.Version
+		path    string
+		status  int
+	}{
+		{utils.V1, "/co2/weekly?yaer=2020", 200},
+		{utils.V1, "/co2/weekly?yaer=2020&strict=true", 400},
+		{utils.V2, "/co2/weekly?yaer=2020", 400},
+		{utils.V2, "/co2/weekly?yaer=2020&strict=false", 200},
+	}
+	for _, testVal := range testVals {
+		w := serveVersion(t, testVal.version, conformanceCase{testVal.path, testVal.status, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT \\* FROM public.co2_weekly_mlo").WillReturnRows(co2Rows())
+		}}, nil)
+		if w.Code != testVal.status {
+			t.Errorf("Wanted status %v for %v, Got: %v: %v", testVal.status, testVal.version.Base()+testVal.path, w.Code, w.Body.String())
+		}
+	}
+}
@@ -26,15 +26,98 @@ package utils
 import (
 	"apiserver/pkg/database/models"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
+	"strconv"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// Codes of the error catalog. Unlike messages, codes are stable: clients may rely on them to tell errors apart.
+const (
+	CodeInvalidParam      = "INVALID_PARAM"
+	CodeOutOfRange        = "OUT_OF_RANGE"
+	CodeUnknownParam      = "UNKNOWN_PARAM"
+	CodeConflictingParams = "CONFLICTING_PARAMS"
+	CodeInvalidBody       = "INVALID_BODY"
+	CodeNotFound          = "NOT_FOUND"
+	CodeMethodNotAllowed  = "METHOD_NOT_ALLOWED"
+	CodePayloadTooLarge   = "PAYLOAD_TOO_LARGE"
+	CodeInternal          = "INTERNAL_ERROR"
+	CodeUnavailable       = "UNAVAILABLE"
+	CodeDbUnavailable     = "DB_UNAVAILABLE"
+	CodeQueryTimeout      = "QUERY_TIMEOUT"
+)
+
+// ProblemMediaType is the media type of RFC 7807 problem details.
+const ProblemMediaType = "application/problem+json"
+
+// ProblemTypeBase prefixes the code of an error to build the type URI of its problem details. The documentation
+// page describes each code under an anchor named after it.
+const ProblemTypeBase = "/docs#"
+
+// ErrorCode describes a code of the error catalog.
+type ErrorCode struct {
+	Code        string
+	Title       string
+	Status      int
+	Description string
+}
+
+// ErrorCatalog lists every code errors are reported with.
+var ErrorCatalog = []ErrorCode{
+	{CodeInvalidParam, "Invalid parameter", 400, "A query or path parameter is malformed, or is not accepted by the endpoint."},
+	{CodeOutOfRange, "Parameter out of range", 400, "A parameter is well formed, but outside of the values accepted by the endpoint."},
+	{CodeUnknownParam, "Unknown parameter", 400, "In strict mode, a parameter is not taken by the endpoint."},
+	{CodeConflictingParams, "Conflicting parameters", 400, "In strict mode, a parameter taking a single value is given more than once, or bounds or ranges contradict each other so that nothing can match."},
+	{CodeInvalidBody, "Invalid request body", 400, "The body of the request is malformed."},
+	{CodeNotFound, "Not found", 404, "The requested resource does not exist, or holds no data."},
+	{CodeMethodNotAllowed, "Method not allowed", 405, "The resource does not support the method of the request."},
+	{CodePayloadTooLarge, "Payload too large", 413, "The body of the request is larger than accepted."},
+	{CodeInternal, "Internal server error", 500, "The server failed to handle the request."},
+	{CodeUnavailable, "Service unavailable", 503, "A service the request depends on is not available."},
+	{CodeDbUnavailable, "Database unavailable", 503, "The server cannot reach its database. The request may be retried later."},
+	{CodeQueryTimeout, "Query timeout", 504, "The database query of the request took too long. Narrower filters may help."},
+}
+
+// CodedError is implemented by errors which know the code of the catalog they are reported with.
+type CodedError interface {
+	error
+	ErrorCode() string
+}
+
+// RangeError is returned when a value is well formed but outside of the values accepted.
+type RangeError struct {
+	Message string
+}
+
+func (err *RangeError) Error() string {
+	return err.Message
+}
+
+// ErrorCode reports range errors as OUT_OF_RANGE.
+func (err *RangeError) ErrorCode() string {
+	return CodeOutOfRange
+}
+
+// OutOfRange returns a RangeError with a formatted message.
+func OutOfRange(format string, a ...interface{}) error {
+	return &RangeError{Message: fmt.Sprintf(format, a...)}
+}
+
+// ParamProblem is a single problem with the parameters of a request.
+type ParamProblem struct {
+	Code   string
+	Param  string
+	Detail string
+}
+
 // ServerError represents an error on the server. It is used not only to provide context to the internal
 // logger, but also to provide context to the response sent to the client.
 type ServerError struct {
@@ -43,14 +126,85 @@ type ServerError struct {
 	HttpCode int
 	Fatal    bool
 
+	// Code is the code of the catalog the error is reported with. When empty it is derived from HttpCode.
+	Code string
+
+	// Param is the name of the parameter at fault, if any
+	Param string
+
+	// Problems lists the problems with the parameters of a request one by one, when they were checked together
+	Problems []ParamProblem
+
 	File string
 	Line int
 }
 
-// NewError returns a new ServerError object used to encode contextual information about a runtime error
+// NewError returns a new ServerError object used to encode contextual information about a runtime error.
+// Server errors caused by an error with a code of the catalog, eg. an unreachable database, are reported with
+// that code and its status.
 func NewError(err error, message string, code int, fatal bool) *ServerError {
 	_, file, line, _ := runtime.Caller(1)
-	return &ServerError{Error: err, Message: message, HttpCode: code, Fatal: fatal, File: filepath.Base(file), Line: line}
+	serverError := &ServerError{Error: err, Message: message, HttpCode: code, Fatal: fatal, File: filepath.Base(file), Line: line}
+
+	var coded CodedError
+	if code >= 500 && errors.As(err, &coded) {
+		if entry, ok := LookupErrorCode(coded.ErrorCode()); ok {
+			serverError.Code = entry.Code
+			serverError.HttpCode = entry.Status
+		}
+	}
+	return serverError
+}
+
+// WithCode sets the code of the catalog an error is reported with.
+func (serverError *ServerError) WithCode(code string) *ServerError {
+	serverError.Code = code
+	return serverError
+}
+
+// ForParam reports an error as caused by the value of a parameter. The error the value was rejected with sets
+// the code: OUT_OF_RANGE for a RangeError, the code of any other CodedError, or INVALID_PARAM.
+func (serverError *ServerError) ForParam(param string, err error) *ServerError {
+	serverError.Param = param
+	serverError.Code = CodeInvalidParam
+
+	var coded CodedError
+	if errors.As(err, &coded) && coded.ErrorCode() != "" {
+		serverError.Code = coded.ErrorCode()
+	}
+	return serverError
+}
+
+// ErrorCode returns the code an error is reported with. Errors without a code are reported with the most generic
+// code matching their status.
+func (serverError *ServerError) ErrorCode() string {
+	if serverError.Code != "" {
+		return serverError.Code
+	}
+	switch {
+	case serverError.HttpCode == 400:
+		return CodeInvalidParam
+	case serverError.HttpCode == 404:
+		return CodeNotFound
+	case serverError.HttpCode == 405:
+		return CodeMethodNotAllowed
+	case serverError.HttpCode == 413:
+		return CodePayloadTooLarge
+	case serverError.HttpCode == 503:
+		return CodeUnavailable
+	default:
+		return CodeInternal
+	}
+}
+
+// LookupErrorCode returns the entry of the error catalog for a code.
+func LookupErrorCode(code string) (ErrorCode, bool) {
+	for _, entry := range ErrorCatalog {
+		if entry.Code == code {
+			return entry, true
+		}
+	}
+	return ErrorCode{}, false
 }
 
 // ErrorLog uses the configured logger to report context from a server error.
@@ -75,30 +229,85 @@ func ErrorLog(serverError *ServerError) {
 }
 
 // HttpJsonError extracts metadata from a ServerError and returns this
-// information to the client.
+// information to the client. Clients accepting 'application/problem+json' are sent RFC 7807 problem
+// details, others the envelope of the version of the API they made the request to (see Serializer).
 func HttpJsonError(w http.ResponseWriter, r *http.Request, err *ServerError) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.Header().Set("X-Content-Type-Options", "nosniff")
-	w.WriteHeader(err.HttpCode)
-
 	// Parse RequestID param
 	id, idError := GetReqId(r)
 	if idError != nil {
 		id = idError.Error()
 	}
 
+	if AcceptsProblem(r) {
+		httpProblem(w, r, err, id)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(err.HttpCode)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	enc.SetEscapeHTML(false)
+	enc.Encode(SerializerOf(GetVersion(r)).Error(err, Meta{RequestId: id}))
+}
+
+// httpProblem returns a ServerError to the client as RFC 7807 problem details.
+func httpProblem(w http.ResponseWriter, r *http.Request, err *ServerError, id string) {
+	code := err.ErrorCode()
+	title := http.StatusText(err.HttpCode)
+	if entry, ok := LookupErrorCode(code); ok {
+		title = entry.Title
+	}
+
+	w.Header().Set("Content-Type", ProblemMediaType+"; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(err.HttpCode)
+
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "    ")
 	enc.SetEscapeHTML(false)
 	enc.Encode(
-		models.ServerResp{
-			Results:   nil,
-			Status:    "ERROR",
+		models.Problem{
+			Type:      ProblemTypeBase + code,
+			Title:     title,
+			Status:    err.HttpCode,
+			Detail:    err.Message,
+			Instance:  r.URL.Path,
+			Code:      code,
+			Param:     err.Param,
+			Errors:    problemErrors(err.Problems),
 			RequestId: id,
-			Error: &models.ErrorResp{
-				Description: fmt.Sprintf("%v - %v", err.HttpCode, http.StatusText(err.HttpCode)),
-				Message:     err.Message,
-			},
 		},
 	)
 }
+
+// problemErrors converts the problems of a request to the errors member of its problem details.
+func problemErrors(problems []ParamProblem) []models.ProblemError {
+	if len(problems) == 0 {
+		return nil
+	}
+	errors := make([]models.ProblemError, len(problems))
+	for i, problem := range problems {
+		errors[i] = models.ProblemError{Code: problem.Code, Param: problem.Param, Detail: problem.Detail}
+	}
+	return errors
+}
+
+// AcceptsProblem reports whether a request accepts 'application/problem+json' responses, that is if its Accept
+// header lists the media type without a quality of 0. Wildcards do not count, so that clients which do not ask
+// for problem details keep getting the ServerResp envelope.
+func AcceptsProblem(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil || mediaType != ProblemMediaType {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q <= 0 {
+			continue
+		}
+		return true
+	}
+	return false
+}
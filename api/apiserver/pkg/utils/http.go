@@ -86,6 +86,17 @@ func (w gzipResponseWriter) Write(b []byte) (int, error) {
 	return w.Writer.Write(b)
 }
 
+// Flush writes any data buffered by the *gzip.Writer to the original http.ResponseWriter
+// and flushes it, so that streamed responses reach the client incrementally.
+func (w gzipResponseWriter) Flush() {
+	if gz, ok := w.Writer.(*gzip.Writer); ok {
+		gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // Gzip is a middleware function that enables gzip compression on all
 // http responses so long as the header "Accept-Encoding": gzip is
 // present in the request
@@ -49,21 +49,26 @@ const RequestIdDefaultKey = RequestID("RequestID")
 func SetReqId(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		ctx := r.Context()
-		id, err := newRequestId()
-		if err != nil {
-			log.Errorf("Failed to generate new Request ID: %v", err)
-		}
-
-		log.Tracef("Initializing new request with ID: %v\n", id)
-
-		ctx = context.WithValue(ctx, RequestIdDefaultKey, id)
+		ctx, _ := WithReqId(r.Context())
 		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
 	})
 }
 
+// WithReqId returns a copy of ctx holding a new unique request ID, along with the ID itself.
+// This is used directly by servers that do not route requests through SetReqId, such as the gRPC server.
+func WithReqId(ctx context.Context) (context.Context, string) {
+	id, err := newRequestId()
+	if err != nil {
+		log.Errorf("Failed to generate new Request ID: %v", err)
+	}
+
+	log.Tracef("Initializing new request with ID: %v\n", id)
+
+	return context.WithValue(ctx, RequestIdDefaultKey, id), id
+}
+
 func newRequestId() (string, error) {
 	binaryId, err := uuid.New().MarshalBinary()
 	if err != nil {
@@ -99,7 +104,12 @@ func shinkBinary(uuid []byte) ([]byte, error) {
 // GetReqId extracts a request's unique ID value and returns it as a string.
 // If extraction fails, an error is returned.
 func GetReqId(r *http.Request) (string, error) {
-	ctx := r.Context()
+	return GetCtxReqId(r.Context())
+}
+
+// GetCtxReqId extracts the unique ID value of the request a context belongs to.
+// If extraction fails, an error is returned.
+func GetCtxReqId(ctx context.Context) (string, error) {
 	id, ok := ctx.Value(RequestIdDefaultKey).(string)
 	if !ok {
 		return "", fmt.Errorf("request ID key was not set with SetReqId function")
@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package utils
+
+import (
+	"apiserver/pkg/database/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Serializer builds the JSON envelope of the responses of a version of the API.
+type Serializer interface {
+	// Results returns the envelope of a successful response holding results
+	Results(results []interface{}, meta Meta) (interface{}, error)
+
+	// Error returns the envelope of an error
+	Error(err *ServerError, meta Meta) interface{}
+}
+
+// Meta describes a response beyond its results. Serializers include as much of it as their version documents.
+type Meta struct {
+	RequestId string
+
+	// Dataset is the dataset the results are read from, if any
+	Dataset *models.Dataset
+
+	// Metadata describes the dataset to clients. It is set along with Dataset.
+	Metadata *models.Metadata
+}
+
+// serializers holds the Serializer of each version of the API.
+var serializers = map[Version]Serializer{
+	V1: v1Serializer{},
+	V2: v2Serializer{},
+}
+
+// SerializerOf returns the Serializer of a version of the API.
+func SerializerOf(version Version) Serializer {
+	if serializer, ok := serializers[version]; ok {
+		return serializer
+	}
+	return serializers[V1]
+}
+
+// WriteJson encodes results in the envelope of the version of the API a request is made to. The request ID of the
+// request is added to meta. Pretty responses are indented for readability.
+func WriteJson(w http.ResponseWriter, r *http.Request, results []interface{}, meta Meta, pretty bool) *ServerError {
+	// Parse RequestID param
+	id, idError := GetReqId(r)
+	if idError != nil {
+		return NewError(idError, "cannot extract request ID", 500, false)
+	}
+	meta.RequestId = id
+
+	resp, err := SerializerOf(GetVersion(r)).Results(results, meta)
+	if err != nil {
+		return NewError(err, "error encoding data as json", 500, false)
+	}
+
+	enc := json.NewEncoder(w)
+	if pretty {
+		enc.SetIndent("", "    ")
+	}
+	if err := enc.Encode(resp); err != nil {
+		return NewError(err, "error encoding data as json", 500, false)
+	}
+	return nil
+}
+
+// v1Serializer builds the models.ServerResp envelope.
+type v1Serializer struct{}
+
+func (v1Serializer) Results(results []interface{}, meta Meta) (interface{}, error) {
+	// This prevents the 'Results' part of the response from being omitted if
+	// there are no results.
+	if meta.Dataset != nil && len(results) == 0 {
+		results = []interface{}{
+			nil,
+		}
+	}
+
+	return models.ServerResp{
+		Results:   results,
+		Status:    "OK",
+		RequestId: meta.RequestId,
+		Metadata:  meta.Metadata,
+		Error:     nil,
+	}, nil
+}
+
+func (v1Serializer) Error(err *ServerError, meta Meta) interface{} {
+	return models.ServerResp{
+		Results:   nil,
+		Status:    "ERROR",
+		RequestId: meta.RequestId,
+		Error: &models.ErrorResp{
+			Description: fmt.Sprintf("%v - %v", err.HttpCode, http.StatusText(err.HttpCode)),
+			Message:     err.Message,
+		},
+	}
+}
+
+// v2Serializer builds the models.ServerRespV2 envelope. Results are converted with models.V2.
+type v2Serializer struct{}
+
+func (v2Serializer) Results(results []interface{}, meta Meta) (interface{}, error) {
+	data := make([]interface{}, len(results))
+	for i, result := range results {
+		value, err := models.V2(result, meta.Dataset)
+		if err != nil {
+			return nil, err
+		}
+		data[i] = value
+	}
+
+	count := len(data)
+	v2 := v2Meta(meta, "ok", &count)
+	if meta.Metadata != nil {
+		dataset, err := models.V2(*meta.Metadata, meta.Dataset)
+		if err != nil {
+			return nil, err
+		}
+		v2.Dataset = dataset
+	}
+	return models.ServerRespV2{
+		Data: data,
+		Meta: v2,
+	}, nil
+}
+
+func (v2Serializer) Error(err *ServerError, meta Meta) interface{} {
+	code := err.ErrorCode()
+	title := http.StatusText(err.HttpCode)
+	if entry, ok := LookupErrorCode(code); ok {
+		title = entry.Title
+	}
+
+	return models.ServerRespV2{
+		Meta: v2Meta(meta, "error", nil),
+		Error: &models.ErrorRespV2{
+			Status:  err.HttpCode,
+			Code:    code,
+			Title:   title,
+			Message: err.Message,
+			Param:   err.Param,
+			Errors:  problemErrors(err.Problems),
+		},
+	}
+}
+
+func v2Meta(meta Meta, status string, count *int) models.MetaV2 {
+	return models.MetaV2{ApiVersion: string(V2), Status: status, RequestId: meta.RequestId, Count: count}
+}
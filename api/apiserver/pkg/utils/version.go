@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Version is a major version of the API, served under a path of the same name, eg. '/v2'. Versions are served
+// side by side from the same handlers, and only differ in how responses are serialized (see Serializer).
+type Version string
+
+const (
+	// V1 is the original version of the API. Its envelope names members in PascalCase.
+	V1 Version = "v1"
+
+	// V2 names members in lower_snake case, writes missing measurements as null and describes responses in
+	// a meta object.
+	V2 Version = "v2"
+)
+
+// Versions lists the versions of the API, oldest first.
+var Versions = []Version{V1, V2}
+
+// Base returns the path the version is served under, eg. '/v2'.
+func (version Version) Base() string {
+	return "/" + string(version)
+}
+
+// PathVersion returns the version of the API a path is served by, if any.
+func PathVersion(path string) (Version, bool) {
+	for _, version := range Versions {
+		if path == version.Base() || strings.HasPrefix(path, version.Base()+"/") {
+			return version, true
+		}
+	}
+	return "", false
+}
+
+// GetVersion returns the version of the API a request is made to. Requests outside of the API, eg. to '/docs',
+// are answered as V1 answers them.
+func GetVersion(r *http.Request) Version {
+	if version, ok := PathVersion(r.URL.Path); ok {
+		return version
+	}
+	return V1
+}
+
+// Deprecation returns a middleware announcing that a version of the API is deprecated in the Deprecation header
+// (RFC 9745) of its responses, and the date it will stop being served on in the Sunset header (RFC 8594).
+// Either header is left out if its time is zero.
+func Deprecation(version Version, deprecated time.Time, sunset time.Time) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if v, ok := PathVersion(r.URL.Path); ok && v == version {
+				if !deprecated.IsZero() {
+					w.Header().Set("Deprecation", fmt.Sprintf("@%d", deprecated.Unix()))
+				}
+				if !sunset.IsZero() {
+					w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
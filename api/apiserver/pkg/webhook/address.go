@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// blockedNets lists the networks deliveries are never sent to, so that webhooks cannot be used to reach the
+// services running next to the API: loopback, private, carrier-grade NAT, link-local (including the cloud
+// metadata service at 169.254.169.254), multicast and reserved addresses.
+var blockedNets = parseNets(
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12",
+	"192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "224.0.0.0/4", "240.0.0.0/4",
+	"::/128", "::1/128", "fc00::/7", "fe80::/10", "ff00::/8",
+)
+
+func parseNets(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, nets[i], _ = net.ParseCIDR(cidr)
+	}
+	return nets
+}
+
+// publicIP reports whether ip is a public address deliveries may be sent to. IPv4 addresses mapped to IPv6
+// are checked as IPv4 addresses.
+func publicIP(ip net.IP) bool {
+	for _, blocked := range blockedNets {
+		if blocked.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// publicHost reports whether the host of a webhook URL may be public. Addresses are checked directly, names
+// are only rejected when they are reserved for the local host, as they are checked when connecting (see dialPublic).
+func publicHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if i := strings.LastIndex(host, "%"); i >= 0 {
+		host = host[:i]
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return publicIP(ip)
+	}
+	return host != "localhost" && !strings.HasSuffix(host, ".localhost")
+}
+
+// dialPublic is the Control function of the dialer deliveries are sent with. It refuses to connect to an
+// address that is not public. As it runs once the name of the host was resolved, a host validated when its
+// subscription was created cannot be rebound to an internal address, nor redirect deliveries to one.
+func dialPublic(network, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return fmt.Errorf("refusing to deliver to non-public address %v", address)
+	}
+	return nil
+}
@@ -0,0 +1,480 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package webhook
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/notify"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Headers sent with every delivery
+const (
+	// EventHeader names the event of the subscription, eg. 'threshold'
+	EventHeader = "X-PlanetPulse-Event"
+
+	// DeliveryHeader holds the Id of the delivered event. Retries of a delivery share it.
+	DeliveryHeader = "X-PlanetPulse-Delivery"
+
+	// TimestampHeader holds the unix time the delivery was signed at
+	TimestampHeader = "X-PlanetPulse-Timestamp"
+
+	// SignatureHeader holds the signature of the delivery, see Sign
+	SignatureHeader = "X-PlanetPulse-Signature"
+)
+
+const (
+	// DefaultMaxAttempts is the number of times a delivery is attempted before it fails
+	DefaultMaxAttempts = 5
+
+	// DefaultDisableAfter is the number of consecutive failed deliveries after which a subscription is disabled
+	DefaultDisableAfter = 5
+
+	// DefaultPollInterval is how often the delivery log is checked for attempts that are due
+	DefaultPollInterval = 15 * time.Second
+
+	// maxBackoff caps the delay between two attempts
+	maxBackoff = time.Hour
+
+	// claimBatch is the number of attempts claimed at once
+	claimBatch = 20
+
+	// claimLease is how long claimed attempts are left to the server claiming them. It exceeds the timeout of the
+	// client of NewDispatcher.
+	claimLease = 5 * time.Minute
+)
+
+// Payload is the body of a delivery.
+type Payload struct {
+	// Id identifies the event being delivered, see eventId
+	Id string `json:"id"`
+
+	Event          Event  `json:"event"`
+	SubscriptionId string `json:"subscriptionId"`
+	Dataset        string `json:"dataset"`
+
+	// Date is the date of the observation, YYYY-MM-DD
+	Date    string `json:"date"`
+	Revised bool   `json:"revised"`
+
+	// Condition and Value are the threshold crossed and the value that crossed it, for threshold events
+	Condition *Condition `json:"condition,omitempty"`
+	Value     *float64   `json:"value,omitempty"`
+
+	Entry interface{} `json:"entry"`
+}
+
+// Dispatcher delivers the events published by a broker to the matching subscriptions. Deliveries are queued in
+// the delivery log, and every server running a Dispatcher delivers the attempts it claims from it, so that each
+// attempt is made once and pending attempts outlive the server that queued them.
+type Dispatcher struct {
+	Store Store
+
+	// Client sends deliveries. It should have a timeout.
+	Client *http.Client
+
+	// MaxAttempts is the number of times a delivery is attempted, DefaultMaxAttempts when zero
+	MaxAttempts int
+
+	// DisableAfter is the number of consecutive failed deliveries after which a subscription is disabled,
+	// DefaultDisableAfter when zero
+	DisableAfter int
+
+	// Backoff returns the delay before an attempt, numbered from 2. It doubles from a minute by default.
+	Backoff func(attempt int) time.Duration
+
+	// PollInterval is how often the delivery log is checked for attempts that are due, DefaultPollInterval when zero
+	PollInterval time.Duration
+
+	// wake signals that attempts were queued
+	wake chan struct{}
+}
+
+// NewDispatcher returns a Dispatcher storing subscriptions in db. Its client only connects to public addresses
+// (see dialPublic), and ignores proxies so that the addresses it connects to are always checked.
+func NewDispatcher(db *database.Database) *Dispatcher {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: dialPublic}
+	return &Dispatcher{
+		Store: Store{Database: db},
+		wake:  make(chan struct{}, 1),
+		Client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: 5 * time.Second},
+		},
+	}
+}
+
+// Run queues the events published by broker for delivery and delivers the attempts that are due until ctx is done,
+// then waits for deliveries in progress. Events missed while the dispatcher falls behind the broker are replayed from
+// the history of the broker, and attempts left pending by a previous run are resumed.
+func (d *Dispatcher) Run(ctx context.Context, broker *notify.Broker) error {
+	var delivering sync.WaitGroup
+	delivering.Add(1)
+	go func() {
+		defer delivering.Done()
+		d.deliverDue(ctx)
+	}()
+	defer delivering.Wait()
+
+	var lastId uint64
+	resume := false
+	for {
+		sub := broker.Subscribe(nil, lastId, resume)
+		if sub.Missed {
+			log.Errorf("webhook deliveries were missed as the dispatcher fell behind")
+		}
+		for _, event := range sub.Replay {
+			d.Dispatch(ctx, event)
+			lastId = event.Id
+		}
+
+	receive:
+		for {
+			select {
+			case event, ok := <-sub.Events:
+				if !ok {
+					break receive
+				}
+				d.Dispatch(ctx, event)
+				lastId = event.Id
+			case <-ctx.Done():
+				broker.Unsubscribe(sub)
+				return ctx.Err()
+			}
+		}
+		broker.Unsubscribe(sub)
+		resume = true
+	}
+}
+
+// Dispatch queues an event for delivery to every subscription it matches. Errors are logged.
+func (d *Dispatcher) Dispatch(ctx context.Context, event notify.Event) {
+	subs, err := d.Store.Enabled(event.Dataset)
+	if err != nil {
+		log.Errorf("error loading webhook subscriptions to dataset '%v': %v", event.Dataset, err)
+		return
+	}
+	id, err := eventId(event)
+	if err != nil {
+		log.Errorf("error identifying event %v: %v", event.Id, err)
+		return
+	}
+
+	// Previous values are only read once per field, and only when a threshold subscription needs them
+	previous := map[string]*float64{}
+	queued := false
+	for _, sub := range subs {
+		payload, ok, err := d.match(sub, id, event, previous)
+		if err != nil {
+			log.Errorf("error matching event %v to webhook subscription '%v': %v", id, sub.Id, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		body, err := json.Marshal(payload)
+		if err != nil {
+			log.Errorf("error encoding webhook delivery %v: %v", id, err)
+			continue
+		}
+		pending := Pending{Subscription: sub, EventId: id, Attempt: 1, Payload: body, NextAttemptAt: time.Now().UTC()}
+		if err := d.Store.Enqueue(pending); err != nil {
+			log.Errorf("error queueing webhook delivery %v to subscription '%v': %v", id, sub.Id, err)
+			continue
+		}
+		queued = true
+	}
+
+	if queued {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Deliver makes the attempts that are due and returns once they are complete.
+func (d *Dispatcher) Deliver(ctx context.Context) error {
+	for {
+		claimed, err := d.Store.Claim(claimBatch, claimLease)
+		if err != nil {
+			return err
+		}
+
+		var wg sync.WaitGroup
+		for _, pending := range claimed {
+			wg.Add(1)
+			go func(pending Pending) {
+				defer wg.Done()
+				d.deliver(ctx, pending)
+			}(pending)
+		}
+		wg.Wait()
+
+		if len(claimed) < claimBatch || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// deliverDue delivers the attempts that are due until ctx is done. The delivery log is checked when attempts are
+// queued, and every PollInterval for retries.
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	interval := d.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.Deliver(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("error delivering webhooks: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-d.wake:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// eventId identifies an event by its change. Every server publishes the changes it detects with its own event Ids,
+// so the change is identified by the dataset, the date and the hash of the observation, which are the same on every
+// server, for its deliveries to be queued once.
+func eventId(event notify.Event) (string, error) {
+	entry, err := json.Marshal(event.Entry)
+	if err != nil {
+		return "", err
+	}
+	date := event.Date.Format("20060102")
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%v/%v/%v/%s", event.Dataset, date, event.Revised, entry)))
+	return event.Dataset + "/" + date + "/" + hex.EncodeToString(sum[:8]), nil
+}
+
+// match returns the payload to deliver to a subscription for an event, if the subscription fires on it.
+// New data subscriptions fire on every event. Threshold subscriptions fire when a new observation crosses
+// their threshold, compared with the preceding observation.
+func (d *Dispatcher) match(sub Subscription, id string, event notify.Event, previous map[string]*float64) (Payload, bool, error) {
+	payload := Payload{
+		Id:             id,
+		Event:          sub.Event,
+		SubscriptionId: sub.Id,
+		Dataset:        event.Dataset,
+		Date:           event.Date.Format("2006-01-02"),
+		Revised:        event.Revised,
+		Entry:          event.Entry,
+	}
+	if sub.Event == NewData {
+		return payload, true, nil
+	}
+	if sub.Event != Threshold || sub.Condition == nil || event.Revised {
+		return payload, false, nil
+	}
+
+	dataset, ok := models.DatasetById(event.Dataset)
+	if !ok {
+		return payload, false, fmt.Errorf("unknown dataset '%v'", event.Dataset)
+	}
+	col, ok := dataset.Column(sub.Condition.Field)
+	if !ok {
+		return payload, false, fmt.Errorf("dataset '%v' has no column '%v'", dataset.Id, sub.Condition.Field)
+	}
+
+	current, err := measurement(dataset, col, event.Entry)
+	if err != nil || current == nil {
+		return payload, false, err
+	}
+
+	before, ok := previous[col.Name]
+	if !ok {
+		if before, err = d.previous(dataset, col, event.Date); err != nil {
+			return payload, false, err
+		}
+		previous[col.Name] = before
+	}
+
+	if !sub.Condition.Crossed(before, *current) {
+		return payload, false, nil
+	}
+	payload.Condition = sub.Condition
+	payload.Value = current
+	return payload, true, nil
+}
+
+// previous returns the value of a column in the observation preceding a date, or nil when there is none
+// or its measurement is missing.
+func (d *Dispatcher) previous(dataset models.Dataset, col models.Column, date time.Time) (*float64, error) {
+	query := database.NewQuery(dataset.Table, nil, "yyyymmdd DESC")
+	query.Select([]models.Column{col})
+	query.Where = []string{"yyyymmdd < $1"}
+	query.Args = []interface{}{date.Format("2006-01-02")}
+	query.Limit = 1
+
+	table := []interface{}{}
+	if err := d.Store.Database.Query(query, &models.FieldsTable{Columns: query.Fields, Entries: &table}); err != nil {
+		return nil, err
+	}
+	if len(table) == 0 {
+		return nil, nil
+	}
+	return measurement(dataset, col, table[0])
+}
+
+// measurement returns the value of a float column of an observation, or nil when it is missing.
+func measurement(dataset models.Dataset, col models.Column, entry interface{}) (*float64, error) {
+	var val interface{}
+	if fields, ok := entry.(models.Fields); ok {
+		for i, c := range fields.Columns {
+			if c.Name == col.Name {
+				val = fields.Values[i]
+			}
+		}
+	} else {
+		values, err := models.Values(entry, []models.Column{col})
+		if err != nil {
+			return nil, err
+		}
+		val = values[0]
+	}
+
+	v, ok := val.(float32)
+	if !ok {
+		return nil, fmt.Errorf("observation has no measurement '%v'", col.Name)
+	}
+	if dataset.IsMissing(v) {
+		return nil, nil
+	}
+	// Single precision measurements are compared as the shortest decimal representing them
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'f', -1, 32), 64)
+	return &f, nil
+}
+
+// deliver makes a pending attempt and logs it, queueing the next attempt after a failure. Subscriptions are
+// disabled after repeated failed deliveries. Attempts interrupted as ctx is done are left pending.
+func (d *Dispatcher) deliver(ctx context.Context, pending Pending) {
+	maxAttempts := d.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	disableAfter := d.DisableAfter
+	if disableAfter <= 0 {
+		disableAfter = DefaultDisableAfter
+	}
+
+	sub := pending.Subscription
+	delivery := d.attempt(ctx, sub, pending.EventId, pending.Payload)
+	delivery.Attempt = pending.Attempt
+	if ctx.Err() != nil {
+		return
+	}
+
+	var next *Pending
+	if delivery.Error != "" && pending.Attempt < maxAttempts {
+		retry := pending
+		retry.Attempt++
+		retry.NextAttemptAt = delivery.DeliveredAt.Add(d.backoff(retry.Attempt))
+		next = &retry
+	}
+	if err := d.Store.LogDelivery(delivery, next); err != nil {
+		log.Errorf("error logging webhook delivery %v to subscription '%v': %v", pending.EventId, sub.Id, err)
+		return
+	}
+
+	switch {
+	case delivery.Error == "":
+		if err := d.Store.RecordSuccess(sub.Id); err != nil {
+			log.Errorf("error recording webhook delivery %v to subscription '%v': %v", pending.EventId, sub.Id, err)
+		}
+	case next == nil:
+		enabled, err := d.Store.RecordFailure(sub.Id, disableAfter)
+		if err != nil {
+			log.Errorf("error recording failed webhook delivery %v to subscription '%v': %v", pending.EventId, sub.Id, err)
+		} else if !enabled {
+			log.Infof("disabled webhook subscription '%v' after %v failed deliveries", sub.Id, disableAfter)
+		}
+	}
+}
+
+// attempt POSTs a signed payload to a subscription once. Any response other than 2xx is a failure.
+func (d *Dispatcher) attempt(ctx context.Context, sub Subscription, id string, body []byte) Delivery {
+	delivery := Delivery{SubscriptionId: sub.Id, EventId: id, DeliveredAt: time.Now().UTC()}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", sub.Url, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	timestamp := delivery.DeliveredAt.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PlanetPulse-Webhook")
+	req.Header.Set(EventHeader, string(sub.Event))
+	req.Header.Set(DeliveryHeader, id)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, timestamp, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	delivery.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		delivery.Error = "receiver responded with " + resp.Status
+	}
+	return delivery
+}
+
+// backoff returns the delay before an attempt.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	if d.Backoff != nil {
+		return d.Backoff(attempt)
+	}
+	delay := time.Minute << uint(attempt-2)
+	if delay > maxBackoff || delay <= 0 {
+		return maxBackoff
+	}
+	return delay
+}
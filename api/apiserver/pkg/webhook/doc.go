@@ -0,0 +1,33 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+// Package webhook delivers the changes published by a notify.Broker to the webhooks registered by clients.
+//
+// A subscription either fires on every new or revised observation of a dataset, or when a new observation
+// crosses a threshold, eg. weekly CO2 above 425 ppm. Deliveries are POST requests signed with an HMAC of the
+// body keyed by the secret of the subscription. Failed deliveries are retried with exponential backoff, every
+// attempt is kept in a delivery log, and subscriptions are disabled after repeated failed deliveries. Attempts are
+// queued in the delivery log until they are made, so that retries survive restarts and servers sharing the
+// database deliver each attempt once.
+// Subscriptions and deliveries are persisted in the database (see pipeline/sql/create_webhook_*.sql).
+package webhook
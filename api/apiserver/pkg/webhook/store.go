@@ -0,0 +1,336 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package webhook
+
+import (
+	"apiserver/pkg/database"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+const subscriptionColumns = "id, url, secret, dataset, event, field, op, threshold, enabled, failures, created_at"
+
+// insertPending queues an attempt in the delivery log. The attempt is only queued once, however many servers
+// queue it.
+const insertPending = "INSERT INTO public.webhook_deliveries (subscription_id, event_id, attempt, payload, next_attempt_at) VALUES ($1, $2, $3, $4, $5) " +
+	"ON CONFLICT (subscription_id, event_id, attempt) DO NOTHING"
+
+// Delivery records an attempt to deliver an event to a subscription.
+type Delivery struct {
+	SubscriptionId string
+
+	// EventId is the Id of the delivered event. Retries of a delivery share it.
+	EventId string
+
+	// Attempt numbers the attempts of a delivery from 1
+	Attempt int
+
+	// StatusCode is the status of the response of the receiver, or 0 when it did not respond
+	StatusCode int
+
+	// Error describes why the attempt failed. It is empty for successful attempts.
+	Error string `json:",omitempty"`
+
+	DeliveredAt time.Time
+}
+
+// Pending is an attempt to deliver an event that is waiting in the delivery log.
+type Pending struct {
+	Subscription Subscription
+
+	// EventId is the Id of the event, see Delivery
+	EventId string
+
+	// Attempt numbers the attempts of a delivery from 1
+	Attempt int
+
+	// Payload is the body of the delivery
+	Payload []byte
+
+	// NextAttemptAt is the time the attempt is due
+	NextAttemptAt time.Time
+}
+
+// Store persists subscriptions and their delivery log in the database.
+type Store struct {
+	Database *database.Database
+}
+
+// Create stores a new subscription, after generating its Id, secret and creation time.
+func (store Store) Create(sub *Subscription) error {
+	if err := store.Database.ProbeConnection(); err != nil {
+		return err
+	}
+
+	var err error
+	if sub.Id, err = newId(); err != nil {
+		return err
+	}
+	if sub.Secret, err = newSecret(); err != nil {
+		return err
+	}
+	sub.Enabled = true
+	sub.Failures = 0
+	sub.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	var field, op sql.NullString
+	var threshold sql.NullFloat64
+	if sub.Condition != nil {
+		field = sql.NullString{String: sub.Condition.Field, Valid: true}
+		op = sql.NullString{String: sub.Condition.Op, Valid: true}
+		threshold = sql.NullFloat64{Float64: sub.Condition.Value, Valid: true}
+	}
+
+	_, err = store.Database.DB.Exec(
+		"INSERT INTO public.webhook_subscriptions ("+subscriptionColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)",
+		sub.Id, sub.Url, sub.Secret, sub.Dataset, string(sub.Event), field, op, threshold, sub.Enabled, sub.Failures, sub.CreatedAt,
+	)
+	return err
+}
+
+// Get returns a subscription. sql.ErrNoRows is returned if it does not exist.
+func (store Store) Get(id string) (Subscription, error) {
+	if err := store.Database.ProbeConnection(); err != nil {
+		return Subscription{}, err
+	}
+
+	rows, err := store.Database.DB.Query("SELECT "+subscriptionColumns+" FROM public.webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return Subscription{}, err
+	}
+	subs, err := scanSubscriptions(rows)
+	if err != nil {
+		return Subscription{}, err
+	}
+	if len(subs) == 0 {
+		return Subscription{}, sql.ErrNoRows
+	}
+	return subs[0], nil
+}
+
+// Enabled returns the enabled subscriptions to a dataset.
+func (store Store) Enabled(dataset string) ([]Subscription, error) {
+	if err := store.Database.ProbeConnection(); err != nil {
+		return nil, err
+	}
+
+	rows, err := store.Database.DB.Query("SELECT "+subscriptionColumns+" FROM public.webhook_subscriptions WHERE dataset = $1 AND enabled ORDER BY created_at", dataset)
+	if err != nil {
+		return nil, err
+	}
+	return scanSubscriptions(rows)
+}
+
+// Delete deletes a subscription and its delivery log. sql.ErrNoRows is returned if it does not exist.
+func (store Store) Delete(id string) error {
+	if err := store.Database.ProbeConnection(); err != nil {
+		return err
+	}
+
+	result, err := store.Database.DB.Exec("DELETE FROM public.webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if deleted, err := result.RowsAffected(); err != nil {
+		return err
+	} else if deleted == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = store.Database.DB.Exec("DELETE FROM public.webhook_deliveries WHERE subscription_id = $1", id)
+	return err
+}
+
+// Enqueue queues an attempt in the delivery log, unless it already is.
+func (store Store) Enqueue(pending Pending) error {
+	if err := store.Database.ProbeConnection(); err != nil {
+		return err
+	}
+
+	_, err := store.Database.DB.Exec(insertPending,
+		pending.Subscription.Id, pending.EventId, pending.Attempt, string(pending.Payload), pending.NextAttemptAt)
+	return err
+}
+
+// Claim returns up to limit attempts that are due for the enabled subscriptions, oldest first. Claimed attempts are
+// postponed by lease, so that no other server claims them until the lease runs out. Attempts claimed by a server that
+// stops before logging them are claimed again once their lease has run out.
+func (store Store) Claim(limit int, lease time.Duration) ([]Pending, error) {
+	if err := store.Database.ProbeConnection(); err != nil {
+		return nil, err
+	}
+
+	// Rows being claimed by another server are skipped rather than waited for
+	now := time.Now().UTC()
+	rows, err := store.Database.DB.Query(
+		"UPDATE public.webhook_deliveries AS d SET next_attempt_at = $2 FROM public.webhook_subscriptions AS s "+
+			"WHERE s.id = d.subscription_id AND (d.subscription_id, d.event_id, d.attempt) IN ("+
+			"SELECT p.subscription_id, p.event_id, p.attempt FROM public.webhook_deliveries AS p JOIN public.webhook_subscriptions AS e ON e.id = p.subscription_id "+
+			"WHERE p.delivered_at IS NULL AND p.next_attempt_at <= $3 AND e.enabled ORDER BY p.next_attempt_at LIMIT $1 FOR UPDATE OF p SKIP LOCKED) "+
+			"RETURNING d.event_id, d.attempt, d.payload, d.next_attempt_at, s."+strings.ReplaceAll(subscriptionColumns, ", ", ", s."),
+		limit, now.Add(lease), now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claimed []Pending
+	for rows.Next() {
+		var pending Pending
+		var payload string
+		sub, err := scanSubscription(rows, &pending.EventId, &pending.Attempt, &payload, &pending.NextAttemptAt)
+		if err != nil {
+			return nil, err
+		}
+		pending.Subscription = sub
+		pending.Payload = []byte(payload)
+		claimed = append(claimed, pending)
+	}
+	return claimed, rows.Err()
+}
+
+// LogDelivery records an attempt in the delivery log. When next is not nil, it is queued as the attempt following it.
+func (store Store) LogDelivery(delivery Delivery, next *Pending) error {
+	if err := store.Database.ProbeConnection(); err != nil {
+		return err
+	}
+
+	var statusCode sql.NullInt64
+	if delivery.StatusCode != 0 {
+		statusCode = sql.NullInt64{Int64: int64(delivery.StatusCode), Valid: true}
+	}
+	var deliveryErr sql.NullString
+	if delivery.Error != "" {
+		deliveryErr = sql.NullString{String: delivery.Error, Valid: true}
+	}
+
+	tx, err := store.Database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"UPDATE public.webhook_deliveries SET status_code = $4, error = $5, delivered_at = $6, next_attempt_at = NULL WHERE subscription_id = $1 AND event_id = $2 AND attempt = $3",
+		delivery.SubscriptionId, delivery.EventId, delivery.Attempt, statusCode, deliveryErr, delivery.DeliveredAt,
+	)
+	if err != nil {
+		return err
+	}
+	if next != nil {
+		if _, err := tx.Exec(insertPending, next.Subscription.Id, next.EventId, next.Attempt, string(next.Payload), next.NextAttemptAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Deliveries returns the most recent attempts to deliver events to a subscription, newest first. Attempts that are
+// still pending are not returned.
+func (store Store) Deliveries(id string, limit int) ([]Delivery, error) {
+	if err := store.Database.ProbeConnection(); err != nil {
+		return nil, err
+	}
+
+	rows, err := store.Database.DB.Query(
+		"SELECT subscription_id, event_id, attempt, status_code, error, delivered_at FROM public.webhook_deliveries WHERE subscription_id = $1 AND delivered_at IS NOT NULL ORDER BY delivered_at DESC, attempt DESC LIMIT $2",
+		id, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []Delivery{}
+	for rows.Next() {
+		var delivery Delivery
+		var statusCode sql.NullInt64
+		var deliveryErr sql.NullString
+		if err := rows.Scan(&delivery.SubscriptionId, &delivery.EventId, &delivery.Attempt, &statusCode, &deliveryErr, &delivery.DeliveredAt); err != nil {
+			return nil, err
+		}
+		delivery.StatusCode = int(statusCode.Int64)
+		delivery.Error = deliveryErr.String
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// RecordSuccess resets the count of consecutive failed deliveries of a subscription.
+func (store Store) RecordSuccess(id string) error {
+	if err := store.Database.ProbeConnection(); err != nil {
+		return err
+	}
+
+	_, err := store.Database.DB.Exec("UPDATE public.webhook_subscriptions SET failures = 0 WHERE id = $1", id)
+	return err
+}
+
+// RecordFailure counts a failed delivery to a subscription, and disables the subscription once disableAfter
+// consecutive deliveries failed. It reports whether the subscription is still enabled.
+func (store Store) RecordFailure(id string, disableAfter int) (bool, error) {
+	if err := store.Database.ProbeConnection(); err != nil {
+		return false, err
+	}
+
+	var enabled bool
+	err := store.Database.DB.QueryRow(
+		"UPDATE public.webhook_subscriptions SET failures = failures + 1, enabled = enabled AND failures + 1 < $2 WHERE id = $1 RETURNING enabled",
+		id, disableAfter,
+	).Scan(&enabled)
+	return enabled, err
+}
+
+func scanSubscriptions(rows *sql.Rows) ([]Subscription, error) {
+	defer rows.Close()
+
+	var subs []Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// scanSubscription scans a row holding the given values, followed by the subscriptionColumns.
+func scanSubscription(rows *sql.Rows, dest ...interface{}) (Subscription, error) {
+	var sub Subscription
+	var event string
+	var field, op sql.NullString
+	var threshold sql.NullFloat64
+	dest = append(dest, &sub.Id, &sub.Url, &sub.Secret, &sub.Dataset, &event, &field, &op, &threshold, &sub.Enabled, &sub.Failures, &sub.CreatedAt)
+	if err := rows.Scan(dest...); err != nil {
+		return Subscription{}, err
+	}
+	sub.Event = Event(event)
+	if field.Valid {
+		sub.Condition = &Condition{Field: field.String, Op: op.String, Value: threshold.Float64}
+	}
+	return sub, nil
+}
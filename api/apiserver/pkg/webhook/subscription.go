@@ -0,0 +1,177 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package webhook
+
+import (
+	"apiserver/pkg/database/models"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Event names the changes a subscription fires on.
+type Event string
+
+const (
+	// NewData subscriptions fire on every new or revised observation of a dataset
+	NewData Event = "new_data"
+
+	// Threshold subscriptions fire when a new observation crosses a threshold
+	Threshold Event = "threshold"
+)
+
+// Comparison operators of thresholds, named like the query parameters of the API
+var comparisons = map[string]func(val, threshold float64) bool{
+	"gt":  func(val, threshold float64) bool { return val > threshold },
+	"gte": func(val, threshold float64) bool { return val >= threshold },
+	"lt":  func(val, threshold float64) bool { return val < threshold },
+	"lte": func(val, threshold float64) bool { return val <= threshold },
+}
+
+// Subscription represents a webhook registered by a client.
+type Subscription struct {
+	// Id identifies the subscription. It is random, so that it can only be managed by whoever registered it.
+	Id string
+
+	// Url is the endpoint deliveries are POSTed to
+	Url string
+
+	// Dataset is the Id of the dataset the subscription watches
+	Dataset string
+
+	Event Event
+
+	// Condition is the threshold a Threshold subscription fires on crossing
+	Condition *Condition `json:",omitempty"`
+
+	// Secret keys the signatures of deliveries. It is only returned when the subscription is created.
+	Secret string `json:",omitempty"`
+
+	// Enabled is false once the subscription was disabled after repeated failed deliveries
+	Enabled bool
+
+	// Failures counts the consecutive failed deliveries
+	Failures int
+
+	CreatedAt time.Time
+}
+
+// Condition is the threshold of a Threshold subscription, eg. 'average gt 425'.
+type Condition struct {
+	// Field is the name of a measurement column of the dataset, eg. 'average'
+	Field string
+
+	// Op is one of 'gt', 'gte', 'lt' or 'lte'
+	Op string
+
+	Value float64
+}
+
+// Matches reports whether a value satisfies the condition.
+func (condition Condition) Matches(val float64) bool {
+	compare, ok := comparisons[condition.Op]
+	return ok && compare(val, condition.Value)
+}
+
+// Crossed reports whether the condition became satisfied between a previous value and the current one.
+// Without a previous value, the condition is crossed as soon as it is satisfied.
+func (condition Condition) Crossed(previous *float64, current float64) bool {
+	return condition.Matches(current) && (previous == nil || !condition.Matches(*previous))
+}
+
+// Validate checks a subscription registered by a client, and returns an error message describing the
+// first problem found.
+func (sub Subscription) Validate() error {
+	u, err := url.Parse(sub.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("'Url' must be an absolute http or https URL: Url=[%v]", sub.Url)
+	}
+	if !publicHost(u.Hostname()) {
+		return fmt.Errorf("'Url' must not point to a loopback, private or link-local address: Url=[%v]", sub.Url)
+	}
+
+	dataset, ok := models.DatasetById(sub.Dataset)
+	if !ok {
+		return fmt.Errorf("unknown dataset '%v': Dataset=[%v]", sub.Dataset, sub.Dataset)
+	}
+
+	switch sub.Event {
+	case NewData:
+		if sub.Condition != nil {
+			return fmt.Errorf("a 'Condition' is only allowed for '%v' subscriptions: Event=[%v]", Threshold, sub.Event)
+		}
+	case Threshold:
+		if sub.Condition == nil {
+			return fmt.Errorf("'%v' subscriptions require a 'Condition': Condition=[]", Threshold)
+		}
+		if col, ok := dataset.Column(sub.Condition.Field); !ok || col.Type != models.Float {
+			return fmt.Errorf("'%v' is not a measurement of dataset '%v': Condition.Field=[%v]", sub.Condition.Field, dataset.Id, sub.Condition.Field)
+		}
+		if _, ok := comparisons[sub.Condition.Op]; !ok {
+			return fmt.Errorf("comparison must be one of 'gt', 'gte', 'lt' or 'lte': Condition.Op=[%v]", sub.Condition.Op)
+		}
+	default:
+		return fmt.Errorf("event must be either '%v' or '%v': Event=[%v]", NewData, Threshold, sub.Event)
+	}
+	return nil
+}
+
+// newSecret returns a random hex encoded key of 32 bytes.
+func newSecret() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// newId returns a random subscription Id.
+func newId() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// Sign returns the signature of a delivery sent at a unix timestamp, as sent in the SignatureHeader. The
+// timestamp is signed along with the body so that receivers can reject replayed deliveries.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether a signature was made by Sign with the same secret, timestamp and body.
+// Receivers written in Go may use it to authenticate deliveries.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
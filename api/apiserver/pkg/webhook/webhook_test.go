@@ -0,0 +1,525 @@
+/*
+Copyright 2021 The PlanetPulse Authors.
+
+Planet Pulse is an API designed to serve climate data pulled from NOAA's
+Global Monitoring Laboratory FTP server. This API is based on the
+OpenAPI v3 specification.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+A copy of the GNU General Public License can be found here:
+https://www.gnu.org/licenses/
+
+Contact: planetpulse.api@gmail.com
+*/
+
+package webhook
+
+import (
+	"apiserver/pkg/database"
+	"apiserver/pkg/database/models"
+	"apiserver/pkg/notify"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const selectEnabled = `SELECT id, url, secret, dataset, event, field, op, threshold, enabled, failures, created_at FROM public.webhook_subscriptions WHERE dataset = $1 AND enabled ORDER BY created_at`
+
+const claimDue = `UPDATE public.webhook_deliveries AS d SET next_attempt_at = $2 FROM public.webhook_subscriptions AS s`
+
+var subscriptionRows = []string{"id", "url", "secret", "dataset", "event", "field", "op", "threshold", "enabled", "failures", "created_at"}
+
+var claimRows = append([]string{"event_id", "attempt", "payload", "next_attempt_at"}, subscriptionRows...)
+
+// receiver is a webhook endpoint recording the deliveries it accepts. It responds with the given statuses in
+// turn, then with 200.
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	payloads []Payload
+	headers  []http.Header
+}
+
+func newReceiver(t *testing.T, secret string, statuses ...int) *receiver {
+	rec := &receiver{statuses: statuses}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+
+		body, _ := ioutil.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		if !Verify(secret, timestamp, body, r.Header.Get(SignatureHeader)) {
+			t.Errorf("Delivery signature '%v' does not match its body", r.Header.Get(SignatureHeader))
+		}
+
+		var payload Payload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("Delivery is not valid JSON: %v", err)
+		}
+		rec.payloads = append(rec.payloads, payload)
+		rec.headers = append(rec.headers, r.Header)
+
+		if len(rec.statuses) > 0 {
+			w.WriteHeader(rec.statuses[0])
+			rec.statuses = rec.statuses[1:]
+		}
+	}))
+	return rec
+}
+
+func newTestDispatcher(t *testing.T) (*Dispatcher, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error generating mock database: %s", err.Error())
+	}
+	d := NewDispatcher(&database.Database{DB: db})
+	d.Backoff = func(int) time.Duration { return time.Millisecond }
+
+	// Test receivers listen on the loopback interface, which the client of NewDispatcher refuses to connect to
+	d.Client = &http.Client{Timeout: 10 * time.Second}
+	return d, mock, func() { db.Close() }
+}
+
+func co2Event(average float32, revised bool) notify.Event {
+	columns := []models.Column{{Name: "year", Field: "Year", Type: models.Integer}, {Name: "average", Field: "Average", Type: models.Float}}
+	return notify.Event{Id: 1622505600000, Change: notify.Change{
+		Dataset: "co2_weekly_mlo",
+		Date:    time.Date(2021, 5, 30, 0, 0, 0, 0, time.UTC),
+		Revised: revised,
+		Entry:   models.Fields{Columns: columns, Values: []interface{}{2021, average}},
+	}}
+}
+
+// payloadArg matches the payload of a queued attempt, and keeps it to be claimed later.
+type payloadArg struct {
+	value string
+}
+
+func (arg *payloadArg) Match(v driver.Value) bool {
+	payload, ok := v.(string)
+	if ok {
+		arg.value = payload
+	}
+	return ok
+}
+
+// deliveryLog mocks the delivery log of an event to the subscription sub1.
+type deliveryLog struct {
+	sqlmock.Sqlmock
+	eventId string
+	sub     []driver.Value
+	payload payloadArg
+}
+
+func newDeliveryLog(t *testing.T, mock sqlmock.Sqlmock, event notify.Event, sub ...driver.Value) *deliveryLog {
+	id, err := eventId(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &deliveryLog{Sqlmock: mock, eventId: id, sub: sub}
+}
+
+func (l *deliveryLog) expectEnqueue(attempt int) {
+	l.ExpectExec(regexp.QuoteMeta(insertPending)).
+		WithArgs("sub1", l.eventId, attempt, &l.payload, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// expectClaim mocks claiming an attempt, whose payload is the one last queued.
+func (l *deliveryLog) expectClaim(attempt int) {
+	row := append([]driver.Value{l.eventId, attempt, l.payload.value, time.Now()}, l.sub...)
+	l.ExpectQuery(regexp.QuoteMeta(claimDue)).
+		WithArgs(claimBatch, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(claimRows).AddRow(row...))
+}
+
+// expectDelivery mocks logging an attempt, and queueing the next attempt when retry is true.
+func (l *deliveryLog) expectDelivery(attempt int, status interface{}, retry bool) {
+	l.ExpectBegin()
+	l.ExpectExec(regexp.QuoteMeta(`UPDATE public.webhook_deliveries SET status_code = $4, error = $5, delivered_at = $6, next_attempt_at = NULL WHERE subscription_id = $1 AND event_id = $2 AND attempt = $3`)).
+		WithArgs("sub1", l.eventId, attempt, status, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if retry {
+		l.expectEnqueue(attempt + 1)
+	}
+	l.ExpectCommit()
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	signature := Sign("secret", 1622505600, body)
+	if !regexp.MustCompile(`^sha256=[0-9a-f]{64}$`).MatchString(signature) {
+		t.Errorf("Wanted a hex encoded sha256 signature, Got: %v", signature)
+	}
+	if !Verify("secret", 1622505600, body, signature) {
+		t.Errorf("Wanted a signature to verify with the same secret, timestamp and body")
+	}
+	if Verify("other", 1622505600, body, signature) || Verify("secret", 1622505601, body, signature) || Verify("secret", 1622505600, []byte(`{}`), signature) {
+		t.Errorf("Wanted a signature to fail verification with a different secret, timestamp or body")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testVals := []struct {
+		sub   Subscription
+		valid bool
+	}{
+		{Subscription{Url: "https://example.com/hook", Dataset: "co2_weekly_mlo", Event: NewData}, true},
+		{Subscription{Url: "https://example.com/hook", Dataset: "ch4_mm_gl", Event: Threshold, Condition: &Condition{"trend", "gte", 1900}}, true},
+		{Subscription{Url: "ftp://example.com/hook", Dataset: "co2_weekly_mlo", Event: NewData}, false},
+		{Subscription{Url: "/hook", Dataset: "co2_weekly_mlo", Event: NewData}, false},
+		{Subscription{Url: "https://example.com/hook", Dataset: "co2", Event: NewData}, false},
+		{Subscription{Url: "https://example.com/hook", Dataset: "co2_weekly_mlo", Event: "new"}, false},
+		{Subscription{Url: "https://example.com/hook", Dataset: "co2_weekly_mlo", Event: NewData, Condition: &Condition{"average", "gt", 425}}, false},
+		{Subscription{Url: "https://example.com/hook", Dataset: "co2_weekly_mlo", Event: Threshold}, false},
+		{Subscription{Url: "https://example.com/hook", Dataset: "co2_weekly_mlo", Event: Threshold, Condition: &Condition{"year", "gt", 2020}}, false},
+		{Subscription{Url: "https://example.com/hook", Dataset: "co2_weekly_mlo", Event: Threshold, Condition: &Condition{"average", "above", 425}}, false},
+		{Subscription{Url: "https://93.184.216.34/hook", Dataset: "co2_weekly_mlo", Event: NewData}, true},
+		{Subscription{Url: "http://127.0.0.1:8080/hook", Dataset: "co2_weekly_mlo", Event: NewData}, false},
+		{Subscription{Url: "http://localhost/hook", Dataset: "co2_weekly_mlo", Event: NewData}, false},
+		{Subscription{Url: "http://api.localhost./hook", Dataset: "co2_weekly_mlo", Event: NewData}, false},
+		{Subscription{Url: "http://169.254.169.254/latest/meta-data/", Dataset: "co2_weekly_mlo", Event: NewData}, false},
+		{Subscription{Url: "http://10.0.0.12/hook", Dataset: "co2_weekly_mlo", Event: NewData}, false},
+		{Subscription{Url: "http://192.168.1.1/hook", Dataset: "co2_weekly_mlo", Event: NewData}, false},
+		{Subscription{Url: "http://[::1]:8080/hook", Dataset: "co2_weekly_mlo", Event: NewData}, false},
+		{Subscription{Url: "http://[fe80::1%25eth0]/hook", Dataset: "co2_weekly_mlo", Event: NewData}, false},
+		{Subscription{Url: "http://[::ffff:169.254.169.254]/hook", Dataset: "co2_weekly_mlo", Event: NewData}, false},
+	}
+
+	for _, testVal := range testVals {
+		if err := testVal.sub.Validate(); (err == nil) != testVal.valid {
+			t.Errorf("Wanted valid=%v for %+v, Got: %v", testVal.valid, testVal.sub, err)
+		}
+	}
+}
+
+func TestConditionCrossed(t *testing.T) {
+	above, below := 425.5, 424.5
+	condition := Condition{Field: "average", Op: "gt", Value: 425}
+	testVals := []struct {
+		previous *float64
+		current  float64
+		crossed  bool
+	}{
+		{&below, 425.5, true},
+		{&above, 426, false},
+		{&above, 424, false},
+		{nil, 425.5, true},
+		{nil, 425, false},
+	}
+
+	for _, testVal := range testVals {
+		if crossed := condition.Crossed(testVal.previous, testVal.current); crossed != testVal.crossed {
+			t.Errorf("Wanted crossed=%v from %v to %v, Got: %v", testVal.crossed, testVal.previous, testVal.current, crossed)
+		}
+	}
+}
+
+func TestEventId(t *testing.T) {
+	id, err := eventId(co2Event(419.5, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^co2_weekly_mlo/20210530/[0-9a-f]{16}$`).MatchString(id) {
+		t.Errorf("Wanted the Id to name the dataset and date of the observation, Got: %v", id)
+	}
+
+	// Servers publish the same change with their own event Ids
+	event := co2Event(419.5, false)
+	event.Id++
+	if other, _ := eventId(event); other != id {
+		t.Errorf("Wanted the same change to have the same Id, Got: %v and %v", id, other)
+	}
+	for _, event := range []notify.Event{co2Event(419.5, true), co2Event(419.6, false)} {
+		if other, _ := eventId(event); other == id {
+			t.Errorf("Wanted a revised or different observation to have another Id than %v", id)
+		}
+	}
+}
+
+func TestDispatchNewData(t *testing.T) {
+	d, mock, closeDb := newTestDispatcher(t)
+	defer closeDb()
+
+	// The first attempt fails and is retried
+	rec := newReceiver(t, "s3cret", http.StatusServiceUnavailable)
+	defer rec.Close()
+
+	event := co2Event(419.5, true)
+	l := newDeliveryLog(t, mock, event, "sub1", rec.URL, "s3cret", "co2_weekly_mlo", "new_data", nil, nil, nil, true, 2, time.Now())
+	mock.ExpectQuery(regexp.QuoteMeta(selectEnabled)).WithArgs("co2_weekly_mlo").WillReturnRows(
+		sqlmock.NewRows(subscriptionRows).AddRow(l.sub...),
+	)
+	l.expectEnqueue(1)
+	d.Dispatch(context.Background(), event)
+
+	l.expectClaim(1)
+	l.expectDelivery(1, int64(503), true)
+	if err := d.Deliver(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	l.expectClaim(2)
+	l.expectDelivery(2, int64(200), false)
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE public.webhook_subscriptions SET failures = 0 WHERE id = $1`)).
+		WithArgs("sub1").WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := d.Deliver(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	if len(rec.payloads) != 2 {
+		t.Fatalf("Wanted 2 delivery attempts, Got: %v", len(rec.payloads))
+	}
+	payload := rec.payloads[1]
+	if payload.Id != l.eventId || payload.Event != NewData || payload.SubscriptionId != "sub1" || payload.Date != "2021-05-30" || !payload.Revised {
+		t.Errorf("Wanted a payload describing the revised observation, Got: %+v", payload)
+	}
+	if header := rec.headers[1]; header.Get(EventHeader) != "new_data" || header.Get(DeliveryHeader) != rec.headers[0].Get(DeliveryHeader) {
+		t.Errorf("Wanted retries to share the delivery ID, Got headers: %v then %v", rec.headers[0], header)
+	}
+}
+
+func TestDispatchDisable(t *testing.T) {
+	d, mock, closeDb := newTestDispatcher(t)
+	defer closeDb()
+	d.MaxAttempts = 3
+
+	rec := newReceiver(t, "s3cret", 500, 500, 500)
+	defer rec.Close()
+
+	event := co2Event(419.5, false)
+	l := newDeliveryLog(t, mock, event, "sub1", rec.URL, "s3cret", "co2_weekly_mlo", "new_data", nil, nil, nil, true, 4, time.Now())
+	mock.ExpectQuery(regexp.QuoteMeta(selectEnabled)).WithArgs("co2_weekly_mlo").WillReturnRows(
+		sqlmock.NewRows(subscriptionRows).AddRow(l.sub...),
+	)
+	l.expectEnqueue(1)
+	d.Dispatch(context.Background(), event)
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		l.expectClaim(attempt)
+		l.expectDelivery(attempt, int64(500), attempt < 3)
+		if attempt == 3 {
+			mock.ExpectQuery(regexp.QuoteMeta(`UPDATE public.webhook_subscriptions SET failures = failures + 1, enabled = enabled AND failures + 1 < $2 WHERE id = $1 RETURNING enabled`)).
+				WithArgs("sub1", DefaultDisableAfter).
+				WillReturnRows(sqlmock.NewRows([]string{"enabled"}).AddRow(false))
+		}
+		if err := d.Deliver(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	if len(rec.payloads) != 3 {
+		t.Errorf("Wanted 3 delivery attempts, Got: %v", len(rec.payloads))
+	}
+}
+
+func TestDeliverResumes(t *testing.T) {
+	d, mock, closeDb := newTestDispatcher(t)
+	defer closeDb()
+
+	rec := newReceiver(t, "s3cret")
+	defer rec.Close()
+
+	// An attempt queued before a restart is claimed from the delivery log, without the event being dispatched again
+	l := newDeliveryLog(t, mock, co2Event(419.5, false), "sub1", rec.URL, "s3cret", "co2_weekly_mlo", "new_data", nil, nil, nil, true, 2, time.Now())
+	l.payload.value = `{"id":"` + l.eventId + `","event":"new_data","subscriptionId":"sub1"}`
+	l.expectClaim(3)
+	l.expectDelivery(3, int64(200), false)
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE public.webhook_subscriptions SET failures = 0`)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := d.Deliver(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	if len(rec.payloads) != 1 || rec.payloads[0].Id != l.eventId || rec.headers[0].Get(DeliveryHeader) != l.eventId {
+		t.Errorf("Wanted the queued payload to be delivered, Got: %+v", rec.payloads)
+	}
+}
+
+func TestDispatchRefusesInternal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error generating mock database: %s", err.Error())
+	}
+	defer db.Close()
+	d := NewDispatcher(&database.Database{DB: db})
+	d.MaxAttempts = 1
+
+	// A subscription stored with a public name that now resolves to the loopback interface
+	rec := newReceiver(t, "s3cret")
+	defer rec.Close()
+
+	event := co2Event(419.5, false)
+	l := newDeliveryLog(t, mock, event, "sub1", rec.URL, "s3cret", "co2_weekly_mlo", "new_data", nil, nil, nil, true, 0, time.Now())
+	mock.ExpectQuery(regexp.QuoteMeta(selectEnabled)).WithArgs("co2_weekly_mlo").WillReturnRows(
+		sqlmock.NewRows(subscriptionRows).AddRow(l.sub...),
+	)
+	l.expectEnqueue(1)
+	d.Dispatch(context.Background(), event)
+
+	l.expectClaim(1)
+	l.expectDelivery(1, nil, false)
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE public.webhook_subscriptions SET failures = failures + 1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"enabled"}).AddRow(true))
+	if err := d.Deliver(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	if len(rec.payloads) != 0 {
+		t.Errorf("Wanted no delivery to a loopback address, Got: %v", len(rec.payloads))
+	}
+	if _, err := d.Client.Get("http://169.254.169.254/latest/meta-data/"); err == nil || !strings.Contains(err.Error(), "non-public address") {
+		t.Errorf("Wanted the metadata service to be refused, Got: %v", err)
+	}
+}
+
+func TestDispatchThreshold(t *testing.T) {
+	testVals := []struct {
+		name     string
+		previous interface{}
+		current  float32
+		fired    bool
+	}{
+		{"crossing", float32(424.9), 425.5, true},
+		{"already above", float32(425.2), 425.5, false},
+		{"below", float32(424.9), 424.95, false},
+		{"after a missing measurement", float32(-999.99), 425.5, true},
+	}
+
+	for _, testVal := range testVals {
+		d, mock, closeDb := newTestDispatcher(t)
+		rec := newReceiver(t, "s3cret")
+
+		event := co2Event(testVal.current, false)
+		l := newDeliveryLog(t, mock, event, "sub1", rec.URL, "s3cret", "co2_weekly_mlo", "threshold", "average", "gt", 425.0, true, 0, time.Now())
+		mock.ExpectQuery(regexp.QuoteMeta(selectEnabled)).WithArgs("co2_weekly_mlo").WillReturnRows(
+			sqlmock.NewRows(subscriptionRows).AddRow(l.sub...),
+		)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT average FROM public.co2_weekly_mlo WHERE yyyymmdd < $1 ORDER BY yyyymmdd DESC LIMIT 1`)).
+			WithArgs("2021-05-30").
+			WillReturnRows(sqlmock.NewRows([]string{"average"}).AddRow(testVal.previous))
+		if testVal.fired {
+			l.expectEnqueue(1)
+		}
+		d.Dispatch(context.Background(), event)
+
+		if testVal.fired {
+			l.expectClaim(1)
+			l.expectDelivery(1, int64(200), false)
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE public.webhook_subscriptions SET failures = 0`)).WillReturnResult(sqlmock.NewResult(0, 1))
+			if err := d.Deliver(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations %v: %s", testVal.name, err)
+		}
+		if fired := len(rec.payloads) == 1; fired != testVal.fired {
+			t.Errorf("Wanted fired=%v %v, Got: %v deliveries", testVal.fired, testVal.name, len(rec.payloads))
+		} else if fired {
+			payload := rec.payloads[0]
+			if payload.Event != Threshold || payload.Value == nil || *payload.Value != float64(testVal.current) || payload.Condition == nil || payload.Condition.Value != 425 {
+				t.Errorf("Wanted a payload with the crossed threshold and value %v, Got: %+v", testVal.name, payload)
+			}
+		}
+		rec.Close()
+		closeDb()
+	}
+}
+
+func TestDispatchThresholdRevised(t *testing.T) {
+	d, mock, closeDb := newTestDispatcher(t)
+	defer closeDb()
+
+	// Revisions never cross thresholds, so the previous observation is not read
+	mock.ExpectQuery(regexp.QuoteMeta(selectEnabled)).WithArgs("co2_weekly_mlo").WillReturnRows(
+		sqlmock.NewRows(subscriptionRows).AddRow("sub1", "http://127.0.0.1:1", "s3cret", "co2_weekly_mlo", "threshold", "average", "gt", 425.0, true, 0, time.Now()),
+	)
+	d.Dispatch(context.Background(), co2Event(425.5, true))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	d, mock, closeDb := newTestDispatcher(t)
+	defer closeDb()
+	d.PollInterval = time.Hour
+
+	rec := newReceiver(t, "s3cret")
+	defer rec.Close()
+
+	// Run first resumes the attempts left pending, which it may look for before or after the event is queued
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectQuery(regexp.QuoteMeta(claimDue)).WillReturnRows(sqlmock.NewRows(claimRows))
+
+	change := notify.Change{Dataset: "ch4_mm_gl", Date: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)}
+	l := newDeliveryLog(t, mock, notify.Event{Change: change}, "sub1", rec.URL, "s3cret", "ch4_mm_gl", "new_data", nil, nil, nil, true, 0, time.Now())
+	mock.ExpectQuery(regexp.QuoteMeta(selectEnabled)).WithArgs("ch4_mm_gl").WillReturnRows(
+		sqlmock.NewRows(subscriptionRows).AddRow(l.sub...),
+	)
+	l.expectEnqueue(1)
+	l.payload.value = `{"id":"` + l.eventId + `"}`
+	l.expectClaim(1)
+	l.expectDelivery(1, int64(200), false)
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE public.webhook_subscriptions SET failures = 0`)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	broker := notify.NewBroker(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.Run(ctx, broker) }()
+
+	// Events are only delivered once the dispatcher has subscribed to the broker
+	time.Sleep(10 * time.Millisecond)
+	broker.Publish(change)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for mock.ExpectationsWereMet() != nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Wanted Run to stop when cancelled, Got: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	if len(rec.payloads) != 1 || rec.headers[0].Get(DeliveryHeader) != l.eventId {
+		t.Errorf("Wanted the published change to be delivered once, Got: %+v", rec.payloads)
+	}
+}
@@ -1,6 +1,7 @@
 import argparse
 import os
 import pkg_resources
+from datetime import datetime
 from pyspark.sql import SparkSession
 from pyspark import SparkContext, SparkConf
 
@@ -42,6 +43,11 @@ def spark_write_to_db(source, table_name, db_user, db_psrwd, db_endpoint, aws_ac
     df = spark.read.parquet(source)
     df.write.jdbc(url=db_endpoint, table=table_name, mode=mode, properties=properties)
 
+    # Record the ingestion so the API can report how fresh each dataset is.
+    # The log is append-only, unlike the dataset tables themselves.
+    ingest_log = spark.createDataFrame([(table_name, datetime.utcnow(), df.count())], ['dataset', 'ingested_at', 'row_count'])
+    ingest_log.write.jdbc(url=db_endpoint, table='ingest_log', mode='append', properties=properties)
+
 def get_args():
     parser = argparse.ArgumentParser(description="Spark Write to DB CLI")
     parser.add_argument('--input_path', type=str, dest="input_path", help="s3 path to parquet files", required=True)
@@ -0,0 +1,6 @@
+CREATE TABLE ingest_log (
+  dataset  text NOT NULL,
+  ingested_at  timestamptz NOT NULL,
+  row_count  int NOT NULL
+);
+CREATE INDEX idx_ingest_log_dataset ON ingest_log(dataset, ingested_at);
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE OR REPLACE FUNCTION notify_ingest() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('planetpulse_ingest', NEW.dataset);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER ingest_log_notify AFTER INSERT ON ingest_log FOR EACH ROW EXECUTE PROCEDURE notify_ingest();
\ No newline at end of file
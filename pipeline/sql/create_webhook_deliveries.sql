@@ -0,0 +1,13 @@
+CREATE TABLE webhook_deliveries (
+  subscription_id  text NOT NULL,
+  event_id  text NOT NULL,
+  attempt  int NOT NULL,
+  payload  text NOT NULL,
+  status_code  int,
+  error  text,
+  next_attempt_at  timestamptz,
+  delivered_at  timestamptz,
+  PRIMARY KEY (subscription_id, event_id, attempt)
+);
+CREATE INDEX idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, delivered_at);
+CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at) WHERE delivered_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE webhook_subscriptions (
+  id  text PRIMARY KEY,
+  url  text NOT NULL,
+  secret  text NOT NULL,
+  dataset  text NOT NULL,
+  event  text NOT NULL,
+  field  text,
+  op  text,
+  threshold  double precision,
+  enabled  boolean NOT NULL DEFAULT true,
+  failures  int NOT NULL DEFAULT 0,
+  created_at  timestamptz NOT NULL
+);
+CREATE INDEX idx_webhook_subscriptions_dataset ON webhook_subscriptions(dataset) WHERE enabled;
\ No newline at end of file